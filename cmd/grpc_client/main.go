@@ -3,20 +3,26 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"time"
 
+	"todo/internal/auth"
 	"todo/internal/grpcapi"
 
+	"github.com/joho/godotenv"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 )
 
 func main() {
+	_ = godotenv.Load()
+
 	conn, err := grpc.Dial(
-    "127.0.0.1:50505",
-    grpc.WithTransportCredentials(insecure.NewCredentials()),
-)
+		"127.0.0.1:50505",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -27,6 +33,17 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	// Входим администратором из .env, дальше токен едет в метаданных каждого вызова
+	login, password, err := auth.AdminFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	loginRes, err := client.Login(ctx, &grpcapi.LoginRequest{Login: login, Password: password})
+	if err != nil {
+		log.Fatal("Login:", err)
+	}
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+loginRes.Token)
+
 	// Создание задачи
 	createRes, err := client.Create(ctx, &grpcapi.CreateTaskRequest{
 		Title:       "gRPC Example",
@@ -45,30 +62,77 @@ func main() {
 	}
 	fmt.Println("Fetched:", task)
 
-	// Обновляем
-	_, err = client.Update(ctx, &grpcapi.UpdateTaskRequest{
-		Id:       createRes.Id,
-		Status:   "done",
-		Priority: 3,
-	})
-	if err != nil {
-		log.Println("Update:", err)
+	// Обновляем: сразу из new в done нельзя, идём через in_progress
+	for _, st := range []string{"in_progress", "done"} {
+		_, err = client.Update(ctx, &grpcapi.UpdateTaskRequest{
+			Id:       createRes.Id,
+			Status:   st,
+			Priority: 3,
+		})
+		if err != nil {
+			log.Println("Update:", err)
+		}
 	}
 
-	// Получаем список
-	list, err := client.List(ctx, &grpcapi.Empty{})
-	if err != nil {
-		log.Println("List:", err)
+	// Получаем список: готовые задачи, сначала важные, по 20 штук
+	req := &grpcapi.ListTasksRequest{
+		Statuses: []string{"done"},
+		Sort:     "priority:desc,created_at",
+		PageSize: 20,
+	}
+	for {
+		list, err := client.ListTasks(ctx, req)
+		if err != nil {
+			log.Println("ListTasks:", err)
+			break
+		}
+		for _, item := range list.Items {
+			fmt.Printf("→ %v [%v]\n", item.Title, item.Status)
+		}
+		if list.NextPageToken == "" {
+			break
+		}
+		req.PageToken = list.NextPageToken
+	}
+
+	// Вложение: загружаем потоком по кускам и скачиваем обратно
+	up, err := client.UploadAttachment(ctx)
+	if err == nil {
+		err = up.Send(&grpcapi.AttachmentChunk{Id: createRes.Id, Name: "notes.txt", Data: []byte("hello, ")})
+	}
+	if err == nil {
+		err = up.Send(&grpcapi.AttachmentChunk{Data: []byte("attachment\n")})
 	}
-	for _, item := range list.Items {
-		fmt.Printf("→ %v [%v]\n", item.Title, item.Status)
+	var att *grpcapi.Attachment
+	if err == nil {
+		att, err = up.CloseAndRecv()
+	}
+	if err != nil {
+		log.Println("UploadAttachment:", err)
+	} else {
+		fmt.Printf("Attached %s (%d bytes, sha256 %s)\n", att.Name, att.Size, att.Checksum)
+		down, err := client.DownloadAttachment(ctx, &grpcapi.AttachmentRequest{Id: createRes.Id, AttachmentId: att.Id})
+		for err == nil {
+			var part *grpcapi.AttachmentData
+			if part, err = down.Recv(); err == nil {
+				fmt.Print(string(part.Data))
+			}
+		}
+		if err != io.EOF {
+			log.Println("DownloadAttachment:", err)
+		}
 	}
 
-	// Удаляем
+	// Удаляем (вложения уходят вместе с задачей)
 	_, err = client.Delete(ctx, &grpcapi.TaskID{Id: createRes.Id})
 	if err != nil {
 		log.Println("Delete:", err)
 	} else {
 		fmt.Println("Deleted", createRes.Id)
 	}
-}
\ No newline at end of file
+
+	// Выходим: refresh-токен сессии больше не сработает, access-токен отозван
+	if _, err := client.Logout(ctx, &grpcapi.Empty{}); err != nil {
+		log.Println("Logout:", err)
+	}
+}
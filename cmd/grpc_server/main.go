@@ -1,15 +1,19 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net"
+	"os"
 	"path/filepath"
 
 	"github.com/joho/godotenv"
 	"google.golang.org/grpc"
 
+	"todo/internal/auth"
 	"todo/internal/grpcapi"
 	"todo/internal/grpcserver"
+	"todo/internal/model"
 	"todo/internal/repository"
 	"todo/internal/service"
 )
@@ -17,16 +21,68 @@ import (
 func main() {
 	_ = godotenv.Load()
 
+	keys, err := auth.KeysFromEnv()
+	if err != nil {
+		log.Fatalf("JWT_SECRET: %v", err)
+	}
+
 	// Всегда смотрим относительно корня проекта
 	dataPath := filepath.Join("cmd", "data", "tasks.json")
 
 	log.Println("[DATA PATH]:", dataPath)
 
-	store := repository.NewJSONStore("cmd/data/tasks.json")
-	svc, err := service.New(store)
-	if err != nil {
+	var opts []service.Option
+	if path := os.Getenv("TRANSITIONS_FILE"); path != "" {
+		tr, err := model.LoadTransitions(path)
+		if err != nil {
+			log.Fatalf("transitions: %v", err)
+		}
+		opts = append(opts, service.WithTransitions(tr))
+	}
+	var blobs service.BlobStore = repository.NewFSBlobs(filepath.Join("cmd", "data", "blobs"))
+	if s3, err := repository.S3BlobsFromEnv(context.Background()); err != nil {
+		log.Fatalf("s3: %v", err)
+	} else if s3 != nil {
+		blobs = s3
+	}
+	opts = append(opts, service.WithBlobs(blobs))
+
+	tenants := service.NewTenants(func(t model.TenantID) (service.ProjectStore, func(model.ProjectID) service.Store) {
+		tasksPath := repository.JSONTenantPath(dataPath, t)
+		return repository.NewJSONProjectStore(repository.JSONTenantPath("cmd/data/projects.json", t)),
+			func(p model.ProjectID) service.Store {
+				return repository.NewJSONStore(repository.JSONProjectPath(tasksPath, p))
+			}
+	}, opts...)
+	if _, err := tenants.Tenant(context.Background(), model.DefaultTenant); err != nil {
 		log.Fatalf("service init error: %v", err)
 	}
+	// фоновая очистка и автоархив идут по всем арендаторам, у кого есть пользователи
+	userStore := repository.NewJSONUserStore("cmd/data/users.json")
+	tenants.ListFrom(userStore)
+	retention, err := service.TrashRetentionFromEnv()
+	if err != nil {
+		log.Fatalf("TRASH_RETENTION: %v", err)
+	}
+	go service.PurgeTrashPeriodically(context.Background(), tenants, retention)
+	archiveAfter, err := service.ArchiveAfterFromEnv()
+	if err != nil {
+		log.Fatalf("ARCHIVE_AFTER: %v", err)
+	}
+	go service.ArchiveDonePeriodically(context.Background(), tenants, archiveAfter)
+
+	users := auth.NewUsers(userStore)
+	login, password, err := auth.AdminFromEnv()
+	if err != nil {
+		log.Fatalf("admin: %v", err)
+	}
+	if created, err := users.Bootstrap(context.Background(), login, password); err != nil {
+		log.Fatalf("bootstrap admin: %v", err)
+	} else if created {
+		log.Println("[gRPC] created admin", login)
+	}
+	sessions := auth.NewSessions(users, auth.NewTokens(keys, auth.AccessTTL), repository.NewMemorySessionStore(), auth.SessionTTL)
+	apiKeys := auth.NewAPIKeys(users, repository.NewJSONAPIKeyStore("cmd/data/api_keys.json"))
 
 	addr := "127.0.0.1:50505"
 	lis, err := net.Listen("tcp", addr)
@@ -34,11 +90,14 @@ func main() {
 		log.Fatalf("listen %s: %v", addr, err)
 	}
 
-	s := grpc.NewServer()
-	grpcapi.RegisterTodoServiceServer(s, grpcserver.New(svc))
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(grpcserver.TraceInterceptor, grpcserver.AuthInterceptor(sessions, apiKeys)),
+		grpc.ChainStreamInterceptor(grpcserver.StreamTraceInterceptor, grpcserver.StreamAuthInterceptor(sessions, apiKeys)),
+	)
+	grpcapi.RegisterTodoServiceServer(s, grpcserver.New(tenants, users, sessions))
 
 	log.Println("[gRPC] listening on", addr)
 	if err := s.Serve(lis); err != nil {
 		log.Fatalf("serve error: %v", err)
 	}
-}
\ No newline at end of file
+}
@@ -10,27 +10,59 @@ package main
 
 import (
 	"bufio"
-	"fmt"
 	"context"
-	"os/signal"
-	"sync"
-	"syscall"
+	"fmt"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
+	"github.com/joho/godotenv"
+	"todo/internal/audit"
+	"todo/internal/auth"
 	"todo/internal/model"
-	"todo/internal/service"
 	"todo/internal/repository"
-	"todo/internal/audit"
+	"todo/internal/reqctx"
+	"todo/internal/service"
 	"todo/internal/web"
-	"github.com/joho/godotenv"
 )
 
 func main() {
 	_ = godotenv.Load()
 
+	// Без своего ключа подписи не стартуем: иначе токен выпишет кто угодно
+	keys, err := auth.KeysFromEnv()
+	if err != nil {
+		fmt.Println("JWT_SECRET error:", err)
+		os.Exit(1)
+	}
+
+	// Своя таблица переходов статусов (необязательно)
+	var opts []service.Option
+	if path := os.Getenv("TRANSITIONS_FILE"); path != "" {
+		tr, err := model.LoadTransitions(path)
+		if err != nil {
+			fmt.Println("transitions error:", err)
+			os.Exit(1)
+		}
+		opts = append(opts, service.WithTransitions(tr))
+		fmt.Println("✓ Таблица переходов статусов:", path)
+	}
+
+	// Вложения: S3 (MinIO), если задан S3_BUCKET, иначе локальный каталог
+	var blobs service.BlobStore = repository.NewFSBlobs("cmd/data/blobs")
+	if s3, err := repository.S3BlobsFromEnv(context.Background()); err != nil {
+		fmt.Println("S3 error:", err)
+		os.Exit(1)
+	} else if s3 != nil {
+		blobs = s3
+		fmt.Println("✓ Вложения в S3:", os.Getenv("S3_BUCKET"))
+	}
+	opts = append(opts, service.WithBlobs(blobs))
+
 	// PostgreSQL
 	pgConn := os.Getenv("POSTGRES_CONN")
 	if pgConn == "" {
@@ -38,27 +70,35 @@ func main() {
 	}
 	pgStore, err := repository.NewPostgresStore(pgConn)
 	if err == nil {
-		svc, err := service.New(pgStore)
-		if err == nil {
+		tenants := service.NewTenants(func(t model.TenantID) (service.ProjectStore, func(model.ProjectID) service.Store) {
+			ts := pgStore.Tenant(t)
+			return ts.Projects(), func(p model.ProjectID) service.Store { return ts.Project(p) }
+		}, opts...)
+		// общий арендатор поднимаем сразу: заодно проверка, что миграции на месте
+		if _, err = tenants.Tenant(context.Background(), model.DefaultTenant); err == nil {
 			fmt.Println("✓ PostgreSQL в работе:", pgConn)
-			runApp(svc)
+			runApp(tenants, pgStore.Users(), pgStore.Sessions(), pgStore.APIKeys(), keys)
 			return
 		}
 	}
 	fmt.Println("⚠ PostgreSQL connection failed:", err)
 
 	// MongoDB
-	mongoURI := "mongodb://127.0.0.1:27017"
+	mongoURI := "mongodb://127.0.0.1:27017/?directConnection=true"
 	mongoStore, err := repository.NewMongoStore(mongoURI, "todo_db", "tasks")
 	if err == nil {
 		fmt.Println("✓ MongoDB подключена:", mongoURI)
 		redisLogger := audit.NewRedisLogger("127.0.0.1:6379", "", 0, 24*time.Hour, "audit")
+		sessions := repository.NewRedisSessionStore("127.0.0.1:6379", "", 0, "auth")
 		service.Logger = redisLogger
 		fmt.Println("✓ Redis подключен: 127.0.0.1:6379 (TTL: 24h)")
 
-		svc, err := service.New(mongoStore)
-		if err == nil {
-			runApp(svc)
+		tenants := service.NewTenants(func(t model.TenantID) (service.ProjectStore, func(model.ProjectID) service.Store) {
+			ts := mongoStore.Tenant(t)
+			return ts.Projects(), func(p model.ProjectID) service.Store { return ts.Project(p) }
+		}, opts...)
+		if _, err = tenants.Tenant(context.Background(), model.DefaultTenant); err == nil {
+			runApp(tenants, mongoStore.Users(), sessions, mongoStore.APIKeys(), keys)
 			return
 		}
 	}
@@ -68,22 +108,83 @@ func main() {
 	fmt.Println("⚠ Fallback к JSON‑хранилищу…")
 
 	storePath := "cmd/data/tasks.json"
-	svc, err := service.New(repository.NewJSONStore(storePath))
-	if err != nil {
+	// у каждого проекта свой файл задач рядом с общим (repository.JSONProjectPath),
+	// у каждого арендатора — свой каталог (repository.JSONTenantPath)
+	tenants := service.NewTenants(func(t model.TenantID) (service.ProjectStore, func(model.ProjectID) service.Store) {
+		tasksPath := repository.JSONTenantPath(storePath, t)
+		return repository.NewJSONProjectStore(repository.JSONTenantPath("cmd/data/projects.json", t)),
+			func(p model.ProjectID) service.Store {
+				return repository.NewJSONStore(repository.JSONProjectPath(tasksPath, p))
+			}
+	}, opts...)
+	if _, err := tenants.Tenant(context.Background(), model.DefaultTenant); err != nil {
 		fmt.Println("init error:", err)
 		os.Exit(1)
 	}
 	fmt.Println("✓ Использую JSON‑хранилище:", storePath)
-	runApp(svc)
+	// сессии в памяти: после перезапуска все входят заново
+	runApp(tenants, repository.NewJSONUserStore("cmd/data/users.json"), repository.NewMemorySessionStore(),
+		repository.NewJSONAPIKeyStore("cmd/data/api_keys.json"), keys)
+}
+
+// bootstrapAdmin — первый администратор из окружения (см. auth.AdminFromEnv),
+// если пользователей ещё нет. Слабый пароль в окружении или пустая база без учётки — ошибка:
+// с такими не стартуем
+func bootstrapAdmin(ctx context.Context, users *auth.Users) error {
+	login, password, err := auth.AdminFromEnv()
+	if err != nil {
+		return err
+	}
+	created, err := users.Bootstrap(ctx, login, password)
+	if err != nil {
+		return err
+	}
+	if created {
+		fmt.Println("✓ Создан администратор:", login)
+	}
+	return nil
 }
-	
 
-func runApp(svc *service.Service) {
+func runApp(tenants *service.Tenants, userStore auth.UserStore, sessionStore auth.SessionStore, keyStore auth.APIKeyStore, keys *auth.Keyring) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	retention, err := service.TrashRetentionFromEnv()
+	if err != nil {
+		fmt.Println("TRASH_RETENTION error:", err)
+		return
+	}
+	archiveAfter, err := service.ArchiveAfterFromEnv()
+	if err != nil {
+		fmt.Println("ARCHIVE_AFTER error:", err)
+		return
+	}
+
+	// фоновая очистка и автоархив идут по всем арендаторам, у кого есть пользователи
+	if l, ok := userStore.(service.TenantLister); ok {
+		tenants.ListFrom(l)
+	}
+	users := auth.NewUsers(userStore)
+	if err := bootstrapAdmin(ctx, users); err != nil {
+		fmt.Println("Ошибка администратора:", err)
+		return
+	}
+	sessions := auth.NewSessions(users, auth.NewTokens(keys, auth.AccessTTL), sessionStore, auth.SessionTTL)
+
+	// консоль работает с общим проектом общего арендатора, остальные — через API
+	projects, err := tenants.Tenant(ctx, model.DefaultTenant)
+	if err != nil {
+		fmt.Println("Ошибка инициализации проекта по умолчанию:", err)
+		return
+	}
+	svc, err := projects.Tasks(ctx, model.DefaultProject)
+	if err != nil {
+		fmt.Println("Ошибка инициализации проекта по умолчанию:", err)
+		return
+	}
+
 	go func() {
-		webServer := web.New(svc)
+		webServer := web.New(tenants, users, sessions, auth.NewAPIKeys(users, keyStore))
 		if err := webServer.Start(8080); err != nil {
 			fmt.Println("web server error:", err)
 			cancel()
@@ -100,7 +201,7 @@ func runApp(svc *service.Service) {
 	}()
 
 	var wg sync.WaitGroup
-	wg.Add(4)
+	wg.Add(6)
 
 	// Старый авто-дистрибьютор
 	go func() {
@@ -123,6 +224,16 @@ func runApp(svc *service.Service) {
 		defer wg.Done()
 		service.LogTaskAdditions(200*time.Millisecond, ctx)
 	}()
+	// Корзина: старое удалённое стираем насовсем
+	go func() {
+		defer wg.Done()
+		service.PurgeTrashPeriodically(ctx, tenants, retention)
+	}()
+	// Архив: давно сделанное убираем из горячих задач
+	go func() {
+		defer wg.Done()
+		service.ArchiveDonePeriodically(ctx, tenants, archiveAfter)
+	}()
 
 	in := bufio.NewScanner(os.Stdin)
 
@@ -144,12 +255,27 @@ func runApp(svc *service.Service) {
 		fmt.Println("2)  Список всех задач")
 		fmt.Println("3)  Список по статусу")
 		fmt.Println("11) Показать задачу")
+		fmt.Println("14) Поиск по тексту")
+		fmt.Println("21) Список по тегам")
 		fmt.Println()
 		fmt.Println("4)  Обновить заголовок/описание")
 		fmt.Println("5)  Поменять статус")
 		fmt.Println("6)  Поменять приоритет")
 		fmt.Println("7)  Установить/очистить срок (Due)")
+		fmt.Println("19) Повторение задачи (RRULE)")
+		fmt.Println("20) Теги: добавить/снять")
+		fmt.Println("22) Назначить исполнителя")
+		fmt.Println("23) Комментировать задачу")
+		fmt.Println("24) Чек-лист задачи")
 		fmt.Println("8)  Удалить задачу")
+		fmt.Println("25) Корзина: вернуть/очистить")
+		fmt.Println("26) Архив: убрать/вернуть")
+		fmt.Println("27) Отменить последнее действие")
+		fmt.Println("28) Повторить отменённое")
+		fmt.Println("15) Перенести задачу под другую (подзадачи)")
+		fmt.Println("16) Зависимости: добавить/снять блокер")
+		fmt.Println("17) Готовые к работе (блокеры сделаны)")
+		fmt.Println("18) Порядок выполнения")
 		fmt.Println()
 		fmt.Println(" Расширенные служебные функции:")
 		fmt.Println("10) Перенумеровать ID (1..N)")
@@ -160,29 +286,31 @@ func runApp(svc *service.Service) {
 		fmt.Print("Выбор: ")
 
 		choice := readLine(in)
+		// пункт меню — одно действие: свой trace id в аудите и один шаг для отмены (27)
+		ctx := reqctx.WithRequest(reqctx.WithTraceID(ctx, reqctx.NewTraceID()))
 		switch choice {
 
 		case "1":
-			handleAdd(in, svc)
+			handleAdd(ctx, in, svc)
 		case "2":
-			printTasks(svc.List(nil))
+			printQuery(ctx, svc, model.TaskQuery{})
 		case "3":
 			st, ok := askStatus(in)
 			if !ok {
 				fmt.Println("отмена")
 				continue
 			}
-			printTasks(svc.List(&st))
+			printQuery(ctx, svc, model.TaskQuery{Statuses: []model.Status{st}})
 		case "4":
-			handleUpdateText(in, svc)
+			handleUpdateText(ctx, in, svc)
 		case "5":
-			handleStatus(in, svc)
+			handleStatus(ctx, in, svc)
 		case "6":
-			handlePriority(in, svc)
+			handlePriority(ctx, in, svc)
 		case "7":
-			handleDue(in, svc)
+			handleDue(ctx, in, svc)
 		case "8":
-			handleDelete(in, svc)
+			handleDelete(ctx, in, svc)
 		case "9":
 			cancel()
 			wg.Wait()
@@ -195,36 +323,75 @@ func runApp(svc *service.Service) {
 				fmt.Println("отмена")
 				break
 			}
-			if err := svc.RenumberIDs(); err != nil {
+			if err := svc.RenumberIDs(ctx); err != nil {
 				fmt.Println("ошибка:", err)
 			} else {
 				fmt.Println("OK: ID перенумерованы")
-				printTasks(svc.List(nil))
+				printQuery(ctx, svc, model.TaskQuery{})
 			}
 		case "11":
 			id, ok := askID(in)
 			if !ok {
 				break
 			}
-			var found *model.Task
-			for _, t := range svc.List(nil) {
-				if t.ID() == id {
-					found = t
-					break
-				}
-			}
-			if found == nil {
+			found, err := svc.Get(ctx, id)
+			if err != nil {
 				fmt.Println("не найдено")
 				break
 			}
 			printTaskDetails(found)
+			printTreeInfo(ctx, svc, found.ID())
+			printAttachments(ctx, svc, found.ID())
+			printComments(ctx, svc, found.ID())
+		case "14":
+			handleSearch(ctx, in, svc)
+		case "15":
+			handleMove(ctx, in, svc)
+		case "16":
+			handleDependency(ctx, in, svc)
+		case "19":
+			handleRecurrence(ctx, in, svc)
+		case "20":
+			handleTag(ctx, in, svc)
+		case "21":
+			handleListByTags(ctx, in, svc)
+		case "22":
+			handleAssign(ctx, in, svc, users)
+		case "23":
+			handleComment(ctx, in, svc)
+		case "24":
+			handleChecklist(ctx, in, svc)
+		case "25":
+			handleTrash(ctx, in, svc)
+		case "26":
+			handleArchive(ctx, in, svc)
+		case "27":
+			printReplay(svc.Undo(ctx, 1))
+		case "28":
+			printReplay(svc.Redo(ctx, 1))
+		case "17":
+			list, err := svc.Ready(ctx)
+			if err != nil {
+				fmt.Println("ошибка:", err)
+				break
+			}
+			printTasks(list)
+		case "18":
+			list, err := svc.TopoOrder(ctx)
+			if err != nil {
+				fmt.Println("ошибка:", err)
+				break
+			}
+			for i, t := range list {
+				fmt.Printf("%d. #%d %s [%s]\n", i+1, t.ID(), t.Title(), prioText(t.Priority()))
+			}
 		case "12":
 			fmt.Println("= низкий приоритет =")
-			printTasks(repository.LowPriorityTasks)
+			printTasks(repository.Distributed(model.PriorityLow))
 			fmt.Println("= средний приоритет =")
-			printTasks(repository.MediumPriorityTasks)
+			printTasks(repository.Distributed(model.PriorityMedium))
 			fmt.Println("= высокий приоритет =")
-			printTasks(repository.HighPriorityTasks)
+			printTasks(repository.Distributed(model.PriorityHigh))
 		case "13":
 			service.DebugMode = !service.DebugMode
 			if service.DebugMode {
@@ -249,13 +416,30 @@ func printTaskDetails(t *model.Task) {
 	fmt.Printf("Priority: %s\n", prioText(t.Priority()))
 	fmt.Printf("Created: %s\n", t.CreatedAt().Format("2006-01-02 15:04"))
 	fmt.Printf("Updated: %s\n", t.UpdatedAt().Format("2006-01-02 15:04"))
+	fmt.Printf("Version: %d\n", t.Version())
 	fmt.Printf("Due: %s\n", due)
+	if b := t.BlockedBy(); len(b) > 0 {
+		fmt.Printf("Blocked by: %v\n", b)
+	}
+	if r := t.Recurrence(); r != nil {
+		fmt.Printf("Repeats: %s\n", r)
+	}
+	if tags := t.Tags(); len(tags) > 0 {
+		fmt.Printf("Tags: %s\n", strings.Join(tags, ", "))
+	}
+	if u := t.CreatedBy(); u != 0 {
+		fmt.Printf("Creator: user #%d\n", u)
+	}
+	if u := t.Assignee(); u != 0 {
+		fmt.Printf("Assignee: user #%d\n", u)
+	}
+	printChecklist(t)
 	fmt.Println("Description:")
 	fmt.Println(t.Description())
 }
 
 // Создает новую задачу через консоль
-func handleAdd(in *bufio.Scanner, svc *service.Service) {
+func handleAdd(ctx context.Context, in *bufio.Scanner, svc *service.Service) {
 	fmt.Print("Заголовок: ")
 	title := strings.TrimSpace(readLine(in))
 	if title == "" {
@@ -277,7 +461,24 @@ func handleAdd(in *bufio.Scanner, svc *service.Service) {
 		}
 	}
 
-	id, err := svc.Add(title, desc, p, due)
+	var parent model.ID
+	fmt.Print("ID родительской задачи (пусто - верхний уровень): ")
+	if s := strings.TrimSpace(readLine(in)); s != "" {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			fmt.Println("не число")
+			return
+		}
+		parent = model.ID(v)
+	}
+
+	var id model.ID
+	var err error
+	if parent != 0 {
+		id, err = svc.AddSubtask(ctx, parent, title, desc, p, due)
+	} else {
+		id, err = svc.Add(ctx, title, desc, p, due)
+	}
 	if err != nil {
 		fmt.Println("ошибка добавления:", err)
 		return
@@ -285,32 +486,36 @@ func handleAdd(in *bufio.Scanner, svc *service.Service) {
 	fmt.Println("OK, id =", id)
 }
 
-func handleUpdateText(in *bufio.Scanner, svc *service.Service) {
+func handleUpdateText(ctx context.Context, in *bufio.Scanner, svc *service.Service) {
 	id, ok := askID(in)
 	if !ok {
 		return
 	}
 	fmt.Print("Новый заголовок (пусто - пропустить): ")
 	title := strings.TrimSpace(readLine(in))
-	fmt.Print("Новое описание (пусто - пропустить): ")
+	fmt.Print("Новое описание (пусто - пропустить, \"-\" - очистить): ")
 	desc := strings.TrimSpace(readLine(in))
 
+	// заголовок и описание меняются вместе: ошибка в одном не оставит половину правки
+	var p model.TaskPatch
 	if title != "" {
-		if err := svc.UpdateTitle(id, title); err != nil {
-			fmt.Println("ошибка:", err)
-			return
-		}
+		p.Title = &title
 	}
-	if desc != "" {
-		if err := svc.UpdateDesc(id, desc); err != nil {
-			fmt.Println("ошибка:", err)
-			return
-		}
+	switch desc {
+	case "":
+	case "-":
+		p.Description = new(string)
+	default:
+		p.Description = &desc
+	}
+	if err := svc.Patch(ctx, id, p); err != nil {
+		fmt.Println("ошибка:", err)
+		return
 	}
 	fmt.Println("OK")
 }
 
-func handleStatus(in *bufio.Scanner, svc *service.Service) {
+func handleStatus(ctx context.Context, in *bufio.Scanner, svc *service.Service) {
 	id, ok := askID(in)
 	if !ok {
 		return
@@ -319,27 +524,27 @@ func handleStatus(in *bufio.Scanner, svc *service.Service) {
 	if !ok {
 		return
 	}
-	if err := svc.SetStatus(id, st); err != nil {
+	if err := svc.SetStatus(ctx, id, st); err != nil {
 		fmt.Println("ошибка:", err)
 		return
 	}
 	fmt.Println("OK")
 }
 
-func handlePriority(in *bufio.Scanner, svc *service.Service) {
+func handlePriority(ctx context.Context, in *bufio.Scanner, svc *service.Service) {
 	id, ok := askID(in)
 	if !ok {
 		return
 	}
 	p := askPriority(in)
-	if err := svc.SetPriority(id, p); err != nil {
+	if err := svc.SetPriority(ctx, id, p); err != nil {
 		fmt.Println("ошибка:", err)
 		return
 	}
 	fmt.Println("OK")
 }
 
-func handleDue(in *bufio.Scanner, svc *service.Service) {
+func handleDue(ctx context.Context, in *bufio.Scanner, svc *service.Service) {
 	id, ok := askID(in)
 	if !ok {
 		return
@@ -347,7 +552,7 @@ func handleDue(in *bufio.Scanner, svc *service.Service) {
 	fmt.Print("Дата (DD-MM-YYYY) или пусто для очистки: ")
 	raw := strings.TrimSpace(readLine(in))
 	if raw == "" {
-		if err := svc.ClearDue(id); err != nil {
+		if err := svc.ClearDue(ctx, id); err != nil {
 			fmt.Println("ошибка:", err)
 		} else {
 			fmt.Println("OK (очищено)")
@@ -359,23 +564,407 @@ func handleDue(in *bufio.Scanner, svc *service.Service) {
 		fmt.Println("дата некорректна:", err)
 		return
 	}
-	if err := svc.SetDue(id, d); err != nil {
+	if err := svc.SetDue(ctx, id, d); err != nil {
+		fmt.Println("ошибка:", err)
+		return
+	}
+	fmt.Println("OK")
+}
+
+func handleDelete(ctx context.Context, in *bufio.Scanner, svc *service.Service) {
+	id, ok := askID(in)
+	if !ok {
+		return
+	}
+	del := svc.Delete
+	if kids, _ := svc.Children(ctx, id); len(kids) > 0 {
+		fmt.Printf("У задачи %d подзадач(и). Удалить вместе с ними? (y/N, иначе поднимутся на уровень выше): ", len(kids))
+		ans := strings.ToLower(strings.TrimSpace(readLine(in)))
+		if ans == "y" || ans == "yes" {
+			del = svc.DeleteTree
+		}
+	}
+	if err := del(ctx, id); err != nil {
+		fmt.Println("ошибка:", err)
+		return
+	}
+	fmt.Println("OK (в корзине)")
+}
+
+func handleTrash(ctx context.Context, in *bufio.Scanner, svc *service.Service) {
+	list, err := svc.Trash(ctx)
+	if err != nil {
+		fmt.Println("ошибка:", err)
+		return
+	}
+	if len(list) == 0 {
+		fmt.Println("корзина пуста")
+		return
+	}
+	for _, t := range list {
+		fmt.Printf("#%d %s (удалена %s)\n", t.ID(), t.Title(), t.DeletedAt().Local().Format("2006-01-02 15:04"))
+	}
+	fmt.Print("ID - вернуть задачу, \"очистить\" - стереть всё насовсем, пусто - назад: ")
+	raw := strings.ToLower(strings.TrimSpace(readLine(in)))
+	switch raw {
+	case "":
+		return
+	case "очистить":
+		n, err := svc.PurgeTrash(ctx, time.Now())
+		if err != nil {
+			fmt.Println("ошибка:", err)
+			return
+		}
+		fmt.Printf("OK: стёрто %d\n", n)
+		return
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 {
+		fmt.Println("некорректный ID")
+		return
+	}
+	if err := svc.Restore(ctx, model.ID(id)); err != nil {
+		fmt.Println("ошибка:", err)
+		return
+	}
+	fmt.Println("OK (восстановлено)")
+}
+
+func handleArchive(ctx context.Context, in *bufio.Scanner, svc *service.Service) {
+	page, err := svc.Archived(ctx, model.TaskQuery{Limit: 50})
+	if err != nil {
+		fmt.Println("ошибка:", err)
+		return
+	}
+	if len(page.Items) == 0 {
+		fmt.Println("архив пуст")
+	}
+	for _, t := range page.Items {
+		fmt.Printf("#%d %s [%s] (в архиве с %s)\n", t.ID(), t.Title(), t.Status(), t.ArchivedAt().Local().Format("2006-01-02 15:04"))
+	}
+	if page.NextCursor != "" {
+		fmt.Println("... показаны первые 50")
+	}
+	fmt.Print("+ID - убрать задачу в архив, ID - вернуть из архива, пусто - назад: ")
+	raw := strings.TrimSpace(readLine(in))
+	if raw == "" {
+		return
+	}
+	raw, toArchive := strings.CutPrefix(raw, "+")
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 {
+		fmt.Println("некорректный ID")
+		return
+	}
+	if toArchive {
+		if err := svc.Archive(ctx, model.ID(id)); err != nil {
+			fmt.Println("ошибка:", err)
+			return
+		}
+		fmt.Println("OK (в архиве)")
+		return
+	}
+	if err := svc.Unarchive(ctx, model.ID(id)); err != nil {
+		fmt.Println("ошибка:", err)
+		return
+	}
+	fmt.Println("OK (возвращена)")
+}
+
+func printReplay(steps []service.UndoStep, err error) {
+	for _, st := range steps {
+		fmt.Printf("OK: %s от %s", strings.Join(st.Ops, ", "), st.At.Local().Format("2006-01-02 15:04"))
+		if len(st.TaskIDs) > 0 {
+			fmt.Printf(", задачи %v", st.TaskIDs)
+		}
+		fmt.Println()
+	}
+	if err != nil {
+		fmt.Println("ошибка:", err)
+	}
+}
+
+// подсветку из фрагмента в консоли показываем скобками
+var consoleHighlight = strings.NewReplacer(model.HighlightStart, "[", model.HighlightStop, "]")
+
+func handleSearch(ctx context.Context, in *bufio.Scanner, svc *service.Service) {
+	fmt.Print("Что ищем: ")
+	query := strings.TrimSpace(readLine(in))
+	if query == "" {
+		fmt.Println("отмена")
+		return
+	}
+	hits, err := svc.Search(ctx, query, 0)
+	if err != nil {
+		fmt.Println("ошибка:", err)
+		return
+	}
+	if len(hits) == 0 {
+		fmt.Println("ничего не найдено")
+		return
+	}
+	for _, h := range hits {
+		fmt.Printf("#%d [%.2f] %s\n", h.Task.ID, h.Rank, consoleHighlight.Replace(h.Snippet))
+	}
+}
+
+func handleMove(ctx context.Context, in *bufio.Scanner, svc *service.Service) {
+	id, ok := askID(in)
+	if !ok {
+		return
+	}
+	fmt.Print("Новый родитель (ID, 0 - верхний уровень): ")
+	v, err := strconv.ParseInt(strings.TrimSpace(readLine(in)), 10, 64)
+	if err != nil {
+		fmt.Println("не число")
+		return
+	}
+	if err := svc.SetParent(ctx, id, model.ID(v)); err != nil {
 		fmt.Println("ошибка:", err)
 		return
 	}
 	fmt.Println("OK")
 }
 
-func handleDelete(in *bufio.Scanner, svc *service.Service) {
+// консоль работает без пользователя и видит все задачи, поэтому назначать может любому
+func handleAssign(ctx context.Context, in *bufio.Scanner, svc *service.Service, users *auth.Users) {
 	id, ok := askID(in)
 	if !ok {
 		return
 	}
-	if err := svc.Delete(id); err != nil {
+	fmt.Print("Исполнитель (ID пользователя, 0 - снять): ")
+	v, err := strconv.ParseInt(strings.TrimSpace(readLine(in)), 10, 64)
+	if err != nil || v < 0 {
+		fmt.Println("не число")
+		return
+	}
+	if v != 0 {
+		if _, err := users.Get(ctx, model.UserID(v)); err != nil {
+			fmt.Println("ошибка:", err)
+			return
+		}
+	}
+	if err := svc.Assign(ctx, id, model.UserID(v)); err != nil {
 		fmt.Println("ошибка:", err)
 		return
 	}
-	fmt.Println("OK (удалено)")
+	fmt.Println("OK")
+}
+
+func handleRecurrence(ctx context.Context, in *bufio.Scanner, svc *service.Service) {
+	id, ok := askID(in)
+	if !ok {
+		return
+	}
+	fmt.Println("Правило, например FREQ=DAILY, FREQ=WEEKLY;BYDAY=MO,TH, FREQ=MONTHLY;COUNT=12")
+	fmt.Print("Правило (пусто - убрать повторение): ")
+	raw := strings.TrimSpace(readLine(in))
+	var rec *model.Recurrence
+	if raw != "" {
+		var err error
+		if rec, err = model.ParseRecurrence(raw); err != nil {
+			fmt.Println("ошибка:", err)
+			return
+		}
+	}
+	if err := svc.SetRecurrence(ctx, id, rec); err != nil {
+		fmt.Println("ошибка:", err)
+		return
+	}
+	fmt.Println("OK")
+}
+
+func handleTag(ctx context.Context, in *bufio.Scanner, svc *service.Service) {
+	id, ok := askID(in)
+	if !ok {
+		return
+	}
+	fmt.Print("Тег (с минусом в начале - снять): ")
+	raw := strings.TrimSpace(readLine(in))
+	var err error
+	if tag, ok := strings.CutPrefix(raw, "-"); ok {
+		err = svc.RemoveTag(ctx, id, tag)
+	} else {
+		err = svc.AddTag(ctx, id, raw)
+	}
+	if err != nil {
+		fmt.Println("ошибка:", err)
+		return
+	}
+	fmt.Println("OK")
+}
+
+// список по тегам: через запятую — нужны все, через | — хотя бы один
+func handleListByTags(ctx context.Context, in *bufio.Scanner, svc *service.Service) {
+	if counts, _ := svc.TagCounts(ctx); len(counts) > 0 {
+		fmt.Print("Теги:")
+		for _, c := range counts {
+			fmt.Printf(" %s(%d)", c.Tag, c.Count)
+		}
+		fmt.Println()
+	}
+	fmt.Print("Теги (a,b - все сразу; a|b - любой): ")
+	raw := strings.TrimSpace(readLine(in))
+	if raw == "" {
+		fmt.Println("отмена")
+		return
+	}
+	var q model.TaskQuery
+	var err error
+	if strings.Contains(raw, "|") {
+		q.AnyTags, err = model.NormalizeTags(strings.Split(raw, "|"))
+	} else {
+		q.Tags, err = model.NormalizeTags(strings.Split(raw, ","))
+	}
+	if err != nil {
+		fmt.Println("ошибка:", err)
+		return
+	}
+	printQuery(ctx, svc, q)
+}
+
+func handleDependency(ctx context.Context, in *bufio.Scanner, svc *service.Service) {
+	id, ok := askID(in)
+	if !ok {
+		return
+	}
+	fmt.Print("Блокер (ID задачи, которую надо сделать раньше; с минусом - снять): ")
+	raw := strings.TrimSpace(readLine(in))
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || v == 0 {
+		fmt.Println("не число")
+		return
+	}
+	if v < 0 {
+		err = svc.RemoveDependency(ctx, id, model.ID(-v))
+	} else {
+		err = svc.AddDependency(ctx, id, model.ID(v))
+	}
+	if err != nil {
+		fmt.Println("ошибка:", err)
+		return
+	}
+	fmt.Println("OK")
+}
+
+func printChecklist(t *model.Task) {
+	items := t.Checklist()
+	if len(items) == 0 {
+		return
+	}
+	p := t.ChecklistProgress()
+	fmt.Printf("Checklist: %d/%d (%d%%)\n", p.Done, p.Total, p.Percent())
+	for _, it := range items {
+		mark := " "
+		if it.Done {
+			mark = "x"
+		}
+		fmt.Printf("  [%s] #%d %s\n", mark, it.ID, it.Text)
+	}
+}
+
+// чек-лист одной строкой: текст - добавить, +N - отметить/снять, -N - убрать, N>M - поставить на место M
+func handleChecklist(ctx context.Context, in *bufio.Scanner, svc *service.Service) {
+	id, ok := askID(in)
+	if !ok {
+		return
+	}
+	t, err := svc.Get(ctx, id)
+	if err != nil {
+		fmt.Println("не найдено")
+		return
+	}
+	printChecklist(t)
+	fmt.Print("Пункт (текст - добавить; +N - отметить/снять; -N - убрать; N>M - на место M): ")
+	raw := strings.TrimSpace(readLine(in))
+	num := func(s string) int {
+		v, _ := strconv.Atoi(strings.TrimSpace(s))
+		return v
+	}
+	from, to, move := strings.Cut(raw, ">")
+	switch {
+	case raw == "":
+		fmt.Println("отмена")
+		return
+	case move && num(from) > 0 && num(to) > 0:
+		err = svc.MoveChecklistItem(ctx, id, num(from), num(to)-1)
+	case strings.HasPrefix(raw, "+") && num(raw[1:]) > 0:
+		_, err = svc.ToggleChecklistItem(ctx, id, num(raw[1:]))
+	case strings.HasPrefix(raw, "-") && num(raw[1:]) > 0:
+		err = svc.RemoveChecklistItem(ctx, id, num(raw[1:]))
+	default:
+		_, err = svc.AddChecklistItem(ctx, id, raw)
+	}
+	if err != nil {
+		fmt.Println("ошибка:", err)
+		return
+	}
+	if t, err := svc.Get(ctx, id); err == nil {
+		printChecklist(t)
+	}
+}
+
+// путь от корня и прогресс подзадач
+func printTreeInfo(ctx context.Context, svc *service.Service, id model.ID) {
+	if anc, _ := svc.Ancestors(ctx, id); len(anc) > 0 {
+		path := make([]string, 0, len(anc))
+		for i := len(anc) - 1; i >= 0; i-- {
+			path = append(path, fmt.Sprintf("#%d %s", anc[i].ID(), anc[i].Title()))
+		}
+		fmt.Println("Parent:", strings.Join(path, " / "))
+	}
+	if kids, _ := svc.Children(ctx, id); len(kids) > 0 {
+		p, _ := svc.Progress(ctx, id)
+		fmt.Printf("Subtasks: %d, done %d/%d (%d%%)\n", len(kids), p.Done, p.Total, p.Percent())
+	}
+}
+
+func printAttachments(ctx context.Context, svc *service.Service, id model.ID) {
+	list, _ := svc.Attachments(ctx, id)
+	if len(list) == 0 {
+		return
+	}
+	fmt.Printf("Attachments (%d):\n", len(list))
+	for _, a := range list {
+		fmt.Printf("  [%d] %s, %d bytes, %s\n", a.ID, a.Name, a.Size, a.ContentType)
+	}
+}
+
+// ветка комментариев под карточкой задачи, старые сверху
+func printComments(ctx context.Context, svc *service.Service, id model.ID) {
+	list, _ := svc.Comments(ctx, id)
+	if len(list) == 0 {
+		return
+	}
+	fmt.Printf("Comments (%d):\n", len(list))
+	for _, c := range list {
+		author := "-"
+		if c.Author != 0 {
+			author = fmt.Sprintf("user #%d", c.Author)
+		}
+		edited := ""
+		if c.EditedAt != nil {
+			edited = " (изменён)"
+		}
+		fmt.Printf("  [%d] %s, %s%s:\n", c.ID, author, c.CreatedAt.Format("2006-01-02 15:04"), edited)
+		for _, line := range strings.Split(c.Body, "\n") {
+			fmt.Println("    " + line)
+		}
+	}
+}
+
+func handleComment(ctx context.Context, in *bufio.Scanner, svc *service.Service) {
+	id, ok := askID(in)
+	if !ok {
+		return
+	}
+	fmt.Print("Комментарий: ")
+	c, err := svc.AddComment(ctx, id, readLine(in))
+	if err != nil {
+		fmt.Println("ошибка:", err)
+		return
+	}
+	fmt.Printf("OK, комментарий #%d\n", c.ID)
 }
 
 func askID(in *bufio.Scanner) (model.ID, bool) {
@@ -436,6 +1025,16 @@ func askPriority(in *bufio.Scanner) model.Priority {
 	}
 }
 
+// printQuery — выборка из сервиса и вывод таблицей
+func printQuery(ctx context.Context, svc *service.Service, q model.TaskQuery) {
+	page, err := svc.List(ctx, q)
+	if err != nil {
+		fmt.Println("ошибка:", err)
+		return
+	}
+	printTasks(page.Items)
+}
+
 // вывод всех задач таблицей
 func printTasks(list []*model.Task) {
 	if len(list) == 0 {
@@ -443,14 +1042,22 @@ func printTasks(list []*model.Task) {
 		return
 	}
 	fmt.Println("ID | Title | Status | Prio | Created | Due | Desc")
-	for _, t := range list {
+	for _, n := range model.Tree(list) {
+		t := n.Task
 		due := "-"
 		if d := t.DueAt(); d != nil {
 			due = d.Format("02-01-2006")
 		}
 		desc := trunc(t.Description(), 43)
-		fmt.Printf("%d | %s | %s | %s | %s | %s | %s\n",
-			t.ID(), t.Title(), t.Status(), prioText(t.Priority()),
+		indent := ""
+		if n.Depth > 0 {
+			indent = strings.Repeat("   ", n.Depth-1) + "└─ " // подзадачи лесенкой под родителем
+		}
+		if t.Recurrence() != nil {
+			due += " ↻" // повторяется
+		}
+		fmt.Printf("%d | %s%s | %s | %s | %s | %s | %s\n",
+			t.ID(), indent, t.Title(), t.Status(), prioText(t.Priority()),
 			t.CreatedAt().Format("2006-01-02 15:04"),
 			due, desc,
 		)
@@ -525,4 +1132,4 @@ func readLine(in *bufio.Scanner) string {
 		return in.Text()
 	}
 	return ""
-}
\ No newline at end of file
+}
@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"todo/internal/model"
+	"todo/internal/policy"
+	"todo/internal/repository"
+	"todo/internal/reqctx"
+)
+
+// APIKeyPrefix — с него начинается каждый ключ: так его легко отличить от JWT
+// в заголовке Authorization и найти сканером секретов в логах и репозиториях
+const APIKeyPrefix = "todo_"
+
+// keyTouchEvery — last_used_at пишем не чаще раза в минуту, а не на каждый запрос
+const keyTouchEvery = time.Minute
+
+// APIKeyStore — ключи API (PostgreSQL, MongoDB или JSON-файл).
+// Нет ключа — repository.ErrNotFound.
+type APIKeyStore interface {
+	CreateKey(ctx context.Context, k model.APIKey) error
+	GetKey(ctx context.Context, id string) (model.APIKey, error)
+	ListKeys(ctx context.Context) ([]model.APIKey, error) // по времени создания
+	RevokeKey(ctx context.Context, id string, at time.Time) error
+	TouchKey(ctx context.Context, id string, at time.Time) error
+}
+
+var (
+	ErrKeyNotFound = errors.New("api key not found")
+	ErrBadKeyName  = errors.New("api key name must be 1..64 characters")
+	ErrBadScope    = errors.New("bad scope")
+	ErrBadExpiry   = errors.New("api key expiry must be in the future")
+)
+
+// KeyInfo — ключ без хеша, для ответов API
+type KeyInfo struct {
+	ID         string       `json:"id"`
+	Name       string       `json:"name"`
+	UserID     model.UserID `json:"user_id"`
+	Scopes     []string     `json:"scopes"`
+	CreatedAt  time.Time    `json:"created_at"`
+	ExpiresAt  *time.Time   `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time   `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time   `json:"revoked_at,omitempty"`
+}
+
+func KeyInfoOf(k model.APIKey) KeyInfo {
+	return KeyInfo{ID: k.ID, Name: k.Name, UserID: k.UserID, Scopes: k.Scopes, CreatedAt: k.CreatedAt,
+		ExpiresAt: k.ExpiresAt, LastUsedAt: k.LastUsedAt, RevokedAt: k.RevokedAt}
+}
+
+// APIKeys — выпуск, список, отзыв и проверка ключей API
+type APIKeys struct {
+	users *Users
+	store APIKeyStore
+}
+
+func NewAPIKeys(users *Users, store APIKeyStore) *APIKeys {
+	return &APIKeys{users: users, store: store}
+}
+
+// IsAPIKey — похоже ли значение Authorization на ключ API, а не на JWT
+func IsAPIKey(raw string) bool {
+	return strings.HasPrefix(raw, APIKeyPrefix)
+}
+
+// Create выпускает ключ от имени пользователя owner. Scopes обязательны
+// и не шире роли владельца. Сам ключ возвращается только здесь, один раз.
+func (k *APIKeys) Create(ctx context.Context, name string, owner model.UserID, scopes []policy.Action, expiresAt *time.Time) (string, model.APIKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 64 {
+		return "", model.APIKey{}, ErrBadKeyName
+	}
+	u, err := k.users.Get(ctx, owner)
+	if err != nil {
+		return "", model.APIKey{}, err
+	}
+	if len(scopes) == 0 {
+		return "", model.APIKey{}, fmt.Errorf("%w: at least one scope is required", ErrBadScope)
+	}
+	names := make([]string, 0, len(scopes))
+	for _, a := range scopes {
+		if !a.Valid() {
+			return "", model.APIKey{}, fmt.Errorf("%w %q", ErrBadScope, a)
+		}
+		if !policy.Allowed(u.Role, a) {
+			return "", model.APIKey{}, fmt.Errorf("%w: %s can't %s", ErrBadScope, u.Role, a)
+		}
+		names = append(names, string(a))
+	}
+	now := time.Now()
+	if expiresAt != nil && !expiresAt.After(now) {
+		return "", model.APIKey{}, ErrBadExpiry
+	}
+	secret := randomToken()
+	key := model.APIKey{
+		ID:        randomToken()[:12],
+		Name:      name,
+		UserID:    u.ID,
+		Scopes:    names,
+		Hash:      hashToken(secret),
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+	}
+	if err := k.store.CreateKey(ctx, key); err != nil {
+		return "", model.APIKey{}, err
+	}
+	return APIKeyPrefix + key.ID + "." + secret, key, nil
+}
+
+// List — все ключи арендатора, включая отозванные и истёкшие
+func (k *APIKeys) List(ctx context.Context) ([]model.APIKey, error) {
+	keys, err := k.store.ListKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := keys[:0]
+	for _, key := range keys {
+		ok, err := k.ownerVisible(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			out = append(out, key)
+		}
+	}
+	return out, nil
+}
+
+// ownerVisible — владелец ключа из арендатора вызывающего (см. Users.Get)
+func (k *APIKeys) ownerVisible(ctx context.Context, key model.APIKey) (bool, error) {
+	_, err := k.users.Get(ctx, key.UserID)
+	if errors.Is(err, ErrUserNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Revoke — ключ больше не принимается; запись остаётся для истории
+func (k *APIKeys) Revoke(ctx context.Context, id string) error {
+	key, err := k.store.GetKey(ctx, id)
+	if err == nil {
+		var ok bool
+		if ok, err = k.ownerVisible(ctx, key); err == nil && !ok {
+			err = repository.ErrNotFound // ключ чужого арендатора
+		}
+	}
+	if err == nil {
+		err = k.store.RevokeKey(ctx, id, time.Now())
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("%w: %s", ErrKeyNotFound, id)
+	}
+	return err
+}
+
+// Verify проверяет ключ и возвращает его владельца для reqctx. Роль берём из базы,
+// так что понижение роли владельца сразу сужает и ключ.
+func (k *APIKeys) Verify(ctx context.Context, raw string) (reqctx.User, error) {
+	id, secret, ok := strings.Cut(strings.TrimPrefix(raw, APIKeyPrefix), ".")
+	if !ok || !IsAPIKey(raw) {
+		return reqctx.User{}, ErrBadToken
+	}
+	key, err := k.store.GetKey(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return reqctx.User{}, ErrBadToken
+	}
+	if err != nil {
+		return reqctx.User{}, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(secret)), []byte(key.Hash)) != 1 {
+		return reqctx.User{}, ErrBadToken
+	}
+	now := time.Now()
+	if !key.Active(now) {
+		return reqctx.User{}, fmt.Errorf("%w: api key revoked or expired", ErrBadToken)
+	}
+	u, err := k.users.Get(ctx, key.UserID)
+	if errors.Is(err, ErrUserNotFound) {
+		return reqctx.User{}, ErrBadToken
+	}
+	if err != nil {
+		return reqctx.User{}, err
+	}
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= keyTouchEvery {
+		// отметка служебная: не смогли записать — запрос всё равно пускаем
+		_ = k.store.TouchKey(ctx, key.ID, now)
+	}
+	scopes := key.Scopes
+	if scopes == nil {
+		scopes = []string{} // nil в reqctx значит «без ограничений» — ключу так нельзя
+	}
+	return reqctx.User{ID: u.ID, Login: u.Login, Role: u.Role, Tenant: u.Tenant.OrDefault(), Scopes: scopes}, nil
+}
@@ -0,0 +1,351 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"todo/internal/auth"
+	"todo/internal/model"
+	"todo/internal/policy"
+	"todo/internal/repository"
+	"todo/internal/reqctx"
+)
+
+var ctx = context.Background()
+
+func newUsers(t *testing.T) *auth.Users {
+	t.Helper()
+	return auth.NewUsers(repository.NewJSONUserStore(filepath.Join(t.TempDir(), "users.json")))
+}
+
+func TestUsers_RegisterAndAuthenticate(t *testing.T) {
+	users := newUsers(t)
+
+	u, err := users.Register(ctx, " Alice ", "correct horse", model.RoleMember)
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if u.ID == 0 || u.Login != "alice" || u.PasswordHash == "correct horse" {
+		t.Fatalf("unexpected user: %+v", u)
+	}
+	if _, err := users.Register(ctx, "ALICE", "another pass", model.RoleMember); !errors.Is(err, auth.ErrLoginTaken) {
+		t.Fatalf("expected ErrLoginTaken, got %v", err)
+	}
+	if _, err := users.Register(ctx, "bob", "short", model.RoleMember); !errors.Is(err, auth.ErrWeakPassword) {
+		t.Fatalf("expected ErrWeakPassword, got %v", err)
+	}
+	if _, err := users.Register(ctx, "b o b", "long enough", model.RoleMember); !errors.Is(err, auth.ErrBadLogin) {
+		t.Fatalf("expected ErrBadLogin, got %v", err)
+	}
+
+	got, err := users.Authenticate(ctx, "alice", "correct horse")
+	if err != nil || got.ID != u.ID {
+		t.Fatalf("Authenticate: %+v, %v", got, err)
+	}
+	for _, c := range [][2]string{{"alice", "wrong horse"}, {"nobody", "correct horse"}} {
+		if _, err := users.Authenticate(ctx, c[0], c[1]); !errors.Is(err, auth.ErrBadCredentials) {
+			t.Fatalf("Authenticate(%q): expected ErrBadCredentials, got %v", c[0], err)
+		}
+	}
+
+	if err := users.ChangePassword(ctx, u.ID, "wrong horse", "battery staple"); !errors.Is(err, auth.ErrBadCredentials) {
+		t.Fatalf("expected ErrBadCredentials, got %v", err)
+	}
+	if err := users.ChangePassword(ctx, u.ID, "correct horse", "battery staple"); err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+	if _, err := users.Authenticate(ctx, "alice", "battery staple"); err != nil {
+		t.Fatalf("new password rejected: %v", err)
+	}
+}
+
+func TestUsers_BootstrapOnlyOnce(t *testing.T) {
+	users := newUsers(t)
+
+	created, err := users.Bootstrap(ctx, "admin", "admin12345")
+	if err != nil || !created {
+		t.Fatalf("Bootstrap: %v, %v", created, err)
+	}
+	admin, err := users.Authenticate(ctx, "admin", "admin12345")
+	if err != nil || admin.Role != model.RoleAdmin {
+		t.Fatalf("bootstrap admin: %+v, %v", admin, err)
+	}
+	if created, err := users.Bootstrap(ctx, "root", "root12345"); err != nil || created {
+		t.Fatalf("second Bootstrap must do nothing: %v, %v", created, err)
+	}
+}
+
+func TestTokens_IssueAndParse(t *testing.T) {
+	secret := []byte(strings.Repeat("k", auth.MinSecretLen))
+	users := newUsers(t)
+	u, _ := users.Register(ctx, "carol", "carol12345", model.RoleAdmin)
+
+	tokens := auth.NewTokens(auth.NewKeyring(secret), time.Hour)
+	raw, err := tokens.Issue(u, "s1")
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	who, err := tokens.Parse(raw)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if who.ID != u.ID || who.Login != "carol" || who.Role != model.RoleAdmin || who.Tenant != model.DefaultTenant {
+		t.Fatalf("unexpected principal: %+v", who)
+	}
+
+	other := auth.NewTokens(auth.NewKeyring([]byte(strings.Repeat("x", auth.MinSecretLen))), time.Hour)
+	if _, err := other.Parse(raw); !errors.Is(err, auth.ErrBadToken) {
+		t.Fatalf("foreign signature accepted: %v", err)
+	}
+	expired, _ := auth.NewTokens(auth.NewKeyring(secret), -time.Minute).Issue(u, "s1")
+	if _, err := tokens.Parse(expired); !errors.Is(err, auth.ErrBadToken) {
+		t.Fatalf("expired token accepted: %v", err)
+	}
+}
+
+func TestSecretFromEnv(t *testing.T) {
+	for _, bad := range []string{"", "default_secret", "supersecret", "too-short-secret"} {
+		t.Setenv("JWT_SECRET", bad)
+		if _, err := auth.SecretFromEnv(); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+	t.Setenv("JWT_SECRET", strings.Repeat("s", auth.MinSecretLen))
+	if _, err := auth.SecretFromEnv(); err != nil {
+		t.Fatalf("SecretFromEnv: %v", err)
+	}
+}
+
+func TestAdminFromEnv(t *testing.T) {
+	for _, bad := range [][2]string{
+		{"admin", "admin12345"},          // из старого .env
+		{"root", "short-pass"},           // короче MinAdminPasswordLen
+		{"rootroot1234", "ROOTROOT1234"}, // совпадает с логином
+		{"", "long enough password"},
+		{"root", ""},
+	} {
+		t.Setenv("ADMIN_LOGIN", bad[0])
+		t.Setenv("ADMIN_PASSWORD", bad[1])
+		if _, _, err := auth.AdminFromEnv(); err == nil {
+			t.Errorf("expected error for %q/%q", bad[0], bad[1])
+		}
+	}
+	t.Setenv("ADMIN_LOGIN", "root")
+	t.Setenv("ADMIN_PASSWORD", "correct horse battery")
+	if login, _, err := auth.AdminFromEnv(); err != nil || login != "root" {
+		t.Fatalf("AdminFromEnv: %q, %v", login, err)
+	}
+
+	// пустая база без учётки — не стартуем: войти было бы некому
+	if _, err := newUsers(t).Bootstrap(ctx, "", ""); !errors.Is(err, auth.ErrNoAdmin) {
+		t.Fatalf("expected ErrNoAdmin, got %v", err)
+	}
+}
+
+func TestUsers_SetRole(t *testing.T) {
+	users := newUsers(t)
+	u, _ := users.Register(ctx, "dave", "dave12345", model.RoleMember)
+
+	if _, err := users.SetRole(ctx, u.ID, "owner"); !errors.Is(err, auth.ErrBadRole) {
+		t.Fatalf("expected ErrBadRole, got %v", err)
+	}
+	if _, err := users.SetRole(ctx, u.ID+100, model.RoleViewer); !errors.Is(err, auth.ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+	if _, err := users.SetRole(ctx, u.ID, model.RoleViewer); err != nil {
+		t.Fatalf("SetRole: %v", err)
+	}
+	got, _ := users.Get(ctx, u.ID)
+	if got.Role != model.RoleViewer {
+		t.Fatalf("role not saved: %q", got.Role)
+	}
+}
+
+func TestTokens_KeyRotation(t *testing.T) {
+	oldKey := []byte(strings.Repeat("o", auth.MinSecretLen))
+	newKey := []byte(strings.Repeat("n", auth.MinSecretLen))
+	u := model.User{ID: 1, Login: "erin", Role: model.RoleMember}
+
+	raw, _ := auth.NewTokens(auth.NewKeyring(oldKey), time.Hour).Issue(u, "s1")
+	rotated := auth.NewTokens(auth.NewKeyring(newKey, oldKey), time.Hour)
+	if _, err := rotated.Parse(raw); err != nil {
+		t.Fatalf("token signed with previous key rejected: %v", err)
+	}
+	fresh, _ := rotated.Issue(u, "s1")
+	if _, err := auth.NewTokens(auth.NewKeyring(newKey), time.Hour).Parse(fresh); err != nil {
+		t.Fatalf("new token must be signed with the current key: %v", err)
+	}
+	if _, err := auth.NewTokens(auth.NewKeyring(newKey), time.Hour).Parse(raw); !errors.Is(err, auth.ErrBadToken) {
+		t.Fatalf("dropped key still accepted: %v", err)
+	}
+
+	t.Setenv("JWT_SECRET", string(newKey))
+	t.Setenv("JWT_PREVIOUS_SECRETS", "secret")
+	if _, err := auth.KeysFromEnv(); err == nil {
+		t.Fatal("weak previous secret accepted")
+	}
+}
+
+func TestSessions_RefreshAndLogout(t *testing.T) {
+	users := newUsers(t)
+	users.Register(ctx, "frank", "frank12345", model.RoleMember)
+	tokens := auth.NewTokens(auth.NewKeyring([]byte(strings.Repeat("k", auth.MinSecretLen))), time.Minute)
+	sessions := auth.NewSessions(users, tokens, repository.NewMemorySessionStore(), time.Hour)
+
+	first, err := sessions.Login(ctx, "frank", "frank12345")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if who, err := sessions.Verify(ctx, first.AccessToken); err != nil || who.Login != "frank" {
+		t.Fatalf("Verify: %+v, %v", who, err)
+	}
+
+	second, err := sessions.Refresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("refresh token not rotated")
+	}
+	// повтор старого токена закрывает сессию: новый тоже больше не работает
+	if _, err := sessions.Refresh(ctx, first.RefreshToken); !errors.Is(err, auth.ErrBadToken) {
+		t.Fatalf("reused refresh token accepted: %v", err)
+	}
+	if _, err := sessions.Refresh(ctx, second.RefreshToken); !errors.Is(err, auth.ErrBadToken) {
+		t.Fatalf("session must be closed after reuse: %v", err)
+	}
+
+	third, _ := sessions.Login(ctx, "frank", "frank12345")
+	if err := sessions.Logout(ctx, third.AccessToken); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+	if _, err := sessions.Verify(ctx, third.AccessToken); !errors.Is(err, auth.ErrBadToken) {
+		t.Fatalf("revoked access token accepted: %v", err)
+	}
+	if _, err := sessions.Refresh(ctx, third.RefreshToken); !errors.Is(err, auth.ErrBadToken) {
+		t.Fatalf("refresh after logout accepted: %v", err)
+	}
+}
+
+func TestAPIKeys_ScopesAndRevoke(t *testing.T) {
+	users := newUsers(t)
+	ci, _ := users.Register(ctx, "ci-bot", "ci-bot-pass", model.RoleMember)
+	keys := auth.NewAPIKeys(users, repository.NewJSONAPIKeyStore(filepath.Join(t.TempDir(), "api_keys.json")))
+
+	if _, _, err := keys.Create(ctx, "ci", ci.ID, nil, nil); !errors.Is(err, auth.ErrBadScope) {
+		t.Fatalf("key without scopes: expected ErrBadScope, got %v", err)
+	}
+	if _, _, err := keys.Create(ctx, "ci", ci.ID, []policy.Action{policy.Renumber}, nil); !errors.Is(err, auth.ErrBadScope) {
+		t.Fatalf("scope wider than role: expected ErrBadScope, got %v", err)
+	}
+	raw, key, err := keys.Create(ctx, "ci", ci.ID, []policy.Action{policy.Read, policy.Create}, nil)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !auth.IsAPIKey(raw) || strings.Contains(key.Hash, strings.TrimPrefix(raw, auth.APIKeyPrefix+key.ID+".")) {
+		t.Fatalf("unexpected key %q / %+v", raw, key)
+	}
+
+	who, err := keys.Verify(ctx, raw)
+	if err != nil || who.ID != ci.ID || who.Role != model.RoleMember {
+		t.Fatalf("Verify: %+v, %v", who, err)
+	}
+	if err := policy.Authorize(who, policy.Delete); !errors.Is(err, policy.ErrForbidden) {
+		t.Fatalf("delete is outside the key scopes: %v", err)
+	}
+	if _, err := keys.Verify(ctx, raw+"x"); !errors.Is(err, auth.ErrBadToken) {
+		t.Fatalf("wrong secret accepted: %v", err)
+	}
+	list, _ := keys.List(ctx)
+	if len(list) != 1 || list[0].LastUsedAt == nil {
+		t.Fatalf("last use not recorded: %+v", list)
+	}
+
+	if err := keys.Revoke(ctx, key.ID); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, err := keys.Verify(ctx, raw); !errors.Is(err, auth.ErrBadToken) {
+		t.Fatalf("revoked key accepted: %v", err)
+	}
+	if err := keys.Revoke(ctx, "nope"); !errors.Is(err, auth.ErrKeyNotFound) {
+		t.Fatalf("expected ErrKeyNotFound, got %v", err)
+	}
+
+	past := time.Now().Add(-time.Minute)
+	if _, _, err := keys.Create(ctx, "old", ci.ID, []policy.Action{policy.Read}, &past); !errors.Is(err, auth.ErrBadExpiry) {
+		t.Fatalf("expected ErrBadExpiry, got %v", err)
+	}
+}
+
+func TestTenants_UsersAndKeysStayInTenant(t *testing.T) {
+	users := newUsers(t)
+	root, _ := users.Register(ctx, "root", "root12345", model.RoleAdmin)
+	asRoot := reqctx.WithUser(ctx, reqctx.User{ID: root.ID, Login: root.Login, Role: root.Role, Tenant: root.Tenant})
+
+	// из общего арендатора можно завести первого администратора нового
+	acmeAdmin, err := users.RegisterIn(asRoot, "ACME", "acme-admin", "acme12345", model.RoleAdmin)
+	if err != nil || acmeAdmin.Tenant != "acme" {
+		t.Fatalf("RegisterIn: %+v, %v", acmeAdmin, err)
+	}
+	if _, err := users.RegisterIn(asRoot, "bad/tenant", "eve", "eve12345", model.RoleMember); !errors.Is(err, auth.ErrBadTenant) {
+		t.Fatalf("expected ErrBadTenant, got %v", err)
+	}
+	asAcme := reqctx.WithUser(ctx, reqctx.User{ID: acmeAdmin.ID, Login: acmeAdmin.Login, Role: model.RoleAdmin, Tenant: "acme"})
+	// а из чужого — нет, ни к себе в обход, ни в общий
+	if _, err := users.RegisterIn(asAcme, "globex", "mallory", "mallory123", model.RoleAdmin); !errors.Is(err, policy.ErrForbidden) {
+		t.Fatalf("foreign tenant registers users elsewhere: %v", err)
+	}
+	bob, err := users.Register(asAcme, "acme-bob", "bob1234567", model.RoleMember)
+	if err != nil || bob.Tenant != "acme" {
+		t.Fatalf("Register inherits tenant: %+v, %v", bob, err)
+	}
+
+	// пользователи чужого арендатора не существуют
+	if _, err := users.Get(asAcme, root.ID); !errors.Is(err, auth.ErrUserNotFound) {
+		t.Fatalf("acme sees default user: %v", err)
+	}
+	if _, err := users.SetRole(asAcme, root.ID, model.RoleViewer); !errors.Is(err, auth.ErrUserNotFound) {
+		t.Fatalf("acme changes default user: %v", err)
+	}
+	if _, err := users.Get(asRoot, bob.ID); !errors.Is(err, auth.ErrUserNotFound) {
+		t.Fatalf("default sees acme user: %v", err)
+	}
+
+	// арендатор едет в токене и в ключе API
+	tokens := auth.NewTokens(auth.NewKeyring([]byte(strings.Repeat("k", auth.MinSecretLen))), time.Hour)
+	raw, _ := tokens.Issue(bob, "s1")
+	if who, err := tokens.Parse(raw); err != nil || who.Tenant != "acme" {
+		t.Fatalf("token tenant: %+v, %v", who, err)
+	}
+	keys := auth.NewAPIKeys(users, repository.NewJSONAPIKeyStore(filepath.Join(t.TempDir(), "api_keys.json")))
+	rootKey, _, err := keys.Create(asRoot, "ci", root.ID, []policy.Action{policy.Read}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bobKey, bobInfo, err := keys.Create(asAcme, "ci", bob.ID, []policy.Action{policy.Read}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := keys.Create(asAcme, "steal", root.ID, []policy.Action{policy.Read}, nil); !errors.Is(err, auth.ErrUserNotFound) {
+		t.Fatalf("key for a foreign user: %v", err)
+	}
+	if who, err := keys.Verify(ctx, bobKey); err != nil || who.Tenant != "acme" {
+		t.Fatalf("key tenant: %+v, %v", who, err)
+	}
+	if who, err := keys.Verify(ctx, rootKey); err != nil || who.Tenant != model.DefaultTenant {
+		t.Fatalf("key tenant: %+v, %v", who, err)
+	}
+	if list, _ := keys.List(asAcme); len(list) != 1 || list[0].ID != bobInfo.ID {
+		t.Fatalf("acme lists %+v", list)
+	}
+	if err := keys.Revoke(asRoot, bobInfo.ID); !errors.Is(err, auth.ErrKeyNotFound) {
+		t.Fatalf("default revokes acme key: %v", err)
+	}
+	if _, err := keys.Verify(ctx, bobKey); err != nil {
+		t.Fatalf("key revoked across tenants: %v", err)
+	}
+}
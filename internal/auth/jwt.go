@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo/internal/model"
+	"todo/internal/reqctx"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// MinSecretLen — HS256 с ключом короче 32 байт подбирается слишком легко
+const MinSecretLen = 32
+
+// секреты из примеров и старых версий, с ними не стартуем
+var knownSecrets = map[string]bool{
+	"default_secret": true,
+	"supersecret":    true,
+	"secret":         true,
+	"changeme":       true,
+}
+
+// SecretFromEnv — ключ подписи из JWT_SECRET. Пустой, известный или короткий ключ —
+// ошибка: раньше молча подставлялся "default_secret", и токен мог выписать кто угодно.
+func SecretFromEnv() ([]byte, error) {
+	return checkSecret("JWT_SECRET", os.Getenv("JWT_SECRET"))
+}
+
+func checkSecret(name, secret string) ([]byte, error) {
+	switch {
+	case secret == "":
+		return nil, fmt.Errorf("%s is not set", name)
+	case knownSecrets[secret]:
+		return nil, fmt.Errorf("%s is a well-known default, set your own", name)
+	case len(secret) < MinSecretLen:
+		return nil, fmt.Errorf("%s is too short: want at least %d bytes", name, MinSecretLen)
+	}
+	return []byte(secret), nil
+}
+
+// KeysFromEnv — JWT_SECRET для подписи и JWT_PREVIOUS_SECRETS (через запятую) только для проверки.
+// Смена ключа: старый переносим в JWT_PREVIOUS_SECRETS, пока не истекут выписанные им токены.
+func KeysFromEnv() (*Keyring, error) {
+	current, err := SecretFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	var previous [][]byte
+	for _, raw := range strings.Split(os.Getenv("JWT_PREVIOUS_SECRETS"), ",") {
+		if raw = strings.TrimSpace(raw); raw == "" {
+			continue
+		}
+		secret, err := checkSecret("JWT_PREVIOUS_SECRETS", raw)
+		if err != nil {
+			return nil, err
+		}
+		previous = append(previous, secret)
+	}
+	return NewKeyring(current, previous...), nil
+}
+
+// Keyring — ключи подписи: текущим подписываем, предыдущими только проверяем.
+// Ключ выбирается по kid из заголовка токена, поэтому смена ключа никого не разлогинивает.
+type Keyring struct {
+	current string
+	keys    map[string][]byte
+}
+
+func NewKeyring(current []byte, previous ...[]byte) *Keyring {
+	k := &Keyring{current: keyID(current), keys: map[string][]byte{keyID(current): current}}
+	for _, p := range previous {
+		k.keys[keyID(p)] = p
+	}
+	return k
+}
+
+// keyID — kid ключа: начало его SHA-256, сам ключ по нему не восстановить
+func keyID(secret []byte) string {
+	sum := sha256.Sum256(secret)
+	return hex.EncodeToString(sum[:4])
+}
+
+var ErrBadToken = errors.New("invalid token")
+
+// Claims — содержимое токена: sub — ID пользователя, jti — ID самого токена
+// (по нему отзываем), sid — сессия, из которой токен выписан, tenant — арендатор
+type Claims struct {
+	Login   string         `json:"login"`
+	Role    model.Role     `json:"role"`
+	Tenant  model.TenantID `json:"tenant,omitempty"`
+	Session string         `json:"sid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Tokens выписывает и проверяет короткоживущие access-токены (HS256)
+type Tokens struct {
+	keys *Keyring
+	ttl  time.Duration
+}
+
+func NewTokens(keys *Keyring, ttl time.Duration) *Tokens {
+	return &Tokens{keys: keys, ttl: ttl}
+}
+
+// TTL — сколько живёт access-токен
+func (t *Tokens) TTL() time.Duration {
+	return t.ttl
+}
+
+// Issue — токен для пользователя в рамках сессии sessionID
+func (t *Tokens) Issue(u model.User, sessionID string) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		Login:   u.Login,
+		Role:    u.Role,
+		Tenant:  u.Tenant.OrDefault(),
+		Session: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        randomToken(),
+			Subject:   strconv.FormatInt(int64(u.ID), 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(t.ttl)),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = t.keys.current
+	return token.SignedString(t.keys.keys[t.keys.current])
+}
+
+// Parse проверяет подпись и срок и возвращает пользователя для reqctx.
+// Отзыв токена тут не проверяется — это дело Sessions.Verify.
+func (t *Tokens) Parse(raw string) (reqctx.User, error) {
+	claims, err := t.claims(raw)
+	if err != nil {
+		return reqctx.User{}, err
+	}
+	return claims.user(), nil
+}
+
+// user — пользователь для reqctx; sub уже проверен в claims.
+// Токены без tenant выписаны до арендаторов, тогда все были в общем.
+func (c *Claims) user() reqctx.User {
+	id, _ := strconv.ParseInt(c.Subject, 10, 64)
+	return reqctx.User{ID: model.UserID(id), Login: c.Login, Role: c.Role, Tenant: c.Tenant.OrDefault()}
+}
+
+func (t *Tokens) claims(raw string) (*Claims, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(raw, &claims, func(token *jwt.Token) (any, error) {
+		// токены без kid выписаны до ротации ключей — текущим ключом
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			kid = t.keys.current
+		}
+		key, ok := t.keys.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key %q", kid)
+		}
+		return key, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadToken, err)
+	}
+	id, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil || id <= 0 {
+		return nil, fmt.Errorf("%w: no user id", ErrBadToken)
+	}
+	// токены без роли (выписанные до ролей) не принимаем: пусть перелогинятся
+	if !claims.Role.Valid() {
+		return nil, fmt.Errorf("%w: bad role %q", ErrBadToken, claims.Role)
+	}
+	return &claims, nil
+}
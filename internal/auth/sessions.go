@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"todo/internal/model"
+	"todo/internal/repository"
+	"todo/internal/reqctx"
+)
+
+// Сроки по умолчанию: access-токен короткий, поэтому отзывать его приходится редко,
+// а сессия живёт, пока клиент хоть раз в SessionTTL обновляет токены
+const (
+	AccessTTL  = 15 * time.Minute
+	SessionTTL = 30 * 24 * time.Hour
+)
+
+// SessionStore — сессии и отозванные access-токены (PostgreSQL, Redis или память).
+// Нет записи — repository.ErrNotFound.
+type SessionStore interface {
+	CreateSession(ctx context.Context, s model.Session) error
+	GetSession(ctx context.Context, id string) (model.Session, error)
+	// RotateSession меняет хеш refresh-токена, только если текущий равен oldHash,
+	// иначе ErrNotFound: два одновременных обновления одним токеном не пройдут оба
+	RotateSession(ctx context.Context, id, oldHash, newHash string, expiresAt time.Time) error
+	DeleteSession(ctx context.Context, id string) error
+	// DenyToken — access-токен с этим jti больше не принимаем; после until он истёк и так
+	DenyToken(ctx context.Context, jti string, until time.Time) error
+	TokenDenied(ctx context.Context, jti string) (bool, error)
+}
+
+// TokenPair — ответ на вход и обновление
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // секунд до истечения access-токена
+}
+
+// Sessions — вход, обновление токенов и выход.
+// Access-токен живёт недолго и проверяется без базы (кроме списка отозванных),
+// refresh-токен одноразовый: каждое обновление выдаёт новый, а старый сгорает.
+type Sessions struct {
+	users  *Users
+	tokens *Tokens
+	store  SessionStore
+	ttl    time.Duration
+}
+
+// NewSessions; ttl — сколько сессия живёт без обновления
+func NewSessions(users *Users, tokens *Tokens, store SessionStore, ttl time.Duration) *Sessions {
+	return &Sessions{users: users, tokens: tokens, store: store, ttl: ttl}
+}
+
+// Login — проверка пароля и новая сессия
+func (s *Sessions) Login(ctx context.Context, login, password string) (TokenPair, error) {
+	u, err := s.users.Authenticate(ctx, login, password)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	secret := randomToken()
+	sess := model.Session{
+		ID:          randomToken(),
+		UserID:      u.ID,
+		RefreshHash: hashToken(secret),
+		ExpiresAt:   time.Now().Add(s.ttl),
+	}
+	if err := s.store.CreateSession(ctx, sess); err != nil {
+		return TokenPair{}, err
+	}
+	return s.pair(u, sess.ID, secret)
+}
+
+// Refresh меняет refresh-токен на новую пару. Роль берём из базы,
+// так что её смена доезжает до клиента с первым же обновлением.
+func (s *Sessions) Refresh(ctx context.Context, refresh string) (TokenPair, error) {
+	id, secret, ok := strings.Cut(refresh, ".")
+	if !ok {
+		return TokenPair{}, ErrBadToken
+	}
+	sess, err := s.store.GetSession(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return TokenPair{}, ErrBadToken
+	}
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if time.Now().After(sess.ExpiresAt) {
+		_ = s.store.DeleteSession(ctx, id)
+		return TokenPair{}, fmt.Errorf("%w: session expired", ErrBadToken)
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(secret)), []byte(sess.RefreshHash)) != 1 {
+		// уже использованный токен: его могли украсть — закрываем сессию целиком
+		_ = s.store.DeleteSession(ctx, id)
+		return TokenPair{}, fmt.Errorf("%w: refresh token reused", ErrBadToken)
+	}
+	u, err := s.users.Get(ctx, sess.UserID)
+	if errors.Is(err, ErrUserNotFound) {
+		_ = s.store.DeleteSession(ctx, id)
+		return TokenPair{}, ErrBadToken
+	}
+	if err != nil {
+		return TokenPair{}, err
+	}
+	next := randomToken()
+	err = s.store.RotateSession(ctx, id, sess.RefreshHash, hashToken(next), time.Now().Add(s.ttl))
+	if errors.Is(err, repository.ErrNotFound) {
+		// кто-то успел обновиться этим же токеном раньше — то же, что повтор
+		_ = s.store.DeleteSession(ctx, id)
+		return TokenPair{}, fmt.Errorf("%w: refresh token reused", ErrBadToken)
+	}
+	if err != nil {
+		return TokenPair{}, err
+	}
+	return s.pair(u, id, next)
+}
+
+// Logout закрывает сессию access-токена и отзывает сам токен
+func (s *Sessions) Logout(ctx context.Context, access string) error {
+	claims, err := s.tokens.claims(access)
+	if err != nil {
+		return err
+	}
+	if claims.Session != "" {
+		if err := s.store.DeleteSession(ctx, claims.Session); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+	}
+	return s.store.DenyToken(ctx, claims.ID, claims.ExpiresAt.Time)
+}
+
+// Verify — Tokens.Parse плюс проверка, что токен не отозван
+func (s *Sessions) Verify(ctx context.Context, access string) (reqctx.User, error) {
+	claims, err := s.tokens.claims(access)
+	if err != nil {
+		return reqctx.User{}, err
+	}
+	if claims.ID != "" {
+		denied, err := s.store.TokenDenied(ctx, claims.ID)
+		if err != nil {
+			return reqctx.User{}, err
+		}
+		if denied {
+			return reqctx.User{}, fmt.Errorf("%w: revoked", ErrBadToken)
+		}
+	}
+	return claims.user(), nil
+}
+
+func (s *Sessions) pair(u model.User, sessionID, secret string) (TokenPair, error) {
+	access, err := s.tokens.Issue(u, sessionID)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	return TokenPair{
+		AccessToken:  access,
+		RefreshToken: sessionID + "." + secret,
+		ExpiresIn:    int(s.tokens.TTL().Seconds()),
+	}, nil
+}
+
+// randomToken — 128 случайных бит в hex
+func randomToken() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// hashToken — в хранилище только SHA-256: утечка базы не даёт готовых refresh-токенов
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
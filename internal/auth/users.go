@@ -0,0 +1,237 @@
+// Package auth — учётные записи, пароли и JWT.
+// Транспорт (web, gRPC) только вызывает его и кладёт пользователя в reqctx.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"todo/internal/model"
+	"todo/internal/policy"
+	"todo/internal/repository"
+	"todo/internal/reqctx"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// UserStore — хранилище пользователей, по реализации на каждый бэкенд.
+// ID выдаёт само хранилище; логин уникален (дубль — repository.ErrDuplicate).
+type UserStore interface {
+	Get(ctx context.Context, id model.UserID) (model.User, error)
+	GetByLogin(ctx context.Context, login string) (model.User, error)
+	Insert(ctx context.Context, u model.User) (model.UserID, error)
+	Update(ctx context.Context, u model.User) error
+	Count(ctx context.Context) (int, error)
+}
+
+var (
+	ErrBadCredentials = errors.New("wrong login or password")
+	ErrBadLogin       = errors.New("bad login")
+	ErrLoginTaken     = errors.New("login already taken")
+	ErrWeakPassword   = errors.New("password must be 8..72 bytes")
+	ErrUserNotFound   = errors.New("user not found")
+	ErrBadRole        = errors.New("bad role")
+	ErrBadTenant      = errors.New("bad tenant")
+	ErrNoAdmin        = errors.New("no users yet: set ADMIN_LOGIN and ADMIN_PASSWORD")
+)
+
+// MinAdminPasswordLen — пароль первого администратора задаётся в окружении
+// и живёт там долго, поэтому требования к нему строже, чем к обычным
+const MinAdminPasswordLen = 12
+
+// пароли из примеров и старых версий .env, с ними не стартуем
+var knownPasswords = map[string]bool{
+	"1234":       true,
+	"admin":      true,
+	"admin12345": true,
+	"password":   true,
+	"changeme":   true,
+}
+
+// Profile — пользователь без хеша пароля, для ответов API
+type Profile struct {
+	ID        model.UserID   `json:"id"`
+	Login     string         `json:"login"`
+	Role      model.Role     `json:"role"`
+	Tenant    model.TenantID `json:"tenant"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+func ProfileOf(u model.User) Profile {
+	return Profile{ID: u.ID, Login: u.Login, Role: u.Role, Tenant: u.Tenant.OrDefault(), CreatedAt: u.CreatedAt}
+}
+
+// HashPassword — bcrypt-хеш пароля. bcrypt смотрит только на первые 72 байта,
+// поэтому длинные пароли не обрезаем молча, а отказываем.
+func HashPassword(password string) (string, error) {
+	if len(password) < 8 || len(password) > 72 {
+		return "", ErrWeakPassword
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("no such user"), bcrypt.DefaultCost)
+
+// Users — регистрация, вход и смена пароля
+type Users struct {
+	store UserStore
+}
+
+func NewUsers(store UserStore) *Users {
+	return &Users{store: store}
+}
+
+// Register создаёт пользователя с ролью (что она разрешает — см. policy)
+// у арендатора того, кто регистрирует
+func (s *Users) Register(ctx context.Context, login, password string, role model.Role) (model.User, error) {
+	return s.RegisterIn(ctx, callerTenant(ctx), login, password, role)
+}
+
+// RegisterIn — Register у другого арендатора. Заводить пользователей чужим арендаторам
+// (например, первого администратора нового) могут только пользователи общего.
+func (s *Users) RegisterIn(ctx context.Context, tenant model.TenantID, login, password string, role model.Role) (model.User, error) {
+	tenant, err := model.ParseTenant(string(tenant))
+	if err != nil {
+		return model.User{}, fmt.Errorf("%w: %v", ErrBadTenant, err)
+	}
+	if caller := callerTenant(ctx); caller != tenant && caller != model.DefaultTenant {
+		return model.User{}, fmt.Errorf("%w: users of %s can't register users of %s", policy.ErrForbidden, caller, tenant)
+	}
+	login, err = model.NormalizeLogin(login)
+	if err != nil {
+		return model.User{}, fmt.Errorf("%w: %v", ErrBadLogin, err)
+	}
+	if !role.Valid() {
+		return model.User{}, fmt.Errorf("%w %q", ErrBadRole, role)
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		return model.User{}, err
+	}
+	now := time.Now()
+	u := model.User{Login: login, PasswordHash: hash, Role: role, Tenant: tenant, CreatedAt: now, UpdatedAt: now}
+	u.ID, err = s.store.Insert(ctx, u)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return model.User{}, fmt.Errorf("%w: %s", ErrLoginTaken, login)
+	}
+	return u, err
+}
+
+// Authenticate проверяет логин и пароль. Нет пользователя или пароль не тот —
+// одна и та же ErrBadCredentials, чтобы по ответу нельзя было перебирать логины.
+func (s *Users) Authenticate(ctx context.Context, login, password string) (model.User, error) {
+	login, err := model.NormalizeLogin(login)
+	if err != nil {
+		return model.User{}, ErrBadCredentials
+	}
+	u, err := s.store.GetByLogin(ctx, login)
+	if errors.Is(err, repository.ErrNotFound) {
+		// сравниваем с чем-нибудь, чтобы по времени ответа логин тоже не угадать
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return model.User{}, ErrBadCredentials
+	}
+	if err != nil {
+		return model.User{}, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) != nil {
+		return model.User{}, ErrBadCredentials
+	}
+	return u, nil
+}
+
+// Get — пользователь по ID. Пользователь чужого арендатора для вызывающего не существует;
+// внутренние вызовы (обновление токенов, проверка ключей) видят всех.
+func (s *Users) Get(ctx context.Context, id model.UserID) (model.User, error) {
+	u, err := s.store.Get(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) || err == nil && !visibleTo(ctx, u) {
+		return model.User{}, fmt.Errorf("%w: %d", ErrUserNotFound, id)
+	}
+	return u, err
+}
+
+// callerTenant — арендатор пользователя запроса; без пользователя — общий
+func callerTenant(ctx context.Context) model.TenantID {
+	me, _ := reqctx.UserFrom(ctx)
+	return me.Tenant.OrDefault()
+}
+
+// visibleTo — виден ли пользователь u тому, кто делает запрос
+func visibleTo(ctx context.Context, u model.User) bool {
+	_, ok := reqctx.UserFrom(ctx)
+	return !ok || callerTenant(ctx) == u.Tenant.OrDefault()
+}
+
+// ChangePassword меняет пароль, если старый указан верно
+func (s *Users) ChangePassword(ctx context.Context, id model.UserID, oldPassword, newPassword string) error {
+	u, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(oldPassword)) != nil {
+		return ErrBadCredentials
+	}
+	if u.PasswordHash, err = HashPassword(newPassword); err != nil {
+		return err
+	}
+	u.UpdatedAt = time.Now()
+	return s.store.Update(ctx, u)
+}
+
+// SetRole меняет роль пользователя. Уже выданные токены несут старую роль до истечения.
+func (s *Users) SetRole(ctx context.Context, id model.UserID, role model.Role) (model.User, error) {
+	if !role.Valid() {
+		return model.User{}, fmt.Errorf("%w %q", ErrBadRole, role)
+	}
+	u, err := s.Get(ctx, id)
+	if err != nil || u.Role == role {
+		return u, err
+	}
+	u.Role = role
+	u.UpdatedAt = time.Now()
+	return u, s.store.Update(ctx, u)
+}
+
+// AdminFromEnv — учётка первого администратора из ADMIN_LOGIN/ADMIN_PASSWORD
+// (старые LOGIN/PASSWORD тоже подходят); пустые — не задана.
+// Заданная наполовину, с коротким или известным паролем — ошибка, как и у JWT_SECRET
+func AdminFromEnv() (login, password string, err error) {
+	login, password = os.Getenv("ADMIN_LOGIN"), os.Getenv("ADMIN_PASSWORD")
+	if login == "" && password == "" {
+		login, password = os.Getenv("LOGIN"), os.Getenv("PASSWORD")
+	}
+	switch {
+	case login == "" && password == "":
+		return "", "", nil
+	case login == "":
+		return "", "", errors.New("ADMIN_LOGIN is not set")
+	case password == "":
+		return "", "", errors.New("ADMIN_PASSWORD is not set")
+	case knownPasswords[strings.ToLower(password)] || strings.EqualFold(password, login):
+		return "", "", errors.New("ADMIN_PASSWORD is a well-known default, set your own")
+	case len(password) < MinAdminPasswordLen || len(password) > 72:
+		return "", "", fmt.Errorf("ADMIN_PASSWORD must be %d..72 bytes", MinAdminPasswordLen)
+	}
+	return login, password, nil
+}
+
+// Bootstrap — первый администратор для пустой базы.
+// false — пользователи уже есть, ничего не делали. Пустая база без учётки — ErrNoAdmin:
+// войти было бы некому
+func (s *Users) Bootstrap(ctx context.Context, login, password string) (bool, error) {
+	n, err := s.store.Count(ctx)
+	if err != nil || n > 0 {
+		return false, err
+	}
+	if login == "" || password == "" {
+		return false, ErrNoAdmin
+	}
+	if _, err := s.Register(ctx, login, password, model.RoleAdmin); err != nil {
+		return false, fmt.Errorf("bootstrap admin: %w", err)
+	}
+	return true, nil
+}
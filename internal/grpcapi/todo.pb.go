@@ -9,6 +9,7 @@ package grpcapi
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -33,6 +34,16 @@ type Task struct {
 	CreatedAt     string                 `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	UpdatedAt     string                 `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
 	CompletedAt   string                 `protobuf:"bytes,9,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	ParentId      int64                  `protobuf:"varint,10,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`           // 0 — задача верхнего уровня
+	BlockedBy     []int64                `protobuf:"varint,11,rep,packed,name=blocked_by,json=blockedBy,proto3" json:"blocked_by,omitempty"` // задачи, которые надо сделать раньше
+	Recurrence    string                 `protobuf:"bytes,12,opt,name=recurrence,proto3" json:"recurrence,omitempty"`                        // RRULE, пусто — разовая задача
+	Tags          []string               `protobuf:"bytes,13,rep,name=tags,proto3" json:"tags,omitempty"`                                    // нормализованные, по алфавиту
+	CreatedBy     int64                  `protobuf:"varint,14,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`        // 0 — общая задача
+	Assignee      int64                  `protobuf:"varint,15,opt,name=assignee,proto3" json:"assignee,omitempty"`                           // 0 — не назначена
+	Checklist     []*ChecklistItem       `protobuf:"bytes,16,rep,name=checklist,proto3" json:"checklist,omitempty"`                          // по порядку
+	Version       int64                  `protobuf:"varint,17,opt,name=version,proto3" json:"version,omitempty"`                             // растёт с каждым изменением, см. UpdateTaskRequest.expected_version
+	DeletedAt     string                 `protobuf:"bytes,18,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`         // только у задач из корзины (ListTrash)
+	ArchivedAt    string                 `protobuf:"bytes,19,opt,name=archived_at,json=archivedAt,proto3" json:"archived_at,omitempty"`      // только у задач из архива (ListArchive, Search, Export)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -130,29 +141,1998 @@ func (x *Task) GetCompletedAt() string {
 	return ""
 }
 
-// Запросы/ответы
+func (x *Task) GetParentId() int64 {
+	if x != nil {
+		return x.ParentId
+	}
+	return 0
+}
+
+func (x *Task) GetBlockedBy() []int64 {
+	if x != nil {
+		return x.BlockedBy
+	}
+	return nil
+}
+
+func (x *Task) GetRecurrence() string {
+	if x != nil {
+		return x.Recurrence
+	}
+	return ""
+}
+
+func (x *Task) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Task) GetCreatedBy() int64 {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return 0
+}
+
+func (x *Task) GetAssignee() int64 {
+	if x != nil {
+		return x.Assignee
+	}
+	return 0
+}
+
+func (x *Task) GetChecklist() []*ChecklistItem {
+	if x != nil {
+		return x.Checklist
+	}
+	return nil
+}
+
+func (x *Task) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Task) GetDeletedAt() string {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return ""
+}
+
+func (x *Task) GetArchivedAt() string {
+	if x != nil {
+		return x.ArchivedAt
+	}
+	return ""
+}
+
+// Пункт чек-листа; id свой в пределах задачи и при перестановках не меняется
+type ChecklistItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	Done          bool                   `protobuf:"varint,3,opt,name=done,proto3" json:"done,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChecklistItem) Reset() {
+	*x = ChecklistItem{}
+	mi := &file_todo_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChecklistItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChecklistItem) ProtoMessage() {}
+
+func (x *ChecklistItem) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChecklistItem.ProtoReflect.Descriptor instead.
+func (*ChecklistItem) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ChecklistItem) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ChecklistItem) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *ChecklistItem) GetDone() bool {
+	if x != nil {
+		return x.Done
+	}
+	return false
+}
+
+// id — задача, item — пункт (кроме добавления), text — при добавлении,
+// position — куда переставить (с нуля)
+type ChecklistRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Item          int64                  `protobuf:"varint,2,opt,name=item,proto3" json:"item,omitempty"`
+	Text          string                 `protobuf:"bytes,3,opt,name=text,proto3" json:"text,omitempty"`
+	Position      int32                  `protobuf:"varint,4,opt,name=position,proto3" json:"position,omitempty"`
+	Project       int64                  `protobuf:"varint,5,opt,name=project,proto3" json:"project,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChecklistRequest) Reset() {
+	*x = ChecklistRequest{}
+	mi := &file_todo_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChecklistRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChecklistRequest) ProtoMessage() {}
+
+func (x *ChecklistRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChecklistRequest.ProtoReflect.Descriptor instead.
+func (*ChecklistRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ChecklistRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ChecklistRequest) GetItem() int64 {
+	if x != nil {
+		return x.Item
+	}
+	return 0
+}
+
+func (x *ChecklistRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *ChecklistRequest) GetPosition() int32 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *ChecklistRequest) GetProject() int64 {
+	if x != nil {
+		return x.Project
+	}
+	return 0
+}
+
+// Запросы/ответы. Поле project во всех запросах к задачам: 0 — общий проект
 type TaskID struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Project       int64                  `protobuf:"varint,2,opt,name=project,proto3" json:"project,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskID) Reset() {
+	*x = TaskID{}
+	mi := &file_todo_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskID) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskID) ProtoMessage() {}
+
+func (x *TaskID) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskID.ProtoReflect.Descriptor instead.
+func (*TaskID) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *TaskID) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *TaskID) GetProject() int64 {
+	if x != nil {
+		return x.Project
+	}
+	return 0
+}
+
+// Вызовы, которым нужен только проект
+type ProjectRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Project       int64                  `protobuf:"varint,1,opt,name=project,proto3" json:"project,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProjectRequest) Reset() {
+	*x = ProjectRequest{}
+	mi := &file_todo_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProjectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProjectRequest) ProtoMessage() {}
+
+func (x *ProjectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProjectRequest.ProtoReflect.Descriptor instead.
+func (*ProjectRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ProjectRequest) GetProject() int64 {
+	if x != nil {
+		return x.Project
+	}
+	return 0
+}
+
+type Project struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Members       []int64                `protobuf:"varint,3,rep,packed,name=members,proto3" json:"members,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Project) Reset() {
+	*x = Project{}
+	mi := &file_todo_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Project) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Project) ProtoMessage() {}
+
+func (x *Project) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Project.ProtoReflect.Descriptor instead.
+func (*Project) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Project) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Project) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Project) GetMembers() []int64 {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+type ProjectList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*Project             `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProjectList) Reset() {
+	*x = ProjectList{}
+	mi := &file_todo_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProjectList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProjectList) ProtoMessage() {}
+
+func (x *ProjectList) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProjectList.ProtoReflect.Descriptor instead.
+func (*ProjectList) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ProjectList) GetItems() []*Project {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type CreateTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Priority      int32                  `protobuf:"varint,3,opt,name=priority,proto3" json:"priority,omitempty"`
+	DueAt         string                 `protobuf:"bytes,4,opt,name=due_at,json=dueAt,proto3" json:"due_at,omitempty"`           // optional (format YYYY-MM-DD)
+	ParentId      int64                  `protobuf:"varint,5,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"` // optional: создать как подзадачу
+	Recurrence    string                 `protobuf:"bytes,6,opt,name=recurrence,proto3" json:"recurrence,omitempty"`              // optional: RRULE, например FREQ=WEEKLY;BYDAY=MO
+	Tags          []string               `protobuf:"bytes,7,rep,name=tags,proto3" json:"tags,omitempty"`
+	Assignee      int64                  `protobuf:"varint,8,opt,name=assignee,proto3" json:"assignee,omitempty"` // optional: ID исполнителя
+	Project       int64                  `protobuf:"varint,9,opt,name=project,proto3" json:"project,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTaskRequest) Reset() {
+	*x = CreateTaskRequest{}
+	mi := &file_todo_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTaskRequest) ProtoMessage() {}
+
+func (x *CreateTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTaskRequest.ProtoReflect.Descriptor instead.
+func (*CreateTaskRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CreateTaskRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *CreateTaskRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateTaskRequest) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *CreateTaskRequest) GetDueAt() string {
+	if x != nil {
+		return x.DueAt
+	}
+	return ""
+}
+
+func (x *CreateTaskRequest) GetParentId() int64 {
+	if x != nil {
+		return x.ParentId
+	}
+	return 0
+}
+
+func (x *CreateTaskRequest) GetRecurrence() string {
+	if x != nil {
+		return x.Recurrence
+	}
+	return ""
+}
+
+func (x *CreateTaskRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *CreateTaskRequest) GetAssignee() int64 {
+	if x != nil {
+		return x.Assignee
+	}
+	return 0
+}
+
+func (x *CreateTaskRequest) GetProject() int64 {
+	if x != nil {
+		return x.Project
+	}
+	return 0
+}
+
+type CreateTaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTaskResponse) Reset() {
+	*x = CreateTaskResponse{}
+	mi := &file_todo_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTaskResponse) ProtoMessage() {}
+
+func (x *CreateTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTaskResponse.ProtoReflect.Descriptor instead.
+func (*CreateTaskResponse) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CreateTaskResponse) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type UpdateTaskRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title           string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description     string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Status          string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Priority        int32                  `protobuf:"varint,5,opt,name=priority,proto3" json:"priority,omitempty"`
+	DueAt           string                 `protobuf:"bytes,6,opt,name=due_at,json=dueAt,proto3" json:"due_at,omitempty"`
+	ParentId        *int64                 `protobuf:"varint,7,opt,name=parent_id,json=parentId,proto3,oneof" json:"parent_id,omitempty"` // 0 — вынести на верхний уровень
+	Recurrence      string                 `protobuf:"bytes,8,opt,name=recurrence,proto3" json:"recurrence,omitempty"`                    // RRULE, "-" — убрать повторение
+	Assignee        *int64                 `protobuf:"varint,9,opt,name=assignee,proto3,oneof" json:"assignee,omitempty"`                 // 0 — снять исполнителя
+	Project         int64                  `protobuf:"varint,10,opt,name=project,proto3" json:"project,omitempty"`
+	ExpectedVersion *int64                 `protobuf:"varint,11,opt,name=expected_version,json=expectedVersion,proto3,oneof" json:"expected_version,omitempty"` // задан — менять, только если задача в этой версии, иначе ABORTED
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *UpdateTaskRequest) Reset() {
+	*x = UpdateTaskRequest{}
+	mi := &file_todo_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTaskRequest) ProtoMessage() {}
+
+func (x *UpdateTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTaskRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTaskRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UpdateTaskRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateTaskRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *UpdateTaskRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *UpdateTaskRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *UpdateTaskRequest) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *UpdateTaskRequest) GetDueAt() string {
+	if x != nil {
+		return x.DueAt
+	}
+	return ""
+}
+
+func (x *UpdateTaskRequest) GetParentId() int64 {
+	if x != nil && x.ParentId != nil {
+		return *x.ParentId
+	}
+	return 0
+}
+
+func (x *UpdateTaskRequest) GetRecurrence() string {
+	if x != nil {
+		return x.Recurrence
+	}
+	return ""
+}
+
+func (x *UpdateTaskRequest) GetAssignee() int64 {
+	if x != nil && x.Assignee != nil {
+		return *x.Assignee
+	}
+	return 0
+}
+
+func (x *UpdateTaskRequest) GetProject() int64 {
+	if x != nil {
+		return x.Project
+	}
+	return 0
+}
+
+func (x *UpdateTaskRequest) GetExpectedVersion() int64 {
+	if x != nil && x.ExpectedVersion != nil {
+		return *x.ExpectedVersion
+	}
+	return 0
+}
+
+// Patch: поля из update_mask берутся из task, даже пустые — так их и очищают.
+// Пути: title, description, status, priority, due_at, parent_id, recurrence, assignee
+type PatchTaskRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Project         int64                  `protobuf:"varint,2,opt,name=project,proto3" json:"project,omitempty"`
+	Task            *Task                  `protobuf:"bytes,3,opt,name=task,proto3" json:"task,omitempty"`
+	UpdateMask      *fieldmaskpb.FieldMask `protobuf:"bytes,4,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	ExpectedVersion *int64                 `protobuf:"varint,5,opt,name=expected_version,json=expectedVersion,proto3,oneof" json:"expected_version,omitempty"` // как в UpdateTaskRequest
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *PatchTaskRequest) Reset() {
+	*x = PatchTaskRequest{}
+	mi := &file_todo_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PatchTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PatchTaskRequest) ProtoMessage() {}
+
+func (x *PatchTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PatchTaskRequest.ProtoReflect.Descriptor instead.
+func (*PatchTaskRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PatchTaskRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *PatchTaskRequest) GetProject() int64 {
+	if x != nil {
+		return x.Project
+	}
+	return 0
+}
+
+func (x *PatchTaskRequest) GetTask() *Task {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+func (x *PatchTaskRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+func (x *PatchTaskRequest) GetExpectedVersion() int64 {
+	if x != nil && x.ExpectedVersion != nil {
+		return *x.ExpectedVersion
+	}
+	return 0
+}
+
+type Empty struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Empty) Reset() {
+	*x = Empty{}
+	mi := &file_todo_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Empty) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Empty) ProtoMessage() {}
+
+func (x *Empty) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Empty.ProtoReflect.Descriptor instead.
+func (*Empty) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{11}
+}
+
+type LoginRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Login         string                 `protobuf:"bytes,1,opt,name=login,proto3" json:"login,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoginRequest) Reset() {
+	*x = LoginRequest{}
+	mi := &file_todo_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginRequest) ProtoMessage() {}
+
+func (x *LoginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoginRequest.ProtoReflect.Descriptor instead.
+func (*LoginRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *LoginRequest) GetLogin() string {
+	if x != nil {
+		return x.Login
+	}
+	return ""
+}
+
+func (x *LoginRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+// Токен передаётся в остальные вызовы метаданными authorization: Bearer <token>
+type LoginResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	RefreshToken  string                 `protobuf:"bytes,2,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"` // одноразовый, для Refresh
+	ExpiresIn     int32                  `protobuf:"varint,3,opt,name=expires_in,json=expiresIn,proto3" json:"expires_in,omitempty"`         // секунд до истечения token
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoginResponse) Reset() {
+	*x = LoginResponse{}
+	mi := &file_todo_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginResponse) ProtoMessage() {}
+
+func (x *LoginResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoginResponse.ProtoReflect.Descriptor instead.
+func (*LoginResponse) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *LoginResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *LoginResponse) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
+	}
+	return ""
+}
+
+func (x *LoginResponse) GetExpiresIn() int32 {
+	if x != nil {
+		return x.ExpiresIn
+	}
+	return 0
+}
+
+type RefreshRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RefreshToken  string                 `protobuf:"bytes,1,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefreshRequest) Reset() {
+	*x = RefreshRequest{}
+	mi := &file_todo_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshRequest) ProtoMessage() {}
+
+func (x *RefreshRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshRequest.ProtoReflect.Descriptor instead.
+func (*RefreshRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RefreshRequest) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
+	}
+	return ""
+}
+
+type TagRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Tag           string                 `protobuf:"bytes,2,opt,name=tag,proto3" json:"tag,omitempty"`
+	Project       int64                  `protobuf:"varint,3,opt,name=project,proto3" json:"project,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TagRequest) Reset() {
+	*x = TagRequest{}
+	mi := &file_todo_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TagRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TagRequest) ProtoMessage() {}
+
+func (x *TagRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TagRequest.ProtoReflect.Descriptor instead.
+func (*TagRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *TagRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *TagRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *TagRequest) GetProject() int64 {
+	if x != nil {
+		return x.Project
+	}
+	return 0
+}
+
+type TagCount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tag           string                 `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TagCount) Reset() {
+	*x = TagCount{}
+	mi := &file_todo_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TagCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TagCount) ProtoMessage() {}
+
+func (x *TagCount) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TagCount.ProtoReflect.Descriptor instead.
+func (*TagCount) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *TagCount) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *TagCount) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type TagCountsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*TagCount            `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"` // частые первыми
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TagCountsResponse) Reset() {
+	*x = TagCountsResponse{}
+	mi := &file_todo_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TagCountsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TagCountsResponse) ProtoMessage() {}
+
+func (x *TagCountsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TagCountsResponse.ProtoReflect.Descriptor instead.
+func (*TagCountsResponse) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *TagCountsResponse) GetItems() []*TagCount {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+// Задачу id нельзя начать, пока не сделана blocked_by
+type DependencyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	BlockedBy     int64                  `protobuf:"varint,2,opt,name=blocked_by,json=blockedBy,proto3" json:"blocked_by,omitempty"`
+	Project       int64                  `protobuf:"varint,3,opt,name=project,proto3" json:"project,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DependencyRequest) Reset() {
+	*x = DependencyRequest{}
+	mi := &file_todo_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DependencyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DependencyRequest) ProtoMessage() {}
+
+func (x *DependencyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DependencyRequest.ProtoReflect.Descriptor instead.
+func (*DependencyRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *DependencyRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DependencyRequest) GetBlockedBy() int64 {
+	if x != nil {
+		return x.BlockedBy
+	}
+	return 0
+}
+
+func (x *DependencyRequest) GetProject() int64 {
+	if x != nil {
+		return x.Project
+	}
+	return 0
+}
+
+// Прогресс поддерева: отменённые подзадачи не считаются
+type Progress struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Total         int32                  `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	Done          int32                  `protobuf:"varint,2,opt,name=done,proto3" json:"done,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Progress) Reset() {
+	*x = Progress{}
+	mi := &file_todo_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Progress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Progress) ProtoMessage() {}
+
+func (x *Progress) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Progress.ProtoReflect.Descriptor instead.
+func (*Progress) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Progress) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *Progress) GetDone() int32 {
+	if x != nil {
+		return x.Done
+	}
+	return 0
+}
+
+type TaskList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*Task                `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskList) Reset() {
+	*x = TaskList{}
+	mi := &file_todo_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskList) ProtoMessage() {}
+
+func (x *TaskList) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskList.ProtoReflect.Descriptor instead.
+func (*TaskList) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *TaskList) GetItems() []*Task {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+// Выборка с фильтрами, сортировкой и постраничной выдачей.
+// Даты в формате YYYY-MM-DD, пустые поля — без ограничения.
+type ListTasksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Statuses      []string               `protobuf:"bytes,1,rep,name=statuses,proto3" json:"statuses,omitempty"`
+	MinPriority   int32                  `protobuf:"varint,2,opt,name=min_priority,json=minPriority,proto3" json:"min_priority,omitempty"`
+	MaxPriority   int32                  `protobuf:"varint,3,opt,name=max_priority,json=maxPriority,proto3" json:"max_priority,omitempty"`
+	DueAfter      string                 `protobuf:"bytes,4,opt,name=due_after,json=dueAfter,proto3" json:"due_after,omitempty"`                 // due_at >= due_after
+	DueBefore     string                 `protobuf:"bytes,5,opt,name=due_before,json=dueBefore,proto3" json:"due_before,omitempty"`              // due_at < due_before
+	CreatedFrom   string                 `protobuf:"bytes,6,opt,name=created_from,json=createdFrom,proto3" json:"created_from,omitempty"`        // created_at >= created_from
+	CreatedTo     string                 `protobuf:"bytes,7,opt,name=created_to,json=createdTo,proto3" json:"created_to,omitempty"`              // created_at < created_to
+	Text          string                 `protobuf:"bytes,8,opt,name=text,proto3" json:"text,omitempty"`                                         // подстрока в заголовке или описании
+	Sort          string                 `protobuf:"bytes,9,opt,name=sort,proto3" json:"sort,omitempty"`                                         // например "priority:desc,due_at"
+	PageSize      int32                  `protobuf:"varint,10,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`               // по умолчанию 50, максимум 500
+	PageToken     string                 `protobuf:"bytes,11,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`             // next_page_token из прошлого ответа
+	Tags          []string               `protobuf:"bytes,12,rep,name=tags,proto3" json:"tags,omitempty"`                                        // есть все эти теги
+	AnyTags       []string               `protobuf:"bytes,13,rep,name=any_tags,json=anyTags,proto3" json:"any_tags,omitempty"`                   // есть хотя бы один из тегов
+	CreatedBy     int64                  `protobuf:"varint,14,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`            // автор
+	Assignee      int64                  `protobuf:"varint,15,opt,name=assignee,proto3" json:"assignee,omitempty"`                               // исполнитель
+	CreatedByMe   bool                   `protobuf:"varint,16,opt,name=created_by_me,json=createdByMe,proto3" json:"created_by_me,omitempty"`    // автор — вызывающий
+	AssignedToMe  bool                   `protobuf:"varint,17,opt,name=assigned_to_me,json=assignedToMe,proto3" json:"assigned_to_me,omitempty"` // исполнитель — вызывающий
+	Project       int64                  `protobuf:"varint,18,opt,name=project,proto3" json:"project,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTasksRequest) Reset() {
+	*x = ListTasksRequest{}
+	mi := &file_todo_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTasksRequest) ProtoMessage() {}
+
+func (x *ListTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTasksRequest.ProtoReflect.Descriptor instead.
+func (*ListTasksRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListTasksRequest) GetStatuses() []string {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *ListTasksRequest) GetMinPriority() int32 {
+	if x != nil {
+		return x.MinPriority
+	}
+	return 0
+}
+
+func (x *ListTasksRequest) GetMaxPriority() int32 {
+	if x != nil {
+		return x.MaxPriority
+	}
+	return 0
+}
+
+func (x *ListTasksRequest) GetDueAfter() string {
+	if x != nil {
+		return x.DueAfter
+	}
+	return ""
+}
+
+func (x *ListTasksRequest) GetDueBefore() string {
+	if x != nil {
+		return x.DueBefore
+	}
+	return ""
+}
+
+func (x *ListTasksRequest) GetCreatedFrom() string {
+	if x != nil {
+		return x.CreatedFrom
+	}
+	return ""
+}
+
+func (x *ListTasksRequest) GetCreatedTo() string {
+	if x != nil {
+		return x.CreatedTo
+	}
+	return ""
+}
+
+func (x *ListTasksRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *ListTasksRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+func (x *ListTasksRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListTasksRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListTasksRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *ListTasksRequest) GetAnyTags() []string {
+	if x != nil {
+		return x.AnyTags
+	}
+	return nil
+}
+
+func (x *ListTasksRequest) GetCreatedBy() int64 {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return 0
+}
+
+func (x *ListTasksRequest) GetAssignee() int64 {
+	if x != nil {
+		return x.Assignee
+	}
+	return 0
+}
+
+func (x *ListTasksRequest) GetCreatedByMe() bool {
+	if x != nil {
+		return x.CreatedByMe
+	}
+	return false
+}
+
+func (x *ListTasksRequest) GetAssignedToMe() bool {
+	if x != nil {
+		return x.AssignedToMe
+	}
+	return false
+}
+
+func (x *ListTasksRequest) GetProject() int64 {
+	if x != nil {
+		return x.Project
+	}
+	return 0
+}
+
+type ListTasksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*Task                `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"` // пусто — страниц больше нет
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTasksResponse) Reset() {
+	*x = ListTasksResponse{}
+	mi := &file_todo_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTasksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTasksResponse) ProtoMessage() {}
+
+func (x *ListTasksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTasksResponse.ProtoReflect.Descriptor instead.
+func (*ListTasksResponse) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ListTasksResponse) GetItems() []*Task {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *ListTasksResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+// Полнотекстовый поиск: все слова запроса должны встретиться в заголовке или описании
+type SearchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"` // по умолчанию 20, максимум 100
+	Project       int64                  `protobuf:"varint,3,opt,name=project,proto3" json:"project,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_todo_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *SearchRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetProject() int64 {
+	if x != nil {
+		return x.Project
+	}
+	return 0
+}
+
+type SearchHit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Task          *Task                  `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`
+	Rank          float64                `protobuf:"fixed64,2,opt,name=rank,proto3" json:"rank,omitempty"`
+	Snippet       string                 `protobuf:"bytes,3,opt,name=snippet,proto3" json:"snippet,omitempty"` // HTML: текст экранирован, совпадения обёрнуты в <b></b>
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchHit) Reset() {
+	*x = SearchHit{}
+	mi := &file_todo_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchHit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchHit) ProtoMessage() {}
+
+func (x *SearchHit) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchHit.ProtoReflect.Descriptor instead.
+func (*SearchHit) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *SearchHit) GetTask() *Task {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+func (x *SearchHit) GetRank() float64 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *SearchHit) GetSnippet() string {
+	if x != nil {
+		return x.Snippet
+	}
+	return ""
+}
+
+type SearchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hits          []*SearchHit           `protobuf:"bytes,1,rep,name=hits,proto3" json:"hits,omitempty"` // лучшие первыми
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_todo_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *SearchResponse) GetHits() []*SearchHit {
+	if x != nil {
+		return x.Hits
+	}
+	return nil
+}
+
+// Комментарий к задаче, body — markdown
+type Comment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	TaskId        int64                  `protobuf:"varint,2,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Author        int64                  `protobuf:"varint,3,opt,name=author,proto3" json:"author,omitempty"` // 0 — автор удалён
+	Body          string                 `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	EditedAt      string                 `protobuf:"bytes,6,opt,name=edited_at,json=editedAt,proto3" json:"edited_at,omitempty"` // пусто — не правился
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Comment) Reset() {
+	*x = Comment{}
+	mi := &file_todo_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Comment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Comment) ProtoMessage() {}
+
+func (x *Comment) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Comment.ProtoReflect.Descriptor instead.
+func (*Comment) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *Comment) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Comment) GetTaskId() int64 {
+	if x != nil {
+		return x.TaskId
+	}
+	return 0
+}
+
+func (x *Comment) GetAuthor() int64 {
+	if x != nil {
+		return x.Author
+	}
+	return 0
+}
+
+func (x *Comment) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *Comment) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Comment) GetEditedAt() string {
+	if x != nil {
+		return x.EditedAt
+	}
+	return ""
+}
+
+type CommentList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*Comment             `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"` // старые первыми
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommentList) Reset() {
+	*x = CommentList{}
+	mi := &file_todo_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommentList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommentList) ProtoMessage() {}
+
+func (x *CommentList) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommentList.ProtoReflect.Descriptor instead.
+func (*CommentList) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *CommentList) GetItems() []*Comment {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+// id — задача, comment_id — комментарий (правка и удаление)
+type CommentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	CommentId     int64                  `protobuf:"varint,2,opt,name=comment_id,json=commentId,proto3" json:"comment_id,omitempty"`
+	Body          string                 `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
+	Project       int64                  `protobuf:"varint,4,opt,name=project,proto3" json:"project,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommentRequest) Reset() {
+	*x = CommentRequest{}
+	mi := &file_todo_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommentRequest) ProtoMessage() {}
+
+func (x *CommentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommentRequest.ProtoReflect.Descriptor instead.
+func (*CommentRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *CommentRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *CommentRequest) GetCommentId() int64 {
+	if x != nil {
+		return x.CommentId
+	}
+	return 0
+}
+
+func (x *CommentRequest) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *CommentRequest) GetProject() int64 {
+	if x != nil {
+		return x.Project
+	}
+	return 0
+}
+
+// Вложение: описание, само содержимое — DownloadAttachment
+type Attachment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	TaskId        int64                  `protobuf:"varint,2,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Size          int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	ContentType   string                 `protobuf:"bytes,5,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Checksum      string                 `protobuf:"bytes,6,opt,name=checksum,proto3" json:"checksum,omitempty"`                        // SHA-256, hex
+	UploadedBy    int64                  `protobuf:"varint,7,opt,name=uploaded_by,json=uploadedBy,proto3" json:"uploaded_by,omitempty"` // 0 — загрузил удалённый пользователь или консоль
+	CreatedAt     string                 `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Attachment) Reset() {
+	*x = Attachment{}
+	mi := &file_todo_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Attachment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Attachment) ProtoMessage() {}
+
+func (x *Attachment) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Attachment.ProtoReflect.Descriptor instead.
+func (*Attachment) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *Attachment) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Attachment) GetTaskId() int64 {
+	if x != nil {
+		return x.TaskId
+	}
+	return 0
+}
+
+func (x *Attachment) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Attachment) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Attachment) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *Attachment) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
+func (x *Attachment) GetUploadedBy() int64 {
+	if x != nil {
+		return x.UploadedBy
+	}
+	return 0
+}
+
+func (x *Attachment) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+type AttachmentList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*Attachment          `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"` // старые первыми
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TaskID) Reset() {
-	*x = TaskID{}
-	mi := &file_todo_proto_msgTypes[1]
+func (x *AttachmentList) Reset() {
+	*x = AttachmentList{}
+	mi := &file_todo_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TaskID) String() string {
+func (x *AttachmentList) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TaskID) ProtoMessage() {}
+func (*AttachmentList) ProtoMessage() {}
 
-func (x *TaskID) ProtoReflect() protoreflect.Message {
-	mi := &file_todo_proto_msgTypes[1]
+func (x *AttachmentList) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -163,43 +2143,46 @@ func (x *TaskID) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TaskID.ProtoReflect.Descriptor instead.
-func (*TaskID) Descriptor() ([]byte, []int) {
-	return file_todo_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use AttachmentList.ProtoReflect.Descriptor instead.
+func (*AttachmentList) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{30}
 }
 
-func (x *TaskID) GetId() int64 {
+func (x *AttachmentList) GetItems() []*Attachment {
 	if x != nil {
-		return x.Id
+		return x.Items
 	}
-	return 0
+	return nil
 }
 
-type CreateTaskRequest struct {
+// Загрузка потоком: в первом сообщении — задача и файл (id, project, name, content_type),
+// дальше только data. Пустой content_type угадывается по имени
+type AttachmentChunk struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
-	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
-	Priority      int32                  `protobuf:"varint,3,opt,name=priority,proto3" json:"priority,omitempty"`
-	DueAt         string                 `protobuf:"bytes,4,opt,name=due_at,json=dueAt,proto3" json:"due_at,omitempty"` // optional (format YYYY-MM-DD)
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Project       int64                  `protobuf:"varint,2,opt,name=project,proto3" json:"project,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	ContentType   string                 `protobuf:"bytes,4,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Data          []byte                 `protobuf:"bytes,5,opt,name=data,proto3" json:"data,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateTaskRequest) Reset() {
-	*x = CreateTaskRequest{}
-	mi := &file_todo_proto_msgTypes[2]
+func (x *AttachmentChunk) Reset() {
+	*x = AttachmentChunk{}
+	mi := &file_todo_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateTaskRequest) String() string {
+func (x *AttachmentChunk) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateTaskRequest) ProtoMessage() {}
+func (*AttachmentChunk) ProtoMessage() {}
 
-func (x *CreateTaskRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_todo_proto_msgTypes[2]
+func (x *AttachmentChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -210,61 +2193,71 @@ func (x *CreateTaskRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateTaskRequest.ProtoReflect.Descriptor instead.
-func (*CreateTaskRequest) Descriptor() ([]byte, []int) {
-	return file_todo_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use AttachmentChunk.ProtoReflect.Descriptor instead.
+func (*AttachmentChunk) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{31}
 }
 
-func (x *CreateTaskRequest) GetTitle() string {
+func (x *AttachmentChunk) GetId() int64 {
 	if x != nil {
-		return x.Title
+		return x.Id
 	}
-	return ""
+	return 0
 }
 
-func (x *CreateTaskRequest) GetDescription() string {
+func (x *AttachmentChunk) GetProject() int64 {
 	if x != nil {
-		return x.Description
+		return x.Project
 	}
-	return ""
+	return 0
 }
 
-func (x *CreateTaskRequest) GetPriority() int32 {
+func (x *AttachmentChunk) GetName() string {
 	if x != nil {
-		return x.Priority
+		return x.Name
 	}
-	return 0
+	return ""
 }
 
-func (x *CreateTaskRequest) GetDueAt() string {
+func (x *AttachmentChunk) GetContentType() string {
 	if x != nil {
-		return x.DueAt
+		return x.ContentType
 	}
 	return ""
 }
 
-type CreateTaskResponse struct {
+func (x *AttachmentChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// id — задача, attachment_id — вложение
+type AttachmentRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	AttachmentId  int64                  `protobuf:"varint,2,opt,name=attachment_id,json=attachmentId,proto3" json:"attachment_id,omitempty"`
+	Project       int64                  `protobuf:"varint,3,opt,name=project,proto3" json:"project,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateTaskResponse) Reset() {
-	*x = CreateTaskResponse{}
-	mi := &file_todo_proto_msgTypes[3]
+func (x *AttachmentRequest) Reset() {
+	*x = AttachmentRequest{}
+	mi := &file_todo_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateTaskResponse) String() string {
+func (x *AttachmentRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateTaskResponse) ProtoMessage() {}
+func (*AttachmentRequest) ProtoMessage() {}
 
-func (x *CreateTaskResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_todo_proto_msgTypes[3]
+func (x *AttachmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -275,45 +2268,56 @@ func (x *CreateTaskResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateTaskResponse.ProtoReflect.Descriptor instead.
-func (*CreateTaskResponse) Descriptor() ([]byte, []int) {
-	return file_todo_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use AttachmentRequest.ProtoReflect.Descriptor instead.
+func (*AttachmentRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{32}
 }
 
-func (x *CreateTaskResponse) GetId() int64 {
+func (x *AttachmentRequest) GetId() int64 {
 	if x != nil {
 		return x.Id
 	}
 	return 0
 }
 
-type UpdateTaskRequest struct {
+func (x *AttachmentRequest) GetAttachmentId() int64 {
+	if x != nil {
+		return x.AttachmentId
+	}
+	return 0
+}
+
+func (x *AttachmentRequest) GetProject() int64 {
+	if x != nil {
+		return x.Project
+	}
+	return 0
+}
+
+// Скачивание потоком: в первом сообщении — info, дальше только data
+type AttachmentData struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
-	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
-	Priority      int32                  `protobuf:"varint,5,opt,name=priority,proto3" json:"priority,omitempty"`
-	DueAt         string                 `protobuf:"bytes,6,opt,name=due_at,json=dueAt,proto3" json:"due_at,omitempty"`
+	Info          *Attachment            `protobuf:"bytes,1,opt,name=info,proto3" json:"info,omitempty"`
+	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateTaskRequest) Reset() {
-	*x = UpdateTaskRequest{}
-	mi := &file_todo_proto_msgTypes[4]
+func (x *AttachmentData) Reset() {
+	*x = AttachmentData{}
+	mi := &file_todo_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateTaskRequest) String() string {
+func (x *AttachmentData) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateTaskRequest) ProtoMessage() {}
+func (*AttachmentData) ProtoMessage() {}
 
-func (x *UpdateTaskRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_todo_proto_msgTypes[4]
+func (x *AttachmentData) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -324,74 +2328,103 @@ func (x *UpdateTaskRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateTaskRequest.ProtoReflect.Descriptor instead.
-func (*UpdateTaskRequest) Descriptor() ([]byte, []int) {
-	return file_todo_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use AttachmentData.ProtoReflect.Descriptor instead.
+func (*AttachmentData) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{33}
 }
 
-func (x *UpdateTaskRequest) GetId() int64 {
+func (x *AttachmentData) GetInfo() *Attachment {
 	if x != nil {
-		return x.Id
+		return x.Info
 	}
-	return 0
+	return nil
 }
 
-func (x *UpdateTaskRequest) GetTitle() string {
+func (x *AttachmentData) GetData() []byte {
 	if x != nil {
-		return x.Title
+		return x.Data
 	}
-	return ""
+	return nil
 }
 
-func (x *UpdateTaskRequest) GetDescription() string {
-	if x != nil {
-		return x.Description
-	}
-	return ""
+// steps — сколько своих операций отменить или повторить, 0 — одну
+type UndoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Steps         int32                  `protobuf:"varint,1,opt,name=steps,proto3" json:"steps,omitempty"`
+	Project       int64                  `protobuf:"varint,2,opt,name=project,proto3" json:"project,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateTaskRequest) GetStatus() string {
+func (x *UndoRequest) Reset() {
+	*x = UndoRequest{}
+	mi := &file_todo_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UndoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UndoRequest) ProtoMessage() {}
+
+func (x *UndoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[34]
 	if x != nil {
-		return x.Status
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *UpdateTaskRequest) GetPriority() int32 {
+// Deprecated: Use UndoRequest.ProtoReflect.Descriptor instead.
+func (*UndoRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *UndoRequest) GetSteps() int32 {
 	if x != nil {
-		return x.Priority
+		return x.Steps
 	}
 	return 0
 }
 
-func (x *UpdateTaskRequest) GetDueAt() string {
+func (x *UndoRequest) GetProject() int64 {
 	if x != nil {
-		return x.DueAt
+		return x.Project
 	}
-	return ""
+	return 0
 }
 
-type Empty struct {
+// Отменённая или повторённая операция
+type UndoStep struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ops           []string               `protobuf:"bytes,1,rep,name=ops,proto3" json:"ops,omitempty"`                                // события по порядку: "delete", "set_status"...
+	At            string                 `protobuf:"bytes,2,opt,name=at,proto3" json:"at,omitempty"`                                  // когда её сделали
+	TaskIds       []int64                `protobuf:"varint,3,rep,packed,name=task_ids,json=taskIds,proto3" json:"task_ids,omitempty"` // у перенумерации пусто
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Empty) Reset() {
-	*x = Empty{}
-	mi := &file_todo_proto_msgTypes[5]
+func (x *UndoStep) Reset() {
+	*x = UndoStep{}
+	mi := &file_todo_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Empty) String() string {
+func (x *UndoStep) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Empty) ProtoMessage() {}
+func (*UndoStep) ProtoMessage() {}
 
-func (x *Empty) ProtoReflect() protoreflect.Message {
-	mi := &file_todo_proto_msgTypes[5]
+func (x *UndoStep) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -402,33 +2435,55 @@ func (x *Empty) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Empty.ProtoReflect.Descriptor instead.
-func (*Empty) Descriptor() ([]byte, []int) {
-	return file_todo_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use UndoStep.ProtoReflect.Descriptor instead.
+func (*UndoStep) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{35}
 }
 
-type TaskList struct {
+func (x *UndoStep) GetOps() []string {
+	if x != nil {
+		return x.Ops
+	}
+	return nil
+}
+
+func (x *UndoStep) GetAt() string {
+	if x != nil {
+		return x.At
+	}
+	return ""
+}
+
+func (x *UndoStep) GetTaskIds() []int64 {
+	if x != nil {
+		return x.TaskIds
+	}
+	return nil
+}
+
+type UndoResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Items         []*Task                `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	Steps         []*UndoStep            `protobuf:"bytes,1,rep,name=steps,proto3" json:"steps,omitempty"` // последние первыми
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"` // почему остановились раньше, чем просили
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TaskList) Reset() {
-	*x = TaskList{}
-	mi := &file_todo_proto_msgTypes[6]
+func (x *UndoResponse) Reset() {
+	*x = UndoResponse{}
+	mi := &file_todo_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TaskList) String() string {
+func (x *UndoResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TaskList) ProtoMessage() {}
+func (*UndoResponse) ProtoMessage() {}
 
-func (x *TaskList) ProtoReflect() protoreflect.Message {
-	mi := &file_todo_proto_msgTypes[6]
+func (x *UndoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -439,24 +2494,31 @@ func (x *TaskList) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TaskList.ProtoReflect.Descriptor instead.
-func (*TaskList) Descriptor() ([]byte, []int) {
-	return file_todo_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use UndoResponse.ProtoReflect.Descriptor instead.
+func (*UndoResponse) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{36}
 }
 
-func (x *TaskList) GetItems() []*Task {
+func (x *UndoResponse) GetSteps() []*UndoStep {
 	if x != nil {
-		return x.Items
+		return x.Steps
 	}
 	return nil
 }
 
+func (x *UndoResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
 var File_todo_proto protoreflect.FileDescriptor
 
 const file_todo_proto_rawDesc = "" +
 	"\n" +
 	"\n" +
-	"todo.proto\x12\x04todo\"\xfa\x01\n" +
+	"todo.proto\x12\x04todo\x1a google/protobuf/field_mask.proto\"\xb2\x04\n" +
 	"\x04Task\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
 	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
@@ -468,35 +2530,272 @@ const file_todo_proto_rawDesc = "" +
 	"created_at\x18\a \x01(\tR\tcreatedAt\x12\x1d\n" +
 	"\n" +
 	"updated_at\x18\b \x01(\tR\tupdatedAt\x12!\n" +
-	"\fcompleted_at\x18\t \x01(\tR\vcompletedAt\"\x18\n" +
+	"\fcompleted_at\x18\t \x01(\tR\vcompletedAt\x12\x1b\n" +
+	"\tparent_id\x18\n" +
+	" \x01(\x03R\bparentId\x12\x1d\n" +
+	"\n" +
+	"blocked_by\x18\v \x03(\x03R\tblockedBy\x12\x1e\n" +
+	"\n" +
+	"recurrence\x18\f \x01(\tR\n" +
+	"recurrence\x12\x12\n" +
+	"\x04tags\x18\r \x03(\tR\x04tags\x12\x1d\n" +
+	"\n" +
+	"created_by\x18\x0e \x01(\x03R\tcreatedBy\x12\x1a\n" +
+	"\bassignee\x18\x0f \x01(\x03R\bassignee\x121\n" +
+	"\tchecklist\x18\x10 \x03(\v2\x13.todo.ChecklistItemR\tchecklist\x12\x18\n" +
+	"\aversion\x18\x11 \x01(\x03R\aversion\x12\x1d\n" +
+	"\n" +
+	"deleted_at\x18\x12 \x01(\tR\tdeletedAt\x12\x1f\n" +
+	"\varchived_at\x18\x13 \x01(\tR\n" +
+	"archivedAt\"G\n" +
+	"\rChecklistItem\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\x12\x12\n" +
+	"\x04done\x18\x03 \x01(\bR\x04done\"\x80\x01\n" +
+	"\x10ChecklistRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04item\x18\x02 \x01(\x03R\x04item\x12\x12\n" +
+	"\x04text\x18\x03 \x01(\tR\x04text\x12\x1a\n" +
+	"\bposition\x18\x04 \x01(\x05R\bposition\x12\x18\n" +
+	"\aproject\x18\x05 \x01(\x03R\aproject\"2\n" +
 	"\x06TaskID\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x03R\x02id\"~\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x18\n" +
+	"\aproject\x18\x02 \x01(\x03R\aproject\"*\n" +
+	"\x0eProjectRequest\x12\x18\n" +
+	"\aproject\x18\x01 \x01(\x03R\aproject\"G\n" +
+	"\aProject\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\amembers\x18\x03 \x03(\x03R\amembers\"2\n" +
+	"\vProjectList\x12#\n" +
+	"\x05items\x18\x01 \x03(\v2\r.todo.ProjectR\x05items\"\x85\x02\n" +
 	"\x11CreateTaskRequest\x12\x14\n" +
 	"\x05title\x18\x01 \x01(\tR\x05title\x12 \n" +
 	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x1a\n" +
 	"\bpriority\x18\x03 \x01(\x05R\bpriority\x12\x15\n" +
-	"\x06due_at\x18\x04 \x01(\tR\x05dueAt\"$\n" +
+	"\x06due_at\x18\x04 \x01(\tR\x05dueAt\x12\x1b\n" +
+	"\tparent_id\x18\x05 \x01(\x03R\bparentId\x12\x1e\n" +
+	"\n" +
+	"recurrence\x18\x06 \x01(\tR\n" +
+	"recurrence\x12\x12\n" +
+	"\x04tags\x18\a \x03(\tR\x04tags\x12\x1a\n" +
+	"\bassignee\x18\b \x01(\x03R\bassignee\x12\x18\n" +
+	"\aproject\x18\t \x01(\x03R\aproject\"$\n" +
 	"\x12CreateTaskResponse\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x03R\x02id\"\xa6\x01\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x83\x03\n" +
 	"\x11UpdateTaskRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
 	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
 	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x16\n" +
 	"\x06status\x18\x04 \x01(\tR\x06status\x12\x1a\n" +
 	"\bpriority\x18\x05 \x01(\x05R\bpriority\x12\x15\n" +
-	"\x06due_at\x18\x06 \x01(\tR\x05dueAt\"\a\n" +
-	"\x05Empty\",\n" +
+	"\x06due_at\x18\x06 \x01(\tR\x05dueAt\x12 \n" +
+	"\tparent_id\x18\a \x01(\x03H\x00R\bparentId\x88\x01\x01\x12\x1e\n" +
+	"\n" +
+	"recurrence\x18\b \x01(\tR\n" +
+	"recurrence\x12\x1f\n" +
+	"\bassignee\x18\t \x01(\x03H\x01R\bassignee\x88\x01\x01\x12\x18\n" +
+	"\aproject\x18\n" +
+	" \x01(\x03R\aproject\x12.\n" +
+	"\x10expected_version\x18\v \x01(\x03H\x02R\x0fexpectedVersion\x88\x01\x01B\f\n" +
+	"\n" +
+	"_parent_idB\v\n" +
+	"\t_assigneeB\x13\n" +
+	"\x11_expected_version\"\xde\x01\n" +
+	"\x10PatchTaskRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x18\n" +
+	"\aproject\x18\x02 \x01(\x03R\aproject\x12\x1e\n" +
+	"\x04task\x18\x03 \x01(\v2\n" +
+	".todo.TaskR\x04task\x12;\n" +
+	"\vupdate_mask\x18\x04 \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMask\x12.\n" +
+	"\x10expected_version\x18\x05 \x01(\x03H\x00R\x0fexpectedVersion\x88\x01\x01B\x13\n" +
+	"\x11_expected_version\"\a\n" +
+	"\x05Empty\"@\n" +
+	"\fLoginRequest\x12\x14\n" +
+	"\x05login\x18\x01 \x01(\tR\x05login\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"i\n" +
+	"\rLoginResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12#\n" +
+	"\rrefresh_token\x18\x02 \x01(\tR\frefreshToken\x12\x1d\n" +
+	"\n" +
+	"expires_in\x18\x03 \x01(\x05R\texpiresIn\"5\n" +
+	"\x0eRefreshRequest\x12#\n" +
+	"\rrefresh_token\x18\x01 \x01(\tR\frefreshToken\"H\n" +
+	"\n" +
+	"TagRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x10\n" +
+	"\x03tag\x18\x02 \x01(\tR\x03tag\x12\x18\n" +
+	"\aproject\x18\x03 \x01(\x03R\aproject\"2\n" +
+	"\bTagCount\x12\x10\n" +
+	"\x03tag\x18\x01 \x01(\tR\x03tag\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\"9\n" +
+	"\x11TagCountsResponse\x12$\n" +
+	"\x05items\x18\x01 \x03(\v2\x0e.todo.TagCountR\x05items\"\\\n" +
+	"\x11DependencyRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
+	"\n" +
+	"blocked_by\x18\x02 \x01(\x03R\tblockedBy\x12\x18\n" +
+	"\aproject\x18\x03 \x01(\x03R\aproject\"4\n" +
+	"\bProgress\x12\x14\n" +
+	"\x05total\x18\x01 \x01(\x05R\x05total\x12\x12\n" +
+	"\x04done\x18\x02 \x01(\x05R\x04done\",\n" +
 	"\bTaskList\x12 \n" +
 	"\x05items\x18\x01 \x03(\v2\n" +
-	".todo.TaskR\x05items2\xe4\x01\n" +
-	"\vTodoService\x12;\n" +
+	".todo.TaskR\x05items\"\xa4\x04\n" +
+	"\x10ListTasksRequest\x12\x1a\n" +
+	"\bstatuses\x18\x01 \x03(\tR\bstatuses\x12!\n" +
+	"\fmin_priority\x18\x02 \x01(\x05R\vminPriority\x12!\n" +
+	"\fmax_priority\x18\x03 \x01(\x05R\vmaxPriority\x12\x1b\n" +
+	"\tdue_after\x18\x04 \x01(\tR\bdueAfter\x12\x1d\n" +
+	"\n" +
+	"due_before\x18\x05 \x01(\tR\tdueBefore\x12!\n" +
+	"\fcreated_from\x18\x06 \x01(\tR\vcreatedFrom\x12\x1d\n" +
+	"\n" +
+	"created_to\x18\a \x01(\tR\tcreatedTo\x12\x12\n" +
+	"\x04text\x18\b \x01(\tR\x04text\x12\x12\n" +
+	"\x04sort\x18\t \x01(\tR\x04sort\x12\x1b\n" +
+	"\tpage_size\x18\n" +
+	" \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\v \x01(\tR\tpageToken\x12\x12\n" +
+	"\x04tags\x18\f \x03(\tR\x04tags\x12\x19\n" +
+	"\bany_tags\x18\r \x03(\tR\aanyTags\x12\x1d\n" +
+	"\n" +
+	"created_by\x18\x0e \x01(\x03R\tcreatedBy\x12\x1a\n" +
+	"\bassignee\x18\x0f \x01(\x03R\bassignee\x12\"\n" +
+	"\rcreated_by_me\x18\x10 \x01(\bR\vcreatedByMe\x12$\n" +
+	"\x0eassigned_to_me\x18\x11 \x01(\bR\fassignedToMe\x12\x18\n" +
+	"\aproject\x18\x12 \x01(\x03R\aproject\"]\n" +
+	"\x11ListTasksResponse\x12 \n" +
+	"\x05items\x18\x01 \x03(\v2\n" +
+	".todo.TaskR\x05items\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"U\n" +
+	"\rSearchRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x18\n" +
+	"\aproject\x18\x03 \x01(\x03R\aproject\"Y\n" +
+	"\tSearchHit\x12\x1e\n" +
+	"\x04task\x18\x01 \x01(\v2\n" +
+	".todo.TaskR\x04task\x12\x12\n" +
+	"\x04rank\x18\x02 \x01(\x01R\x04rank\x12\x18\n" +
+	"\asnippet\x18\x03 \x01(\tR\asnippet\"5\n" +
+	"\x0eSearchResponse\x12#\n" +
+	"\x04hits\x18\x01 \x03(\v2\x0f.todo.SearchHitR\x04hits\"\x9a\x01\n" +
+	"\aComment\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\atask_id\x18\x02 \x01(\x03R\x06taskId\x12\x16\n" +
+	"\x06author\x18\x03 \x01(\x03R\x06author\x12\x12\n" +
+	"\x04body\x18\x04 \x01(\tR\x04body\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\tR\tcreatedAt\x12\x1b\n" +
+	"\tedited_at\x18\x06 \x01(\tR\beditedAt\"2\n" +
+	"\vCommentList\x12#\n" +
+	"\x05items\x18\x01 \x03(\v2\r.todo.CommentR\x05items\"m\n" +
+	"\x0eCommentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
+	"\n" +
+	"comment_id\x18\x02 \x01(\x03R\tcommentId\x12\x12\n" +
+	"\x04body\x18\x03 \x01(\tR\x04body\x12\x18\n" +
+	"\aproject\x18\x04 \x01(\x03R\aproject\"\xdc\x01\n" +
+	"\n" +
+	"Attachment\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\atask_id\x18\x02 \x01(\x03R\x06taskId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\x12!\n" +
+	"\fcontent_type\x18\x05 \x01(\tR\vcontentType\x12\x1a\n" +
+	"\bchecksum\x18\x06 \x01(\tR\bchecksum\x12\x1f\n" +
+	"\vuploaded_by\x18\a \x01(\x03R\n" +
+	"uploadedBy\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\b \x01(\tR\tcreatedAt\"8\n" +
+	"\x0eAttachmentList\x12&\n" +
+	"\x05items\x18\x01 \x03(\v2\x10.todo.AttachmentR\x05items\"\x86\x01\n" +
+	"\x0fAttachmentChunk\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x18\n" +
+	"\aproject\x18\x02 \x01(\x03R\aproject\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12!\n" +
+	"\fcontent_type\x18\x04 \x01(\tR\vcontentType\x12\x12\n" +
+	"\x04data\x18\x05 \x01(\fR\x04data\"b\n" +
+	"\x11AttachmentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12#\n" +
+	"\rattachment_id\x18\x02 \x01(\x03R\fattachmentId\x12\x18\n" +
+	"\aproject\x18\x03 \x01(\x03R\aproject\"J\n" +
+	"\x0eAttachmentData\x12$\n" +
+	"\x04info\x18\x01 \x01(\v2\x10.todo.AttachmentR\x04info\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\"=\n" +
+	"\vUndoRequest\x12\x14\n" +
+	"\x05steps\x18\x01 \x01(\x05R\x05steps\x12\x18\n" +
+	"\aproject\x18\x02 \x01(\x03R\aproject\"G\n" +
+	"\bUndoStep\x12\x10\n" +
+	"\x03ops\x18\x01 \x03(\tR\x03ops\x12\x0e\n" +
+	"\x02at\x18\x02 \x01(\tR\x02at\x12\x19\n" +
+	"\btask_ids\x18\x03 \x03(\x03R\ataskIds\"J\n" +
+	"\fUndoResponse\x12$\n" +
+	"\x05steps\x18\x01 \x03(\v2\x0e.todo.UndoStepR\x05steps\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error2\xa7\x11\n" +
+	"\vTodoService\x120\n" +
+	"\x05Login\x12\x12.todo.LoginRequest\x1a\x13.todo.LoginResponse\x124\n" +
+	"\aRefresh\x12\x14.todo.RefreshRequest\x1a\x13.todo.LoginResponse\x12\"\n" +
+	"\x06Logout\x12\v.todo.Empty\x1a\v.todo.Empty\x12;\n" +
 	"\x06Create\x12\x17.todo.CreateTaskRequest\x1a\x18.todo.CreateTaskResponse\x12-\n" +
 	"\x06Update\x12\x17.todo.UpdateTaskRequest\x1a\n" +
+	".todo.Task\x12+\n" +
+	"\x05Patch\x12\x16.todo.PatchTaskRequest\x1a\n" +
 	".todo.Task\x12#\n" +
-	"\x06Delete\x12\f.todo.TaskID\x1a\v.todo.Empty\x12\x1f\n" +
+	"\x06Delete\x12\f.todo.TaskID\x1a\v.todo.Empty\x12'\n" +
+	"\n" +
+	"DeleteTree\x12\f.todo.TaskID\x1a\v.todo.Empty\x121\n" +
+	"\tListTrash\x12\x14.todo.ProjectRequest\x1a\x0e.todo.TaskList\x12#\n" +
+	"\aRestore\x12\f.todo.TaskID\x1a\n" +
+	".todo.Task\x12$\n" +
+	"\aArchive\x12\f.todo.TaskID\x1a\v.todo.Empty\x12%\n" +
+	"\tUnarchive\x12\f.todo.TaskID\x1a\n" +
+	".todo.Task\x12>\n" +
+	"\vListArchive\x12\x16.todo.ListTasksRequest\x1a\x17.todo.ListTasksResponse\x12,\n" +
+	"\x06Export\x12\x14.todo.ProjectRequest\x1a\n" +
+	".todo.Task0\x01\x12\x1f\n" +
 	"\x03Get\x12\f.todo.TaskID\x1a\n" +
-	".todo.Task\x12#\n" +
-	"\x04List\x12\v.todo.Empty\x1a\x0e.todo.TaskListB\x1fZ\x1dtodo/internal/grpcapi;grpcapib\x06proto3"
+	".todo.Task\x12.\n" +
+	"\fListProjects\x12\v.todo.Empty\x1a\x11.todo.ProjectList\x12,\n" +
+	"\x04List\x12\x14.todo.ProjectRequest\x1a\x0e.todo.TaskList\x12<\n" +
+	"\tListTasks\x12\x16.todo.ListTasksRequest\x1a\x17.todo.ListTasksResponse\x123\n" +
+	"\x06Search\x12\x13.todo.SearchRequest\x1a\x14.todo.SearchResponse\x12(\n" +
+	"\bChildren\x12\f.todo.TaskID\x1a\x0e.todo.TaskList\x12)\n" +
+	"\tAncestors\x12\f.todo.TaskID\x1a\x0e.todo.TaskList\x12/\n" +
+	"\x0fSubtreeProgress\x12\f.todo.TaskID\x1a\x0e.todo.Progress\x124\n" +
+	"\rAddDependency\x12\x17.todo.DependencyRequest\x1a\n" +
+	".todo.Task\x127\n" +
+	"\x10RemoveDependency\x12\x17.todo.DependencyRequest\x1a\n" +
+	".todo.Task\x12-\n" +
+	"\x05Ready\x12\x14.todo.ProjectRequest\x1a\x0e.todo.TaskList\x121\n" +
+	"\tTopoOrder\x12\x14.todo.ProjectRequest\x1a\x0e.todo.TaskList\x12&\n" +
+	"\x06AddTag\x12\x10.todo.TagRequest\x1a\n" +
+	".todo.Task\x12)\n" +
+	"\tRemoveTag\x12\x10.todo.TagRequest\x1a\n" +
+	".todo.Task\x12:\n" +
+	"\tTagCounts\x12\x14.todo.ProjectRequest\x1a\x17.todo.TagCountsResponse\x120\n" +
+	"\vRenumberIDs\x12\x14.todo.ProjectRequest\x1a\v.todo.Empty\x12-\n" +
+	"\x04Undo\x12\x11.todo.UndoRequest\x1a\x12.todo.UndoResponse\x12-\n" +
+	"\x04Redo\x12\x11.todo.UndoRequest\x1a\x12.todo.UndoResponse\x12/\n" +
+	"\fListComments\x12\f.todo.TaskID\x1a\x11.todo.CommentList\x121\n" +
+	"\n" +
+	"AddComment\x12\x14.todo.CommentRequest\x1a\r.todo.Comment\x124\n" +
+	"\rUpdateComment\x12\x14.todo.CommentRequest\x1a\r.todo.Comment\x122\n" +
+	"\rDeleteComment\x12\x14.todo.CommentRequest\x1a\v.todo.Empty\x126\n" +
+	"\x10AddChecklistItem\x12\x16.todo.ChecklistRequest\x1a\n" +
+	".todo.Task\x129\n" +
+	"\x13ToggleChecklistItem\x12\x16.todo.ChecklistRequest\x1a\n" +
+	".todo.Task\x127\n" +
+	"\x11MoveChecklistItem\x12\x16.todo.ChecklistRequest\x1a\n" +
+	".todo.Task\x129\n" +
+	"\x13RemoveChecklistItem\x12\x16.todo.ChecklistRequest\x1a\n" +
+	".todo.Task\x125\n" +
+	"\x0fListAttachments\x12\f.todo.TaskID\x1a\x14.todo.AttachmentList\x12=\n" +
+	"\x10UploadAttachment\x12\x15.todo.AttachmentChunk\x1a\x10.todo.Attachment(\x01\x12E\n" +
+	"\x12DownloadAttachment\x12\x17.todo.AttachmentRequest\x1a\x14.todo.AttachmentData0\x01\x128\n" +
+	"\x10DeleteAttachment\x12\x17.todo.AttachmentRequest\x1a\v.todo.EmptyB\x1fZ\x1dtodo/internal/grpcapi;grpcapib\x06proto3"
 
 var (
 	file_todo_proto_rawDescOnce sync.Once
@@ -510,33 +2809,154 @@ func file_todo_proto_rawDescGZIP() []byte {
 	return file_todo_proto_rawDescData
 }
 
-var file_todo_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_todo_proto_msgTypes = make([]protoimpl.MessageInfo, 37)
 var file_todo_proto_goTypes = []any{
-	(*Task)(nil),               // 0: todo.Task
-	(*TaskID)(nil),             // 1: todo.TaskID
-	(*CreateTaskRequest)(nil),  // 2: todo.CreateTaskRequest
-	(*CreateTaskResponse)(nil), // 3: todo.CreateTaskResponse
-	(*UpdateTaskRequest)(nil),  // 4: todo.UpdateTaskRequest
-	(*Empty)(nil),              // 5: todo.Empty
-	(*TaskList)(nil),           // 6: todo.TaskList
+	(*Task)(nil),                  // 0: todo.Task
+	(*ChecklistItem)(nil),         // 1: todo.ChecklistItem
+	(*ChecklistRequest)(nil),      // 2: todo.ChecklistRequest
+	(*TaskID)(nil),                // 3: todo.TaskID
+	(*ProjectRequest)(nil),        // 4: todo.ProjectRequest
+	(*Project)(nil),               // 5: todo.Project
+	(*ProjectList)(nil),           // 6: todo.ProjectList
+	(*CreateTaskRequest)(nil),     // 7: todo.CreateTaskRequest
+	(*CreateTaskResponse)(nil),    // 8: todo.CreateTaskResponse
+	(*UpdateTaskRequest)(nil),     // 9: todo.UpdateTaskRequest
+	(*PatchTaskRequest)(nil),      // 10: todo.PatchTaskRequest
+	(*Empty)(nil),                 // 11: todo.Empty
+	(*LoginRequest)(nil),          // 12: todo.LoginRequest
+	(*LoginResponse)(nil),         // 13: todo.LoginResponse
+	(*RefreshRequest)(nil),        // 14: todo.RefreshRequest
+	(*TagRequest)(nil),            // 15: todo.TagRequest
+	(*TagCount)(nil),              // 16: todo.TagCount
+	(*TagCountsResponse)(nil),     // 17: todo.TagCountsResponse
+	(*DependencyRequest)(nil),     // 18: todo.DependencyRequest
+	(*Progress)(nil),              // 19: todo.Progress
+	(*TaskList)(nil),              // 20: todo.TaskList
+	(*ListTasksRequest)(nil),      // 21: todo.ListTasksRequest
+	(*ListTasksResponse)(nil),     // 22: todo.ListTasksResponse
+	(*SearchRequest)(nil),         // 23: todo.SearchRequest
+	(*SearchHit)(nil),             // 24: todo.SearchHit
+	(*SearchResponse)(nil),        // 25: todo.SearchResponse
+	(*Comment)(nil),               // 26: todo.Comment
+	(*CommentList)(nil),           // 27: todo.CommentList
+	(*CommentRequest)(nil),        // 28: todo.CommentRequest
+	(*Attachment)(nil),            // 29: todo.Attachment
+	(*AttachmentList)(nil),        // 30: todo.AttachmentList
+	(*AttachmentChunk)(nil),       // 31: todo.AttachmentChunk
+	(*AttachmentRequest)(nil),     // 32: todo.AttachmentRequest
+	(*AttachmentData)(nil),        // 33: todo.AttachmentData
+	(*UndoRequest)(nil),           // 34: todo.UndoRequest
+	(*UndoStep)(nil),              // 35: todo.UndoStep
+	(*UndoResponse)(nil),          // 36: todo.UndoResponse
+	(*fieldmaskpb.FieldMask)(nil), // 37: google.protobuf.FieldMask
 }
 var file_todo_proto_depIdxs = []int32{
-	0, // 0: todo.TaskList.items:type_name -> todo.Task
-	2, // 1: todo.TodoService.Create:input_type -> todo.CreateTaskRequest
-	4, // 2: todo.TodoService.Update:input_type -> todo.UpdateTaskRequest
-	1, // 3: todo.TodoService.Delete:input_type -> todo.TaskID
-	1, // 4: todo.TodoService.Get:input_type -> todo.TaskID
-	5, // 5: todo.TodoService.List:input_type -> todo.Empty
-	3, // 6: todo.TodoService.Create:output_type -> todo.CreateTaskResponse
-	0, // 7: todo.TodoService.Update:output_type -> todo.Task
-	5, // 8: todo.TodoService.Delete:output_type -> todo.Empty
-	0, // 9: todo.TodoService.Get:output_type -> todo.Task
-	6, // 10: todo.TodoService.List:output_type -> todo.TaskList
-	6, // [6:11] is the sub-list for method output_type
-	1, // [1:6] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	1,  // 0: todo.Task.checklist:type_name -> todo.ChecklistItem
+	5,  // 1: todo.ProjectList.items:type_name -> todo.Project
+	0,  // 2: todo.PatchTaskRequest.task:type_name -> todo.Task
+	37, // 3: todo.PatchTaskRequest.update_mask:type_name -> google.protobuf.FieldMask
+	16, // 4: todo.TagCountsResponse.items:type_name -> todo.TagCount
+	0,  // 5: todo.TaskList.items:type_name -> todo.Task
+	0,  // 6: todo.ListTasksResponse.items:type_name -> todo.Task
+	0,  // 7: todo.SearchHit.task:type_name -> todo.Task
+	24, // 8: todo.SearchResponse.hits:type_name -> todo.SearchHit
+	26, // 9: todo.CommentList.items:type_name -> todo.Comment
+	29, // 10: todo.AttachmentList.items:type_name -> todo.Attachment
+	29, // 11: todo.AttachmentData.info:type_name -> todo.Attachment
+	35, // 12: todo.UndoResponse.steps:type_name -> todo.UndoStep
+	12, // 13: todo.TodoService.Login:input_type -> todo.LoginRequest
+	14, // 14: todo.TodoService.Refresh:input_type -> todo.RefreshRequest
+	11, // 15: todo.TodoService.Logout:input_type -> todo.Empty
+	7,  // 16: todo.TodoService.Create:input_type -> todo.CreateTaskRequest
+	9,  // 17: todo.TodoService.Update:input_type -> todo.UpdateTaskRequest
+	10, // 18: todo.TodoService.Patch:input_type -> todo.PatchTaskRequest
+	3,  // 19: todo.TodoService.Delete:input_type -> todo.TaskID
+	3,  // 20: todo.TodoService.DeleteTree:input_type -> todo.TaskID
+	4,  // 21: todo.TodoService.ListTrash:input_type -> todo.ProjectRequest
+	3,  // 22: todo.TodoService.Restore:input_type -> todo.TaskID
+	3,  // 23: todo.TodoService.Archive:input_type -> todo.TaskID
+	3,  // 24: todo.TodoService.Unarchive:input_type -> todo.TaskID
+	21, // 25: todo.TodoService.ListArchive:input_type -> todo.ListTasksRequest
+	4,  // 26: todo.TodoService.Export:input_type -> todo.ProjectRequest
+	3,  // 27: todo.TodoService.Get:input_type -> todo.TaskID
+	11, // 28: todo.TodoService.ListProjects:input_type -> todo.Empty
+	4,  // 29: todo.TodoService.List:input_type -> todo.ProjectRequest
+	21, // 30: todo.TodoService.ListTasks:input_type -> todo.ListTasksRequest
+	23, // 31: todo.TodoService.Search:input_type -> todo.SearchRequest
+	3,  // 32: todo.TodoService.Children:input_type -> todo.TaskID
+	3,  // 33: todo.TodoService.Ancestors:input_type -> todo.TaskID
+	3,  // 34: todo.TodoService.SubtreeProgress:input_type -> todo.TaskID
+	18, // 35: todo.TodoService.AddDependency:input_type -> todo.DependencyRequest
+	18, // 36: todo.TodoService.RemoveDependency:input_type -> todo.DependencyRequest
+	4,  // 37: todo.TodoService.Ready:input_type -> todo.ProjectRequest
+	4,  // 38: todo.TodoService.TopoOrder:input_type -> todo.ProjectRequest
+	15, // 39: todo.TodoService.AddTag:input_type -> todo.TagRequest
+	15, // 40: todo.TodoService.RemoveTag:input_type -> todo.TagRequest
+	4,  // 41: todo.TodoService.TagCounts:input_type -> todo.ProjectRequest
+	4,  // 42: todo.TodoService.RenumberIDs:input_type -> todo.ProjectRequest
+	34, // 43: todo.TodoService.Undo:input_type -> todo.UndoRequest
+	34, // 44: todo.TodoService.Redo:input_type -> todo.UndoRequest
+	3,  // 45: todo.TodoService.ListComments:input_type -> todo.TaskID
+	28, // 46: todo.TodoService.AddComment:input_type -> todo.CommentRequest
+	28, // 47: todo.TodoService.UpdateComment:input_type -> todo.CommentRequest
+	28, // 48: todo.TodoService.DeleteComment:input_type -> todo.CommentRequest
+	2,  // 49: todo.TodoService.AddChecklistItem:input_type -> todo.ChecklistRequest
+	2,  // 50: todo.TodoService.ToggleChecklistItem:input_type -> todo.ChecklistRequest
+	2,  // 51: todo.TodoService.MoveChecklistItem:input_type -> todo.ChecklistRequest
+	2,  // 52: todo.TodoService.RemoveChecklistItem:input_type -> todo.ChecklistRequest
+	3,  // 53: todo.TodoService.ListAttachments:input_type -> todo.TaskID
+	31, // 54: todo.TodoService.UploadAttachment:input_type -> todo.AttachmentChunk
+	32, // 55: todo.TodoService.DownloadAttachment:input_type -> todo.AttachmentRequest
+	32, // 56: todo.TodoService.DeleteAttachment:input_type -> todo.AttachmentRequest
+	13, // 57: todo.TodoService.Login:output_type -> todo.LoginResponse
+	13, // 58: todo.TodoService.Refresh:output_type -> todo.LoginResponse
+	11, // 59: todo.TodoService.Logout:output_type -> todo.Empty
+	8,  // 60: todo.TodoService.Create:output_type -> todo.CreateTaskResponse
+	0,  // 61: todo.TodoService.Update:output_type -> todo.Task
+	0,  // 62: todo.TodoService.Patch:output_type -> todo.Task
+	11, // 63: todo.TodoService.Delete:output_type -> todo.Empty
+	11, // 64: todo.TodoService.DeleteTree:output_type -> todo.Empty
+	20, // 65: todo.TodoService.ListTrash:output_type -> todo.TaskList
+	0,  // 66: todo.TodoService.Restore:output_type -> todo.Task
+	11, // 67: todo.TodoService.Archive:output_type -> todo.Empty
+	0,  // 68: todo.TodoService.Unarchive:output_type -> todo.Task
+	22, // 69: todo.TodoService.ListArchive:output_type -> todo.ListTasksResponse
+	0,  // 70: todo.TodoService.Export:output_type -> todo.Task
+	0,  // 71: todo.TodoService.Get:output_type -> todo.Task
+	6,  // 72: todo.TodoService.ListProjects:output_type -> todo.ProjectList
+	20, // 73: todo.TodoService.List:output_type -> todo.TaskList
+	22, // 74: todo.TodoService.ListTasks:output_type -> todo.ListTasksResponse
+	25, // 75: todo.TodoService.Search:output_type -> todo.SearchResponse
+	20, // 76: todo.TodoService.Children:output_type -> todo.TaskList
+	20, // 77: todo.TodoService.Ancestors:output_type -> todo.TaskList
+	19, // 78: todo.TodoService.SubtreeProgress:output_type -> todo.Progress
+	0,  // 79: todo.TodoService.AddDependency:output_type -> todo.Task
+	0,  // 80: todo.TodoService.RemoveDependency:output_type -> todo.Task
+	20, // 81: todo.TodoService.Ready:output_type -> todo.TaskList
+	20, // 82: todo.TodoService.TopoOrder:output_type -> todo.TaskList
+	0,  // 83: todo.TodoService.AddTag:output_type -> todo.Task
+	0,  // 84: todo.TodoService.RemoveTag:output_type -> todo.Task
+	17, // 85: todo.TodoService.TagCounts:output_type -> todo.TagCountsResponse
+	11, // 86: todo.TodoService.RenumberIDs:output_type -> todo.Empty
+	36, // 87: todo.TodoService.Undo:output_type -> todo.UndoResponse
+	36, // 88: todo.TodoService.Redo:output_type -> todo.UndoResponse
+	27, // 89: todo.TodoService.ListComments:output_type -> todo.CommentList
+	26, // 90: todo.TodoService.AddComment:output_type -> todo.Comment
+	26, // 91: todo.TodoService.UpdateComment:output_type -> todo.Comment
+	11, // 92: todo.TodoService.DeleteComment:output_type -> todo.Empty
+	0,  // 93: todo.TodoService.AddChecklistItem:output_type -> todo.Task
+	0,  // 94: todo.TodoService.ToggleChecklistItem:output_type -> todo.Task
+	0,  // 95: todo.TodoService.MoveChecklistItem:output_type -> todo.Task
+	0,  // 96: todo.TodoService.RemoveChecklistItem:output_type -> todo.Task
+	30, // 97: todo.TodoService.ListAttachments:output_type -> todo.AttachmentList
+	29, // 98: todo.TodoService.UploadAttachment:output_type -> todo.Attachment
+	33, // 99: todo.TodoService.DownloadAttachment:output_type -> todo.AttachmentData
+	11, // 100: todo.TodoService.DeleteAttachment:output_type -> todo.Empty
+	57, // [57:101] is the sub-list for method output_type
+	13, // [13:57] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
 }
 
 func init() { file_todo_proto_init() }
@@ -544,13 +2964,15 @@ func file_todo_proto_init() {
 	if File_todo_proto != nil {
 		return
 	}
+	file_todo_proto_msgTypes[9].OneofWrappers = []any{}
+	file_todo_proto_msgTypes[10].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_todo_proto_rawDesc), len(file_todo_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   7,
+			NumMessages:   37,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -2,96 +2,1113 @@ package grpcserver
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"todo/internal/auth"
 	"todo/internal/grpcapi"
 	"todo/internal/model"
+	"todo/internal/policy"
+	"todo/internal/reqctx"
 	"todo/internal/service"
-	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 type Server struct {
 	grpcapi.UnimplementedTodoServiceServer
-	svc service.TaskUseCase
+	tenants  *service.Tenants
+	users    *auth.Users
+	sessions *auth.Sessions
 }
 
-func New(svc service.TaskUseCase) *Server {
-	return &Server{svc: svc}
+func New(tenants *service.Tenants, users *auth.Users, sessions *auth.Sessions) *Server {
+	return &Server{tenants: tenants, users: users, sessions: sessions}
 }
 
-func (s *Server) Create(ctx context.Context, req *grpcapi.CreateTaskRequest) (*grpcapi.CreateTaskResponse, error) {
-	var due *time.Time
-	if req.DueAt != "" {
-		t, err := time.Parse("2006-01-02", req.DueAt)
-		if err == nil {
-			due = &t
+// tasks — сервис задач проекта из запроса, 0 — общий проект; арендатор — из токена.
+// Проект, в который вызывающего не пускают, — NotFound, как и чужая задача.
+func (s *Server) tasks(ctx context.Context, project int64) (service.TaskUseCase, error) {
+	p := model.ProjectID(project)
+	if p == 0 {
+		p = model.DefaultProject
+	}
+	ps, err := s.tenants.Projects(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	svc, err := ps.Tasks(ctx, p)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return svc, nil
+}
+
+func (s *Server) ListProjects(ctx context.Context, _ *grpcapi.Empty) (*grpcapi.ProjectList, error) {
+	ps, err := s.tenants.Projects(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &grpcapi.ProjectList{}
+	for _, p := range ps.List(ctx) {
+		pp := &grpcapi.Project{Id: int64(p.ID), Name: p.Name}
+		for _, m := range p.Members {
+			pp.Members = append(pp.Members, int64(m))
 		}
+		resp.Items = append(resp.Items, pp)
+	}
+	return resp, nil
+}
+
+func (s *Server) Login(ctx context.Context, req *grpcapi.LoginRequest) (*grpcapi.LoginResponse, error) {
+	pair, err := s.sessions.Login(ctx, req.Login, req.Password)
+	if errors.Is(err, auth.ErrBadCredentials) {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pairToProto(pair), nil
+}
+
+func (s *Server) Refresh(ctx context.Context, req *grpcapi.RefreshRequest) (*grpcapi.LoginResponse, error) {
+	pair, err := s.sessions.Refresh(ctx, req.RefreshToken)
+	if errors.Is(err, auth.ErrBadToken) {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pairToProto(pair), nil
+}
+
+func (s *Server) Logout(ctx context.Context, _ *grpcapi.Empty) (*grpcapi.Empty, error) {
+	raw, _ := bearer(ctx)
+	err := s.sessions.Logout(ctx, raw)
+	if errors.Is(err, auth.ErrBadToken) {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &grpcapi.Empty{}, nil
+}
+
+func pairToProto(p auth.TokenPair) *grpcapi.LoginResponse {
+	return &grpcapi.LoginResponse{Token: p.AccessToken, RefreshToken: p.RefreshToken, ExpiresIn: int32(p.ExpiresIn)}
+}
+
+// checkUser — есть ли пользователь, которого назначают исполнителем
+func (s *Server) checkUser(ctx context.Context, id model.UserID) error {
+	_, err := s.users.Get(ctx, id)
+	if errors.Is(err, auth.ErrUserNotFound) {
+		return status.Error(codes.NotFound, err.Error())
 	}
-	id, err := s.svc.Add(req.Title, req.Description, model.Priority(req.Priority), due)
+	return err
+}
+
+func (s *Server) Create(ctx context.Context, req *grpcapi.CreateTaskRequest) (*grpcapi.CreateTaskResponse, error) {
+	svc, err := s.tasks(ctx, req.Project)
 	if err != nil {
 		return nil, err
 	}
+	// те же поля, что и в патче: срок и правило разбираем так же
+	var p model.TaskPatch
+	if err := patchDue(&p, req.DueAt); err != nil {
+		return nil, err
+	}
+	if err := patchRecurrence(&p, req.Recurrence); err != nil {
+		return nil, err
+	}
+	assignee := model.UserID(req.Assignee)
+	if assignee != 0 {
+		if err := s.checkUser(ctx, assignee); err != nil {
+			return nil, err
+		}
+	}
+	// как и в HTTP: задача собирается целиком в сервисе и вставляется один раз
+	id, err := svc.Create(ctx, model.TaskDraft{
+		Title:       req.Title,
+		Description: req.Description,
+		Priority:    model.Priority(req.Priority),
+		DueAt:       p.DueAt,
+		ParentID:    model.ID(req.ParentId),
+		Recurrence:  p.Recurrence,
+		Tags:        req.Tags,
+		Assignee:    assignee,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
 	return &grpcapi.CreateTaskResponse{Id: int64(id)}, nil
 }
 
 func (s *Server) Update(ctx context.Context, req *grpcapi.UpdateTaskRequest) (*grpcapi.Task, error) {
-	id := model.ID(req.Id)
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	// пустые поля не трогаем, "-" очищает срок и повторение
+	var p model.TaskPatch
 	if req.Title != "" {
-		_ = s.svc.UpdateTitle(id, req.Title)
+		p.Title = &req.Title
 	}
 	if req.Description != "" {
-		_ = s.svc.UpdateDesc(id, req.Description)
+		p.Description = &req.Description
 	}
 	if req.Status != "" {
-		_ = s.svc.SetStatus(id, model.Status(req.Status))
+		st := model.Status(req.Status)
+		p.Status = &st
+	}
+	if req.Priority != 0 {
+		pr := model.Priority(req.Priority)
+		p.Priority = &pr
 	}
-	if req.Priority > 0 {
-		_ = s.svc.SetPriority(id, model.Priority(req.Priority))
+	if req.ParentId != nil {
+		parent := model.ID(*req.ParentId)
+		p.ParentID = &parent
 	}
-	if req.DueAt != "" {
-		if req.DueAt == "-" {
-			_ = s.svc.ClearDue(id)
-		} else if t, err := time.Parse("2006-01-02", req.DueAt); err == nil {
-			_ = s.svc.SetDue(id, t)
+	if req.DueAt == "-" {
+		p.ClearDue = true
+	} else if err := patchDue(&p, req.DueAt); err != nil {
+		return nil, err
+	}
+	if req.Recurrence == "-" {
+		p.ClearRecurrence = true
+	} else if err := patchRecurrence(&p, req.Recurrence); err != nil {
+		return nil, err
+	}
+	if req.Assignee != nil {
+		u := model.UserID(*req.Assignee)
+		p.Assignee = &u
+	}
+	return s.patch(ctx, svc, model.ID(req.Id), req.ExpectedVersion, p)
+}
+
+// Patch — меняет ровно поля из update_mask; пустое значение в task очищает поле
+func (s *Server) Patch(ctx context.Context, req *grpcapi.PatchTaskRequest) (*grpcapi.Task, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.UpdateMask.GetPaths()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "update_mask is empty")
+	}
+	src := req.Task
+	if src == nil {
+		src = &grpcapi.Task{}
+	}
+	var p model.TaskPatch
+	for _, path := range req.UpdateMask.GetPaths() {
+		switch path {
+		case "title":
+			p.Title = &src.Title
+		case "description":
+			p.Description = &src.Description
+		case "status":
+			st := model.Status(src.Status)
+			p.Status = &st
+		case "priority":
+			pr := model.Priority(src.Priority)
+			p.Priority = &pr
+		case "parent_id":
+			parent := model.ID(src.ParentId)
+			p.ParentID = &parent
+		case "due_at":
+			p.ClearDue = src.DueAt == ""
+			if err := patchDue(&p, src.DueAt); err != nil {
+				return nil, err
+			}
+		case "recurrence":
+			p.ClearRecurrence = src.Recurrence == ""
+			if err := patchRecurrence(&p, src.Recurrence); err != nil {
+				return nil, err
+			}
+		case "assignee":
+			u := model.UserID(src.Assignee)
+			p.Assignee = &u
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "update_mask: unknown path %q", path)
 		}
 	}
-	for _, t := range s.svc.List(nil) {
-		if t.ID() == id {
-			return dtoToProto(t), nil
+	return s.patch(ctx, svc, model.ID(req.Id), req.ExpectedVersion, p)
+}
+
+// patchDue — срок YYYY-MM-DD в патч, пусто — не трогаем
+func patchDue(p *model.TaskPatch, raw string) error {
+	if raw == "" {
+		return nil
+	}
+	due, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "bad due_at %q: want YYYY-MM-DD", raw)
+	}
+	p.DueAt = &due
+	return nil
+}
+
+// patchRecurrence — RRULE в патч, пусто — не трогаем
+func patchRecurrence(p *model.TaskPatch, raw string) error {
+	if raw == "" {
+		return nil
+	}
+	rec, err := model.ParseRecurrence(raw)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	p.Recurrence = rec
+	return nil
+}
+
+// patch — общий хвост Update и Patch: исполнитель, версия, сам патч и новая задача
+// version == nil — без проверки; заданный 0 не совпадёт ни с какой задачей (версии с 1)
+func (s *Server) patch(ctx context.Context, svc service.TaskUseCase, id model.ID, version *int64, p model.TaskPatch) (*grpcapi.Task, error) {
+	if p.Assignee != nil && *p.Assignee != 0 {
+		if err := s.checkUser(ctx, *p.Assignee); err != nil {
+			return nil, err
 		}
 	}
-	return nil, nil
+	if version != nil {
+		ctx = service.ExpectVersion(ctx, id, *version)
+	}
+	if err := svc.Patch(ctx, id, p); err != nil {
+		return nil, toStatus(err)
+	}
+	t, err := svc.Get(ctx, id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return dtoToProto(t), nil
 }
 
 func (s *Server) Delete(ctx context.Context, req *grpcapi.TaskID) (*grpcapi.Empty, error) {
-	_ = s.svc.Delete(model.ID(req.Id))
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.Delete(ctx, model.ID(req.Id)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &grpcapi.Empty{}, nil
+}
+
+func (s *Server) DeleteTree(ctx context.Context, req *grpcapi.TaskID) (*grpcapi.Empty, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.DeleteTree(ctx, model.ID(req.Id)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &grpcapi.Empty{}, nil
+}
+
+func (s *Server) ListTrash(ctx context.Context, req *grpcapi.ProjectRequest) (*grpcapi.TaskList, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	list, err := svc.Trash(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return taskList(list), nil
+}
+
+func (s *Server) Restore(ctx context.Context, req *grpcapi.TaskID) (*grpcapi.Task, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.Restore(ctx, model.ID(req.Id)); err != nil {
+		return nil, toStatus(err)
+	}
+	t, err := svc.Get(ctx, model.ID(req.Id))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return dtoToProto(t), nil
+}
+
+func (s *Server) Archive(ctx context.Context, req *grpcapi.TaskID) (*grpcapi.Empty, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.Archive(ctx, model.ID(req.Id)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &grpcapi.Empty{}, nil
+}
+
+func (s *Server) Unarchive(ctx context.Context, req *grpcapi.TaskID) (*grpcapi.Task, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.Unarchive(ctx, model.ID(req.Id)); err != nil {
+		return nil, toStatus(err)
+	}
+	t, err := svc.Get(ctx, model.ID(req.Id))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return dtoToProto(t), nil
+}
+
+// Export — задачи идут по одной, пока читаем хранилище страницами
+func (s *Server) Export(req *grpcapi.ProjectRequest, stream grpc.ServerStreamingServer[grpcapi.Task]) error {
+	ctx := stream.Context()
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return err
+	}
+	err = svc.Export(ctx, func(t *model.Task) error { return stream.Send(dtoToProto(t)) })
+	if err != nil {
+		return toStatus(err)
+	}
+	return nil
+}
+
+func (s *Server) Children(ctx context.Context, req *grpcapi.TaskID) (*grpcapi.TaskList, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	list, err := svc.Children(ctx, model.ID(req.Id))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return taskList(list), nil
+}
+
+func (s *Server) Ancestors(ctx context.Context, req *grpcapi.TaskID) (*grpcapi.TaskList, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	list, err := svc.Ancestors(ctx, model.ID(req.Id))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return taskList(list), nil
+}
+
+func (s *Server) SubtreeProgress(ctx context.Context, req *grpcapi.TaskID) (*grpcapi.Progress, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	p, err := svc.Progress(ctx, model.ID(req.Id))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &grpcapi.Progress{Total: int32(p.Total), Done: int32(p.Done)}, nil
+}
+
+func (s *Server) AddDependency(ctx context.Context, req *grpcapi.DependencyRequest) (*grpcapi.Task, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.AddDependency(ctx, model.ID(req.Id), model.ID(req.BlockedBy)); err != nil {
+		return nil, toStatus(err)
+	}
+	return s.Get(ctx, &grpcapi.TaskID{Id: req.Id, Project: req.Project})
+}
+
+func (s *Server) RemoveDependency(ctx context.Context, req *grpcapi.DependencyRequest) (*grpcapi.Task, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.RemoveDependency(ctx, model.ID(req.Id), model.ID(req.BlockedBy)); err != nil {
+		return nil, toStatus(err)
+	}
+	return s.Get(ctx, &grpcapi.TaskID{Id: req.Id, Project: req.Project})
+}
+
+func (s *Server) Ready(ctx context.Context, req *grpcapi.ProjectRequest) (*grpcapi.TaskList, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	list, err := svc.Ready(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return taskList(list), nil
+}
+
+func (s *Server) TopoOrder(ctx context.Context, req *grpcapi.ProjectRequest) (*grpcapi.TaskList, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	list, err := svc.TopoOrder(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return taskList(list), nil
+}
+
+func (s *Server) AddTag(ctx context.Context, req *grpcapi.TagRequest) (*grpcapi.Task, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := model.NormalizeTag(req.Tag); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := svc.AddTag(ctx, model.ID(req.Id), req.Tag); err != nil {
+		return nil, toStatus(err)
+	}
+	return s.Get(ctx, &grpcapi.TaskID{Id: req.Id, Project: req.Project})
+}
+
+func (s *Server) RemoveTag(ctx context.Context, req *grpcapi.TagRequest) (*grpcapi.Task, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.RemoveTag(ctx, model.ID(req.Id), req.Tag); err != nil {
+		return nil, toStatus(err)
+	}
+	return s.Get(ctx, &grpcapi.TaskID{Id: req.Id, Project: req.Project})
+}
+
+func (s *Server) TagCounts(ctx context.Context, req *grpcapi.ProjectRequest) (*grpcapi.TagCountsResponse, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := svc.TagCounts(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &grpcapi.TagCountsResponse{}
+	for _, c := range counts {
+		resp.Items = append(resp.Items, &grpcapi.TagCount{Tag: c.Tag, Count: int32(c.Count)})
+	}
+	return resp, nil
+}
+
+func (s *Server) RenumberIDs(ctx context.Context, req *grpcapi.ProjectRequest) (*grpcapi.Empty, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.RenumberIDs(ctx); err != nil {
+		return nil, toStatus(err)
+	}
+	return &grpcapi.Empty{}, nil
+}
+
+func (s *Server) Undo(ctx context.Context, req *grpcapi.UndoRequest) (*grpcapi.UndoResponse, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	return undoResponse(svc.Undo(ctx, int(req.Steps)))
+}
+
+func (s *Server) Redo(ctx context.Context, req *grpcapi.UndoRequest) (*grpcapi.UndoResponse, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	return undoResponse(svc.Redo(ctx, int(req.Steps)))
+}
+
+// undoResponse — ответ Undo/Redo; если часть успела до ошибки, ошибка едет в поле error
+func undoResponse(steps []service.UndoStep, err error) (*grpcapi.UndoResponse, error) {
+	if err != nil && len(steps) == 0 {
+		return nil, toStatus(err)
+	}
+	resp := &grpcapi.UndoResponse{}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	for _, st := range steps {
+		var ids []int64
+		for _, id := range st.TaskIDs {
+			ids = append(ids, int64(id))
+		}
+		resp.Steps = append(resp.Steps, &grpcapi.UndoStep{Ops: st.Ops, At: st.At.Format("2006-01-02 15:04"), TaskIds: ids})
+	}
+	return resp, nil
+}
+
+func (s *Server) ListComments(ctx context.Context, req *grpcapi.TaskID) (*grpcapi.CommentList, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	list, err := svc.Comments(ctx, model.ID(req.Id))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	out := &grpcapi.CommentList{}
+	for _, c := range list {
+		out.Items = append(out.Items, commentToProto(c))
+	}
+	return out, nil
+}
+
+func (s *Server) AddComment(ctx context.Context, req *grpcapi.CommentRequest) (*grpcapi.Comment, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	c, err := svc.AddComment(ctx, model.ID(req.Id), req.Body)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return commentToProto(c), nil
+}
+
+func (s *Server) UpdateComment(ctx context.Context, req *grpcapi.CommentRequest) (*grpcapi.Comment, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	c, err := svc.EditComment(ctx, model.ID(req.Id), model.CommentID(req.CommentId), req.Body)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return commentToProto(c), nil
+}
+
+func (s *Server) DeleteComment(ctx context.Context, req *grpcapi.CommentRequest) (*grpcapi.Empty, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.DeleteComment(ctx, model.ID(req.Id), model.CommentID(req.CommentId)); err != nil {
+		return nil, toStatus(err)
+	}
 	return &grpcapi.Empty{}, nil
 }
 
+func (s *Server) AddChecklistItem(ctx context.Context, req *grpcapi.ChecklistRequest) (*grpcapi.Task, error) {
+	return s.checklist(ctx, req, func(svc service.TaskUseCase) error {
+		_, err := svc.AddChecklistItem(ctx, model.ID(req.Id), req.Text)
+		return err
+	})
+}
+
+func (s *Server) ToggleChecklistItem(ctx context.Context, req *grpcapi.ChecklistRequest) (*grpcapi.Task, error) {
+	return s.checklist(ctx, req, func(svc service.TaskUseCase) error {
+		_, err := svc.ToggleChecklistItem(ctx, model.ID(req.Id), int(req.Item))
+		return err
+	})
+}
+
+func (s *Server) MoveChecklistItem(ctx context.Context, req *grpcapi.ChecklistRequest) (*grpcapi.Task, error) {
+	return s.checklist(ctx, req, func(svc service.TaskUseCase) error {
+		return svc.MoveChecklistItem(ctx, model.ID(req.Id), int(req.Item), int(req.Position))
+	})
+}
+
+func (s *Server) RemoveChecklistItem(ctx context.Context, req *grpcapi.ChecklistRequest) (*grpcapi.Task, error) {
+	return s.checklist(ctx, req, func(svc service.TaskUseCase) error {
+		return svc.RemoveChecklistItem(ctx, model.ID(req.Id), int(req.Item))
+	})
+}
+
+// checklist — правка чек-листа, в ответ задача целиком
+func (s *Server) checklist(ctx context.Context, req *grpcapi.ChecklistRequest, fn func(svc service.TaskUseCase) error) (*grpcapi.Task, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	if err := fn(svc); err != nil {
+		return nil, toStatus(err)
+	}
+	return s.Get(ctx, &grpcapi.TaskID{Id: req.Id, Project: req.Project})
+}
+
+func (s *Server) ListAttachments(ctx context.Context, req *grpcapi.TaskID) (*grpcapi.AttachmentList, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	list, err := svc.Attachments(ctx, model.ID(req.Id))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	out := &grpcapi.AttachmentList{}
+	for _, a := range list {
+		out.Items = append(out.Items, attachmentToProto(a))
+	}
+	return out, nil
+}
+
+// UploadAttachment — куски из потока читаются по мере того, как сервис пишет файл,
+// целиком он в памяти не бывает
+func (s *Server) UploadAttachment(stream grpc.ClientStreamingServer[grpcapi.AttachmentChunk, grpcapi.Attachment]) error {
+	ctx := stream.Context()
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "empty upload")
+	}
+	if err != nil {
+		return err
+	}
+	svc, err := s.tasks(ctx, first.Project)
+	if err != nil {
+		return err
+	}
+	body := &chunkReader{stream: stream, buf: first.Data}
+	a, err := svc.Attach(ctx, model.ID(first.Id), first.Name, first.ContentType, body)
+	if err != nil {
+		return toStatus(err)
+	}
+	return stream.SendAndClose(attachmentToProto(a))
+}
+
+func (s *Server) DownloadAttachment(req *grpcapi.AttachmentRequest, stream grpc.ServerStreamingServer[grpcapi.AttachmentData]) error {
+	ctx := stream.Context()
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return err
+	}
+	a, body, err := svc.OpenAttachment(ctx, model.ID(req.Id), model.AttachmentID(req.AttachmentId))
+	if err != nil {
+		return toStatus(err)
+	}
+	defer body.Close()
+	if err := stream.Send(&grpcapi.AttachmentData{Info: attachmentToProto(a)}); err != nil {
+		return err
+	}
+	buf := make([]byte, 64<<10)
+	for {
+		n, err := body.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&grpcapi.AttachmentData{Data: buf[:n]}); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) DeleteAttachment(ctx context.Context, req *grpcapi.AttachmentRequest) (*grpcapi.Empty, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.DeleteAttachment(ctx, model.ID(req.Id), model.AttachmentID(req.AttachmentId)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &grpcapi.Empty{}, nil
+}
+
+// chunkReader — поток AttachmentChunk как io.Reader
+type chunkReader struct {
+	stream grpc.ClientStreamingServer[grpcapi.AttachmentChunk, grpcapi.Attachment]
+	buf    []byte
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		chunk, err := r.stream.Recv()
+		if err != nil {
+			return 0, err // io.EOF — клиент дослал всё
+		}
+		r.buf = chunk.Data
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
 func (s *Server) Get(ctx context.Context, req *grpcapi.TaskID) (*grpcapi.Task, error) {
-	for _, t := range s.svc.List(nil) {
-		if t.ID() == model.ID(req.Id) {
-			return dtoToProto(t), nil
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	t, err := svc.Get(ctx, model.ID(req.Id))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return dtoToProto(t), nil
+}
+
+func (s *Server) List(ctx context.Context, req *grpcapi.ProjectRequest) (*grpcapi.TaskList, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	page, err := svc.List(ctx, model.TaskQuery{})
+	if err != nil {
+		return nil, err
+	}
+	return taskList(page.Items), nil
+}
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+func (s *Server) ListTasks(ctx context.Context, req *grpcapi.ListTasksRequest) (*grpcapi.ListTasksResponse, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	me, _ := reqctx.UserFrom(ctx)
+	q, err := queryFromProto(req, me.ID)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	page, err := svc.List(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	resp := &grpcapi.ListTasksResponse{NextPageToken: page.NextCursor}
+	for _, t := range page.Items {
+		resp.Items = append(resp.Items, dtoToProto(t))
+	}
+	return resp, nil
+}
+
+func (s *Server) ListArchive(ctx context.Context, req *grpcapi.ListTasksRequest) (*grpcapi.ListTasksResponse, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	me, _ := reqctx.UserFrom(ctx)
+	q, err := queryFromProto(req, me.ID)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	page, err := svc.Archived(ctx, q)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &grpcapi.ListTasksResponse{NextPageToken: page.NextCursor}
+	for _, t := range page.Items {
+		resp.Items = append(resp.Items, dtoToProto(t))
+	}
+	return resp, nil
+}
+
+func (s *Server) Search(ctx context.Context, req *grpcapi.SearchRequest) (*grpcapi.SearchResponse, error) {
+	svc, err := s.tasks(ctx, req.Project)
+	if err != nil {
+		return nil, err
+	}
+	hits, err := svc.Search(ctx, req.Query, int(req.Limit))
+	switch {
+	case errors.Is(err, service.ErrEmptyQuery):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrSearchUnsupported):
+		return nil, status.Error(codes.Unimplemented, err.Error())
+	case err != nil:
+		return nil, err
+	}
+	resp := &grpcapi.SearchResponse{}
+	for _, h := range hits {
+		t, err := model.FromDTO(h.Task)
+		if err != nil {
+			continue
+		}
+		resp.Hits = append(resp.Hits, &grpcapi.SearchHit{Task: dtoToProto(t), Rank: h.Rank, Snippet: h.Snippet})
+	}
+	return resp, nil
+}
+
+// queryFromProto собирает TaskQuery из полей ListTasksRequest; me — вызывающий (для *_me)
+func queryFromProto(req *grpcapi.ListTasksRequest, me model.UserID) (model.TaskQuery, error) {
+	q := model.TaskQuery{
+		MinPriority: model.Priority(req.MinPriority),
+		MaxPriority: model.Priority(req.MaxPriority),
+		Text:        strings.TrimSpace(req.Text),
+		CreatedBy:   model.UserID(req.CreatedBy),
+		Assignee:    model.UserID(req.Assignee),
+		Limit:       defaultPageSize,
+	}
+	if req.CreatedByMe {
+		q.CreatedBy = me
+	}
+	if req.AssignedToMe {
+		q.Assignee = me
+	}
+	for _, raw := range req.Statuses {
+		st := model.Status(raw)
+		if !st.Valid() {
+			return q, fmt.Errorf("bad status %q", raw)
+		}
+		q.Statuses = append(q.Statuses, st)
+	}
+	if q.MinPriority != 0 && !q.MinPriority.Valid() || q.MaxPriority != 0 && !q.MaxPriority.Valid() {
+		return q, fmt.Errorf("bad priority range %d..%d", req.MinPriority, req.MaxPriority)
+	}
+	dates := []struct {
+		raw string
+		dst **time.Time
+	}{
+		{req.DueAfter, &q.DueAfter},
+		{req.DueBefore, &q.DueBefore},
+		{req.CreatedFrom, &q.CreatedFrom},
+		{req.CreatedTo, &q.CreatedTo},
+	}
+	for _, d := range dates {
+		if d.raw == "" {
+			continue
+		}
+		t, err := time.Parse("2006-01-02", d.raw)
+		if err != nil {
+			return q, fmt.Errorf("bad date %q: want YYYY-MM-DD", d.raw)
+		}
+		*d.dst = &t
+	}
+	var err error
+	if q.Tags, err = model.NormalizeTags(req.Tags); err != nil {
+		return q, err
+	}
+	if q.AnyTags, err = model.NormalizeTags(req.AnyTags); err != nil {
+		return q, err
+	}
+	if q.Sort, err = model.ParseSort(req.Sort); err != nil {
+		return q, err
+	}
+	if req.PageSize > 0 {
+		q.Limit = min(int(req.PageSize), maxPageSize)
+	}
+	if req.PageToken != "" {
+		if q.After, err = model.DecodeCursor(req.PageToken); err != nil {
+			return q, err
+		}
+	}
+	return q, nil
+}
+
+// toStatus — ошибки сервиса в коды gRPC: нет задачи — NotFound, не хватает прав — PermissionDenied,
+// конфликт с состоянием задач (см. service.IsConflict) — FailedPrecondition
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, service.ErrNotFound), errors.Is(err, service.ErrProjectNotFound),
+		errors.Is(err, service.ErrCommentNotFound), errors.Is(err, service.ErrAttachmentNotFound),
+		errors.Is(err, service.ErrChecklistItemNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrBadComment), errors.Is(err, service.ErrBadAttachment),
+		errors.Is(err, service.ErrBadChecklistItem), errors.Is(err, service.ErrBadPatch), errors.Is(err, service.ErrBadTask):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrAttachmentTooLarge):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, service.ErrCommentsUnsupported), errors.Is(err, service.ErrAttachmentsUnsupported),
+		errors.Is(err, service.ErrArchiveUnsupported):
+		return status.Error(codes.Unimplemented, err.Error())
+	case errors.Is(err, policy.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case service.IsConflict(err):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrVersionMismatch):
+		return status.Error(codes.Aborted, err.Error())
+	default:
+		return err
+	}
+}
+
+// TraceInterceptor — кладёт trace id из метаданных x-request-id (или новый) в контекст
+// и возвращает его клиенту в заголовке ответа; плюс серверный идентификатор вызова
+// для отмены операций (reqctx.WithRequest). Дедлайн клиента уже живёт в ctx.
+func TraceInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(withTrace(ctx), req)
+}
+
+// StreamTraceInterceptor — то же для потоковых вызовов
+func StreamTraceInterceptor(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, ctxStream{ss, withTrace(ss.Context())})
+}
+
+func withTrace(ctx context.Context) context.Context {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get("x-request-id"); len(v) > 0 {
+			id = v[0]
 		}
 	}
-	return nil, nil
+	if id == "" {
+		id = reqctx.NewTraceID()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs("x-request-id", id))
+	return reqctx.WithRequest(reqctx.WithTraceID(ctx, id))
+}
+
+// ctxStream — поток со своим контекстом: так перехватчики передают дальше trace id и пользователя
+type ctxStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s ctxStream) Context() context.Context { return s.ctx }
+
+// publicMethods — вызовы без токена
+var publicMethods = map[string]bool{
+	grpcapi.TodoService_Login_FullMethodName:   true,
+	grpcapi.TodoService_Refresh_FullMethodName: true,
 }
 
-func (s *Server) List(ctx context.Context, _ *grpcapi.Empty) (*grpcapi.TaskList, error) {
-	list := s.svc.List(nil)
+// methodActions — какое действие policy нужно для каждого вызова; вызова нет в списке — запрещён.
+// Пустое действие — только токен, любая роль.
+var methodActions = map[string]policy.Action{
+	grpcapi.TodoService_Logout_FullMethodName:              "",
+	grpcapi.TodoService_Create_FullMethodName:              policy.Create,
+	grpcapi.TodoService_Update_FullMethodName:              policy.Update,
+	grpcapi.TodoService_Patch_FullMethodName:               policy.Update,
+	grpcapi.TodoService_Delete_FullMethodName:              policy.Delete,
+	grpcapi.TodoService_DeleteTree_FullMethodName:          policy.Delete,
+	grpcapi.TodoService_ListTrash_FullMethodName:           policy.Read,
+	grpcapi.TodoService_Restore_FullMethodName:             policy.Delete,
+	grpcapi.TodoService_Archive_FullMethodName:             policy.Update,
+	grpcapi.TodoService_Unarchive_FullMethodName:           policy.Update,
+	grpcapi.TodoService_ListArchive_FullMethodName:         policy.Read,
+	grpcapi.TodoService_Export_FullMethodName:              policy.Export,
+	grpcapi.TodoService_Get_FullMethodName:                 policy.Read,
+	grpcapi.TodoService_ListProjects_FullMethodName:        policy.Read,
+	grpcapi.TodoService_List_FullMethodName:                policy.Read,
+	grpcapi.TodoService_ListTasks_FullMethodName:           policy.Read,
+	grpcapi.TodoService_Search_FullMethodName:              policy.Read,
+	grpcapi.TodoService_Children_FullMethodName:            policy.Read,
+	grpcapi.TodoService_Ancestors_FullMethodName:           policy.Read,
+	grpcapi.TodoService_SubtreeProgress_FullMethodName:     policy.Read,
+	grpcapi.TodoService_AddDependency_FullMethodName:       policy.Update,
+	grpcapi.TodoService_RemoveDependency_FullMethodName:    policy.Update,
+	grpcapi.TodoService_Ready_FullMethodName:               policy.Read,
+	grpcapi.TodoService_TopoOrder_FullMethodName:           policy.Read,
+	grpcapi.TodoService_AddTag_FullMethodName:              policy.Update,
+	grpcapi.TodoService_RemoveTag_FullMethodName:           policy.Update,
+	grpcapi.TodoService_TagCounts_FullMethodName:           policy.Read,
+	grpcapi.TodoService_RenumberIDs_FullMethodName:         policy.Renumber,
+	grpcapi.TodoService_Undo_FullMethodName:                policy.Update,
+	grpcapi.TodoService_Redo_FullMethodName:                policy.Update,
+	grpcapi.TodoService_ListComments_FullMethodName:        policy.Read,
+	grpcapi.TodoService_AddComment_FullMethodName:          policy.Update,
+	grpcapi.TodoService_UpdateComment_FullMethodName:       policy.Update,
+	grpcapi.TodoService_DeleteComment_FullMethodName:       policy.Update,
+	grpcapi.TodoService_AddChecklistItem_FullMethodName:    policy.Update,
+	grpcapi.TodoService_ToggleChecklistItem_FullMethodName: policy.Update,
+	grpcapi.TodoService_MoveChecklistItem_FullMethodName:   policy.Update,
+	grpcapi.TodoService_RemoveChecklistItem_FullMethodName: policy.Update,
+	grpcapi.TodoService_ListAttachments_FullMethodName:     policy.Read,
+	grpcapi.TodoService_UploadAttachment_FullMethodName:    policy.Update,
+	grpcapi.TodoService_DownloadAttachment_FullMethodName:  policy.Read,
+	grpcapi.TodoService_DeleteAttachment_FullMethodName:    policy.Update,
+}
+
+// AuthInterceptor — пользователь из метаданных authorization: Bearer <token> едет в reqctx,
+// права роли на вызов проверяет policy (та же матрица, что и в web).
+// Вместо токена подходит ключ API, тогда права ещё сужают его scopes.
+// Без токена пускаем только в publicMethods; что кому видно, решает сервис.
+func AuthInterceptor(sessions *auth.Sessions, keys *auth.APIKeys) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, sessions, keys, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthInterceptor — то же для потоковых вызовов (вложения)
+func StreamAuthInterceptor(sessions *auth.Sessions, keys *auth.APIKeys) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), sessions, keys, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, ctxStream{ss, ctx})
+	}
+}
+
+// authenticate — контекст с пользователем из токена, если роли можно вызывать method
+func authenticate(ctx context.Context, sessions *auth.Sessions, keys *auth.APIKeys, method string) (context.Context, error) {
+	if publicMethods[method] {
+		return ctx, nil
+	}
+	raw, ok := bearer(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing token")
+	}
+	verify := sessions.Verify
+	if auth.IsAPIKey(raw) {
+		verify = keys.Verify
+	}
+	u, err := verify(ctx, raw)
+	if errors.Is(err, auth.ErrBadToken) {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	if err != nil {
+		return nil, err
+	}
+	act, ok := methodActions[method]
+	if !ok {
+		return nil, status.Errorf(codes.PermissionDenied, "no policy for %s", method)
+	}
+	if act != "" {
+		if err := policy.Authorize(u, act); err != nil {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+	}
+	return reqctx.WithUser(ctx, u), nil
+}
+
+// bearer — токен из метаданных authorization: Bearer <token>
+func bearer(ctx context.Context) (string, bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get("authorization"); len(v) > 0 {
+		return strings.CutPrefix(v[0], "Bearer ")
+	}
+	return "", false
+}
+
+func taskList(list []*model.Task) *grpcapi.TaskList {
 	resp := &grpcapi.TaskList{}
 	for _, t := range list {
-		resp.Items = append(resp.Items, dtoToProto(t)) // было *dtoToProto(t)
+		resp.Items = append(resp.Items, dtoToProto(t))
 	}
-	return resp, nil
+	return resp
 }
 
 func dtoToProto(t *model.Task) *grpcapi.Task {
-	var due, comp string
+	var due, comp, deleted, archived string
 	if t.DueAt() != nil {
 		due = t.DueAt().Format("2006-01-02")
 	}
 	if t.CompletedAt() != nil {
 		comp = t.CompletedAt().Format("2006-01-02 15:04")
 	}
+	if t.DeletedAt() != nil {
+		deleted = t.DeletedAt().Format("2006-01-02 15:04")
+	}
+	if t.ArchivedAt() != nil {
+		archived = t.ArchivedAt().Format("2006-01-02 15:04")
+	}
+	var blockers []int64
+	for _, b := range t.BlockedBy() {
+		blockers = append(blockers, int64(b))
+	}
+	var rule string
+	if r := t.Recurrence(); r != nil {
+		rule = r.String()
+	}
+	var checklist []*grpcapi.ChecklistItem
+	for _, it := range t.Checklist() {
+		checklist = append(checklist, &grpcapi.ChecklistItem{Id: int64(it.ID), Text: it.Text, Done: it.Done})
+	}
 	return &grpcapi.Task{
 		Id:          int64(t.ID()),
 		Title:       t.Title(),
@@ -102,5 +1119,43 @@ func dtoToProto(t *model.Task) *grpcapi.Task {
 		CreatedAt:   t.CreatedAt().Format("2006-01-02 15:04"),
 		UpdatedAt:   t.UpdatedAt().Format("2006-01-02 15:04"),
 		CompletedAt: comp,
+		ParentId:    int64(t.ParentID()),
+		BlockedBy:   blockers,
+		Recurrence:  rule,
+		Tags:        t.Tags(),
+		CreatedBy:   int64(t.CreatedBy()),
+		Assignee:    int64(t.Assignee()),
+		Checklist:   checklist,
+		Version:     t.Version(),
+		DeletedAt:   deleted,
+		ArchivedAt:  archived,
 	}
-}
\ No newline at end of file
+}
+
+func commentToProto(c model.Comment) *grpcapi.Comment {
+	var edited string
+	if c.EditedAt != nil {
+		edited = c.EditedAt.Format("2006-01-02 15:04")
+	}
+	return &grpcapi.Comment{
+		Id:        int64(c.ID),
+		TaskId:    int64(c.TaskID),
+		Author:    int64(c.Author),
+		Body:      c.Body,
+		CreatedAt: c.CreatedAt.Format("2006-01-02 15:04"),
+		EditedAt:  edited,
+	}
+}
+
+func attachmentToProto(a model.Attachment) *grpcapi.Attachment {
+	return &grpcapi.Attachment{
+		Id:          int64(a.ID),
+		TaskId:      int64(a.TaskID),
+		Name:        a.Name,
+		Size:        a.Size,
+		ContentType: a.ContentType,
+		Checksum:    a.Checksum,
+		UploadedBy:  int64(a.UploadedBy),
+		CreatedAt:   a.CreatedAt.Format("2006-01-02 15:04"),
+	}
+}
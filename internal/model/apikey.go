@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// APIKey — ключ для автоматизации (CI, скрипты): действует от имени пользователя UserID,
+// но только в пределах Scopes (действия policy). Сам ключ знает только клиент, у нас — SHA-256.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	UserID     UserID     `json:"user_id"`
+	Scopes     []string   `json:"scopes"`
+	Hash       string     `json:"hash"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"` // nil — бессрочный
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Active — ключ не отозван и не истёк
+func (k APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"fmt"
+	"mime"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// AttachmentID — номер вложения, выдаёт хранилище
+type AttachmentID int64
+
+// MaxAttachmentSize — предел размера одного файла
+const MaxAttachmentSize = 25 << 20
+
+// Attachment — файл, приложенный к задаче. Само содержимое лежит в хранилище
+// файлов под ключом BlobKey, здесь — только описание. Checksum — SHA-256 в hex.
+type Attachment struct {
+	ID          AttachmentID `json:"id"`
+	TaskID      ID           `json:"task_id"`
+	Name        string       `json:"name"`
+	Size        int64        `json:"size"`
+	ContentType string       `json:"content_type"`
+	Checksum    string       `json:"checksum"`
+	BlobKey     string       `json:"-"`
+	UploadedBy  UserID       `json:"uploaded_by,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+// NormalizeAttachmentName — имя файла без пути (браузеры бывает шлют C:\...), 1..255 символов
+func NormalizeAttachmentName(raw string) (string, error) {
+	name := strings.TrimSpace(path.Base(strings.ReplaceAll(raw, `\`, "/")))
+	if name == "." || name == "/" || name == ".." {
+		name = ""
+	}
+	if n := utf8.RuneCountInString(name); n == 0 || n > 255 || strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return "", fmt.Errorf("bad attachment name %q", raw)
+	}
+	return name, nil
+}
+
+// NormalizeContentType — тип содержимого без лишнего; пустой угадываем по расширению имени
+func NormalizeContentType(raw, name string) (string, error) {
+	if strings.TrimSpace(raw) == "" {
+		raw = mime.TypeByExtension(path.Ext(name))
+		if raw == "" {
+			return "application/octet-stream", nil
+		}
+	}
+	media, params, err := mime.ParseMediaType(raw)
+	if err != nil {
+		return "", fmt.Errorf("bad content type %q", raw)
+	}
+	return mime.FormatMediaType(media, params), nil
+}
@@ -0,0 +1,107 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Пределы чек-листа: это список мелочей, а не замена подзадачам
+const (
+	MaxChecklistItems = 100
+	MaxChecklistText  = 500
+)
+
+// ChecklistItem — пункт чек-листа задачи. ID свой в пределах задачи и при перестановках
+// не меняется, так что клиент может на него ссылаться. Порядок — порядок в списке.
+type ChecklistItem struct {
+	ID   int    `json:"id"`
+	Text string `json:"text"`
+	Done bool   `json:"done,omitempty"`
+}
+
+// NormalizeChecklistText — одна строка без пробелов по краям, 1..MaxChecklistText символов
+func NormalizeChecklistText(raw string) (string, error) {
+	text := strings.Join(strings.Fields(raw), " ")
+	if n := utf8.RuneCountInString(text); n == 0 || n > MaxChecklistText {
+		return "", fmt.Errorf("bad checklist item: want 1..%d characters", MaxChecklistText)
+	}
+	return text, nil
+}
+
+func (t *Task) Checklist() []ChecklistItem { return slices.Clone(t.checklist) }
+
+// ChecklistProgress — сколько пунктов и сколько отмечено
+func (t *Task) ChecklistProgress() Progress {
+	p := Progress{Total: len(t.checklist)}
+	for _, it := range t.checklist {
+		if it.Done {
+			p.Done++
+		}
+	}
+	return p
+}
+
+// AddChecklistItem — пункт в конец списка, ID следующий за наибольшим
+func (t *Task) AddChecklistItem(text string) (ChecklistItem, error) {
+	text, err := NormalizeChecklistText(text)
+	if err != nil {
+		return ChecklistItem{}, err
+	}
+	if len(t.checklist) >= MaxChecklistItems {
+		return ChecklistItem{}, fmt.Errorf("checklist is full: %d items", MaxChecklistItems)
+	}
+	it := ChecklistItem{ID: 1, Text: text}
+	for _, c := range t.checklist {
+		it.ID = max(it.ID, c.ID+1)
+	}
+	t.checklist = append(slices.Clone(t.checklist), it)
+	t.touch()
+	return it, nil
+}
+
+// ToggleChecklistItem — переключает отметку; false — такого пункта нет
+func (t *Task) ToggleChecklistItem(id int) (ChecklistItem, bool) {
+	i := t.checklistIndex(id)
+	if i < 0 {
+		return ChecklistItem{}, false
+	}
+	t.checklist = slices.Clone(t.checklist)
+	t.checklist[i].Done = !t.checklist[i].Done
+	t.touch()
+	return t.checklist[i], true
+}
+
+// MoveChecklistItem — ставит пункт на место pos (с нуля; дальше конца — в конец).
+// found=false — такого пункта нет, moved=false — он и так там стоит.
+func (t *Task) MoveChecklistItem(id, pos int) (found, moved bool) {
+	i := t.checklistIndex(id)
+	if i < 0 {
+		return false, false
+	}
+	pos = min(max(pos, 0), len(t.checklist)-1)
+	if pos == i {
+		return true, false
+	}
+	it := t.checklist[i]
+	list := slices.Delete(slices.Clone(t.checklist), i, i+1)
+	t.checklist = slices.Insert(list, pos, it)
+	t.touch()
+	return true, true
+}
+
+// RemoveChecklistItem — убирает пункт; false — такого не было
+func (t *Task) RemoveChecklistItem(id int) bool {
+	i := t.checklistIndex(id)
+	if i < 0 {
+		return false
+	}
+	t.checklist = slices.Delete(slices.Clone(t.checklist), i, i+1)
+	t.touch()
+	return true
+}
+
+func (t *Task) checklistIndex(id int) int {
+	return slices.IndexFunc(t.checklist, func(it ChecklistItem) bool { return it.ID == id })
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// CommentID — номер комментария, выдаёт хранилище
+type CommentID int64
+
+// MaxCommentLen — предел длины комментария в символах
+const MaxCommentLen = 10000
+
+// Comment — комментарий к задаче. Body — markdown как есть: рендерит клиент,
+// мы только храним. Author 0 — комментарий из консоли или внутреннего вызова.
+type Comment struct {
+	ID        CommentID  `json:"id"`
+	TaskID    ID         `json:"task_id"`
+	Author    UserID     `json:"author,omitempty"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"created_at"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"` // nil — не правили
+}
+
+// NormalizeCommentBody — текст без пустых строк и пробелов по краям, 1..MaxCommentLen символов
+func NormalizeCommentBody(raw string) (string, error) {
+	body := strings.TrimSpace(raw)
+	if n := utf8.RuneCountInString(body); n == 0 || n > MaxCommentLen {
+		return "", fmt.Errorf("bad comment: want 1..%d characters", MaxCommentLen)
+	}
+	return body, nil
+}
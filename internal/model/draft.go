@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// TaskDraft — новая задача со всеми полями, которые можно задать при создании (см. Service.Create).
+// Нулевые значения — «не задано»: приоритет по умолчанию, без срока, без родителя и т.д.
+type TaskDraft struct {
+	Title       string
+	Description string
+	Priority    Priority // 0 — PriorityMedium
+	DueAt       *time.Time
+	ParentID    ID
+	Recurrence  *Recurrence
+	Tags        []string
+	Assignee    UserID
+}
+
+// Build собирает задачу из черновика. Любое недопустимое поле — ошибка
+// и никакой задачи: создаётся либо всё целиком, либо ничего
+func (d TaskDraft) Build() (*Task, error) {
+	t, err := NewTask(d.Title, d.Description)
+	if err != nil {
+		return nil, err
+	}
+	if d.Priority != 0 {
+		if err := t.SetPriority(d.Priority); err != nil {
+			return nil, err
+		}
+	}
+	if d.DueAt != nil {
+		t.SetDueAt(*d.DueAt)
+	}
+	if d.ParentID != 0 {
+		t.SetParent(d.ParentID)
+	}
+	// после срока: месячное правило без дня берёт его из срока
+	if err := t.SetRecurrence(d.Recurrence); err != nil {
+		return nil, err
+	}
+	for _, tag := range d.Tags {
+		if _, err := t.AddTag(tag); err != nil {
+			return nil, err
+		}
+	}
+	t.SetAssignee(d.Assignee)
+	return t, nil
+}
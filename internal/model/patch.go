@@ -0,0 +1,54 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TaskPatch — несколько изменений задачи разом (см. Service.Patch).
+// nil — поле не трогаем. Clear* — убрать значение у полей, где «пустого» значения нет.
+type TaskPatch struct {
+	Title           *string
+	Description     *string // "" — очистить
+	Status          *Status
+	Priority        *Priority
+	DueAt           *time.Time
+	ClearDue        bool
+	ParentID        *ID // 0 — вынести на верхний уровень
+	Recurrence      *Recurrence
+	ClearRecurrence bool
+	Assignee        *UserID // 0 — снять
+}
+
+// Empty — менять нечего
+func (p TaskPatch) Empty() bool {
+	return p == TaskPatch{}
+}
+
+// Validate — проверка значений до того, как трогать задачу:
+// патч применяется целиком или никак
+func (p TaskPatch) Validate() error {
+	if p.Title != nil && strings.TrimSpace(*p.Title) == "" {
+		return errors.New("title is empty")
+	}
+	if p.Status != nil && !p.Status.Valid() {
+		return fmt.Errorf("invalid status: %s", *p.Status)
+	}
+	if p.Priority != nil && !p.Priority.Valid() {
+		return fmt.Errorf("invalid priority: %d", *p.Priority)
+	}
+	if p.DueAt != nil && p.ClearDue {
+		return errors.New("due_at is both set and cleared")
+	}
+	if p.Recurrence != nil {
+		if p.ClearRecurrence {
+			return errors.New("recurrence is both set and cleared")
+		}
+		if err := p.Recurrence.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
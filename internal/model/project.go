@@ -0,0 +1,45 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ProjectID — номер проекта, выдаёт хранилище
+type ProjectID int64
+
+// DefaultProject — общий проект: в нём все задачи, созданные до проектов,
+// и в него попадают запросы без проекта (старые маршруты, консоль, gRPC без project).
+// Он открыт всем пользователям, остальные — только участникам.
+const DefaultProject ProjectID = 1
+
+// Project — отдельный список задач со своей нумерацией и участниками
+type Project struct {
+	ID        ProjectID `json:"id"`
+	Name      string    `json:"name"`
+	Members   []UserID  `json:"members"`
+	CreatedBy UserID    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HasMember — участник ли пользователь проекта
+func (p Project) HasMember(u UserID) bool {
+	return slices.Contains(p.Members, u)
+}
+
+// Open — проект без проверки участия (общий)
+func (p Project) Open() bool {
+	return p.ID == DefaultProject
+}
+
+// NormalizeProjectName — имя без пробелов по краям, 1..64 символа
+func NormalizeProjectName(raw string) (string, error) {
+	name := strings.TrimSpace(raw)
+	if n := utf8.RuneCountInString(name); n == 0 || n > 64 {
+		return "", fmt.Errorf("bad project name %q: want 1..64 characters", raw)
+	}
+	return name, nil
+}
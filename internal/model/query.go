@@ -0,0 +1,356 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SortField — поле, по которому можно сортировать выдачу
+type SortField string
+
+const (
+	SortCreated  SortField = "created_at"
+	SortUpdated  SortField = "updated_at"
+	SortDue      SortField = "due_at"
+	SortPriority SortField = "priority"
+	SortTitle    SortField = "title"
+	SortID       SortField = "id"
+)
+
+func (f SortField) Valid() bool {
+	switch f {
+	case SortCreated, SortUpdated, SortDue, SortPriority, SortTitle, SortID:
+		return true
+	default:
+		return false
+	}
+}
+
+// SortKey — одно поле сортировки и направление
+type SortKey struct {
+	Field SortField
+	Desc  bool
+}
+
+// DueSortMax — задачи без срока при сортировке по due_at считаем «самыми поздними».
+// Одно и то же значение используют все хранилища, чтобы курсоры совпадали.
+var DueSortMax = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// DueSortValue — значение due_at для сортировки и сравнения с курсором
+func DueSortValue(d *time.Time) time.Time {
+	if d == nil {
+		return DueSortMax
+	}
+	return *d
+}
+
+// ParseSort разбирает строку вида "priority:desc,created_at" (или "-priority,created_at")
+func ParseSort(raw string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		k := SortKey{}
+		if strings.HasPrefix(part, "-") {
+			k.Desc = true
+			part = part[1:]
+		}
+		if name, dir, ok := strings.Cut(part, ":"); ok {
+			part = name
+			switch strings.ToLower(dir) {
+			case "asc":
+			case "desc":
+				k.Desc = true
+			default:
+				return nil, fmt.Errorf("bad sort direction %q", dir)
+			}
+		}
+		k.Field = SortField(part)
+		if !k.Field.Valid() {
+			return nil, fmt.Errorf("unknown sort field %q", part)
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// TaskQuery — условия выборки задач из хранилища.
+// Пустой запрос означает «все задачи по порядку создания».
+// Хранилища обязаны отработать все условия сами (SQL, BSON или в памяти через Apply).
+type TaskQuery struct {
+	Statuses    []Status   // если пусто — любой статус
+	MinPriority Priority   // 0 — без нижней границы
+	MaxPriority Priority   // 0 — без верхней границы
+	DueAfter    *time.Time // due_at >= DueAfter; задачи без срока не попадают
+	DueBefore   *time.Time // due_at < DueBefore; задачи без срока не попадают
+	CreatedFrom *time.Time // created_at >= CreatedFrom
+	CreatedTo   *time.Time // created_at < CreatedTo
+	Text        string     // подстрока в заголовке или описании, без учёта регистра
+	Tags        []string   // есть все эти теги (AND); теги уже нормализованы
+	AnyTags     []string   // есть хотя бы один из тегов (OR)
+	CreatedBy   UserID     // 0 — любой автор
+	Assignee    UserID     // 0 — любой исполнитель
+	VisibleTo   UserID     // 0 — без ограничений, иначе только задачи, видные пользователю (см. Task.VisibleTo)
+	WithDeleted bool       // вместе с задачами из корзины; без него их в выдаче нет
+	Archived    bool       // выборка из архива вместо горячих задач
+
+	Sort  []SortKey // по умолчанию created_at
+	After *Cursor   // только записи строго после этой позиции в выдаче
+	Limit int       // 0 — без ограничения
+}
+
+// SortKeys — ключи сортировки с добавленным в конец ID, чтобы порядок был однозначным
+func (q TaskQuery) SortKeys() []SortKey {
+	keys := make([]SortKey, 0, len(q.Sort)+1)
+	seen := make(map[SortField]bool, len(q.Sort))
+	hasID := false
+	for _, k := range q.Sort {
+		if seen[k.Field] {
+			continue
+		}
+		seen[k.Field] = true
+		keys = append(keys, k)
+		if k.Field == SortID {
+			hasID = true
+			break // после ID остальное не влияет
+		}
+	}
+	if len(keys) == 0 {
+		keys = append(keys, SortKey{Field: SortCreated})
+	}
+	if !hasID {
+		keys = append(keys, SortKey{Field: SortID})
+	}
+	return keys
+}
+
+// Match — подходит ли запись под фильтры запроса (без учёта курсора и лимита)
+func (q TaskQuery) Match(r TaskDTO) bool {
+	if len(q.Statuses) > 0 {
+		ok := false
+		for _, s := range q.Statuses {
+			if r.Status == s {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	if q.MinPriority != 0 && r.Priority < q.MinPriority {
+		return false
+	}
+	if q.MaxPriority != 0 && r.Priority > q.MaxPriority {
+		return false
+	}
+	if q.DueAfter != nil && (r.DueAt == nil || r.DueAt.Before(*q.DueAfter)) {
+		return false
+	}
+	if q.DueBefore != nil && (r.DueAt == nil || !r.DueAt.Before(*q.DueBefore)) {
+		return false
+	}
+	if q.CreatedFrom != nil && r.CreatedAt.Before(*q.CreatedFrom) {
+		return false
+	}
+	if q.CreatedTo != nil && !r.CreatedAt.Before(*q.CreatedTo) {
+		return false
+	}
+	if q.Text != "" {
+		text := strings.ToLower(q.Text)
+		if !strings.Contains(strings.ToLower(r.Title), text) &&
+			!strings.Contains(strings.ToLower(r.Description), text) {
+			return false
+		}
+	}
+	if len(q.Tags) > 0 && !hasAllTags(r.Tags, q.Tags) {
+		return false
+	}
+	if len(q.AnyTags) > 0 && !hasAnyTag(r.Tags, q.AnyTags) {
+		return false
+	}
+	if q.CreatedBy != 0 && r.CreatedBy != q.CreatedBy {
+		return false
+	}
+	if q.Assignee != 0 && r.Assignee != q.Assignee {
+		return false
+	}
+	if q.VisibleTo != 0 && !visibleTo(r.CreatedBy, r.Assignee, q.VisibleTo) {
+		return false
+	}
+	if !q.WithDeleted && r.DeletedAt != nil {
+		return false
+	}
+	if q.Archived != (r.ArchivedAt != nil) {
+		return false
+	}
+	return true
+}
+
+// Apply — выполнение запроса в памяти: фильтр, сортировка, курсор, лимит.
+// Нужен хранилищам без собственного языка запросов (JSON) и тестам.
+func (q TaskQuery) Apply(items []TaskDTO) []TaskDTO {
+	keys := q.SortKeys()
+	var after TaskDTO
+	if q.After != nil {
+		after = q.After.row()
+	}
+	out := make([]TaskDTO, 0, len(items))
+	for _, r := range items {
+		if !q.Match(r) {
+			continue
+		}
+		if q.After != nil && compareRows(keys, r, after) <= 0 {
+			continue
+		}
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return compareRows(keys, out[i], out[j]) < 0
+	})
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[:q.Limit]
+	}
+	return out
+}
+
+// compareRows сравнивает две записи по ключам сортировки (с учётом направления)
+func compareRows(keys []SortKey, a, b TaskDTO) int {
+	for _, k := range keys {
+		c := compareField(k.Field, a, b)
+		if k.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareField(f SortField, a, b TaskDTO) int {
+	switch f {
+	case SortCreated:
+		return a.CreatedAt.Compare(b.CreatedAt)
+	case SortUpdated:
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	case SortDue:
+		return DueSortValue(a.DueAt).Compare(DueSortValue(b.DueAt))
+	case SortPriority:
+		return int(a.Priority) - int(b.Priority)
+	case SortTitle:
+		return strings.Compare(a.Title, b.Title)
+	default:
+		switch {
+		case a.ID < b.ID:
+			return -1
+		case a.ID > b.ID:
+			return 1
+		}
+		return 0
+	}
+}
+
+// Cursor — позиция в выдаче для keyset-пагинации:
+// значения полей сортировки у последней выданной записи.
+type Cursor struct {
+	ID        ID         `json:"id"`
+	CreatedAt *time.Time `json:"c,omitempty"`
+	UpdatedAt *time.Time `json:"u,omitempty"`
+	DueAt     *time.Time `json:"d,omitempty"` // уже приведено через DueSortValue
+	Priority  Priority   `json:"p,omitempty"`
+	Title     *string    `json:"t,omitempty"`
+}
+
+// CursorFor — курсор, указывающий на запись r при данном порядке сортировки
+func CursorFor(r TaskDTO, keys []SortKey) Cursor {
+	c := Cursor{ID: r.ID}
+	for _, k := range keys {
+		switch k.Field {
+		case SortCreated:
+			v := r.CreatedAt
+			c.CreatedAt = &v
+		case SortUpdated:
+			v := r.UpdatedAt
+			c.UpdatedAt = &v
+		case SortDue:
+			v := DueSortValue(r.DueAt)
+			c.DueAt = &v
+		case SortPriority:
+			c.Priority = r.Priority
+		case SortTitle:
+			v := r.Title
+			c.Title = &v
+		}
+	}
+	return c
+}
+
+// Value — значение поля курсора в том виде, в каком его сравнивает хранилище
+func (c Cursor) Value(f SortField) any {
+	r := c.row()
+	switch f {
+	case SortCreated:
+		return r.CreatedAt
+	case SortUpdated:
+		return r.UpdatedAt
+	case SortDue:
+		return DueSortValue(r.DueAt)
+	case SortPriority:
+		return r.Priority
+	case SortTitle:
+		return r.Title
+	default:
+		return r.ID
+	}
+}
+
+// row — курсор как неполная запись, чтобы сравнивать его тем же compareRows
+func (c Cursor) row() TaskDTO {
+	r := TaskDTO{ID: c.ID, Priority: c.Priority}
+	if c.CreatedAt != nil {
+		r.CreatedAt = *c.CreatedAt
+	}
+	if c.UpdatedAt != nil {
+		r.UpdatedAt = *c.UpdatedAt
+	}
+	if c.DueAt != nil && !c.DueAt.Equal(DueSortMax) {
+		r.DueAt = c.DueAt
+	}
+	if c.Title != nil {
+		r.Title = *c.Title
+	}
+	return r
+}
+
+// Encode — непрозрачная строка для клиента
+func (c Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor — обратное к Encode
+func DecodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("bad cursor")
+	}
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, errors.New("bad cursor")
+	}
+	return &c, nil
+}
+
+// TaskPage — одна страница выдачи
+type TaskPage struct {
+	Items      []*Task
+	NextCursor string // пусто — дальше ничего нет
+}
@@ -0,0 +1,265 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency — базовый шаг повторения
+type Frequency string
+
+const (
+	FreqDaily   Frequency = "DAILY"
+	FreqWeekly  Frequency = "WEEKLY"
+	FreqMonthly Frequency = "MONTHLY"
+	FreqYearly  Frequency = "YEARLY"
+)
+
+// Recurrence — правило повторения в духе RRULE (RFC 5545), только нужное нам подмножество:
+// FREQ, INTERVAL, BYDAY, BYMONTHDAY, COUNT, UNTIL.
+// Правило неизменяемое: чтобы поменять, собираем новое.
+type Recurrence struct {
+	Freq     Frequency
+	Interval int            // каждые N шагов, по умолчанию 1
+	Weekdays []time.Weekday // BYDAY: только эти дни недели (для DAILY и WEEKLY)
+	MonthDay int            // BYMONTHDAY: число месяца для MONTHLY, 0 — как у текущего срока
+	Count    int            // сколько повторений осталось, включая текущее; 0 — без ограничения
+	Until    *time.Time     // последний возможный срок включительно
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+func weekdayCode(d time.Weekday) string {
+	return strings.ToUpper(d.String()[:2])
+}
+
+// ParseRecurrence разбирает строку вида "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;COUNT=10".
+// Префикс "RRULE:" можно не писать, UNTIL — YYYY-MM-DD или 20261231.
+func ParseRecurrence(s string) (*Recurrence, error) {
+	r, err := parseRule(s)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// parseStoredRecurrence — правило из хранилища. Сохранённое до проверки DAILY с шагом
+// в неделю поднимаем как есть, а не теряем задачу: Next такую серию просто заканчивает
+func parseStoredRecurrence(s string) (*Recurrence, error) {
+	r, err := parseRule(s)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.validShape(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// parseRule — разбор без проверок
+func parseRule(s string) (*Recurrence, error) {
+	s = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "RRULE:")
+	if s == "" {
+		return nil, fmt.Errorf("recurrence: empty rule")
+	}
+	r := &Recurrence{Interval: 1}
+	for _, part := range strings.Split(s, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return nil, fmt.Errorf("recurrence: bad part %q", part)
+		}
+		var err error
+		switch key {
+		case "FREQ":
+			r.Freq = Frequency(val)
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(val)
+		case "COUNT":
+			r.Count, err = strconv.Atoi(val)
+		case "BYMONTHDAY":
+			r.MonthDay, err = strconv.Atoi(val)
+		case "BYDAY":
+			for _, code := range strings.Split(val, ",") {
+				d, ok := weekdayCodes[code]
+				if !ok {
+					return nil, fmt.Errorf("recurrence: bad weekday %q", code)
+				}
+				if !slices.Contains(r.Weekdays, d) {
+					r.Weekdays = append(r.Weekdays, d)
+				}
+			}
+		case "UNTIL":
+			var t time.Time
+			if t, err = time.Parse("2006-01-02", val); err != nil {
+				t, err = time.Parse("20060102", val[:min(8, len(val))])
+			}
+			r.Until = &t
+		default:
+			return nil, fmt.Errorf("recurrence: unsupported %s", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("recurrence: bad %s %q", key, val)
+		}
+	}
+	return r, nil
+}
+
+// Validate — правило можно применять
+func (r *Recurrence) Validate() error {
+	if err := r.validShape(); err != nil {
+		return err
+	}
+	// шаг кратен неделе — день недели никогда не меняется, и BYDAY либо лишний, либо
+	// не совпадёт ни разу. Такие правила пишут как WEEKLY с BYDAY
+	if r.Freq == FreqDaily && len(r.Weekdays) > 0 && r.Interval%7 == 0 {
+		return fmt.Errorf("recurrence: DAILY with INTERVAL=%d never changes the weekday, use FREQ=WEEKLY;INTERVAL=%d with BYDAY", r.Interval, r.Interval/7)
+	}
+	return nil
+}
+
+// validShape — поля правила в допустимых пределах
+func (r *Recurrence) validShape() error {
+	switch r.Freq {
+	case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+	default:
+		return fmt.Errorf("recurrence: bad FREQ %q", r.Freq)
+	}
+	if r.Interval < 1 {
+		return fmt.Errorf("recurrence: INTERVAL must be positive")
+	}
+	if r.Count < 0 {
+		return fmt.Errorf("recurrence: COUNT must not be negative")
+	}
+	if r.Count > 0 && r.Until != nil {
+		return fmt.Errorf("recurrence: COUNT and UNTIL are mutually exclusive")
+	}
+	if r.MonthDay < 0 || r.MonthDay > 31 {
+		return fmt.Errorf("recurrence: bad BYMONTHDAY %d", r.MonthDay)
+	}
+	if len(r.Weekdays) > 0 && r.Freq != FreqDaily && r.Freq != FreqWeekly {
+		return fmt.Errorf("recurrence: BYDAY works only with DAILY or WEEKLY")
+	}
+	return nil
+}
+
+// String — обратно в текст RRULE, в каноническом порядке
+func (r *Recurrence) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.Weekdays) > 0 {
+		days := slices.Clone(r.Weekdays)
+		// неделя с понедельника
+		slices.SortFunc(days, func(a, b time.Weekday) int { return (int(a)+6)%7 - (int(b)+6)%7 })
+		codes := make([]string, len(days))
+		for i, d := range days {
+			codes[i] = weekdayCode(d)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.MonthDay > 0 {
+		parts = append(parts, "BYMONTHDAY="+strconv.Itoa(r.MonthDay))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// rule — текст для DTO, у nil — пусто
+func (r *Recurrence) rule() string {
+	if r == nil {
+		return ""
+	}
+	return r.String()
+}
+
+// Next — следующий срок после from; false — повторения кончились (UNTIL)
+// или подходящего дня нет вовсе
+func (r *Recurrence) Next(from time.Time) (time.Time, bool) {
+	var next time.Time
+	switch r.Freq {
+	case FreqDaily:
+		next = from.AddDate(0, 0, r.Interval)
+		// шаг, не кратный неделе, за 7 шагов проходит все дни недели; кратный — стоит
+		// на месте (такое Validate не пропускает, но правило могло прийти из старых данных)
+		for i := 1; len(r.Weekdays) > 0 && !slices.Contains(r.Weekdays, next.Weekday()); i++ {
+			if i == 7 {
+				return time.Time{}, false
+			}
+			next = next.AddDate(0, 0, r.Interval)
+		}
+	case FreqWeekly:
+		next = r.nextWeekly(from)
+	case FreqMonthly:
+		day := r.MonthDay
+		if day == 0 {
+			day = from.Day()
+		}
+		next = addMonthsClamped(from, r.Interval, day)
+	case FreqYearly:
+		next = addMonthsClamped(from, 12*r.Interval, from.Day())
+	}
+	if r.Until != nil && next.After(endOfDay(*r.Until)) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// nextWeekly — без BYDAY просто +N недель; с BYDAY — следующий подходящий день
+// в этой же неделе, иначе первый подходящий через N недель (неделя с понедельника)
+func (r *Recurrence) nextWeekly(from time.Time) time.Time {
+	if len(r.Weekdays) == 0 {
+		return from.AddDate(0, 0, 7*r.Interval)
+	}
+	offset := (int(from.Weekday()) + 6) % 7 // дней с понедельника
+	monday := from.AddDate(0, 0, -offset)
+	for d := offset + 1; d < 7; d++ {
+		if day := monday.AddDate(0, 0, d); slices.Contains(r.Weekdays, day.Weekday()) {
+			return day
+		}
+	}
+	monday = monday.AddDate(0, 0, 7*r.Interval)
+	for d := 0; ; d++ {
+		if day := monday.AddDate(0, 0, d); slices.Contains(r.Weekdays, day.Weekday()) {
+			return day
+		}
+	}
+}
+
+// Following — правило для следующего повторения; false — это было последним (COUNT)
+func (r *Recurrence) Following() (*Recurrence, bool) {
+	if r.Count == 1 {
+		return nil, false
+	}
+	c := *r
+	if c.Count > 0 {
+		c.Count--
+	}
+	return &c, true
+}
+
+// addMonthsClamped — +n месяцев на число day; если такого числа нет (31 февраля) — последний день месяца
+func addMonthsClamped(from time.Time, n, day int) time.Time {
+	first := time.Date(from.Year(), from.Month(), 1, from.Hour(), from.Minute(), from.Second(), from.Nanosecond(), from.Location())
+	first = first.AddDate(0, n, 0)
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(day, last)-1)
+}
+
+func endOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, t.Location())
+}
@@ -0,0 +1,31 @@
+package model
+
+// Метки подсветки совпадений во фрагменте (как у ts_headline по умолчанию).
+// Фрагмент — HTML: текст задачи в нём экранирован, разметка только эта
+const (
+	HighlightStart = "<b>"
+	HighlightStop  = "</b>"
+)
+
+// SearchHit — найденная задача, её релевантность и фрагмент текста с подсветкой
+type SearchHit struct {
+	Task    TaskDTO `json:"task"`
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+// SearchQuery — полнотекстовый запрос к хранилищу. Корзину и видимость хранилище отсекает само,
+// до лимита: иначе удалённые и чужие задачи с высоким рангом съедали бы места в выдаче
+type SearchQuery struct {
+	Text      string
+	VisibleTo UserID // 0 — без ограничений, иначе только задачи, видные пользователю (см. Task.VisibleTo)
+	Limit     int    // 0 — без ограничения
+}
+
+// Match — проходит ли запись фильтры запроса (для поиска в памяти)
+func (q SearchQuery) Match(r TaskDTO) bool {
+	if r.DeletedAt != nil {
+		return false // корзина в поиск не попадает
+	}
+	return q.VisibleTo == 0 || visibleTo(r.CreatedBy, r.Assignee, q.VisibleTo)
+}
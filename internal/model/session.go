@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// Session — один вход пользователя; живёт, пока клиент обновляет refresh-токен.
+// Сам refresh-токен знает только клиент, у нас — хеш текущего (см. auth.Sessions).
+type Session struct {
+	ID          string    `json:"id"`
+	UserID      UserID    `json:"user_id"`
+	RefreshHash string    `json:"refresh_hash"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
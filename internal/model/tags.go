@@ -0,0 +1,81 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// MaxTagLen — длина тега в символах после нормализации
+const MaxTagLen = 32
+
+// NormalizeTag приводит тег к одному виду: без # в начале, в нижнем регистре,
+// пробелы внутри заменены на «-». Разрешены буквы, цифры, «-», «_» и «.».
+func NormalizeTag(raw string) (string, error) {
+	tag := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(raw), "#"))
+	tag = strings.Join(strings.Fields(tag), "-")
+	if tag == "" {
+		return "", fmt.Errorf("bad tag %q: empty", raw)
+	}
+	if n := len([]rune(tag)); n > MaxTagLen {
+		return "", fmt.Errorf("bad tag %q: longer than %d", raw, MaxTagLen)
+	}
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' && r != '.' {
+			return "", fmt.Errorf("bad tag %q: unexpected %q", raw, r)
+		}
+	}
+	return tag, nil
+}
+
+// NormalizeTags — нормализованные теги без повторов, по алфавиту; nil для пустого списка
+func NormalizeTags(raw []string) ([]string, error) {
+	var tags []string
+	for _, r := range raw {
+		tag, err := NormalizeTag(r)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+	return slices.Compact(tags), nil
+}
+
+// TagCount — сколько задач помечено тегом
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// SortTagCounts — частые теги первыми, при равенстве по алфавиту
+func SortTagCounts(counts []TagCount) {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Tag < counts[j].Tag
+	})
+}
+
+// hasAllTags — у задачи есть каждый из тегов
+func hasAllTags(have, want []string) bool {
+	for _, t := range want {
+		if !slices.Contains(have, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasAnyTag — у задачи есть хотя бы один из тегов
+func hasAnyTag(have, want []string) bool {
+	for _, t := range want {
+		if slices.Contains(have, t) {
+			return true
+		}
+	}
+	return false
+}
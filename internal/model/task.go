@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"slices"
 	"strings"
 	"time"
 )
@@ -58,14 +59,19 @@ func (p Priority) Valid() bool {
 }
 
 // meta — просто технич поля про время создания/обновления/завершения
+// и номер версии для проверки одновременных правок (ETag)
 type meta struct {
 	createdAt   time.Time
 	updatedAt   time.Time
 	completedAt *time.Time
+	deletedAt   *time.Time // в корзине с этого момента, nil — живая задача
+	archivedAt  *time.Time // в архиве с этого момента, nil — горячая задача
+	version     int64
 }
 
 func (m *meta) touch() {
 	m.updatedAt = time.Now()
+	m.version++
 }
 
 // Task — основная структура задачи
@@ -76,7 +82,18 @@ type Task struct {
 	description string //необязательно
 	status      Status
 	priority    Priority
-	dueAt       *time.Time // дедлайн, необязательный 
+	dueAt       *time.Time  // дедлайн, необязательный
+	parentID    ID          // родительская задача, 0 — верхний уровень
+	blockedBy   []ID        // задачи, которые должны быть сделаны раньше этой
+	recurrence  *Recurrence // правило повторения, nil — разовая задача
+	tags        []string    // нормализованные, без повторов, по алфавиту
+	createdBy   UserID      // автор, 0 — общая задача (консоль или до появления пользователей)
+	assignee    UserID      // исполнитель, 0 — не назначен
+	checklist   []ChecklistItem
+	// что Delete отцепил, перенося задачу в корзину: подзадачи, поднятые на уровень выше,
+	// и задачи, у которых она была блокером. Restore цепляет их обратно
+	detachedChildren   []ID
+	detachedDependents []ID
 }
 
 // NewTask — создает новую задачу, с базовыми полями (id поле трогаем если только знаем, что ничего плохого не будет!)
@@ -91,19 +108,52 @@ func NewTask(title, description string) (*Task, error) {
 		title:       title,
 		description: strings.TrimSpace(description),
 		status:      StatusNew,
-		priority:    PriorityMedium, 
+		priority:    PriorityMedium,
 		meta: meta{
 			createdAt: now,
 			updatedAt: now,
+			version:   1,
 		},
 	}, nil
 }
 
+// Clone — копия задачи: сервис меняет копию, а опубликованную версию не трогает
+func (t *Task) Clone() *Task {
+	c := *t
+	c.blockedBy = slices.Clone(t.blockedBy)
+	c.tags = slices.Clone(t.tags)
+	c.checklist = slices.Clone(t.checklist)
+	c.detachedChildren = slices.Clone(t.detachedChildren)
+	c.detachedDependents = slices.Clone(t.detachedDependents)
+	return &c
+}
+
 // SetID — может пригодиться при пересоздании или нумерации
 func (t *Task) SetID(id ID) {
 	t.id = id
 }
 
+// Renumber — переводит ID задачи и все ссылки (родитель, блокеры, отцепленные в корзине) на новые номера.
+// Ссылки на задачи, которых нет в remap, выкидываем. updatedAt не трогаем.
+func (t *Task) Renumber(remap map[ID]ID) {
+	t.id = remap[t.id]
+	t.parentID = remap[t.parentID]
+	t.blockedBy = RemapIDs(t.blockedBy, remap)
+	t.detachedChildren = RemapIDs(t.detachedChildren, remap)
+	t.detachedDependents = RemapIDs(t.detachedDependents, remap)
+}
+
+// RemapIDs — ссылки на задачи по remap; тех, кого в remap нет, выкидываем
+func RemapIDs(ids []ID, remap map[ID]ID) []ID {
+	var out []ID
+	for _, id := range ids {
+		if n, ok := remap[id]; ok {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
 func (t *Task) TypeName() string { return "task" }
 
 // Геттеры и всякое для получения
@@ -116,6 +166,35 @@ func (t *Task) DueAt() *time.Time       { return t.dueAt }
 func (t *Task) CreatedAt() time.Time    { return t.createdAt }
 func (t *Task) UpdatedAt() time.Time    { return t.updatedAt }
 func (t *Task) CompletedAt() *time.Time { return t.completedAt }
+func (t *Task) DeletedAt() *time.Time   { return t.deletedAt }
+func (t *Task) ArchivedAt() *time.Time  { return t.archivedAt }
+func (t *Task) Version() int64          { return t.version }
+func (t *Task) ParentID() ID            { return t.parentID }
+func (t *Task) BlockedBy() []ID         { return slices.Clone(t.blockedBy) }
+func (t *Task) Recurrence() *Recurrence { return t.recurrence }
+func (t *Task) Tags() []string          { return slices.Clone(t.tags) }
+func (t *Task) CreatedBy() UserID       { return t.createdBy }
+func (t *Task) Assignee() UserID        { return t.assignee }
+
+// Автор задачи — ставится один раз при создании, updatedAt не трогаем
+func (t *Task) SetCreatedBy(u UserID) {
+	t.createdBy = u
+}
+
+// Назначает исполнителя, 0 — снять назначение
+func (t *Task) SetAssignee(u UserID) {
+	t.assignee = u
+	t.touch()
+}
+
+// Видна ли задача пользователю u: общая, его собственная или назначена ему
+func (t *Task) VisibleTo(u UserID) bool {
+	return visibleTo(t.createdBy, t.assignee, u)
+}
+
+func visibleTo(createdBy, assignee, u UserID) bool {
+	return createdBy == 0 || createdBy == u || assignee == u
+}
 
 // Меняет заголовок и трогает updatedAt
 func (t *Task) SetTitle(title string) error {
@@ -134,11 +213,23 @@ func (t *Task) SetDescription(desc string) {
 	t.touch()
 }
 
-// Меняет статус, если done — ставит отметку о завершении
+// Меняет статус по графу DefaultTransitions, если done — ставит отметку о завершении
 func (t *Task) SetStatus(s Status) error {
+	return t.Transition(DefaultTransitions, s)
+}
+
+// Transition — смена статуса по заданной таблице переходов.
+// Недопустимый переход — *TransitionError, тот же статус — ничего не меняет.
+func (t *Task) Transition(tr Transitions, s Status) error {
 	if !s.Valid() {
 		return fmt.Errorf("invalid status: %s", s)
 	}
+	if s == t.status {
+		return nil
+	}
+	if !tr.Allowed(t.status, s) {
+		return &TransitionError{From: t.status, To: s, Allowed: tr[t.status]}
+	}
 	t.status = s
 	if s == StatusDone {
 		now := time.Now()
@@ -167,23 +258,218 @@ func (t *Task) SetDueAt(d time.Time) {
 	t.touch()
 }
 
+// Переносит задачу под другого родителя (0 — на верхний уровень).
+// Что родитель существует и не получится цикл, проверяет сервис.
+func (t *Task) SetParent(p ID) {
+	t.parentID = p
+	t.touch()
+}
+
+// Задаёт правило повторения, nil — убрать.
+// Для MONTHLY без BYMONTHDAY запоминаем число из срока, чтобы 31-е не сползало на 28-е.
+func (t *Task) SetRecurrence(r *Recurrence) error {
+	if r != nil {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+		if r.Freq == FreqMonthly && r.MonthDay == 0 && t.dueAt != nil {
+			c := *r
+			c.MonthDay = t.dueAt.Day()
+			r = &c
+		}
+	}
+	t.recurrence = r
+	t.touch()
+	return nil
+}
+
+// NextOccurrence — следующая задача по правилу: те же поля, срок сдвинут, статус new.
+// Точка отсчёта — срок задачи, а если его нет — момент now.
+// false — задача разовая или повторения кончились.
+func (t *Task) NextOccurrence(now time.Time) (*Task, bool) {
+	if t.recurrence == nil {
+		return nil, false
+	}
+	rule, ok := t.recurrence.Following()
+	if !ok {
+		return nil, false
+	}
+	from := now
+	if t.dueAt != nil {
+		from = *t.dueAt
+	}
+	due, ok := t.recurrence.Next(from)
+	if !ok {
+		return nil, false
+	}
+	next, err := NewTask(t.title, t.description)
+	if err != nil {
+		return nil, false
+	}
+	next.priority = t.priority
+	next.parentID = t.parentID
+	next.dueAt = &due
+	next.recurrence = rule
+	next.tags = slices.Clone(t.tags)
+	next.createdBy = t.createdBy
+	next.assignee = t.assignee
+	// чек-лист переезжает в следующий раз неотмеченным
+	for _, it := range t.checklist {
+		it.Done = false
+		next.checklist = append(next.checklist, it)
+	}
+	return next, true
+}
+
+// Помечена ли задача тегом (тег сравниваем после нормализации)
+func (t *Task) HasTag(tag string) bool {
+	tag, err := NormalizeTag(tag)
+	if err != nil {
+		return false
+	}
+	_, ok := slices.BinarySearch(t.tags, tag)
+	return ok
+}
+
+// Добавляет тег; false — такой уже был
+func (t *Task) AddTag(tag string) (bool, error) {
+	tag, err := NormalizeTag(tag)
+	if err != nil {
+		return false, err
+	}
+	i, ok := slices.BinarySearch(t.tags, tag)
+	if ok {
+		return false, nil
+	}
+	t.tags = slices.Insert(slices.Clone(t.tags), i, tag)
+	t.touch()
+	return true, nil
+}
+
+// Убирает тег; false — такого не было
+func (t *Task) RemoveTag(tag string) bool {
+	tag, err := NormalizeTag(tag)
+	if err != nil {
+		return false
+	}
+	i, ok := slices.BinarySearch(t.tags, tag)
+	if !ok {
+		return false
+	}
+	t.tags = slices.Delete(slices.Clone(t.tags), i, i+1)
+	t.touch()
+	return true
+}
+
+// Добавляет блокер; false — уже был
+func (t *Task) AddBlocker(id ID) bool {
+	if slices.Contains(t.blockedBy, id) {
+		return false
+	}
+	t.blockedBy = append(slices.Clone(t.blockedBy), id)
+	t.touch()
+	return true
+}
+
+// Заблокирована ли задача задачей id
+func (t *Task) DependsOn(id ID) bool {
+	return slices.Contains(t.blockedBy, id)
+}
+
+// Убирает блокер; false — такого не было
+func (t *Task) RemoveBlocker(id ID) bool {
+	i := slices.Index(t.blockedBy, id)
+	if i < 0 {
+		return false
+	}
+	t.blockedBy = slices.Delete(slices.Clone(t.blockedBy), i, i+1)
+	t.touch()
+	return true
+}
+
+// Открыта ли задача: не сделана и не отменена
+func (t *Task) Open() bool {
+	return t.status != StatusDone && t.status != StatusCanceled
+}
+
 // Убирает срок если решили без него
 func (t *Task) ClearDue() {
 	t.dueAt = nil
 	t.touch()
 }
 
+// MoveToTrash — задача удалена, но пока лежит в корзине (см. Service.Restore)
+// children и dependents — что Delete от неё отцепил (см. Detached)
+func (t *Task) MoveToTrash(at time.Time, children, dependents []ID) {
+	at = at.UTC()
+	t.deletedAt = &at
+	t.detachedChildren = slices.Clone(children)
+	t.detachedDependents = slices.Clone(dependents)
+	t.touch()
+}
+
+// Detached — подзадачи и зависимые, отцепленные при удалении; у живых задач пусто
+func (t *Task) Detached() (children, dependents []ID) {
+	return t.detachedChildren, t.detachedDependents
+}
+
+// Restore — достать задачу из корзины. Отцепленное возвращает сервис, задача о нём забывает
+func (t *Task) Restore() {
+	t.deletedAt = nil
+	t.detachedChildren, t.detachedDependents = nil, nil
+	t.touch()
+}
+
+// MoveToArchive — закрытая задача уезжает в архив (см. Service.Archive).
+// Это переезд, а не правка: версию и updatedAt не трогаем
+func (t *Task) MoveToArchive(at time.Time) {
+	at = at.UTC()
+	t.archivedAt = &at
+}
+
+// Unarchive — вернуть задачу из архива к горячим
+func (t *Task) Unarchive() {
+	t.archivedAt = nil
+}
+
+// RevertTo — содержимое задачи из снимка r, вместе с местом (корзина, архив):
+// так отменяют операцию (см. Service.Undo). Номер остаётся свой, версия растёт как при правке
+func (t *Task) RevertTo(r TaskDTO) error {
+	c, err := FromDTO(r)
+	if err != nil {
+		return err
+	}
+	c.id, c.version = t.id, t.version
+	*t = *c
+	t.touch()
+	return nil
+}
+
 // TaskDTO — используется чтобы сохранять задачу в JSON
 type TaskDTO struct {
-	ID          ID         `json:"id"`
-	Title       string     `json:"title"`
-	Description string     `json:"description,omitempty"`
-	Status      Status     `json:"status"`
-	Priority    Priority   `json:"priority"`
-	DueAt       *time.Time `json:"due_at,omitempty"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
-	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ID          ID              `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description,omitempty"`
+	Status      Status          `json:"status"`
+	Priority    Priority        `json:"priority"`
+	DueAt       *time.Time      `json:"due_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+	DeletedAt   *time.Time      `json:"deleted_at,omitempty"`  // задача в корзине
+	ArchivedAt  *time.Time      `json:"archived_at,omitempty"` // задача в архиве
+	ParentID    ID              `json:"parent_id,omitempty"`
+	BlockedBy   []ID            `json:"blocked_by,omitempty"`
+	Recurrence  string          `json:"recurrence,omitempty"` // RRULE, см. ParseRecurrence
+	Tags        []string        `json:"tags,omitempty"`
+	CreatedBy   UserID          `json:"created_by,omitempty"`
+	Assignee    UserID          `json:"assignee,omitempty"`
+	Checklist   []ChecklistItem `json:"checklist,omitempty"`
+	Version     int64           `json:"version"` // растёт с каждым изменением, 0 — записано до версий
+
+	// только у задач в корзине: что отцепили при удалении (см. Task.Detached)
+	DetachedChildren   []ID `json:"detached_children,omitempty"`
+	DetachedDependents []ID `json:"detached_dependents,omitempty"`
 }
 
 func (t *Task) ToDTO() TaskDTO {
@@ -197,6 +483,19 @@ func (t *Task) ToDTO() TaskDTO {
 		CreatedAt:   t.createdAt,
 		UpdatedAt:   t.updatedAt,
 		CompletedAt: t.completedAt,
+		DeletedAt:   t.deletedAt,
+		ArchivedAt:  t.archivedAt,
+		ParentID:    t.parentID,
+		BlockedBy:   slices.Clone(t.blockedBy),
+		Recurrence:  t.recurrence.rule(),
+		Tags:        slices.Clone(t.tags),
+		CreatedBy:   t.createdBy,
+		Assignee:    t.assignee,
+		Checklist:   slices.Clone(t.checklist),
+		Version:     t.version,
+
+		DetachedChildren:   slices.Clone(t.detachedChildren),
+		DetachedDependents: slices.Clone(t.detachedDependents),
 	}
 }
 
@@ -211,6 +510,17 @@ func FromDTO(r TaskDTO) (*Task, error) {
 	if !r.Priority.Valid() {
 		return nil, fmt.Errorf("record: bad priority %d", r.Priority)
 	}
+	var rec *Recurrence
+	if r.Recurrence != "" {
+		var err error
+		if rec, err = parseStoredRecurrence(r.Recurrence); err != nil {
+			return nil, fmt.Errorf("record: %w", err)
+		}
+	}
+	tags, err := NormalizeTags(r.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("record: %w", err)
+	}
 	return &Task{
 		id:          r.ID,
 		title:       strings.TrimSpace(r.Title),
@@ -218,10 +528,23 @@ func FromDTO(r TaskDTO) (*Task, error) {
 		status:      r.Status,
 		priority:    r.Priority,
 		dueAt:       r.DueAt,
+		parentID:    r.ParentID,
+		blockedBy:   slices.Clone(r.BlockedBy),
+		recurrence:  rec,
+		tags:        tags,
+		createdBy:   r.CreatedBy,
+		assignee:    r.Assignee,
+		checklist:   slices.Clone(r.Checklist),
+
+		detachedChildren:   slices.Clone(r.DetachedChildren),
+		detachedDependents: slices.Clone(r.DetachedDependents),
 		meta: meta{
 			createdAt:   r.CreatedAt,
 			updatedAt:   r.UpdatedAt,
 			completedAt: r.CompletedAt,
+			deletedAt:   r.DeletedAt,
+			archivedAt:  r.ArchivedAt,
+			version:     r.Version,
 		},
 	}, nil
-}
\ No newline at end of file
+}
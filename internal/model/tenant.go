@@ -0,0 +1,42 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TenantID — арендатор (отдел, команда), у каждого свои проекты и задачи
+// в отдельном хранилище: своя база в MongoDB, свой каталог в JSON-режиме,
+// свои строки под row-level security в PostgreSQL.
+// Имя идёт в пути и имена баз, поэтому только латиница, цифры и «-».
+type TenantID string
+
+// DefaultTenant — арендатор для всех данных, созданных до арендаторов,
+// и для внутренних вызовов без пользователя (консоль, фоновые задачи)
+const DefaultTenant TenantID = "default"
+
+// OrDefault — пустой арендатор (записи и токены до арендаторов) — DefaultTenant
+func (t TenantID) OrDefault() TenantID {
+	if t == "" {
+		return DefaultTenant
+	}
+	return t
+}
+
+// ParseTenant — имя арендатора в нижнем регистре: 1..32 символа, латиница, цифры и «-»;
+// пусто — DefaultTenant
+func ParseTenant(raw string) (TenantID, error) {
+	name := strings.ToLower(strings.TrimSpace(raw))
+	if name == "" {
+		return DefaultTenant, nil
+	}
+	if len(name) > 32 || name[0] == '-' {
+		return "", fmt.Errorf("bad tenant %q: want 1..32 characters, not starting with '-'", raw)
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return "", fmt.Errorf("bad tenant %q: unexpected %q", raw, r)
+		}
+	}
+	return TenantID(name), nil
+}
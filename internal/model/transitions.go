@@ -0,0 +1,85 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Transitions — граф статусов: из какого статуса в какие можно перейти.
+// Статус без исходящих переходов — конечный.
+type Transitions map[Status][]Status
+
+// DefaultTransitions — обычный цикл new → in_progress → done.
+// Паузу и отмену можно поставить на любом незавершённом шаге,
+// done и canceled переоткрываются (в работу и в new соответственно).
+var DefaultTransitions = Transitions{
+	StatusNew:        {StatusInProgress, StatusPaused, StatusCanceled},
+	StatusInProgress: {StatusDone, StatusPaused, StatusCanceled},
+	StatusPaused:     {StatusInProgress, StatusCanceled},
+	StatusDone:       {StatusInProgress},
+	StatusCanceled:   {StatusNew},
+}
+
+// Allowed — можно ли из from перейти в to. Остаться в том же статусе можно всегда.
+func (tr Transitions) Allowed(from, to Status) bool {
+	if from == to {
+		return true
+	}
+	for _, s := range tr[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate — в таблице только известные статусы
+func (tr Transitions) Validate() error {
+	for from, targets := range tr {
+		if !from.Valid() {
+			return fmt.Errorf("transitions: unknown status %q", from)
+		}
+		for _, to := range targets {
+			if !to.Valid() {
+				return fmt.Errorf("transitions: unknown status %q in %q", to, from)
+			}
+		}
+	}
+	return nil
+}
+
+// LoadTransitions читает таблицу переходов из JSON-файла вида
+// {"new": ["in_progress", "canceled"], "in_progress": ["done"]}
+func LoadTransitions(path string) (Transitions, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tr Transitions
+	if err := json.Unmarshal(data, &tr); err != nil {
+		return nil, fmt.Errorf("transitions %s: %w", path, err)
+	}
+	if err := tr.Validate(); err != nil {
+		return nil, err
+	}
+	return tr, nil
+}
+
+// TransitionError — переход запрещён таблицей
+type TransitionError struct {
+	From, To Status
+	Allowed  []Status // куда можно из From
+}
+
+func (e *TransitionError) Error() string {
+	if len(e.Allowed) == 0 {
+		return fmt.Sprintf("status %s is final, cannot move to %s", e.From, e.To)
+	}
+	allowed := make([]string, len(e.Allowed))
+	for i, s := range e.Allowed {
+		allowed[i] = string(s)
+	}
+	return fmt.Sprintf("cannot move from %s to %s (allowed: %s)", e.From, e.To, strings.Join(allowed, ", "))
+}
@@ -0,0 +1,63 @@
+package model
+
+// Progress — сколько задач в поддереве и сколько из них сделано.
+// Отменённые не считаются: их и делать не надо.
+type Progress struct {
+	Total int `json:"total"`
+	Done  int `json:"done"`
+}
+
+// Percent — доля сделанного, 0..100; пустое поддерево считаем сделанным
+func (p Progress) Percent() int {
+	if p.Total == 0 {
+		return 100
+	}
+	return p.Done * 100 / p.Total
+}
+
+// TreeNode — задача и её глубина при обходе дерева
+type TreeNode struct {
+	Task  *Task
+	Depth int
+}
+
+// Tree раскладывает список в порядок обхода дерева: родитель, за ним его подзадачи.
+// Порядок соседей сохраняется как в исходном списке. Задача, чьего родителя
+// нет в списке (отфильтровали или удалили), выводится как корень.
+func Tree(list []*Task) []TreeNode {
+	pos := make(map[ID]int, len(list))
+	for i, t := range list {
+		pos[t.ID()] = i
+	}
+	children := make(map[ID][]*Task)
+	var roots []*Task
+	for _, t := range list {
+		if _, ok := pos[t.ParentID()]; ok && t.ParentID() != t.ID() {
+			children[t.ParentID()] = append(children[t.ParentID()], t)
+		} else {
+			roots = append(roots, t)
+		}
+	}
+
+	out := make([]TreeNode, 0, len(list))
+	seen := make(map[ID]bool, len(list))
+	var walk func(t *Task, depth int)
+	walk = func(t *Task, depth int) {
+		if seen[t.ID()] {
+			return
+		}
+		seen[t.ID()] = true
+		out = append(out, TreeNode{Task: t, Depth: depth})
+		for _, c := range children[t.ID()] {
+			walk(c, depth+1)
+		}
+	}
+	for _, t := range roots {
+		walk(t, 0)
+	}
+	// задачи из цикла (в битых данных) без корня — всё равно показываем
+	for _, t := range list {
+		walk(t, 0)
+	}
+	return out
+}
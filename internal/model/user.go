@@ -0,0 +1,66 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// UserID — номер пользователя, выдаёт хранилище
+type UserID int64
+
+// Role — роль пользователя; что кому можно, решает пакет policy
+type Role string
+
+const (
+	RoleViewer Role = "viewer" // только чтение
+	RoleMember Role = "member" // работа с задачами
+	RoleAdmin  Role = "admin"  // всё, включая служебные операции и пользователей
+)
+
+func (r Role) Valid() bool {
+	switch r {
+	case RoleViewer, RoleMember, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// ParseRole — роль из строки; пусто — member
+func ParseRole(raw string) (Role, error) {
+	r := Role(strings.ToLower(strings.TrimSpace(raw)))
+	if r == "" {
+		return RoleMember, nil
+	}
+	if !r.Valid() {
+		return "", fmt.Errorf("bad role %q: want viewer, member or admin", raw)
+	}
+	return r, nil
+}
+
+// User — учётная запись. Пароль хранится только хешем (см. auth.HashPassword),
+// поэтому наружу структуру отдавать нельзя — для ответов есть auth.Profile.
+type User struct {
+	ID           UserID    `json:"id"`
+	Login        string    `json:"login"`
+	PasswordHash string    `json:"password_hash"`
+	Role         Role      `json:"role"`
+	Tenant       TenantID  `json:"tenant,omitempty"` // пусто — DefaultTenant
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// NormalizeLogin — логин без пробелов по краям и в нижнем регистре:
+// 3..32 символа, латиница, цифры, «.», «_» и «-»
+func NormalizeLogin(raw string) (string, error) {
+	login := strings.ToLower(strings.TrimSpace(raw))
+	if len(login) < 3 || len(login) > 32 {
+		return "", fmt.Errorf("bad login %q: want 3..32 characters", raw)
+	}
+	for _, r := range login {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '.' && r != '_' && r != '-' {
+			return "", fmt.Errorf("bad login %q: unexpected %q", raw, r)
+		}
+	}
+	return login, nil
+}
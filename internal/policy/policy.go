@@ -0,0 +1,71 @@
+// Package policy — кто что может делать: матрица прав по ролям.
+// Она одна на всех: её спрашивают web-middleware, gRPC-интерцептор
+// и сервис для служебных операций. Какие задачи кому видны — отдельно, в сервисе.
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"todo/internal/model"
+	"todo/internal/reqctx"
+)
+
+// Action — что пользователь собирается сделать
+type Action string
+
+const (
+	Read           Action = "read"            // задачи, поиск, теги
+	Create         Action = "create"          // новые задачи и подзадачи
+	Update         Action = "update"          // поля, статус, теги, зависимости, исполнитель
+	Delete         Action = "delete"          // удаление задач
+	Export         Action = "export"          // выгрузка задач целиком
+	Renumber       Action = "renumber"        // перенумерация ID всех задач
+	ManageUsers    Action = "manage_users"    // заводить пользователей и менять им роли
+	ManageKeys     Action = "manage_keys"     // выпускать и отзывать ключи API
+	ManageProjects Action = "manage_projects" // заводить проекты и менять их участников
+)
+
+var ErrForbidden = errors.New("forbidden")
+
+var matrix = map[model.Role]map[Action]bool{
+	model.RoleViewer: {Read: true},
+	model.RoleMember: {Read: true, Create: true, Update: true, Delete: true, Export: true},
+	model.RoleAdmin: {Read: true, Create: true, Update: true, Delete: true, Export: true,
+		Renumber: true, ManageUsers: true, ManageKeys: true, ManageProjects: true},
+}
+
+// Valid — известное ли действие (для scopes ключей API)
+func (a Action) Valid() bool {
+	_, ok := matrix[model.RoleAdmin][a]
+	return ok
+}
+
+// Allowed — есть ли у роли право на действие; неизвестной роли нельзя ничего
+func Allowed(role model.Role, a Action) bool {
+	return matrix[role][a]
+}
+
+// Authorize — может ли пользователь выполнить действие.
+// У ключа API должны разрешать и роль владельца, и scopes ключа.
+func Authorize(u reqctx.User, a Action) error {
+	if !Allowed(u.Role, a) {
+		return fmt.Errorf("%w: %s can't %s", ErrForbidden, u.Role, a)
+	}
+	if u.Scopes != nil && !slices.Contains(u.Scopes, string(a)) {
+		return fmt.Errorf("%w: api key has no scope %s", ErrForbidden, a)
+	}
+	return nil
+}
+
+// Check — то же для пользователя из контекста. Внутренние вызовы без пользователя
+// (консоль, фоновые горутины) разрешены: транспорт без токена до сервиса не пускает.
+func Check(ctx context.Context, a Action) error {
+	u, ok := reqctx.UserFrom(ctx)
+	if !ok {
+		return nil
+	}
+	return Authorize(u, a)
+}
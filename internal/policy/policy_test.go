@@ -0,0 +1,63 @@
+package policy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"todo/internal/model"
+	"todo/internal/policy"
+	"todo/internal/reqctx"
+)
+
+func TestAllowed_Matrix(t *testing.T) {
+	all := []policy.Action{policy.Read, policy.Create, policy.Update, policy.Delete,
+		policy.Export, policy.Renumber, policy.ManageUsers, policy.ManageKeys, policy.ManageProjects}
+	want := map[model.Role][]policy.Action{
+		model.RoleViewer: {policy.Read},
+		model.RoleMember: {policy.Read, policy.Create, policy.Update, policy.Delete, policy.Export},
+		model.RoleAdmin:  all,
+		"":               nil, // роль не задана — нельзя ничего
+	}
+	for role, allowed := range want {
+		for _, a := range all {
+			exp := false
+			for _, x := range allowed {
+				exp = exp || x == a
+			}
+			if got := policy.Allowed(role, a); got != exp {
+				t.Errorf("Allowed(%q, %s) = %v, want %v", role, a, got, exp)
+			}
+		}
+	}
+}
+
+func TestCheck_FromContext(t *testing.T) {
+	ctx := context.Background()
+	if err := policy.Check(ctx, policy.Renumber); err != nil {
+		t.Fatalf("internal call must pass: %v", err)
+	}
+	member := reqctx.WithUser(ctx, reqctx.User{ID: 1, Role: model.RoleMember})
+	if err := policy.Check(member, policy.Renumber); !errors.Is(err, policy.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+	admin := reqctx.WithUser(ctx, reqctx.User{ID: 2, Role: model.RoleAdmin})
+	if err := policy.Check(admin, policy.Renumber); err != nil {
+		t.Fatalf("admin: %v", err)
+	}
+}
+
+func TestAuthorize_Scopes(t *testing.T) {
+	key := reqctx.User{ID: 1, Role: model.RoleMember, Scopes: []string{string(policy.Create)}}
+	if err := policy.Authorize(key, policy.Create); err != nil {
+		t.Fatalf("scoped action: %v", err)
+	}
+	if err := policy.Authorize(key, policy.Delete); !errors.Is(err, policy.ErrForbidden) {
+		t.Fatalf("action outside scopes: expected ErrForbidden, got %v", err)
+	}
+	// scope не расширяет роль
+	viewer := reqctx.User{ID: 2, Role: model.RoleViewer, Scopes: []string{string(policy.Create)}}
+	if err := policy.Authorize(viewer, policy.Create); !errors.Is(err, policy.ErrForbidden) {
+		t.Fatalf("scope must not exceed role: %v", err)
+	}
+}
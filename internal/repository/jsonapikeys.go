@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"todo/internal/model"
+)
+
+// JSONAPIKeyStore — ключи API в отдельном JSON-файле, устроен как JSONUserStore
+type JSONAPIKeyStore struct {
+	Path string
+
+	mu     sync.Mutex
+	items  map[string]model.APIKey
+	loaded bool
+}
+
+func NewJSONAPIKeyStore(path string) *JSONAPIKeyStore {
+	return &JSONAPIKeyStore{Path: path}
+}
+
+func (s *JSONAPIKeyStore) CreateKey(ctx context.Context, k model.APIKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ready(ctx); err != nil {
+		return err
+	}
+	if _, ok := s.items[k.ID]; ok {
+		return ErrDuplicate
+	}
+	s.items[k.ID] = k
+	if err := s.flush(); err != nil {
+		delete(s.items, k.ID)
+		return err
+	}
+	return nil
+}
+
+func (s *JSONAPIKeyStore) GetKey(ctx context.Context, id string) (model.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ready(ctx); err != nil {
+		return model.APIKey{}, err
+	}
+	k, ok := s.items[id]
+	if !ok {
+		return model.APIKey{}, ErrNotFound
+	}
+	return k, nil
+}
+
+func (s *JSONAPIKeyStore) ListKeys(ctx context.Context) ([]model.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ready(ctx); err != nil {
+		return nil, err
+	}
+	return s.sorted(), nil
+}
+
+// RevokeKey — повторный отзыв не сдвигает время первого
+func (s *JSONAPIKeyStore) RevokeKey(ctx context.Context, id string, at time.Time) error {
+	return s.update(ctx, id, func(k *model.APIKey) {
+		if k.RevokedAt == nil {
+			k.RevokedAt = &at
+		}
+	})
+}
+
+func (s *JSONAPIKeyStore) TouchKey(ctx context.Context, id string, at time.Time) error {
+	return s.update(ctx, id, func(k *model.APIKey) { k.LastUsedAt = &at })
+}
+
+func (s *JSONAPIKeyStore) update(ctx context.Context, id string, fn func(k *model.APIKey)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ready(ctx); err != nil {
+		return err
+	}
+	prev, ok := s.items[id]
+	if !ok {
+		return ErrNotFound
+	}
+	k := prev
+	fn(&k)
+	s.items[id] = k
+	if err := s.flush(); err != nil {
+		s.items[id] = prev
+		return err
+	}
+	return nil
+}
+
+func (s *JSONAPIKeyStore) ready(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if s.loaded {
+		return nil
+	}
+	if s.Path == "" {
+		return errors.New("empty store path")
+	}
+	_ = os.MkdirAll(filepath.Dir(s.Path), 0o755)
+
+	s.items = make(map[string]model.APIKey)
+	data, err := os.ReadFile(s.Path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if len(data) > 0 {
+		var items []model.APIKey
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		for _, k := range items {
+			s.items[k.ID] = k
+		}
+	}
+	s.loaded = true
+	return nil
+}
+
+func (s *JSONAPIKeyStore) sorted() []model.APIKey {
+	items := make([]model.APIKey, 0, len(s.items))
+	for _, k := range s.items {
+		items = append(items, k)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].CreatedAt.Equal(items[j].CreatedAt) {
+			return items[i].CreatedAt.Before(items[j].CreatedAt)
+		}
+		return items[i].ID < items[j].ID
+	})
+	return items
+}
+
+// flush — через временный файл; 0600, хотя в файле только хеши ключей
+func (s *JSONAPIKeyStore) flush() error {
+	raw, err := json.MarshalIndent(s.sorted(), "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path)
+}
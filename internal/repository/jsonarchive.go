@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"todo/internal/model"
+)
+
+// Архив JSONStore — свой файл рядом с задачами: tasks.json — tasks_archive.json.
+// Его читают только при обращении к архиву, так что файл задач не растёт закрытыми.
+
+// ArchivePath — файл архива
+func (s *JSONStore) ArchivePath() string {
+	ext := filepath.Ext(s.Path)
+	return strings.TrimSuffix(s.Path, ext) + "_archive" + ext
+}
+
+// Archive — сначала пишем архив, потом убираем задачи из основного файла;
+// не вышло со вторым — откатываем первый
+func (s *JSONStore) Archive(ctx context.Context, tasks []model.TaskDTO) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ready(ctx); err != nil {
+		return err
+	}
+	if err := s.archiveReady(); err != nil {
+		return err
+	}
+	for _, t := range tasks {
+		prev, ok := s.items[t.ID]
+		if !ok {
+			return ErrNotFound
+		}
+		if prev.Version != t.Version {
+			return ErrConflict
+		}
+	}
+
+	prevArchive := s.archive
+	next := maps.Clone(s.archive)
+	for _, t := range tasks {
+		next[t.ID] = t
+	}
+	if err := s.writeArchive(next); err != nil {
+		return err
+	}
+	removed := make([]model.TaskDTO, 0, len(tasks))
+	for _, t := range tasks {
+		removed = append(removed, s.items[t.ID])
+		delete(s.items, t.ID)
+	}
+	if err := s.flush(); err != nil {
+		for _, r := range removed {
+			s.items[r.ID] = r
+		}
+		_ = s.writeArchive(prevArchive)
+		return err
+	}
+	for _, t := range tasks {
+		s.index.Remove(t.ID)
+		s.archiveIndex.Put(t)
+	}
+	return nil
+}
+
+// Unarchive — в обратном порядке: сначала к горячим, потом из архива
+func (s *JSONStore) Unarchive(ctx context.Context, id model.ID) (model.TaskDTO, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ready(ctx); err != nil {
+		return model.TaskDTO{}, err
+	}
+	if err := s.archiveReady(); err != nil {
+		return model.TaskDTO{}, err
+	}
+	t, ok := s.archive[id]
+	if !ok {
+		return model.TaskDTO{}, ErrNotFound
+	}
+	if _, ok := s.items[id]; ok {
+		return model.TaskDTO{}, ErrConflict
+	}
+	t.ArchivedAt = nil
+	s.items[id] = t
+	if err := s.flush(); err != nil {
+		delete(s.items, id)
+		return model.TaskDTO{}, err
+	}
+	next := maps.Clone(s.archive)
+	delete(next, id)
+	if err := s.writeArchive(next); err != nil {
+		delete(s.items, id)
+		_ = s.flush()
+		return model.TaskDTO{}, err
+	}
+	s.index.Put(t)
+	s.archiveIndex.Remove(id)
+	return t, nil
+}
+
+func (s *JSONStore) GetArchived(ctx context.Context, id model.ID) (model.TaskDTO, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return model.TaskDTO{}, err
+	}
+	if err := s.archiveReady(); err != nil {
+		return model.TaskDTO{}, err
+	}
+	t, ok := s.archive[id]
+	if !ok {
+		return model.TaskDTO{}, ErrNotFound
+	}
+	return t, nil
+}
+
+// SearchArchive — тот же индекс в памяти, что и у горячих задач, только свой
+func (s *JSONStore) SearchArchive(ctx context.Context, q model.SearchQuery) ([]model.SearchHit, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.archiveReady(); err != nil {
+		return nil, err
+	}
+	return s.archiveIndex.Search(q), nil
+}
+
+// queryArchive — Query по архиву; вызывать под s.mu
+func (s *JSONStore) queryArchive(q model.TaskQuery) ([]model.TaskDTO, error) {
+	if err := s.archiveReady(); err != nil {
+		return nil, err
+	}
+	items := make([]model.TaskDTO, 0, len(s.archive))
+	for _, t := range s.archive {
+		items = append(items, t)
+	}
+	return q.Apply(items), nil
+}
+
+// writeArchive — файл архива целиком через временный; в память next попадает только после записи
+func (s *JSONStore) writeArchive(next map[model.ID]model.TaskDTO) error {
+	items := make([]model.TaskDTO, 0, len(next))
+	for _, t := range next {
+		items = append(items, t)
+	}
+	sortByCreated(items)
+	raw, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := s.ArchivePath()
+	if err := os.WriteFile(path+".tmp", raw, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+	s.archive = next
+	return nil
+}
+
+// archiveReady — как commentsReady, только для файла архива
+func (s *JSONStore) archiveReady() error {
+	if err := s.settleRenumber(); err != nil {
+		return err
+	}
+	if s.archive != nil {
+		return nil
+	}
+	if s.Path == "" {
+		return errors.New("empty store path")
+	}
+	_ = os.MkdirAll(filepath.Dir(s.Path), 0o755)
+
+	archive := make(map[model.ID]model.TaskDTO)
+	index := NewTextIndex()
+	data, err := os.ReadFile(s.ArchivePath())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if len(data) > 0 {
+		var items []model.TaskDTO
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		for _, t := range items {
+			archive[t.ID] = t
+			index.Put(t)
+		}
+	}
+	s.archive, s.archiveIndex = archive, index
+	return nil
+}
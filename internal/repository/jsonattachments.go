@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"todo/internal/model"
+)
+
+// Вложения JSONStore описаны в своём файле, как и комментарии: tasks.json — tasks_attachments.json.
+// Содержимое файлов здесь не хранится, оно в BlobStore (FSBlobs, S3Blobs).
+
+// attachmentRecord — описание вложения на диске: в отличие от API, с ключом файла
+type attachmentRecord struct {
+	ID          model.AttachmentID `json:"id"`
+	TaskID      model.ID           `json:"task_id"`
+	Name        string             `json:"name"`
+	Size        int64              `json:"size"`
+	ContentType string             `json:"content_type"`
+	Checksum    string             `json:"checksum"`
+	BlobKey     string             `json:"blob_key"`
+	UploadedBy  model.UserID       `json:"uploaded_by,omitempty"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
+// AttachmentsPath — файл описаний вложений
+func (s *JSONStore) AttachmentsPath() string {
+	ext := filepath.Ext(s.Path)
+	return strings.TrimSuffix(s.Path, ext) + "_attachments" + ext
+}
+
+func (s *JSONStore) ListAttachments(ctx context.Context, task model.ID) ([]model.Attachment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.attachmentsReady(ctx); err != nil {
+		return nil, err
+	}
+	var out []model.Attachment
+	for _, a := range s.attachments {
+		if a.TaskID == task {
+			out = append(out, a)
+		}
+	}
+	sortAttachments(out)
+	return out, nil
+}
+
+func (s *JSONStore) GetAttachment(ctx context.Context, id model.AttachmentID) (model.Attachment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.attachmentsReady(ctx); err != nil {
+		return model.Attachment{}, err
+	}
+	a, ok := s.attachments[id]
+	if !ok {
+		return model.Attachment{}, ErrNotFound
+	}
+	return a, nil
+}
+
+// InsertAttachment — ID следующий за наибольшим
+func (s *JSONStore) InsertAttachment(ctx context.Context, a model.Attachment) (model.AttachmentID, error) {
+	err := s.changeAttachments(ctx, func(m map[model.AttachmentID]model.Attachment) error {
+		for id := range m {
+			a.ID = max(a.ID, id)
+		}
+		a.ID++
+		m[a.ID] = a
+		return nil
+	})
+	return a.ID, err
+}
+
+func (s *JSONStore) DeleteAttachment(ctx context.Context, id model.AttachmentID) error {
+	return s.changeAttachments(ctx, func(m map[model.AttachmentID]model.Attachment) error {
+		if _, ok := m[id]; !ok {
+			return ErrNotFound
+		}
+		delete(m, id)
+		return nil
+	})
+}
+
+func (s *JSONStore) DeleteTaskAttachments(ctx context.Context, task model.ID) ([]model.Attachment, error) {
+	var gone []model.Attachment
+	err := s.changeAttachments(ctx, func(m map[model.AttachmentID]model.Attachment) error {
+		maps.DeleteFunc(m, func(_ model.AttachmentID, a model.Attachment) bool {
+			if a.TaskID == task {
+				gone = append(gone, a)
+				return true
+			}
+			return false
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return gone, nil
+}
+
+// changeAttachments — как changeComments: на диск и в память попадает только целая копия
+func (s *JSONStore) changeAttachments(ctx context.Context, fn func(m map[model.AttachmentID]model.Attachment) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.attachmentsReady(ctx); err != nil {
+		return err
+	}
+	next := maps.Clone(s.attachments)
+	if err := fn(next); err != nil {
+		return err
+	}
+	items := make([]model.Attachment, 0, len(next))
+	for _, a := range next {
+		items = append(items, a)
+	}
+	sortAttachments(items)
+	records := make([]attachmentRecord, len(items))
+	for i, a := range items {
+		records[i] = attachmentRecord(a)
+	}
+	raw, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := s.AttachmentsPath()
+	if err := os.WriteFile(path+".tmp", raw, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+	s.attachments = next
+	return nil
+}
+
+// attachmentsReady — как ready, только для файла вложений
+func (s *JSONStore) attachmentsReady(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := s.settleRenumber(); err != nil {
+		return err
+	}
+	if s.attachments != nil {
+		return nil
+	}
+	if s.Path == "" {
+		return errors.New("empty store path")
+	}
+	_ = os.MkdirAll(filepath.Dir(s.Path), 0o755)
+
+	attachments := make(map[model.AttachmentID]model.Attachment)
+	data, err := os.ReadFile(s.AttachmentsPath())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if len(data) > 0 {
+		var records []attachmentRecord
+		if err := json.Unmarshal(data, &records); err != nil {
+			return err
+		}
+		for _, r := range records {
+			attachments[r.ID] = model.Attachment(r)
+		}
+	}
+	s.attachments = attachments
+	return nil
+}
+
+func sortAttachments(items []model.Attachment) {
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+}
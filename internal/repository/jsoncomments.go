@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"todo/internal/model"
+)
+
+// Комментарии JSONStore лежат в своём файле рядом с задачами: tasks.json — tasks_comments.json.
+// Так файл задач не раздувается обсуждениями и не меняет формат.
+
+// CommentsPath — файл комментариев
+func (s *JSONStore) CommentsPath() string {
+	ext := filepath.Ext(s.Path)
+	return strings.TrimSuffix(s.Path, ext) + "_comments" + ext
+}
+
+func (s *JSONStore) ListComments(ctx context.Context, task model.ID) ([]model.Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.commentsReady(ctx); err != nil {
+		return nil, err
+	}
+	var out []model.Comment
+	for _, c := range s.comments {
+		if c.TaskID == task {
+			out = append(out, c)
+		}
+	}
+	sortComments(out)
+	return out, nil
+}
+
+func (s *JSONStore) GetComment(ctx context.Context, id model.CommentID) (model.Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.commentsReady(ctx); err != nil {
+		return model.Comment{}, err
+	}
+	c, ok := s.comments[id]
+	if !ok {
+		return model.Comment{}, ErrNotFound
+	}
+	return c, nil
+}
+
+// InsertComment — ID следующий за наибольшим
+func (s *JSONStore) InsertComment(ctx context.Context, c model.Comment) (model.CommentID, error) {
+	err := s.changeComments(ctx, func(m map[model.CommentID]model.Comment) error {
+		for id := range m {
+			c.ID = max(c.ID, id)
+		}
+		c.ID++
+		m[c.ID] = c
+		return nil
+	})
+	return c.ID, err
+}
+
+func (s *JSONStore) UpdateComment(ctx context.Context, c model.Comment) error {
+	return s.changeComments(ctx, func(m map[model.CommentID]model.Comment) error {
+		if _, ok := m[c.ID]; !ok {
+			return ErrNotFound
+		}
+		m[c.ID] = c
+		return nil
+	})
+}
+
+func (s *JSONStore) DeleteComment(ctx context.Context, id model.CommentID) error {
+	return s.changeComments(ctx, func(m map[model.CommentID]model.Comment) error {
+		if _, ok := m[id]; !ok {
+			return ErrNotFound
+		}
+		delete(m, id)
+		return nil
+	})
+}
+
+func (s *JSONStore) DeleteTaskComments(ctx context.Context, task model.ID) error {
+	return s.changeComments(ctx, func(m map[model.CommentID]model.Comment) error {
+		maps.DeleteFunc(m, func(_ model.CommentID, c model.Comment) bool { return c.TaskID == task })
+		return nil
+	})
+}
+
+// changeComments — fn правит копию, на диск и в память она попадает только целиком
+func (s *JSONStore) changeComments(ctx context.Context, fn func(m map[model.CommentID]model.Comment) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.commentsReady(ctx); err != nil {
+		return err
+	}
+	next := maps.Clone(s.comments)
+	if err := fn(next); err != nil {
+		return err
+	}
+	items := make([]model.Comment, 0, len(next))
+	for _, c := range next {
+		items = append(items, c)
+	}
+	sortComments(items)
+	raw, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := s.CommentsPath()
+	if err := os.WriteFile(path+".tmp", raw, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+	s.comments = next
+	return nil
+}
+
+// commentsReady — как ready, только для файла комментариев
+func (s *JSONStore) commentsReady(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := s.settleRenumber(); err != nil {
+		return err
+	}
+	if s.comments != nil {
+		return nil
+	}
+	if s.Path == "" {
+		return errors.New("empty store path")
+	}
+	_ = os.MkdirAll(filepath.Dir(s.Path), 0o755)
+
+	comments := make(map[model.CommentID]model.Comment)
+	data, err := os.ReadFile(s.CommentsPath())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if len(data) > 0 {
+		var items []model.Comment
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		for _, c := range items {
+			comments[c.ID] = c
+		}
+	}
+	s.comments = comments
+	return nil
+}
+
+func sortComments(items []model.Comment) {
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+
+	"todo/internal/model"
+)
+
+// JSONProjectStore — проекты в отдельном JSON-файле, устроен как JSONUserStore
+type JSONProjectStore struct {
+	Path string
+
+	mu     sync.Mutex
+	items  map[model.ProjectID]model.Project
+	loaded bool
+}
+
+func NewJSONProjectStore(path string) *JSONProjectStore {
+	return &JSONProjectStore{Path: path}
+}
+
+func (s *JSONProjectStore) GetProject(ctx context.Context, id model.ProjectID) (model.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ready(ctx); err != nil {
+		return model.Project{}, err
+	}
+	p, ok := s.items[id]
+	if !ok {
+		return model.Project{}, ErrNotFound
+	}
+	return p, nil
+}
+
+func (s *JSONProjectStore) ListProjects(ctx context.Context) ([]model.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ready(ctx); err != nil {
+		return nil, err
+	}
+	return s.sorted(), nil
+}
+
+// InsertProject выдаёт следующий свободный ID
+func (s *JSONProjectStore) InsertProject(ctx context.Context, p model.Project) (model.ProjectID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ready(ctx); err != nil {
+		return 0, err
+	}
+	var maxID model.ProjectID
+	for _, x := range s.items {
+		if x.Name == p.Name {
+			return 0, ErrDuplicate
+		}
+		maxID = max(maxID, x.ID)
+	}
+	p.ID = maxID + 1
+	s.items[p.ID] = p
+	if err := s.flush(); err != nil {
+		delete(s.items, p.ID)
+		return 0, err
+	}
+	return p.ID, nil
+}
+
+func (s *JSONProjectStore) UpdateProject(ctx context.Context, p model.Project) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ready(ctx); err != nil {
+		return err
+	}
+	prev, ok := s.items[p.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	for _, x := range s.items {
+		if x.ID != p.ID && x.Name == p.Name {
+			return ErrDuplicate
+		}
+	}
+	p.CreatedBy, p.CreatedAt = prev.CreatedBy, prev.CreatedAt
+	p.Members = slices.Clone(p.Members)
+	s.items[p.ID] = p
+	if err := s.flush(); err != nil {
+		s.items[p.ID] = prev
+		return err
+	}
+	return nil
+}
+
+func (s *JSONProjectStore) ready(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if s.loaded {
+		return nil
+	}
+	if s.Path == "" {
+		return errors.New("empty store path")
+	}
+	_ = os.MkdirAll(filepath.Dir(s.Path), 0o755)
+
+	s.items = make(map[model.ProjectID]model.Project)
+	data, err := os.ReadFile(s.Path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if len(data) > 0 {
+		var items []model.Project
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		for _, p := range items {
+			s.items[p.ID] = p
+		}
+	}
+	s.loaded = true
+	return nil
+}
+
+func (s *JSONProjectStore) sorted() []model.Project {
+	items := make([]model.Project, 0, len(s.items))
+	for _, p := range s.items {
+		items = append(items, p)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	return items
+}
+
+// flush — как у JSONStore: через временный файл
+func (s *JSONProjectStore) flush() error {
+	raw, err := json.MarshalIndent(s.sorted(), "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path)
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"todo/internal/model"
+)
+
+// Перенумерация JSONStore меняет четыре файла: задачи, архив, комментарии и вложения.
+// Переименование одного файла атомарно, четырёх — нет, поэтому сначала пишем журнал:
+// новое содержимое всех четырёх одним документом tasks_renumber.json. Записанный журнал
+// и есть коммит: дальше раскладываем его по файлам и удаляем. Оборвались посередине —
+// журнал доиграет следующая загрузка (settleRenumber), до него store ничего не читает.
+
+// renumberJournal — всё, что меняет Renumber, целиком
+type renumberJournal struct {
+	Tasks       []model.TaskDTO    `json:"tasks"`
+	Archive     []model.TaskDTO    `json:"archive"`
+	Comments    []model.Comment    `json:"comments"`
+	Attachments []attachmentRecord `json:"attachments"`
+}
+
+// RenumberPath — файл журнала перенумерации
+func (s *JSONStore) RenumberPath() string {
+	ext := filepath.Ext(s.Path)
+	return strings.TrimSuffix(s.Path, ext) + "_renumber" + ext
+}
+
+// Renumber — все четыре файла на новые номера через журнал
+func (s *JSONStore) Renumber(ctx context.Context, remap map[model.ID]model.ID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ready(ctx); err != nil {
+		return err
+	}
+	if err := s.archiveReady(); err != nil {
+		return err
+	}
+	if err := s.commentsReady(ctx); err != nil {
+		return err
+	}
+	if err := s.attachmentsReady(ctx); err != nil {
+		return err
+	}
+
+	items, err := renumberAll(s.items, remap)
+	if err != nil {
+		return err
+	}
+	archive, err := renumberAll(s.archive, remap)
+	if err != nil {
+		return err
+	}
+	for id := range archive {
+		if _, ok := items[id]; ok {
+			return fmt.Errorf("%w: task %d", ErrDuplicate, id)
+		}
+	}
+	moved := movedIDs(remap)
+	comments := maps.Clone(s.comments)
+	for id, c := range comments {
+		if to, ok := moved[c.TaskID]; ok {
+			c.TaskID = to
+			comments[id] = c
+		}
+	}
+	attachments := maps.Clone(s.attachments)
+	for id, a := range attachments {
+		if to, ok := moved[a.TaskID]; ok {
+			a.TaskID = to
+			attachments[id] = a
+		}
+	}
+
+	j := journalOf(items, archive, comments, attachments)
+	if err := writeJSONFile(s.RenumberPath(), j); err != nil {
+		return err
+	}
+	s.items, s.archive, s.comments, s.attachments = items, archive, comments, attachments
+	s.index, s.archiveIndex = NewTextIndex(), NewTextIndex()
+	for _, t := range items {
+		s.index.Put(t)
+	}
+	for _, t := range archive {
+		s.archiveIndex.Put(t)
+	}
+	// коммит уже на диске: не разложится сейчас — доразложит следующее обращение
+	s.journal = &j
+	_ = s.settleRenumber()
+	return nil
+}
+
+func journalOf(items, archive map[model.ID]model.TaskDTO, comments map[model.CommentID]model.Comment,
+	attachments map[model.AttachmentID]model.Attachment) renumberJournal {
+	j := renumberJournal{
+		Tasks:       make([]model.TaskDTO, 0, len(items)),
+		Archive:     make([]model.TaskDTO, 0, len(archive)),
+		Comments:    make([]model.Comment, 0, len(comments)),
+		Attachments: make([]attachmentRecord, 0, len(attachments)),
+	}
+	for _, t := range items {
+		j.Tasks = append(j.Tasks, t)
+	}
+	sortByCreated(j.Tasks)
+	for _, t := range archive {
+		j.Archive = append(j.Archive, t)
+	}
+	sortByCreated(j.Archive)
+	for _, c := range comments {
+		j.Comments = append(j.Comments, c)
+	}
+	sortComments(j.Comments)
+	list := make([]model.Attachment, 0, len(attachments))
+	for _, a := range attachments {
+		list = append(list, a)
+	}
+	sortAttachments(list)
+	for _, a := range list {
+		j.Attachments = append(j.Attachments, attachmentRecord(a))
+	}
+	return j
+}
+
+// settleRenumber — раскладывает журнал по файлам и удаляет его. При первом обращении
+// к store журнал ищем на диске (прошлый запуск оборвался после коммита), дальше
+// остаётся только свой, не разложенный из-за ошибки. Вызывать под s.mu
+// до чтения любого из файлов
+func (s *JSONStore) settleRenumber() error {
+	if !s.journalChecked {
+		if s.Path == "" {
+			return errors.New("empty store path")
+		}
+		data, err := os.ReadFile(s.RenumberPath())
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			return err
+		default:
+			var j renumberJournal
+			if err := json.Unmarshal(data, &j); err != nil {
+				return fmt.Errorf("renumber journal: %w", err)
+			}
+			s.journal = &j
+		}
+		s.journalChecked = true
+	}
+	if s.journal == nil {
+		return nil
+	}
+	j := s.journal
+	for _, f := range []struct {
+		path string
+		v    any
+	}{
+		{s.Path, j.Tasks},
+		{s.ArchivePath(), j.Archive},
+		{s.CommentsPath(), j.Comments},
+		{s.AttachmentsPath(), j.Attachments},
+	} {
+		if err := writeJSONFile(f.path, f.v); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(s.RenumberPath()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	s.journal = nil
+	return nil
+}
+
+// writeJSONFile — файл целиком через временный, как flush
+func writeJSONFile(path string, v any) error {
+	raw, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path+".tmp", raw, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// renumberAll — копия items на номерах remap; номер, доставшийся двоим, — ErrDuplicate
+func renumberAll(items map[model.ID]model.TaskDTO, remap map[model.ID]model.ID) (map[model.ID]model.TaskDTO, error) {
+	next := make(map[model.ID]model.TaskDTO, len(items))
+	for _, t := range items {
+		t = renumberRecord(t, remap)
+		if _, ok := next[t.ID]; ok {
+			return nil, fmt.Errorf("%w: task %d", ErrDuplicate, t.ID)
+		}
+		next[t.ID] = t
+	}
+	return next, nil
+}
@@ -1,18 +1,39 @@
 package repository
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 
 	"todo/internal/model"
 )
 
 // JSONStore — файловое JSON‑хранилище для задач.
+// Записи держим в памяти и после каждого изменения сбрасываем файл целиком
+// (по-другому с одним JSON-документом не выйдет), но читаем его только один раз.
 type JSONStore struct {
 	Path string
+
+	mu     sync.Mutex
+	items  map[model.ID]model.TaskDTO
+	index  *TextIndex // полнотекстовый индекс, живёт вместе с items
+	loaded bool
+
+	comments    map[model.CommentID]model.Comment       // из отдельного файла, см. jsoncomments.go
+	attachments map[model.AttachmentID]model.Attachment // и вложения тоже, см. jsonattachments.go
+
+	archive      map[model.ID]model.TaskDTO // закрытые задачи из своего файла, см. jsonarchive.go
+	archiveIndex *TextIndex
+
+	journal        *renumberJournal // перенумерация, ещё не разложенная по файлам, см. jsonrenumber.go
+	journalChecked bool
 }
 
 // NewJSONStore создаёт новое хранилище по указанному пути.
@@ -20,38 +41,177 @@ func NewJSONStore(path string) *JSONStore {
 	return &JSONStore{Path: path}
 }
 
-// Load загружает DTO‑представления задач из JSON‑файла.
-func (s *JSONStore) Load() ([]model.TaskDTO, error) {
-	if s.Path == "" {
-		return nil, errors.New("empty store path")
+// JSONProjectPath — файл задач проекта: у общего это сам path,
+// у остальных — рядом с ним, tasks_<id>.json
+func JSONProjectPath(path string, p model.ProjectID) string {
+	if p == model.DefaultProject {
+		return path
 	}
-	_ = os.MkdirAll(filepath.Dir(s.Path), 0o755)
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s_%d%s", strings.TrimSuffix(path, ext), p, ext)
+}
 
-	data, err := os.ReadFile(s.Path)
-	if err != nil {
-		if errors.Is(err, fs.ErrNotExist) {
-			return []model.TaskDTO{}, nil
-		}
+// JSONTenantPath — файл арендатора: у общего это сам path,
+// у остальных — в своём каталоге рядом, tenants/<t>/<имя файла>
+func JSONTenantPath(path string, t model.TenantID) string {
+	if t == model.DefaultTenant {
+		return path
+	}
+	return filepath.Join(filepath.Dir(path), "tenants", string(t), filepath.Base(path))
+}
+
+// Get возвращает одну задачу по ID.
+func (s *JSONStore) Get(ctx context.Context, id model.ID) (model.TaskDTO, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ready(ctx); err != nil {
+		return model.TaskDTO{}, err
+	}
+	t, ok := s.items[id]
+	if !ok {
+		return model.TaskDTO{}, ErrNotFound
+	}
+	return t, nil
+}
+
+// Insert добавляет новую задачу, ID не должен повторяться.
+func (s *JSONStore) Insert(ctx context.Context, t model.TaskDTO) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ready(ctx); err != nil {
+		return err
+	}
+	if _, ok := s.items[t.ID]; ok {
+		return fmt.Errorf("task %d already exists", t.ID)
+	}
+	s.items[t.ID] = t
+	if err := s.flush(); err != nil {
+		delete(s.items, t.ID)
+		return err
+	}
+	s.index.Put(t)
+	return nil
+}
+
+// Update перезаписывает существующую задачу, если в файле всё ещё версия version.
+func (s *JSONStore) Update(ctx context.Context, t model.TaskDTO, version int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ready(ctx); err != nil {
+		return err
+	}
+	prev, ok := s.items[t.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if prev.Version != version {
+		return ErrConflict
+	}
+	s.items[t.ID] = t
+	if err := s.flush(); err != nil {
+		s.items[t.ID] = prev
+		return err
+	}
+	s.index.Put(t)
+	return nil
+}
+
+// Delete удаляет задачу по ID.
+func (s *JSONStore) Delete(ctx context.Context, id model.ID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ready(ctx); err != nil {
+		return err
+	}
+	prev, ok := s.items[id]
+	if !ok {
+		return ErrNotFound
+	}
+	delete(s.items, id)
+	if err := s.flush(); err != nil {
+		s.items[id] = prev
+		return err
+	}
+	s.index.Remove(id)
+	return nil
+}
+
+// Query выполняет запрос в памяти: фильтр, сортировка, курсор и лимит.
+func (s *JSONStore) Query(ctx context.Context, q model.TaskQuery) ([]model.TaskDTO, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ready(ctx); err != nil {
 		return nil, err
 	}
-	if len(data) == 0 {
-		return []model.TaskDTO{}, nil
+	if q.Archived {
+		return s.queryArchive(q)
 	}
+	items := make([]model.TaskDTO, 0, len(s.items))
+	for _, t := range s.items {
+		items = append(items, t)
+	}
+	return q.Apply(items), nil
+}
 
-	var items []model.TaskDTO
-	if err := json.Unmarshal(data, &items); err != nil {
+// Search ищет по заголовкам и описаниям через инвертированный индекс в памяти.
+func (s *JSONStore) Search(ctx context.Context, q model.SearchQuery) ([]model.SearchHit, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ready(ctx); err != nil {
 		return nil, err
 	}
-	return items, nil
+	return s.index.Search(q), nil
 }
 
-// Save сохраняет DTO‑представления задач в JSON‑файл.
-func (s *JSONStore) Save(items []model.TaskDTO) error {
+// ready — проверяет, не отменён ли запрос, и подгружает файл при первом обращении
+func (s *JSONStore) ready(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.ensureLoaded()
+}
+
+// ensureLoaded — один раз вычитывает файл в память
+func (s *JSONStore) ensureLoaded() error {
+	if err := s.settleRenumber(); err != nil {
+		return err
+	}
+	if s.loaded {
+		return nil
+	}
 	if s.Path == "" {
 		return errors.New("empty store path")
 	}
 	_ = os.MkdirAll(filepath.Dir(s.Path), 0o755)
 
+	s.items = make(map[model.ID]model.TaskDTO)
+	s.index = NewTextIndex()
+	data, err := os.ReadFile(s.Path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if len(data) > 0 {
+		var items []model.TaskDTO
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		for _, t := range items {
+			s.items[t.ID] = t
+			s.index.Put(t)
+		}
+	}
+	s.loaded = true
+	return nil
+}
+
+// flush сохраняет все задачи в JSON‑файл через временный файл.
+func (s *JSONStore) flush() error {
+	items := make([]model.TaskDTO, 0, len(s.items))
+	for _, t := range s.items {
+		items = append(items, t)
+	}
+	sortByCreated(items)
+
 	raw, err := json.MarshalIndent(items, "", "  ")
 	if err != nil {
 		return err
@@ -62,4 +222,10 @@ func (s *JSONStore) Save(items []model.TaskDTO) error {
 		return err
 	}
 	return os.Rename(tmp, s.Path)
-}
\ No newline at end of file
+}
+
+func sortByCreated(items []model.TaskDTO) {
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+}
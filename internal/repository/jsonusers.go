@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"todo/internal/model"
+)
+
+// JSONUserStore — пользователи в отдельном JSON-файле рядом с задачами.
+// Устроен как JSONStore: всё в памяти, файл перезаписывается целиком.
+type JSONUserStore struct {
+	Path string
+
+	mu     sync.Mutex
+	items  map[model.UserID]model.User
+	loaded bool
+}
+
+func NewJSONUserStore(path string) *JSONUserStore {
+	return &JSONUserStore{Path: path}
+}
+
+func (s *JSONUserStore) Get(ctx context.Context, id model.UserID) (model.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ready(ctx); err != nil {
+		return model.User{}, err
+	}
+	u, ok := s.items[id]
+	if !ok {
+		return model.User{}, ErrNotFound
+	}
+	return u, nil
+}
+
+func (s *JSONUserStore) GetByLogin(ctx context.Context, login string) (model.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ready(ctx); err != nil {
+		return model.User{}, err
+	}
+	for _, u := range s.items {
+		if u.Login == login {
+			return u, nil
+		}
+	}
+	return model.User{}, ErrNotFound
+}
+
+// Insert выдаёт следующий свободный ID
+func (s *JSONUserStore) Insert(ctx context.Context, u model.User) (model.UserID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ready(ctx); err != nil {
+		return 0, err
+	}
+	var maxID model.UserID
+	for _, x := range s.items {
+		if x.Login == u.Login {
+			return 0, ErrDuplicate
+		}
+		maxID = max(maxID, x.ID)
+	}
+	u.ID = maxID + 1
+	s.items[u.ID] = u
+	if err := s.flush(); err != nil {
+		delete(s.items, u.ID)
+		return 0, err
+	}
+	return u.ID, nil
+}
+
+func (s *JSONUserStore) Update(ctx context.Context, u model.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ready(ctx); err != nil {
+		return err
+	}
+	prev, ok := s.items[u.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	for _, x := range s.items {
+		if x.ID != u.ID && x.Login == u.Login {
+			return ErrDuplicate
+		}
+	}
+	s.items[u.ID] = u
+	if err := s.flush(); err != nil {
+		s.items[u.ID] = prev
+		return err
+	}
+	return nil
+}
+
+func (s *JSONUserStore) Count(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ready(ctx); err != nil {
+		return 0, err
+	}
+	return len(s.items), nil
+}
+
+// Tenants — арендаторы, у которых есть пользователи
+func (s *JSONUserStore) Tenants(ctx context.Context) ([]model.TenantID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ready(ctx); err != nil {
+		return nil, err
+	}
+	seen := make(map[model.TenantID]bool)
+	var out []model.TenantID
+	for _, u := range s.items {
+		if t := u.Tenant.OrDefault(); !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out, nil
+}
+
+func (s *JSONUserStore) ready(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if s.loaded {
+		return nil
+	}
+	if s.Path == "" {
+		return errors.New("empty store path")
+	}
+	_ = os.MkdirAll(filepath.Dir(s.Path), 0o755)
+
+	s.items = make(map[model.UserID]model.User)
+	data, err := os.ReadFile(s.Path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if len(data) > 0 {
+		// admin — из файлов до ролей
+		var items []struct {
+			model.User
+			Admin bool `json:"admin"`
+		}
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		for _, it := range items {
+			it.User.Role = legacyRole(it.Role, it.Admin)
+			it.User.Tenant = it.Tenant.OrDefault()
+			s.items[it.ID] = it.User
+		}
+	}
+	s.loaded = true
+	return nil
+}
+
+// legacyRole — роль для записей, сохранённых до ролей: был admin — admin, иначе member
+func legacyRole(role model.Role, admin bool) model.Role {
+	switch {
+	case role != "":
+		return role
+	case admin:
+		return model.RoleAdmin
+	default:
+		return model.RoleMember
+	}
+}
+
+// flush — как у JSONStore: через временный файл; 0600 — в файле хеши паролей
+func (s *JSONUserStore) flush() error {
+	items := make([]model.User, 0, len(s.items))
+	for _, u := range s.items {
+		items = append(items, u)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+
+	raw, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path)
+}
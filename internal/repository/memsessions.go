@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"todo/internal/model"
+)
+
+// MemorySessionStore — сессии в памяти процесса, для JSON-режима и тестов.
+// После перезапуска все входят заново: refresh-токены не переживают рестарт.
+type MemorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]model.Session
+	denied   map[string]time.Time
+}
+
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: make(map[string]model.Session), denied: make(map[string]time.Time)}
+}
+
+func (s *MemorySessionStore) CreateSession(ctx context.Context, sess model.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.sessions[sess.ID]; ok {
+		return ErrDuplicate
+	}
+	s.sessions[sess.ID] = sess
+	return nil
+}
+
+func (s *MemorySessionStore) GetSession(ctx context.Context, id string) (model.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		return model.Session{}, ErrNotFound
+	}
+	return sess, nil
+}
+
+func (s *MemorySessionStore) RotateSession(ctx context.Context, id, oldHash, newHash string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[id]
+	if !ok || sess.RefreshHash != oldHash {
+		return ErrNotFound
+	}
+	sess.RefreshHash, sess.ExpiresAt = newHash, expiresAt
+	s.sessions[id] = sess
+	return nil
+}
+
+func (s *MemorySessionStore) DeleteSession(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.sessions[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.sessions, id)
+	return nil
+}
+
+func (s *MemorySessionStore) DenyToken(ctx context.Context, jti string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// заодно выбрасываем истёкшие, чтобы список не рос бесконечно
+	now := time.Now()
+	for k, t := range s.denied {
+		if now.After(t) {
+			delete(s.denied, k)
+		}
+	}
+	s.denied[jti] = until
+	return nil
+}
+
+func (s *MemorySessionStore) TokenDenied(ctx context.Context, jti string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	until, ok := s.denied[jti]
+	return ok && time.Now().Before(until), nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"todo/internal/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoAPIKeyStore — коллекция api_keys рядом с задачами, ID ключа лежит в _id
+type MongoAPIKeyStore struct {
+	store *MongoStore
+}
+
+// APIKeys — хранилище ключей API в той же базе
+func (s *MongoStore) APIKeys() *MongoAPIKeyStore {
+	return &MongoAPIKeyStore{store: s}
+}
+
+func (s *MongoAPIKeyStore) keys() *mongo.Collection {
+	return s.store.client.Database(s.store.db).Collection("api_keys")
+}
+
+// apiKeyDoc — ключ с bson-тегами
+type apiKeyDoc struct {
+	ID         string       `bson:"_id"`
+	Name       string       `bson:"name"`
+	UserID     model.UserID `bson:"user_id"`
+	Scopes     []string     `bson:"scopes"`
+	Hash       string       `bson:"key_hash"`
+	CreatedAt  time.Time    `bson:"created_at"`
+	ExpiresAt  *time.Time   `bson:"expires_at,omitempty"`
+	LastUsedAt *time.Time   `bson:"last_used_at,omitempty"`
+	RevokedAt  *time.Time   `bson:"revoked_at,omitempty"`
+}
+
+func (d apiKeyDoc) toKey() model.APIKey {
+	return model.APIKey(d)
+}
+
+func (s *MongoAPIKeyStore) CreateKey(ctx context.Context, k model.APIKey) error {
+	ctx, cancel := s.store.withTimeout(ctx)
+	defer cancel()
+
+	_, err := s.keys().InsertOne(ctx, apiKeyDoc(k))
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (s *MongoAPIKeyStore) GetKey(ctx context.Context, id string) (model.APIKey, error) {
+	ctx, cancel := s.store.withTimeout(ctx)
+	defer cancel()
+
+	var d apiKeyDoc
+	err := s.keys().FindOne(ctx, bson.M{"_id": id}).Decode(&d)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.APIKey{}, ErrNotFound
+	}
+	return d.toKey(), err
+}
+
+func (s *MongoAPIKeyStore) ListKeys(ctx context.Context) ([]model.APIKey, error) {
+	ctx, cancel := s.store.withTimeout(ctx)
+	defer cancel()
+
+	cur, err := s.keys().Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var out []model.APIKey
+	for cur.Next(ctx) {
+		var d apiKeyDoc
+		if err := cur.Decode(&d); err != nil {
+			return nil, err
+		}
+		out = append(out, d.toKey())
+	}
+	return out, cur.Err()
+}
+
+// RevokeKey — повторный отзыв не сдвигает время первого
+func (s *MongoAPIKeyStore) RevokeKey(ctx context.Context, id string, at time.Time) error {
+	ctx, cancel := s.store.withTimeout(ctx)
+	defer cancel()
+
+	res, err := s.keys().UpdateOne(ctx, bson.M{"_id": id}, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"revoked_at": bson.M{"$ifNull": bson.A{"$revoked_at", at}}}}},
+	})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *MongoAPIKeyStore) TouchKey(ctx context.Context, id string, at time.Time) error {
+	ctx, cancel := s.store.withTimeout(ctx)
+	defer cancel()
+
+	res, err := s.keys().UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"last_used_at": at}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
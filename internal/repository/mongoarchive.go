@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"todo/internal/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Архив MongoStore — своя коллекция рядом с задачами: tasks — tasks_archive.
+// Документы те же, что у горячих, плюс archived_at. Транзакций нет (нужен replica set),
+// поэтому переезд идёт в два шага, а неудачный второй шаг откатываем руками.
+
+func (s *MongoStore) archive() *mongo.Collection {
+	return s.client.Database(s.db).Collection(s.coll + "_archive")
+}
+
+// индексы архива — как у горячих, имена свои
+var (
+	archiveTextIndexModel = mongo.IndexModel{
+		Keys: textIndexModel.Keys,
+		Options: options.Index().
+			SetName("tasks_archive_text").
+			SetWeights(bson.D{{Key: "title", Value: titleWeight}, {Key: "description", Value: 1}}).
+			SetDefaultLanguage("none"),
+	}
+	archiveTagIndexModel = mongo.IndexModel{
+		Keys:    tagIndexModel.Keys,
+		Options: options.Index().SetName("tasks_archive_tags"),
+	}
+)
+
+// Archive — копируем в архив, затем удаляем горячие документы в ожидаемых версиях.
+// Если какой-то успели изменить, возвращаем всё как было и отдаём ErrConflict
+func (s *MongoStore) Archive(ctx context.Context, tasks []model.TaskDTO) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	docs := make([]any, 0, len(tasks))
+	ids := make([]model.ID, 0, len(tasks))
+	match := bson.A{}
+	for _, t := range tasks {
+		docs = append(docs, dtoToDoc(t))
+		ids = append(ids, t.ID)
+		match = append(match, versionFilter(t.ID, t.Version))
+	}
+	// хвосты прошлой попытки, оборвавшейся посередине
+	if _, err := s.archive().DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+		return err
+	}
+	if _, err := s.archive().InsertMany(ctx, docs); err != nil {
+		return err
+	}
+	res, err := s.collection().DeleteMany(ctx, bson.M{"$or": match})
+	if err == nil && res.DeletedCount == int64(len(tasks)) {
+		return nil
+	}
+	if err == nil {
+		err = ErrConflict
+	}
+	// откат: удалённое возвращаем к горячим, архив чистим
+	for _, t := range tasks {
+		n, cerr := s.collection().CountDocuments(ctx, bson.M{"_id": t.ID})
+		if cerr == nil && n == 0 {
+			t.ArchivedAt = nil
+			_, _ = s.collection().InsertOne(ctx, dtoToDoc(t))
+		}
+	}
+	_, _ = s.archive().DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	return err
+}
+
+// Unarchive — забираем документ из архива и кладём к горячим; не вышло — кладём обратно
+func (s *MongoStore) Unarchive(ctx context.Context, id model.ID) (model.TaskDTO, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var d taskDoc
+	err := s.archive().FindOneAndDelete(ctx, bson.M{"_id": id}).Decode(&d)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.TaskDTO{}, ErrNotFound
+	}
+	if err != nil {
+		return model.TaskDTO{}, err
+	}
+	back := d
+	back.ArchivedAt = nil
+	if _, err := s.collection().InsertOne(ctx, back); err != nil {
+		_, _ = s.archive().InsertOne(ctx, d)
+		if mongo.IsDuplicateKeyError(err) {
+			return model.TaskDTO{}, ErrConflict
+		}
+		return model.TaskDTO{}, err
+	}
+	return back.toDTO(), nil
+}
+
+func (s *MongoStore) GetArchived(ctx context.Context, id model.ID) (model.TaskDTO, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var d taskDoc
+	err := s.archive().FindOne(ctx, bson.M{"_id": id}).Decode(&d)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.TaskDTO{}, ErrNotFound
+	}
+	if err != nil {
+		return model.TaskDTO{}, err
+	}
+	return d.toDTO(), nil
+}
+
+func (s *MongoStore) SearchArchive(ctx context.Context, q model.SearchQuery) ([]model.SearchHit, error) {
+	return s.searchIn(ctx, s.archive(), archiveTextIndexModel, q)
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"todo/internal/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Вложения MongoStore — коллекция <coll>_attachments, устроена как <coll>_comments.
+// Содержимое файлов — в BlobStore, GridFS не используем.
+
+var attachmentTaskIndex = mongo.IndexModel{
+	Keys:    bson.D{{Key: "task_id", Value: 1}, {Key: "_id", Value: 1}},
+	Options: options.Index().SetName("attachments_task"),
+}
+
+func (s *MongoStore) attachments() *mongo.Collection {
+	return s.client.Database(s.db).Collection(s.coll + "_attachments")
+}
+
+// attachmentDoc — описание вложения с bson-тегами, ID лежит в _id
+type attachmentDoc struct {
+	ID          model.AttachmentID `bson:"_id"`
+	TaskID      model.ID           `bson:"task_id"`
+	Name        string             `bson:"name"`
+	Size        int64              `bson:"size"`
+	ContentType string             `bson:"content_type"`
+	Checksum    string             `bson:"checksum"`
+	BlobKey     string             `bson:"blob_key"`
+	UploadedBy  model.UserID       `bson:"uploaded_by,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at"`
+}
+
+func (s *MongoStore) ListAttachments(ctx context.Context, task model.ID) ([]model.Attachment, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	return s.findAttachments(ctx, bson.M{"task_id": task})
+}
+
+func (s *MongoStore) GetAttachment(ctx context.Context, id model.AttachmentID) (model.Attachment, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var d attachmentDoc
+	err := s.attachments().FindOne(ctx, bson.M{"_id": id}).Decode(&d)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.Attachment{}, ErrNotFound
+	}
+	return model.Attachment(d), err
+}
+
+func (s *MongoStore) InsertAttachment(ctx context.Context, a model.Attachment) (model.AttachmentID, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if err := s.ensureIndexOn(ctx, s.attachments(), attachmentTaskIndex); err != nil {
+		return 0, err
+	}
+	var counter struct {
+		Seq model.AttachmentID `bson:"seq"`
+	}
+	err := s.client.Database(s.db).Collection("counters").FindOneAndUpdate(ctx,
+		bson.M{"_id": s.coll + "_attachments"},
+		bson.M{"$inc": bson.M{"seq": 1}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counter)
+	if err != nil {
+		return 0, err
+	}
+	a.ID = counter.Seq
+	if _, err := s.attachments().InsertOne(ctx, attachmentDoc(a)); err != nil {
+		return 0, err
+	}
+	return a.ID, nil
+}
+
+func (s *MongoStore) DeleteAttachment(ctx context.Context, id model.AttachmentID) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	res, err := s.attachments().DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteTaskAttachments — сначала читаем, что удаляем: ключи файлов нужны сервису
+func (s *MongoStore) DeleteTaskAttachments(ctx context.Context, task model.ID) ([]model.Attachment, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	gone, err := s.findAttachments(ctx, bson.M{"task_id": task})
+	if err != nil || len(gone) == 0 {
+		return nil, err
+	}
+	ids := make([]model.AttachmentID, len(gone))
+	for i, a := range gone {
+		ids[i] = a.ID
+	}
+	if _, err := s.attachments().DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+		return nil, err
+	}
+	return gone, nil
+}
+
+func (s *MongoStore) findAttachments(ctx context.Context, filter bson.M) ([]model.Attachment, error) {
+	cur, err := s.attachments().Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var out []model.Attachment
+	for cur.Next(ctx) {
+		var d attachmentDoc
+		if err := cur.Decode(&d); err != nil {
+			return nil, err
+		}
+		out = append(out, model.Attachment(d))
+	}
+	return out, cur.Err()
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"todo/internal/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Комментарии MongoStore — коллекция <coll>_comments рядом с задачами проекта,
+// ID выдаём счётчиком из counters, как пользователям и проектам.
+
+var commentTaskIndex = mongo.IndexModel{
+	Keys:    bson.D{{Key: "task_id", Value: 1}, {Key: "_id", Value: 1}},
+	Options: options.Index().SetName("comments_task"),
+}
+
+func (s *MongoStore) comments() *mongo.Collection {
+	return s.client.Database(s.db).Collection(s.coll + "_comments")
+}
+
+// commentDoc — комментарий с bson-тегами, ID лежит в _id
+type commentDoc struct {
+	ID        model.CommentID `bson:"_id"`
+	TaskID    model.ID        `bson:"task_id"`
+	Author    model.UserID    `bson:"author,omitempty"`
+	Body      string          `bson:"body"`
+	CreatedAt time.Time       `bson:"created_at"`
+	EditedAt  *time.Time      `bson:"edited_at,omitempty"`
+}
+
+func (s *MongoStore) ListComments(ctx context.Context, task model.ID) ([]model.Comment, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	cur, err := s.comments().Find(ctx, bson.M{"task_id": task}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var out []model.Comment
+	for cur.Next(ctx) {
+		var d commentDoc
+		if err := cur.Decode(&d); err != nil {
+			return nil, err
+		}
+		out = append(out, model.Comment(d))
+	}
+	return out, cur.Err()
+}
+
+func (s *MongoStore) GetComment(ctx context.Context, id model.CommentID) (model.Comment, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var d commentDoc
+	err := s.comments().FindOne(ctx, bson.M{"_id": id}).Decode(&d)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.Comment{}, ErrNotFound
+	}
+	return model.Comment(d), err
+}
+
+func (s *MongoStore) InsertComment(ctx context.Context, c model.Comment) (model.CommentID, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if err := s.ensureIndexOn(ctx, s.comments(), commentTaskIndex); err != nil {
+		return 0, err
+	}
+	var counter struct {
+		Seq model.CommentID `bson:"seq"`
+	}
+	err := s.client.Database(s.db).Collection("counters").FindOneAndUpdate(ctx,
+		bson.M{"_id": s.coll + "_comments"},
+		bson.M{"$inc": bson.M{"seq": 1}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counter)
+	if err != nil {
+		return 0, err
+	}
+	c.ID = counter.Seq
+	if _, err := s.comments().InsertOne(ctx, commentDoc(c)); err != nil {
+		return 0, err
+	}
+	return c.ID, nil
+}
+
+func (s *MongoStore) UpdateComment(ctx context.Context, c model.Comment) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	res, err := s.comments().UpdateOne(ctx, bson.M{"_id": c.ID},
+		bson.M{"$set": bson.M{"body": c.Body, "edited_at": c.EditedAt}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *MongoStore) DeleteComment(ctx context.Context, id model.CommentID) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	res, err := s.comments().DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *MongoStore) DeleteTaskComments(ctx context.Context, task model.ID) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	_, err := s.comments().DeleteMany(ctx, bson.M{"task_id": task})
+	return err
+}
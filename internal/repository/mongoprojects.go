@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"todo/internal/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoProjectStore — коллекция projects, участники лежат массивом в документе.
+// ID выдаём счётчиком из counters, как и пользователям.
+type MongoProjectStore struct {
+	store *MongoStore
+}
+
+// Projects — хранилище проектов в той же базе
+func (s *MongoStore) Projects() *MongoProjectStore {
+	return &MongoProjectStore{store: s}
+}
+
+var projectNameIndex = mongo.IndexModel{
+	Keys:    bson.D{{Key: "name", Value: 1}},
+	Options: options.Index().SetName("projects_name").SetUnique(true),
+}
+
+func (s *MongoProjectStore) projects() *mongo.Collection {
+	return s.store.client.Database(s.store.db).Collection("projects")
+}
+
+// projectDoc — проект с bson-тегами, ID лежит в _id
+type projectDoc struct {
+	ID        model.ProjectID `bson:"_id"`
+	Name      string          `bson:"name"`
+	Members   []model.UserID  `bson:"members"`
+	CreatedBy model.UserID    `bson:"created_by,omitempty"`
+	CreatedAt time.Time       `bson:"created_at"`
+}
+
+func (s *MongoProjectStore) GetProject(ctx context.Context, id model.ProjectID) (model.Project, error) {
+	ctx, cancel := s.store.withTimeout(ctx)
+	defer cancel()
+
+	var d projectDoc
+	err := s.projects().FindOne(ctx, bson.M{"_id": id}).Decode(&d)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.Project{}, ErrNotFound
+	}
+	return model.Project(d), err
+}
+
+func (s *MongoProjectStore) ListProjects(ctx context.Context) ([]model.Project, error) {
+	ctx, cancel := s.store.withTimeout(ctx)
+	defer cancel()
+
+	cur, err := s.projects().Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var out []model.Project
+	for cur.Next(ctx) {
+		var d projectDoc
+		if err := cur.Decode(&d); err != nil {
+			return nil, err
+		}
+		out = append(out, model.Project(d))
+	}
+	return out, cur.Err()
+}
+
+func (s *MongoProjectStore) InsertProject(ctx context.Context, p model.Project) (model.ProjectID, error) {
+	ctx, cancel := s.store.withTimeout(ctx)
+	defer cancel()
+
+	if err := s.store.ensureIndexOn(ctx, s.projects(), projectNameIndex); err != nil {
+		return 0, err
+	}
+	var counter struct {
+		Seq model.ProjectID `bson:"seq"`
+	}
+	err := s.store.client.Database(s.store.db).Collection("counters").FindOneAndUpdate(ctx,
+		bson.M{"_id": "projects"},
+		bson.M{"$inc": bson.M{"seq": 1}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counter)
+	if err != nil {
+		return 0, err
+	}
+	p.ID = counter.Seq
+	if _, err := s.projects().InsertOne(ctx, projectDoc(p)); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return 0, ErrDuplicate
+		}
+		return 0, err
+	}
+	return p.ID, nil
+}
+
+func (s *MongoProjectStore) UpdateProject(ctx context.Context, p model.Project) error {
+	ctx, cancel := s.store.withTimeout(ctx)
+	defer cancel()
+
+	res, err := s.projects().UpdateOne(ctx, bson.M{"_id": p.ID},
+		bson.M{"$set": bson.M{"name": p.Name, "members": p.Members}})
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
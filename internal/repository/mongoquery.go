@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"todo/internal/model"
+)
+
+// поле, которое пайплайн добавляет для сортировки по сроку (null → DueSortMax)
+const dueSortField = "due_sort"
+
+// mongoField — имя поля документа для ключа сортировки
+func mongoField(f model.SortField) string {
+	switch f {
+	case model.SortDue:
+		return dueSortField
+	case model.SortID:
+		return "_id"
+	default:
+		return string(f)
+	}
+}
+
+// buildTaskFilter переводит фильтры TaskQuery в BSON
+func buildTaskFilter(q model.TaskQuery) bson.D {
+	filter := bson.D{}
+	if len(q.Statuses) > 0 {
+		filter = append(filter, bson.E{Key: "status", Value: bson.M{"$in": q.Statuses}})
+	}
+	prio := bson.M{}
+	if q.MinPriority != 0 {
+		prio["$gte"] = q.MinPriority
+	}
+	if q.MaxPriority != 0 {
+		prio["$lte"] = q.MaxPriority
+	}
+	if len(prio) > 0 {
+		filter = append(filter, bson.E{Key: "priority", Value: prio})
+	}
+	due := bson.M{}
+	if q.DueAfter != nil {
+		due["$gte"] = *q.DueAfter
+	}
+	if q.DueBefore != nil {
+		due["$lt"] = *q.DueBefore
+	}
+	if len(due) > 0 {
+		filter = append(filter, bson.E{Key: "due_at", Value: due})
+	}
+	created := bson.M{}
+	if q.CreatedFrom != nil {
+		created["$gte"] = *q.CreatedFrom
+	}
+	if q.CreatedTo != nil {
+		created["$lt"] = *q.CreatedTo
+	}
+	if len(created) > 0 {
+		filter = append(filter, bson.E{Key: "created_at", Value: created})
+	}
+	if q.Text != "" {
+		re := bson.M{"$regex": regexp.QuoteMeta(q.Text), "$options": "i"}
+		filter = append(filter, bson.E{Key: "$or", Value: bson.A{
+			bson.M{"title": re},
+			bson.M{"description": re},
+		}})
+	}
+	tags := bson.M{}
+	if len(q.Tags) > 0 {
+		tags["$all"] = q.Tags
+	}
+	if len(q.AnyTags) > 0 {
+		tags["$in"] = q.AnyTags
+	}
+	if len(tags) > 0 {
+		filter = append(filter, bson.E{Key: "tags", Value: tags})
+	}
+	if q.CreatedBy != 0 {
+		filter = append(filter, bson.E{Key: "created_by", Value: q.CreatedBy})
+	}
+	if q.Assignee != 0 {
+		filter = append(filter, bson.E{Key: "assignee", Value: q.Assignee})
+	}
+	if q.VisibleTo != 0 {
+		// $or уже может быть занят текстовым фильтром, поэтому через $and
+		filter = append(filter, bson.E{Key: "$and", Value: bson.A{visibleFilter(q.VisibleTo)}})
+	}
+	if !q.WithDeleted {
+		// nil ловит и отсутствующее поле — документы до корзины
+		filter = append(filter, bson.E{Key: "deleted_at", Value: nil})
+	}
+	return filter
+}
+
+// visibleFilter — задачи, видные пользователю u: общие, свои и назначенные.
+// created_by: nil ловит и отсутствующее поле — общие задачи
+func visibleFilter(u model.UserID) bson.M {
+	return bson.M{"$or": bson.A{
+		bson.M{"created_by": nil},
+		bson.M{"created_by": u},
+		bson.M{"assignee": u},
+	}}
+}
+
+// buildTaskPipeline — фильтр, вычисляемое поле для сроков, курсор, сортировка и лимит
+func buildTaskPipeline(q model.TaskQuery) mongo.Pipeline {
+	keys := q.SortKeys()
+	pipe := mongo.Pipeline{
+		{{Key: "$match", Value: buildTaskFilter(q)}},
+		{{Key: "$addFields", Value: bson.M{
+			dueSortField: bson.M{"$ifNull": bson.A{"$due_at", model.DueSortMax}},
+		}}},
+	}
+	if q.After != nil {
+		pipe = append(pipe, bson.D{{Key: "$match", Value: keysetFilter(keys, *q.After)}})
+	}
+	sort := bson.D{}
+	for _, k := range keys {
+		dir := 1
+		if k.Desc {
+			dir = -1
+		}
+		sort = append(sort, bson.E{Key: mongoField(k.Field), Value: dir})
+	}
+	pipe = append(pipe, bson.D{{Key: "$sort", Value: sort}})
+	if q.Limit > 0 {
+		pipe = append(pipe, bson.D{{Key: "$limit", Value: q.Limit}})
+	}
+	return pipe
+}
+
+// keysetFilter — «строго после курсора», как keysetCondition для SQL
+func keysetFilter(keys []model.SortKey, c model.Cursor) bson.M {
+	ors := bson.A{}
+	for i, k := range keys {
+		and := bson.D{}
+		for _, prev := range keys[:i] {
+			and = append(and, bson.E{Key: mongoField(prev.Field), Value: c.Value(prev.Field)})
+		}
+		op := "$gt"
+		if k.Desc {
+			op = "$lt"
+		}
+		and = append(and, bson.E{Key: mongoField(k.Field), Value: bson.M{op: c.Value(k.Field)}})
+		ors = append(ors, and)
+	}
+	return bson.M{"$or": ors}
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+
+	"todo/internal/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Renumber — вся перенумерация проекта в одной транзакции: задачи с корзиной, архив,
+// а за ними комментарии и вложения. Транзакции MongoDB бывают только на replica set
+// (в docker-compose он из одного узла); на одиночном сервере отказывает первый же запрос,
+// и ничего не меняется
+func (s *MongoStore) Renumber(ctx context.Context, remap map[model.ID]model.ID) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	sess, err := s.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer sess.EndSession(ctx)
+	moved := movedIDs(remap)
+	_, err = sess.WithTransaction(ctx, func(sc mongo.SessionContext) (any, error) {
+		for _, coll := range []*mongo.Collection{s.collection(), s.archive()} {
+			if err := renumberDocs(sc, coll, remap); err != nil {
+				return nil, err
+			}
+		}
+		if len(moved) == 0 {
+			return nil, nil
+		}
+		for _, coll := range []*mongo.Collection{s.comments(), s.attachments()} {
+			if err := moveTaskIDs(sc, coll, moved); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+	return err
+}
+
+// renumberDocs — переписываем только документы coll, у которых что-то поменялось:
+// сначала удаляем старые, потом вставляем новые, чтобы цепочки 3→2, 2→1 не столкнулись
+func renumberDocs(ctx context.Context, coll *mongo.Collection, remap map[model.ID]model.ID) error {
+	cur, err := coll.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	var old []model.ID
+	var docs []any
+	for cur.Next(ctx) {
+		var d taskDoc
+		if err := cur.Decode(&d); err != nil {
+			cur.Close(ctx)
+			return err
+		}
+		r := d.toDTO()
+		n := renumberRecord(r, remap)
+		if renumbered(r, n) {
+			old = append(old, r.ID)
+			docs = append(docs, dtoToDoc(n))
+		}
+	}
+	cur.Close(ctx)
+	if err := cur.Err(); err != nil {
+		return err
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+	if _, err := coll.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": old}}); err != nil {
+		return err
+	}
+	_, err = coll.InsertMany(ctx, docs)
+	return err
+}
+
+// moveTaskIDs — комментарии или вложения coll за своими задачами. Сначала находим, что куда,
+// потом переписываем по _id: последовательные UpdateMany по task_id перепутали бы цепочки 3→2, 2→1
+func moveTaskIDs(ctx context.Context, coll *mongo.Collection, moved map[model.ID]model.ID) error {
+	olds := make([]model.ID, 0, len(moved))
+	for old := range moved {
+		olds = append(olds, old)
+	}
+	cur, err := coll.Find(ctx, bson.M{"task_id": bson.M{"$in": olds}},
+		options.Find().SetProjection(bson.M{"task_id": 1}))
+	if err != nil {
+		return err
+	}
+	var writes []mongo.WriteModel
+	for cur.Next(ctx) {
+		var d struct {
+			ID     any      `bson:"_id"`
+			TaskID model.ID `bson:"task_id"`
+		}
+		if err := cur.Decode(&d); err != nil {
+			cur.Close(ctx)
+			return err
+		}
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": d.ID}).
+			SetUpdate(bson.M{"$set": bson.M{"task_id": moved[d.TaskID]}}))
+	}
+	cur.Close(ctx)
+	if err := cur.Err(); err != nil || len(writes) == 0 {
+		return err
+	}
+	_, err = coll.BulkWrite(ctx, writes)
+	return err
+}
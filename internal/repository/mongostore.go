@@ -3,6 +3,10 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"todo/internal/model"
@@ -12,11 +16,17 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// MongoStore — задачи одного проекта: у общего коллекция coll, у остальных — coll_<id>
+// (см. Project), так что ID задач в проектах не пересекаются и индексы не общие.
+// У каждого арендатора своя база db_<tenant> (см. Tenant), у общего — сама db.
 type MongoStore struct {
 	client  *mongo.Client
 	db      string
 	coll    string
 	timeout time.Duration
+
+	indexMu sync.Mutex
+	indexed map[string]bool // какие индексы уже созданы, по имени
 }
 
 func NewMongoStore(uri, db, coll string) (*MongoStore, error) {
@@ -29,19 +39,64 @@ func NewMongoStore(uri, db, coll string) (*MongoStore, error) {
 		db:      db,
 		coll:    coll,
 		timeout: 5 * time.Second,
+		indexed: make(map[string]bool),
 	}, nil
 }
 
+// Tenant — общий проект арендатора t: его база на том же клиенте.
+// Пользователи и ключи API остаются в общей базе, их берут у исходного store.
+func (s *MongoStore) Tenant(t model.TenantID) *MongoStore {
+	db := s.db
+	if t != model.DefaultTenant {
+		db = s.db + "_" + string(t)
+	}
+	return &MongoStore{
+		client:  s.client,
+		db:      db,
+		coll:    s.coll,
+		timeout: s.timeout,
+		indexed: make(map[string]bool),
+	}
+}
+
+// Project — задачи проекта p того же арендатора на том же клиенте
+func (s *MongoStore) Project(p model.ProjectID) *MongoStore {
+	coll := s.coll
+	if p != model.DefaultProject {
+		coll = fmt.Sprintf("%s_%d", s.coll, p)
+	}
+	return &MongoStore{
+		client:  s.client,
+		db:      s.db,
+		coll:    coll,
+		timeout: s.timeout,
+		indexed: make(map[string]bool),
+	}
+}
+
 type taskDoc struct {
-	ID          model.ID        `bson:"_id"`
-	Title       string          `bson:"title"`
-	Description string          `bson:"description,omitempty"`
-	Status      model.Status    `bson:"status"`
-	Priority    model.Priority  `bson:"priority"`
-	DueAt       *time.Time      `bson:"due_at,omitempty"`
-	CreatedAt   time.Time       `bson:"created_at"`
-	UpdatedAt   time.Time       `bson:"updated_at"`
-	CompletedAt *time.Time      `bson:"completed_at,omitempty"`
+	ID          model.ID              `bson:"_id"`
+	Title       string                `bson:"title"`
+	Description string                `bson:"description,omitempty"`
+	Status      model.Status          `bson:"status"`
+	Priority    model.Priority        `bson:"priority"`
+	DueAt       *time.Time            `bson:"due_at,omitempty"`
+	CreatedAt   time.Time             `bson:"created_at"`
+	UpdatedAt   time.Time             `bson:"updated_at"`
+	CompletedAt *time.Time            `bson:"completed_at,omitempty"`
+	DeletedAt   *time.Time            `bson:"deleted_at,omitempty"`
+	ArchivedAt  *time.Time            `bson:"archived_at,omitempty"` // только в архиве
+	ParentID    model.ID              `bson:"parent_id,omitempty"`
+	BlockedBy   []model.ID            `bson:"blocked_by,omitempty"`
+	Recurrence  string                `bson:"recurrence,omitempty"`
+	Tags        []string              `bson:"tags,omitempty"`
+	CreatedBy   model.UserID          `bson:"created_by,omitempty"`
+	Assignee    model.UserID          `bson:"assignee,omitempty"`
+	Checklist   []model.ChecklistItem `bson:"checklist,omitempty"`
+	Version     int64                 `bson:"version"`
+
+	DetachedChildren   []model.ID `bson:"detached_children,omitempty"` // только в корзине
+	DetachedDependents []model.ID `bson:"detached_dependents,omitempty"`
 }
 
 func (d taskDoc) toDTO() model.TaskDTO {
@@ -55,6 +110,19 @@ func (d taskDoc) toDTO() model.TaskDTO {
 		CreatedAt:   d.CreatedAt,
 		UpdatedAt:   d.UpdatedAt,
 		CompletedAt: d.CompletedAt,
+		DeletedAt:   d.DeletedAt,
+		ArchivedAt:  d.ArchivedAt,
+		ParentID:    d.ParentID,
+		BlockedBy:   d.BlockedBy,
+		Recurrence:  d.Recurrence,
+		Tags:        d.Tags,
+		CreatedBy:   d.CreatedBy,
+		Assignee:    d.Assignee,
+		Checklist:   d.Checklist,
+		Version:     d.Version,
+
+		DetachedChildren:   d.DetachedChildren,
+		DetachedDependents: d.DetachedDependents,
 	}
 }
 
@@ -69,20 +137,121 @@ func dtoToDoc(t model.TaskDTO) taskDoc {
 		CreatedAt:   t.CreatedAt,
 		UpdatedAt:   t.UpdatedAt,
 		CompletedAt: t.CompletedAt,
+		DeletedAt:   t.DeletedAt,
+		ArchivedAt:  t.ArchivedAt,
+		ParentID:    t.ParentID,
+		BlockedBy:   t.BlockedBy,
+		Recurrence:  t.Recurrence,
+		Tags:        t.Tags,
+		CreatedBy:   t.CreatedBy,
+		Assignee:    t.Assignee,
+		Checklist:   t.Checklist,
+		Version:     t.Version,
+
+		DetachedChildren:   t.DetachedChildren,
+		DetachedDependents: t.DetachedDependents,
+	}
+}
+
+// withTimeout — если у запроса нет своего дедлайна, ставим запасной,
+// чтобы зависшая база не держала горутину вечно
+func (s *MongoStore) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.timeout)
+}
+
+func (s *MongoStore) collection() *mongo.Collection {
+	return s.client.Database(s.db).Collection(s.coll)
+}
+
+func (s *MongoStore) Get(ctx context.Context, id model.ID) (model.TaskDTO, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var d taskDoc
+	err := s.collection().FindOne(ctx, bson.M{"_id": id}).Decode(&d)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.TaskDTO{}, ErrNotFound
 	}
+	if err != nil {
+		return model.TaskDTO{}, err
+	}
+	return d.toDTO(), nil
+}
+
+func (s *MongoStore) Insert(ctx context.Context, t model.TaskDTO) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	_, err := s.collection().InsertOne(ctx, dtoToDoc(t))
+	return err
 }
 
-func (s *MongoStore) Load() ([]model.TaskDTO, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+// Update заменяет документ, только если в нём версия version: условие стоит
+// в фильтре ReplaceOne, так что проверка и запись атомарны
+func (s *MongoStore) Update(ctx context.Context, t model.TaskDTO, version int64) error {
+	ctx, cancel := s.withTimeout(ctx)
 	defer cancel()
 
-	col := s.client.Database(s.db).Collection(s.coll)
-	cur, err := col.Find(ctx, bson.D{})
+	res, err := s.collection().ReplaceOne(ctx, versionFilter(t.ID, version), dtoToDoc(t))
 	if err != nil {
-		// если коллекции нет — вернём пусто
-		if err == mongo.ErrNilDocument {
-			return []model.TaskDTO{}, nil
+		return err
+	}
+	if res.MatchedCount > 0 {
+		return nil
+	}
+	n, err := s.collection().CountDocuments(ctx, bson.M{"_id": t.ID})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return ErrConflict
+}
+
+// versionFilter — документ id в версии version
+func versionFilter(id model.ID, version int64) bson.M {
+	filter := bson.M{"_id": id, "version": version}
+	if version == 0 {
+		// документы, записанные до версий, поля не имеют
+		filter["version"] = bson.M{"$in": bson.A{0, nil}}
+	}
+	return filter
+}
+
+func (s *MongoStore) Delete(ctx context.Context, id model.ID) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	res, err := s.collection().DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *MongoStore) Query(ctx context.Context, q model.TaskQuery) ([]model.TaskDTO, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	coll, tagIndex := s.collection(), tagIndexModel
+	if q.Archived {
+		coll, tagIndex = s.archive(), archiveTagIndexModel
+	}
+	if len(q.Tags) > 0 || len(q.AnyTags) > 0 {
+		if err := s.ensureIndexOn(ctx, coll, tagIndex); err != nil {
+			return nil, err
 		}
+	}
+
+	cur, err := coll.Aggregate(ctx, buildTaskPipeline(q))
+	if err != nil {
 		return nil, err
 	}
 	defer cur.Close(ctx)
@@ -98,21 +267,87 @@ func (s *MongoStore) Load() ([]model.TaskDTO, error) {
 	return items, cur.Err()
 }
 
-func (s *MongoStore) Save(items []model.TaskDTO) error {
-	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
-	defer cancel()
+// Текстовый индекс для поиска. default_language none — без стемминга,
+// как и в остальных хранилищах.
+var textIndexModel = mongo.IndexModel{
+	Keys: bson.D{{Key: "title", Value: "text"}, {Key: "description", Value: "text"}},
+	Options: options.Index().
+		SetName("tasks_text").
+		SetWeights(bson.D{{Key: "title", Value: titleWeight}, {Key: "description", Value: 1}}).
+		SetDefaultLanguage("none"),
+}
 
-	col := s.client.Database(s.db).Collection(s.coll)
-	if _, err := col.DeleteMany(ctx, bson.D{}); err != nil {
+// Индекс по массиву тегов (multikey) — для фильтров $all/$in
+var tagIndexModel = mongo.IndexModel{
+	Keys:    bson.D{{Key: "tags", Value: 1}},
+	Options: options.Index().SetName("tasks_tags"),
+}
+
+// ensureIndexOn — создаёт индекс коллекции при первом запросе, которому он нужен;
+// имена индексов не должны повторяться
+func (s *MongoStore) ensureIndexOn(ctx context.Context, coll *mongo.Collection, m mongo.IndexModel) error {
+	name := *m.Options.Name
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+	if s.indexed[name] {
+		return nil
+	}
+	if _, err := coll.Indexes().CreateOne(ctx, m); err != nil {
 		return err
 	}
-	if len(items) == 0 {
-		return nil
+	s.indexed[name] = true
+	return nil
+}
+
+// Search — поиск по текстовому индексу, ранг из textScore.
+// Фрагмент строим сами: у Mongo подсветки нет.
+func (s *MongoStore) Search(ctx context.Context, q model.SearchQuery) ([]model.SearchHit, error) {
+	return s.searchIn(ctx, s.collection(), textIndexModel, q)
+}
+
+// searchIn — Search по коллекции coll с её текстовым индексом; корзина и видимость — в том же фильтре, до лимита
+func (s *MongoStore) searchIn(ctx context.Context, coll *mongo.Collection, index mongo.IndexModel, q model.SearchQuery) ([]model.SearchHit, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	terms := uniqueTerms(q.Text)
+	if len(terms) == 0 {
+		return nil, nil
 	}
-	docs := make([]any, 0, len(items))
-	for _, it := range items {
-		docs = append(docs, dtoToDoc(it))
+	if err := s.ensureIndexOn(ctx, coll, index); err != nil {
+		return nil, err
 	}
-	_, err := col.InsertMany(ctx, docs)
-	return err
-}
\ No newline at end of file
+
+	// каждое слово в кавычках — тогда $text требует все слова, а не любое
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(q.Limit))
+	filter := bson.M{
+		"$text":      bson.M{"$search": `"` + strings.Join(terms, `" "`) + `"`},
+		"deleted_at": nil,
+	}
+	if q.VisibleTo != 0 {
+		filter["$and"] = bson.A{visibleFilter(q.VisibleTo)}
+	}
+	cur, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var hits []model.SearchHit
+	for cur.Next(ctx) {
+		var d struct {
+			taskDoc `bson:",inline"`
+			Score   float64 `bson:"score"`
+		}
+		if err := cur.Decode(&d); err != nil {
+			return nil, err
+		}
+		t := d.toDTO()
+		hits = append(hits, model.SearchHit{Task: t, Rank: d.Score, Snippet: snippet(t, terms)})
+	}
+	return hits, cur.Err()
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"todo/internal/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoUserStore — коллекция users рядом с задачами.
+// ID выдаём счётчиком из коллекции counters, логин уникален за счёт индекса.
+type MongoUserStore struct {
+	store *MongoStore
+}
+
+// Users — хранилище пользователей в той же базе
+func (s *MongoStore) Users() *MongoUserStore {
+	return &MongoUserStore{store: s}
+}
+
+var userLoginIndex = mongo.IndexModel{
+	Keys:    bson.D{{Key: "login", Value: 1}},
+	Options: options.Index().SetName("users_login").SetUnique(true),
+}
+
+func (s *MongoUserStore) users() *mongo.Collection {
+	return s.store.client.Database(s.store.db).Collection("users")
+}
+
+func (s *MongoUserStore) findOne(ctx context.Context, filter bson.M) (model.User, error) {
+	ctx, cancel := s.store.withTimeout(ctx)
+	defer cancel()
+
+	var d userDoc
+	err := s.users().FindOne(ctx, filter).Decode(&d)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.User{}, ErrNotFound
+	}
+	return d.toUser(), err
+}
+
+func (s *MongoUserStore) Get(ctx context.Context, id model.UserID) (model.User, error) {
+	return s.findOne(ctx, bson.M{"_id": id})
+}
+
+func (s *MongoUserStore) GetByLogin(ctx context.Context, login string) (model.User, error) {
+	return s.findOne(ctx, bson.M{"login": login})
+}
+
+func (s *MongoUserStore) Insert(ctx context.Context, u model.User) (model.UserID, error) {
+	ctx, cancel := s.store.withTimeout(ctx)
+	defer cancel()
+
+	if err := s.store.ensureIndexOn(ctx, s.users(), userLoginIndex); err != nil {
+		return 0, err
+	}
+	var counter struct {
+		Seq model.UserID `bson:"seq"`
+	}
+	err := s.store.client.Database(s.store.db).Collection("counters").FindOneAndUpdate(ctx,
+		bson.M{"_id": "users"},
+		bson.M{"$inc": bson.M{"seq": 1}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counter)
+	if err != nil {
+		return 0, err
+	}
+	u.ID = counter.Seq
+	if _, err := s.users().InsertOne(ctx, docFromUser(u)); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return 0, ErrDuplicate
+		}
+		return 0, err
+	}
+	return u.ID, nil
+}
+
+func (s *MongoUserStore) Update(ctx context.Context, u model.User) error {
+	ctx, cancel := s.store.withTimeout(ctx)
+	defer cancel()
+
+	res, err := s.users().ReplaceOne(ctx, bson.M{"_id": u.ID}, docFromUser(u))
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *MongoUserStore) Count(ctx context.Context) (int, error) {
+	ctx, cancel := s.store.withTimeout(ctx)
+	defer cancel()
+
+	n, err := s.users().CountDocuments(ctx, bson.M{})
+	return int(n), err
+}
+
+// Tenants — арендаторы, у которых есть пользователи; у документов без tenant — общий
+func (s *MongoUserStore) Tenants(ctx context.Context) ([]model.TenantID, error) {
+	ctx, cancel := s.store.withTimeout(ctx)
+	defer cancel()
+
+	var docs []userDoc
+	cur, err := s.users().Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"tenant": 1}))
+	if err != nil {
+		return nil, err
+	}
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	seen := make(map[model.TenantID]bool)
+	var out []model.TenantID
+	for _, d := range docs {
+		if t := d.Tenant.OrDefault(); !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+// userDoc — пользователь с bson-тегами, ID лежит в _id.
+// admin — из документов до ролей, читаем его, только пока нет role
+type userDoc struct {
+	ID           model.UserID   `bson:"_id"`
+	Login        string         `bson:"login"`
+	PasswordHash string         `bson:"password_hash"`
+	Role         model.Role     `bson:"role"`
+	Admin        bool           `bson:"admin,omitempty"`
+	Tenant       model.TenantID `bson:"tenant,omitempty"`
+	CreatedAt    time.Time      `bson:"created_at"`
+	UpdatedAt    time.Time      `bson:"updated_at"`
+}
+
+func (d userDoc) toUser() model.User {
+	return model.User{ID: d.ID, Login: d.Login, PasswordHash: d.PasswordHash, Role: legacyRole(d.Role, d.Admin),
+		Tenant: d.Tenant.OrDefault(), CreatedAt: d.CreatedAt, UpdatedAt: d.UpdatedAt}
+}
+
+func docFromUser(u model.User) userDoc {
+	return userDoc{ID: u.ID, Login: u.Login, PasswordHash: u.PasswordHash, Role: u.Role,
+		Tenant: u.Tenant, CreatedAt: u.CreatedAt, UpdatedAt: u.UpdatedAt}
+}
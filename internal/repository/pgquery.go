@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+	"todo/internal/model"
+)
+
+// sqlBuilder — копит условия WHERE и аргументы с нумерацией $1..$n
+type sqlBuilder struct {
+	where []string
+	args  []any
+}
+
+func (b *sqlBuilder) arg(v any) string {
+	b.args = append(b.args, v)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+func (b *sqlBuilder) add(cond string) {
+	b.where = append(b.where, cond)
+}
+
+// sortColumn — SQL-выражение для поля сортировки; due_at без срока уходит в конец
+func (b *sqlBuilder) sortColumn(f model.SortField) string {
+	switch f {
+	case model.SortDue:
+		return "COALESCE(due_at, " + b.arg(model.DueSortMax) + ")"
+	case model.SortCreated, model.SortUpdated, model.SortPriority, model.SortTitle:
+		return string(f)
+	default:
+		return "id"
+	}
+}
+
+// buildTaskQuery переводит TaskQuery в SELECT с WHERE/ORDER BY/LIMIT по задачам проекта.
+// Archived — то же по tasks_archive, где теги лежат в самой строке
+func buildTaskQuery(project model.ProjectID, q model.TaskQuery) (string, []any) {
+	b := &sqlBuilder{}
+	table, columns := "tasks", taskColumns
+	if q.Archived {
+		table, columns = "tasks_archive", archiveColumns
+	}
+
+	proj := b.arg(project)
+	b.add("project_id = " + proj)
+	if len(q.Statuses) > 0 {
+		statuses := make([]string, 0, len(q.Statuses))
+		for _, st := range q.Statuses {
+			statuses = append(statuses, string(st))
+		}
+		b.add("status = ANY(" + b.arg(pq.Array(statuses)) + ")")
+	}
+	if q.MinPriority != 0 {
+		b.add("priority >= " + b.arg(q.MinPriority))
+	}
+	if q.MaxPriority != 0 {
+		b.add("priority <= " + b.arg(q.MaxPriority))
+	}
+	if q.DueAfter != nil {
+		b.add("due_at >= " + b.arg(*q.DueAfter))
+	}
+	if q.DueBefore != nil {
+		b.add("due_at < " + b.arg(*q.DueBefore))
+	}
+	if q.CreatedFrom != nil {
+		b.add("created_at >= " + b.arg(*q.CreatedFrom))
+	}
+	if q.CreatedTo != nil {
+		b.add("created_at < " + b.arg(*q.CreatedTo))
+	}
+	if q.Text != "" {
+		p := b.arg("%" + escapeLike(q.Text) + "%")
+		b.add("(title ILIKE " + p + " OR description ILIKE " + p + ")")
+	}
+	if len(q.Tags) > 0 && q.Archived {
+		// в архиве теги лежат в самой строке
+		b.add("tags @> " + b.arg(pq.Array(q.Tags)))
+	} else if len(q.Tags) > 0 {
+		// все теги: у задачи столько совпавших строк в task_tags, сколько тегов в запросе
+		b.add("id IN (SELECT task_id FROM task_tags WHERE project_id = " + proj + " AND tag = ANY(" + b.arg(pq.Array(q.Tags)) +
+			") GROUP BY task_id HAVING COUNT(*) = " + b.arg(len(q.Tags)) + ")")
+	}
+	if len(q.AnyTags) > 0 && q.Archived {
+		b.add("tags && " + b.arg(pq.Array(q.AnyTags)))
+	} else if len(q.AnyTags) > 0 {
+		b.add("EXISTS (SELECT 1 FROM task_tags tt WHERE tt.project_id = tasks.project_id AND tt.task_id = tasks.id AND tt.tag = ANY(" + b.arg(pq.Array(q.AnyTags)) + "))")
+	}
+	if q.CreatedBy != 0 {
+		b.add("created_by = " + b.arg(q.CreatedBy))
+	}
+	if q.Assignee != 0 {
+		b.add("assignee = " + b.arg(q.Assignee))
+	}
+	if q.VisibleTo != 0 {
+		// общие (без автора), свои и назначенные
+		u := b.arg(q.VisibleTo)
+		b.add("(created_by IS NULL OR created_by = " + u + " OR assignee = " + u + ")")
+	}
+	if !q.WithDeleted {
+		b.add("deleted_at IS NULL")
+	}
+
+	keys := q.SortKeys()
+	if q.After != nil {
+		b.add(keysetCondition(b, keys, *q.After))
+	}
+
+	order := make([]string, 0, len(keys))
+	for _, k := range keys {
+		col := b.sortColumn(k.Field)
+		if k.Desc {
+			col += " DESC"
+		}
+		order = append(order, col)
+	}
+
+	query := `SELECT ` + columns + ` FROM ` + table
+	if len(b.where) > 0 {
+		query += ` WHERE ` + strings.Join(b.where, " AND ")
+	}
+	query += ` ORDER BY ` + strings.Join(order, ", ")
+	if q.Limit > 0 {
+		query += ` LIMIT ` + b.arg(q.Limit)
+	}
+	return query, b.args
+}
+
+// keysetCondition — «строго после курсора» для набора ключей с разными направлениями:
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ...
+func keysetCondition(b *sqlBuilder, keys []model.SortKey, c model.Cursor) string {
+	var ors []string
+	for i, k := range keys {
+		var ands []string
+		for _, prev := range keys[:i] {
+			ands = append(ands, b.sortColumn(prev.Field)+" = "+b.arg(c.Value(prev.Field)))
+		}
+		op := " > "
+		if k.Desc {
+			op = " < "
+		}
+		ands = append(ands, b.sortColumn(k.Field)+op+b.arg(c.Value(k.Field)))
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return "(" + strings.Join(ors, " OR ") + ")"
+}
+
+// escapeLike экранирует спецсимволы LIKE, чтобы текст искался буквально
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"todo/internal/model"
+)
+
+// PostgresAPIKeyStore — таблица api_keys (migrations/0012_api_keys)
+type PostgresAPIKeyStore struct {
+	db *sql.DB
+}
+
+// APIKeys — хранилище ключей API в той же базе
+func (s *PostgresStore) APIKeys() *PostgresAPIKeyStore {
+	return &PostgresAPIKeyStore{db: s.db}
+}
+
+const apiKeyColumns = `id, name, user_id, scopes, key_hash, created_at, expires_at, last_used_at, revoked_at`
+
+func scanAPIKey(row rowScanner) (model.APIKey, error) {
+	var k model.APIKey
+	err := row.Scan(&k.ID, &k.Name, &k.UserID, pq.Array(&k.Scopes), &k.Hash, &k.CreatedAt,
+		&k.ExpiresAt, &k.LastUsedAt, &k.RevokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.APIKey{}, ErrNotFound
+	}
+	return k, err
+}
+
+func (s *PostgresAPIKeyStore) CreateKey(ctx context.Context, k model.APIKey) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO api_keys (id, name, user_id, scopes, key_hash, created_at, expires_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7)
+	`, k.ID, k.Name, k.UserID, pq.Array(k.Scopes), k.Hash, k.CreatedAt, k.ExpiresAt)
+	return uniqueViolation(err)
+}
+
+func (s *PostgresAPIKeyStore) GetKey(ctx context.Context, id string) (model.APIKey, error) {
+	return scanAPIKey(s.db.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id=$1`, id))
+}
+
+func (s *PostgresAPIKeyStore) ListKeys(ctx context.Context) ([]model.APIKey, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []model.APIKey
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, k)
+	}
+	return out, rows.Err()
+}
+
+// RevokeKey — повторный отзыв не сдвигает время первого
+func (s *PostgresAPIKeyStore) RevokeKey(ctx context.Context, id string, at time.Time) error {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE api_keys SET revoked_at=COALESCE(revoked_at, $2) WHERE id=$1
+	`, id, at)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
+
+func (s *PostgresAPIKeyStore) TouchKey(ctx context.Context, id string, at time.Time) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at=$2 WHERE id=$1`, id, at)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"todo/internal/model"
+)
+
+// Архив PostgresStore — таблица tasks_archive (migrations/0020_archive): колонки как у tasks,
+// теги прямо в строке (task_tags только для горячих), плюс archived_at.
+// Переезд туда и обратно — одна транзакция.
+
+const archiveColumns = taskFields + `, tags, archived_at`
+
+// archiveDest — куда сканировать archiveColumns
+func archiveDest(r *model.TaskDTO) []any {
+	return append(taskDest(r), &r.ArchivedAt)
+}
+
+func scanArchived(row rowScanner) (model.TaskDTO, error) {
+	var r model.TaskDTO
+	err := row.Scan(archiveDest(&r)...)
+	return r, err
+}
+
+// Archive — строки уходят из tasks (теги за ними по внешнему ключу) в tasks_archive
+func (s *PostgresStore) Archive(ctx context.Context, tasks []model.TaskDTO) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, t := range tasks {
+			res, err := tx.ExecContext(ctx, `DELETE FROM tasks WHERE id=$1 AND project_id=$2 AND version=$3`, t.ID, s.project, t.Version)
+			if err != nil {
+				return err
+			}
+			if err := expectOneRow(res); errors.Is(err, ErrNotFound) {
+				return s.missedVersion(ctx, tx, t.ID)
+			} else if err != nil {
+				return err
+			}
+			if err := s.insertArchived(ctx, tx, t); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// insertArchived — строка в tasks_archive
+func (s *PostgresStore) insertArchived(ctx context.Context, tx *sql.Tx, t model.TaskDTO) error {
+	tags := t.Tags
+	if tags == nil {
+		tags = []string{} // колонка NOT NULL
+	}
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO tasks_archive (`+taskInsertColumns+`, tags, archived_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22)
+	`, append(s.insertArgs(&t), pq.Array(tags), t.ArchivedAt)...)
+	return err
+}
+
+func (s *PostgresStore) Unarchive(ctx context.Context, id model.ID) (model.TaskDTO, error) {
+	var r model.TaskDTO
+	err := s.inTx(ctx, func(tx *sql.Tx) (err error) {
+		r, err = scanArchived(tx.QueryRowContext(ctx, `DELETE FROM tasks_archive WHERE id=$1 AND project_id=$2 RETURNING `+archiveColumns, id, s.project))
+		if err != nil {
+			return err
+		}
+		r.ArchivedAt = nil
+		return s.insertTask(ctx, tx, r)
+	})
+	var pqErr *pq.Error
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return model.TaskDTO{}, ErrNotFound
+	case errors.As(err, &pqErr) && pqErr.Code == "23505":
+		return model.TaskDTO{}, ErrConflict
+	case err != nil:
+		return model.TaskDTO{}, err
+	}
+	return r, nil
+}
+
+func (s *PostgresStore) GetArchived(ctx context.Context, id model.ID) (model.TaskDTO, error) {
+	var r model.TaskDTO
+	err := s.inTx(ctx, func(tx *sql.Tx) (err error) {
+		r, err = scanArchived(tx.QueryRowContext(ctx, `SELECT `+archiveColumns+` FROM tasks_archive WHERE id=$1 AND project_id=$2`, id, s.project))
+		return err
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.TaskDTO{}, ErrNotFound
+	}
+	return r, err
+}
+
+func (s *PostgresStore) SearchArchive(ctx context.Context, q model.SearchQuery) ([]model.SearchHit, error) {
+	return s.search(ctx, searchSQL("tasks_archive", archiveColumns), archiveDest, q)
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"todo/internal/model"
+)
+
+// Вложения PostgresStore — таблица task_attachments (migrations/0016_attachments),
+// устроена как task_comments. Содержимое файлов — в BlobStore.
+
+const attachmentColumns = `id, task_id, name, size, content_type, checksum, blob_key, COALESCE(uploaded_by, 0), created_at`
+
+func scanAttachment(row rowScanner) (model.Attachment, error) {
+	var a model.Attachment
+	err := row.Scan(&a.ID, &a.TaskID, &a.Name, &a.Size, &a.ContentType, &a.Checksum, &a.BlobKey, &a.UploadedBy, &a.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Attachment{}, ErrNotFound
+	}
+	return a, err
+}
+
+func scanAttachments(rows *sql.Rows) ([]model.Attachment, error) {
+	defer rows.Close()
+	var out []model.Attachment
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (s *PostgresStore) ListAttachments(ctx context.Context, task model.ID) ([]model.Attachment, error) {
+	var out []model.Attachment
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `
+			SELECT `+attachmentColumns+` FROM task_attachments WHERE project_id=$1 AND task_id=$2 ORDER BY id
+		`, s.project, task)
+		if err != nil {
+			return err
+		}
+		out, err = scanAttachments(rows)
+		return err
+	})
+	return out, err
+}
+
+func (s *PostgresStore) GetAttachment(ctx context.Context, id model.AttachmentID) (model.Attachment, error) {
+	var a model.Attachment
+	err := s.inTx(ctx, func(tx *sql.Tx) (err error) {
+		a, err = scanAttachment(tx.QueryRowContext(ctx, `
+			SELECT `+attachmentColumns+` FROM task_attachments WHERE id=$1 AND project_id=$2
+		`, id, s.project))
+		return err
+	})
+	return a, err
+}
+
+func (s *PostgresStore) InsertAttachment(ctx context.Context, a model.Attachment) (model.AttachmentID, error) {
+	var id model.AttachmentID
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		return tx.QueryRowContext(ctx, `
+			INSERT INTO task_attachments (project_id, task_id, name, size, content_type, checksum, blob_key, uploaded_by, created_at)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+			RETURNING id
+		`, s.project, a.TaskID, a.Name, a.Size, a.ContentType, a.Checksum, a.BlobKey, nullUser(a.UploadedBy), a.CreatedAt).Scan(&id)
+	})
+	return id, err
+}
+
+func (s *PostgresStore) DeleteAttachment(ctx context.Context, id model.AttachmentID) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `DELETE FROM task_attachments WHERE id=$1 AND project_id=$2`, id, s.project)
+		if err != nil {
+			return err
+		}
+		return expectOneRow(res)
+	})
+}
+
+func (s *PostgresStore) DeleteTaskAttachments(ctx context.Context, task model.ID) ([]model.Attachment, error) {
+	var gone []model.Attachment
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `
+			DELETE FROM task_attachments WHERE project_id=$1 AND task_id=$2
+			RETURNING `+attachmentColumns, s.project, task)
+		if err != nil {
+			return err
+		}
+		gone, err = scanAttachments(rows)
+		return err
+	})
+	return gone, err
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"todo/internal/model"
+)
+
+// Комментарии PostgresStore — таблица task_comments (migrations/0015_comments),
+// как и задачи: проект — фильтром, арендатор — row-level security
+
+const commentColumns = `id, task_id, COALESCE(author, 0), body, created_at, edited_at`
+
+func scanComment(row rowScanner) (model.Comment, error) {
+	var c model.Comment
+	err := row.Scan(&c.ID, &c.TaskID, &c.Author, &c.Body, &c.CreatedAt, &c.EditedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Comment{}, ErrNotFound
+	}
+	return c, err
+}
+
+func (s *PostgresStore) ListComments(ctx context.Context, task model.ID) ([]model.Comment, error) {
+	var out []model.Comment
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `
+			SELECT `+commentColumns+` FROM task_comments WHERE project_id=$1 AND task_id=$2 ORDER BY id
+		`, s.project, task)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			c, err := scanComment(rows)
+			if err != nil {
+				return err
+			}
+			out = append(out, c)
+		}
+		return rows.Err()
+	})
+	return out, err
+}
+
+func (s *PostgresStore) GetComment(ctx context.Context, id model.CommentID) (model.Comment, error) {
+	var c model.Comment
+	err := s.inTx(ctx, func(tx *sql.Tx) (err error) {
+		c, err = scanComment(tx.QueryRowContext(ctx, `
+			SELECT `+commentColumns+` FROM task_comments WHERE id=$1 AND project_id=$2
+		`, id, s.project))
+		return err
+	})
+	return c, err
+}
+
+func (s *PostgresStore) InsertComment(ctx context.Context, c model.Comment) (model.CommentID, error) {
+	var id model.CommentID
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		return tx.QueryRowContext(ctx, `
+			INSERT INTO task_comments (project_id, task_id, author, body, created_at)
+			VALUES ($1,$2,$3,$4,$5)
+			RETURNING id
+		`, s.project, c.TaskID, nullUser(c.Author), c.Body, c.CreatedAt).Scan(&id)
+	})
+	return id, err
+}
+
+func (s *PostgresStore) UpdateComment(ctx context.Context, c model.Comment) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE task_comments SET body=$3, edited_at=$4 WHERE id=$1 AND project_id=$2
+		`, c.ID, s.project, c.Body, c.EditedAt)
+		if err != nil {
+			return err
+		}
+		return expectOneRow(res)
+	})
+}
+
+func (s *PostgresStore) DeleteComment(ctx context.Context, id model.CommentID) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `DELETE FROM task_comments WHERE id=$1 AND project_id=$2`, id, s.project)
+		if err != nil {
+			return err
+		}
+		return expectOneRow(res)
+	})
+}
+
+func (s *PostgresStore) DeleteTaskComments(ctx context.Context, task model.ID) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `DELETE FROM task_comments WHERE project_id=$1 AND task_id=$2`, s.project, task)
+		return err
+	})
+}
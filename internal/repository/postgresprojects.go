@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"todo/internal/model"
+)
+
+// PostgresProjectStore — таблицы projects и project_members (migrations/0013_projects)
+// с проектами одного арендатора, как и PostgresStore
+type PostgresProjectStore struct {
+	db     *sql.DB
+	tenant model.TenantID
+}
+
+// Projects — хранилище проектов арендатора в той же базе
+func (s *PostgresStore) Projects() *PostgresProjectStore {
+	return &PostgresProjectStore{db: s.db, tenant: s.tenant}
+}
+
+const projectColumns = `id, name, COALESCE(created_by, 0), created_at,
+	ARRAY(SELECT user_id FROM project_members WHERE project_id = projects.id ORDER BY user_id)`
+
+func scanProject(row rowScanner) (model.Project, error) {
+	var p model.Project
+	var members pq.Int64Array
+	err := row.Scan(&p.ID, &p.Name, &p.CreatedBy, &p.CreatedAt, &members)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Project{}, ErrNotFound
+	}
+	for _, m := range members {
+		p.Members = append(p.Members, model.UserID(m))
+	}
+	return p, err
+}
+
+func (s *PostgresProjectStore) GetProject(ctx context.Context, id model.ProjectID) (model.Project, error) {
+	var p model.Project
+	err := inTenantTx(ctx, s.db, s.tenant, func(tx *sql.Tx) (err error) {
+		p, err = scanProject(tx.QueryRowContext(ctx, `SELECT `+projectColumns+` FROM projects WHERE id=$1`, id))
+		return err
+	})
+	return p, err
+}
+
+func (s *PostgresProjectStore) ListProjects(ctx context.Context) ([]model.Project, error) {
+	var out []model.Project
+	err := inTenantTx(ctx, s.db, s.tenant, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `SELECT `+projectColumns+` FROM projects ORDER BY id`)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			p, err := scanProject(rows)
+			if err != nil {
+				return err
+			}
+			out = append(out, p)
+		}
+		return rows.Err()
+	})
+	return out, err
+}
+
+// Проект и его участники пишутся в одной транзакции.
+// Нумерация проектов у каждого арендатора своя (общий проект у всех — 1),
+// поэтому ID — следующий за последним видимым, а не из последовательности.
+func (s *PostgresProjectStore) InsertProject(ctx context.Context, p model.Project) (model.ProjectID, error) {
+	var id model.ProjectID
+	err := inTenantTx(ctx, s.db, s.tenant, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO projects (id, name, created_by, created_at)
+			VALUES ((SELECT COALESCE(MAX(id), 0) + 1 FROM projects), $1, $2, $3)
+			RETURNING id
+		`, p.Name, nullUser(p.CreatedBy), p.CreatedAt).Scan(&id)
+		if err != nil {
+			return uniqueViolation(err)
+		}
+		return writeMembers(ctx, tx, id, p.Members)
+	})
+	return id, err
+}
+
+func (s *PostgresProjectStore) UpdateProject(ctx context.Context, p model.Project) error {
+	return inTenantTx(ctx, s.db, s.tenant, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `UPDATE projects SET name=$2 WHERE id=$1`, p.ID, p.Name)
+		if err != nil {
+			return uniqueViolation(err)
+		}
+		if err := expectOneRow(res); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM project_members WHERE project_id=$1`, p.ID); err != nil {
+			return err
+		}
+		return writeMembers(ctx, tx, p.ID, p.Members)
+	})
+}
+
+// writeMembers — участники проекта одним запросом
+func writeMembers(ctx context.Context, tx *sql.Tx, id model.ProjectID, members []model.UserID) error {
+	if len(members) == 0 {
+		return nil
+	}
+	ids := make(pq.Int64Array, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, int64(m))
+	}
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO project_members (project_id, user_id) SELECT $1, unnest($2::int[])
+	`, id, ids)
+	return err
+}
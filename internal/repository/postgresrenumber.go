@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"todo/internal/model"
+)
+
+// Renumber — вся перенумерация проекта одной транзакцией: задачи с корзиной, архив,
+// а за ними комментарии и вложения. Сбой на любом шаге откатывает всё
+func (s *PostgresStore) Renumber(ctx context.Context, remap map[model.ID]model.ID) error {
+	moved := movedIDs(remap)
+	olds := make(pq.Int64Array, 0, len(moved))
+	news := make(pq.Int64Array, 0, len(moved))
+	for old, id := range moved {
+		olds, news = append(olds, int64(old)), append(news, int64(id))
+	}
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := s.renumberRows(ctx, tx, "tasks", taskColumns, scanTask, s.insertTask, remap); err != nil {
+			return err
+		}
+		if err := s.renumberRows(ctx, tx, "tasks_archive", archiveColumns, scanArchived, s.insertArchived, remap); err != nil {
+			return err
+		}
+		if len(olds) == 0 {
+			return nil
+		}
+		// одним UPDATE по таблице соответствий: каждая строка сопоставляется
+		// со старым номером один раз, так что цепочки 3→2, 2→1 не путаются
+		for _, table := range []string{"task_comments", "task_attachments"} {
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE `+table+` x SET task_id = m.new
+				FROM unnest($2::bigint[], $3::bigint[]) AS m(old, new)
+				WHERE x.project_id = $1 AND x.task_id = m.old
+			`, s.project, olds, news); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// renumberRows — переписываем строки table, у которых поменялся номер, родитель или блокеры:
+// сначала удаляем все старые (теги горячих уходят за ними по внешнему ключу), потом вставляем
+// новые, чтобы цепочки 3→2, 2→1 не упёрлись в первичный ключ. Номер, доставшийся двоим,
+// упрётся в него же и откатит всю транзакцию
+func (s *PostgresStore) renumberRows(ctx context.Context, tx *sql.Tx, table, columns string,
+	scan func(rowScanner) (model.TaskDTO, error), insert func(context.Context, *sql.Tx, model.TaskDTO) error,
+	remap map[model.ID]model.ID) error {
+	rows, err := tx.QueryContext(ctx, `SELECT `+columns+` FROM `+table+` WHERE project_id=$1`, s.project)
+	if err != nil {
+		return err
+	}
+	var changed []model.TaskDTO
+	var old []int64
+	for rows.Next() {
+		r, err := scan(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		n := renumberRecord(r, remap)
+		if renumbered(r, n) {
+			changed = append(changed, n)
+			old = append(old, int64(r.ID))
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE project_id=$1 AND id = ANY($2)`, s.project, pq.Array(old)); err != nil {
+		return err
+	}
+	for _, r := range changed {
+		if err := insert(ctx, tx, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"todo/internal/model"
+)
+
+// PostgresSessionStore — таблицы sessions и revoked_tokens (migrations/0011_sessions)
+type PostgresSessionStore struct {
+	db *sql.DB
+}
+
+// Sessions — хранилище сессий в той же базе
+func (s *PostgresStore) Sessions() *PostgresSessionStore {
+	return &PostgresSessionStore{db: s.db}
+}
+
+func (s *PostgresSessionStore) CreateSession(ctx context.Context, sess model.Session) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO sessions (id, user_id, refresh_hash, expires_at) VALUES ($1,$2,$3,$4)
+	`, sess.ID, sess.UserID, sess.RefreshHash, sess.ExpiresAt)
+	return uniqueViolation(err)
+}
+
+func (s *PostgresSessionStore) GetSession(ctx context.Context, id string) (model.Session, error) {
+	var sess model.Session
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, user_id, refresh_hash, expires_at FROM sessions WHERE id=$1
+	`, id).Scan(&sess.ID, &sess.UserID, &sess.RefreshHash, &sess.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Session{}, ErrNotFound
+	}
+	return sess, err
+}
+
+// RotateSession — сравнение и замена одним UPDATE, без гонки между параллельными обновлениями
+func (s *PostgresSessionStore) RotateSession(ctx context.Context, id, oldHash, newHash string, expiresAt time.Time) error {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE sessions SET refresh_hash=$3, expires_at=$4 WHERE id=$1 AND refresh_hash=$2
+	`, id, oldHash, newHash, expiresAt)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
+
+func (s *PostgresSessionStore) DeleteSession(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE id=$1`, id)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(res)
+}
+
+func (s *PostgresSessionStore) DenyToken(ctx context.Context, jti string, until time.Time) error {
+	// заодно выбрасываем истёкшие, чтобы таблица не росла бесконечно
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at < now()`); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO revoked_tokens (jti, expires_at) VALUES ($1,$2) ON CONFLICT (jti) DO NOTHING
+	`, jti, until)
+	return err
+}
+
+func (s *PostgresSessionStore) TokenDenied(ctx context.Context, jti string) (bool, error) {
+	var denied bool
+	err := s.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti=$1 AND expires_at > now())
+	`, jti).Scan(&denied)
+	return denied, err
+}
@@ -3,14 +3,25 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"todo/internal/model"
 )
 
+// PostgresStore — задачи одного проекта одного арендатора в общей таблице tasks
+// (migrations/0013_projects, 0014_tenants). NewPostgresStore смотрит в общий проект
+// общего арендатора, остальные — через Tenant и Project.
+// Арендатора отделяет не WHERE, а row-level security: каждый запрос идёт
+// в транзакции с app.tenant, и чужих строк база просто не отдаёт (см. inTenantTx).
 type PostgresStore struct {
-	db *sql.DB
+	db      *sql.DB
+	tenant  model.TenantID
+	project model.ProjectID
 }
 
 func NewPostgresStore(connStr string) (*PostgresStore, error) {
@@ -23,51 +34,357 @@ func NewPostgresStore(connStr string) (*PostgresStore, error) {
 	if err := db.PingContext(ctx); err != nil {
 		return nil, err
 	}
-	return &PostgresStore{db: db}, nil
+	return &PostgresStore{db: db, tenant: model.DefaultTenant, project: model.DefaultProject}, nil
 }
 
-func (s *PostgresStore) Load() ([]model.TaskDTO, error) {
-	rows, err := s.db.Query(`SELECT id, title, description, status, priority, due_at, created_at, updated_at, completed_at FROM tasks`)
-	if err != nil {
-		return nil, err
+// Tenant — общий проект арендатора t на том же соединении
+func (s *PostgresStore) Tenant(t model.TenantID) *PostgresStore {
+	return &PostgresStore{db: s.db, tenant: t, project: model.DefaultProject}
+}
+
+// Project — задачи проекта p того же арендатора на том же соединении
+func (s *PostgresStore) Project(p model.ProjectID) *PostgresStore {
+	return &PostgresStore{db: s.db, tenant: s.tenant, project: p}
+}
+
+// taskFields — общее у горячих задач и архива; теги у горячих в task_tags, у архива в самой строке
+const taskFields = `id, title, COALESCE(description, ''), status, priority, due_at, created_at, updated_at, completed_at, COALESCE(parent_id, 0), blocked_by, COALESCE(recurrence, ''),
+	COALESCE(created_by, 0), COALESCE(assignee, 0), checklist, version, deleted_at, detached_children, detached_dependents`
+
+const taskColumns = taskFields + `,
+	ARRAY(SELECT tag FROM task_tags WHERE project_id = tasks.project_id AND task_id = tasks.id ORDER BY tag)`
+
+// taskInsertColumns — колонки INSERT под аргументы insertArgs
+const taskInsertColumns = `id, title, description, status, priority, due_at, created_at, updated_at, completed_at, parent_id, blocked_by, recurrence,
+	created_by, assignee, project_id, checklist, version, deleted_at, detached_children, detached_dependents`
+
+// rowScanner — общее у *sql.Row и *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// taskDest — куда сканировать колонки taskColumns
+func taskDest(r *model.TaskDTO) []any {
+	return []any{&r.ID, &r.Title, &r.Description, &r.Status, &r.Priority,
+		&r.DueAt, &r.CreatedAt, &r.UpdatedAt, &r.CompletedAt, &r.ParentID, idArray{&r.BlockedBy}, &r.Recurrence,
+		&r.CreatedBy, &r.Assignee, checklistJSON{&r.Checklist}, &r.Version, &r.DeletedAt,
+		idArray{&r.DetachedChildren}, idArray{&r.DetachedDependents}, tagArray{&r.Tags}}
+}
+
+func scanTask(row rowScanner) (model.TaskDTO, error) {
+	var r model.TaskDTO
+	err := row.Scan(taskDest(&r)...)
+	return r, err
+}
+
+// idArray — []model.ID в колонке BIGINT[]; пустой массив читаем как nil
+type idArray struct{ ids *[]model.ID }
+
+func (a idArray) Scan(src any) error {
+	var raw pq.Int64Array
+	if err := raw.Scan(src); err != nil {
+		return err
+	}
+	*a.ids = nil
+	for _, v := range raw {
+		*a.ids = append(*a.ids, model.ID(v))
 	}
-	defer rows.Close()
+	return nil
+}
 
-	var items []model.TaskDTO
-	for rows.Next() {
-		var r model.TaskDTO
-		rows.Scan(&r.ID, &r.Title, &r.Description, &r.Status, &r.Priority,
-			&r.DueAt, &r.CreatedAt, &r.UpdatedAt, &r.CompletedAt)
-		items = append(items, r)
+func (a idArray) Value() (driver.Value, error) {
+	raw := make(pq.Int64Array, 0, len(*a.ids))
+	for _, id := range *a.ids {
+		raw = append(raw, int64(id))
 	}
-	return items, nil
+	return raw.Value()
 }
 
-func (s *PostgresStore) Save(items []model.TaskDTO) error {
-	tx, err := s.db.Begin()
-	if err != nil {
+// tagArray — теги в TEXT[]; пустой массив читаем как nil, как и idArray
+type tagArray struct{ tags *[]string }
+
+func (a tagArray) Scan(src any) error {
+	var raw pq.StringArray
+	if err := raw.Scan(src); err != nil {
+		return err
+	}
+	*a.tags = nil
+	if len(raw) > 0 {
+		*a.tags = raw
+	}
+	return nil
+}
+
+// checklistJSON — чек-лист в колонке JSONB; пустой список читаем как nil
+type checklistJSON struct{ items *[]model.ChecklistItem }
+
+func (c checklistJSON) Scan(src any) error {
+	raw, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("checklist: unexpected %T", src)
+	}
+	*c.items = nil
+	if err := json.Unmarshal(raw, c.items); err != nil {
 		return err
 	}
-	defer tx.Rollback()
+	if len(*c.items) == 0 {
+		*c.items = nil
+	}
+	return nil
+}
+
+func (c checklistJSON) Value() (driver.Value, error) {
+	if len(*c.items) == 0 {
+		return "[]", nil
+	}
+	raw, err := json.Marshal(*c.items)
+	return string(raw), err
+}
+
+// nullString — пустая строка в базе хранится как NULL
+func nullString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
 
-	if _, err := tx.Exec(`TRUNCATE TABLE tasks RESTART IDENTITY`); err != nil {
+// nullID — 0 в ID означает «нет», в базе это NULL
+func nullID(id model.ID) any {
+	if id == 0 {
+		return nil
+	}
+	return int64(id)
+}
+
+// nullUser — то же для ссылок на пользователей
+func nullUser(id model.UserID) any {
+	if id == 0 {
+		return nil
+	}
+	return int64(id)
+}
+
+func (s *PostgresStore) Get(ctx context.Context, id model.ID) (model.TaskDTO, error) {
+	var r model.TaskDTO
+	err := s.inTx(ctx, func(tx *sql.Tx) (err error) {
+		r, err = scanTask(tx.QueryRowContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id=$1 AND project_id=$2`, id, s.project))
 		return err
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.TaskDTO{}, ErrNotFound
 	}
-	stmt, err := tx.Prepare(`
-		INSERT INTO tasks (id, title, description, status, priority, due_at, created_at, updated_at, completed_at)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
-	`)
+	return r, err
+}
+
+// Задача и её теги пишутся в одной транзакции
+func (s *PostgresStore) Insert(ctx context.Context, t model.TaskDTO) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		return s.insertTask(ctx, tx, t)
+	})
+}
+
+// insertTask — строка в tasks и её теги
+func (s *PostgresStore) insertTask(ctx context.Context, tx *sql.Tx, t model.TaskDTO) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO tasks (`+taskInsertColumns+`)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20)
+	`, s.insertArgs(&t)...)
 	if err != nil {
 		return err
 	}
-	defer stmt.Close()
+	return s.writeTags(ctx, tx, t.ID, t.Tags)
+}
 
-	for _, t := range items {
-		_, err := stmt.Exec(t.ID, t.Title, t.Description, t.Status, t.Priority,
-			t.DueAt, t.CreatedAt, t.UpdatedAt, t.CompletedAt)
+// insertArgs — значения для taskInsertColumns
+func (s *PostgresStore) insertArgs(t *model.TaskDTO) []any {
+	return []any{t.ID, t.Title, t.Description, t.Status, t.Priority,
+		t.DueAt, t.CreatedAt, t.UpdatedAt, t.CompletedAt, nullID(t.ParentID), idArray{&t.BlockedBy}, nullString(t.Recurrence),
+		nullUser(t.CreatedBy), nullUser(t.Assignee), s.project, checklistJSON{&t.Checklist}, t.Version, t.DeletedAt,
+		idArray{&t.DetachedChildren}, idArray{&t.DetachedDependents}}
+}
+
+// Update — условный UPDATE по версии: строку, которую успели изменить, не трогаем
+func (s *PostgresStore) Update(ctx context.Context, t model.TaskDTO, version int64) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE tasks SET title=$2, description=$3, status=$4, priority=$5,
+				due_at=$6, created_at=$7, updated_at=$8, completed_at=$9, parent_id=$10, blocked_by=$11, recurrence=$12,
+				created_by=$13, assignee=$14, checklist=$16, version=$17, deleted_at=$19,
+				detached_children=$20, detached_dependents=$21
+			WHERE id=$1 AND project_id=$15 AND version=$18
+		`, t.ID, t.Title, t.Description, t.Status, t.Priority,
+			t.DueAt, t.CreatedAt, t.UpdatedAt, t.CompletedAt, nullID(t.ParentID), idArray{&t.BlockedBy}, nullString(t.Recurrence),
+			nullUser(t.CreatedBy), nullUser(t.Assignee), s.project, checklistJSON{&t.Checklist}, t.Version, version, t.DeletedAt,
+			idArray{&t.DetachedChildren}, idArray{&t.DetachedDependents})
 		if err != nil {
 			return err
 		}
+		if err := expectOneRow(res); errors.Is(err, ErrNotFound) {
+			return s.missedVersion(ctx, tx, t.ID)
+		} else if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM task_tags WHERE project_id=$1 AND task_id=$2`, s.project, t.ID); err != nil {
+			return err
+		}
+		return s.writeTags(ctx, tx, t.ID, t.Tags)
+	})
+}
+
+// missedVersion — условие по версии не нашло строку: задачу изменили (ErrConflict) или её нет
+func (s *PostgresStore) missedVersion(ctx context.Context, tx *sql.Tx, id model.ID) error {
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM tasks WHERE id=$1 AND project_id=$2)`, id, s.project).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return ErrConflict
+	}
+	return ErrNotFound
+}
+
+// writeTags — вставляет теги задачи в task_tags одним запросом
+func (s *PostgresStore) writeTags(ctx context.Context, tx *sql.Tx, id model.ID, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO task_tags (project_id, task_id, tag) SELECT $1, $2, unnest($3::text[])
+	`, s.project, id, pq.Array(tags))
+	return err
+}
+
+// inTx — fn в транзакции от имени арендатора store
+func (s *PostgresStore) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	return inTenantTx(ctx, s.db, s.tenant, fn)
+}
+
+// inTenantTx — inTx с app.tenant до конца транзакции: политики row-level security
+// (migrations/0014_tenants) пускают только к строкам этого арендатора,
+// а новым строкам tenant_id подставляется оттуда же
+func inTenantTx(ctx context.Context, db *sql.DB, tenant model.TenantID, fn func(tx *sql.Tx) error) error {
+	return inTx(ctx, db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `SELECT set_config('app.tenant', $1, true)`, string(tenant)); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+// inTx — fn в транзакции: ошибка откатывает всё, успех коммитит
+func inTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
 	}
 	return tx.Commit()
-}
\ No newline at end of file
+}
+
+func (s *PostgresStore) Delete(ctx context.Context, id model.ID) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `DELETE FROM tasks WHERE id=$1 AND project_id=$2`, id, s.project)
+		if err != nil {
+			return err
+		}
+		return expectOneRow(res)
+	})
+}
+
+func (s *PostgresStore) Query(ctx context.Context, q model.TaskQuery) ([]model.TaskDTO, error) {
+	query, args := buildTaskQuery(s.project, q)
+	scan := scanTask
+	if q.Archived {
+		scan = scanArchived
+	}
+	var items []model.TaskDTO
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			r, err := scan(rows)
+			if err != nil {
+				return err
+			}
+			items = append(items, r)
+		}
+		return rows.Err()
+	})
+	return items, err
+}
+
+// searchSQL — поиск по сгенерированной колонке search (см. migrations/0003_search).
+// Конфигурация 'simple' без стемминга: задачи пишут вперемешку на русском и английском.
+// У архива (0020_archive) колонка та же, отличаются таблица и список колонок.
+// Корзину и видимость ($4, 0 — без ограничений) проверяем в WHERE, до LIMIT.
+// ts_headline сам ничего не экранирует, поэтому текст экранируем до него:
+// сущности вроде &lt; парсер считает отдельными токенами и не подсвечивает
+func searchSQL(table, columns string) string {
+	return `
+	SELECT ` + columns + `,
+		ts_rank(search, q) AS rank,
+		ts_headline('simple', ` + htmlEscapeSQL(`title || ' ' || COALESCE(description, '')`) + `, q,
+			'StartSel=` + model.HighlightStart + `, StopSel=` + model.HighlightStop + `, MinWords=5, MaxWords=20')
+	FROM ` + table + `, plainto_tsquery('simple', $1) AS q
+	WHERE project_id = $3 AND search @@ q AND deleted_at IS NULL
+		AND ($4 = 0 OR created_by IS NULL OR created_by = $4 OR assignee = $4)
+	ORDER BY rank DESC, id
+	LIMIT $2`
+}
+
+// htmlEscapeSQL — SQL-выражение, экранирующее expr как html.EscapeString
+func htmlEscapeSQL(expr string) string {
+	for _, r := range [][2]string{{"&", "&amp;"}, {"<", "&lt;"}, {">", "&gt;"}, {`"`, "&#34;"}, {"''", "&#39;"}} {
+		expr = "replace(" + expr + ", '" + r[0] + "', '" + r[1] + "')"
+	}
+	return expr
+}
+
+// Search — полнотекстовый поиск с рангом и подсвеченным фрагментом
+func (s *PostgresStore) Search(ctx context.Context, q model.SearchQuery) ([]model.SearchHit, error) {
+	return s.search(ctx, searchSQL("tasks", taskColumns), taskDest, q)
+}
+
+// search — Search по готовому запросу; dest раскладывает колонки задачи
+func (s *PostgresStore) search(ctx context.Context, stmt string, dest func(r *model.TaskDTO) []any, q model.SearchQuery) ([]model.SearchHit, error) {
+	var limit any // NULL — LIMIT ALL
+	if q.Limit > 0 {
+		limit = q.Limit
+	}
+	var hits []model.SearchHit
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, stmt, q.Text, limit, s.project, int64(q.VisibleTo))
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var h model.SearchHit
+			if err := rows.Scan(append(dest(&h.Task), &h.Rank, &h.Snippet)...); err != nil {
+				return err
+			}
+			hits = append(hits, h)
+		}
+		return rows.Err()
+	})
+	return hits, err
+}
+
+// expectOneRow — UPDATE/DELETE без затронутых строк означает, что записи нет
+func expectOneRow(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
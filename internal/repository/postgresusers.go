@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"todo/internal/model"
+)
+
+// PostgresUserStore — таблица users (migrations/0008_users), соединение общее с задачами
+type PostgresUserStore struct {
+	db *sql.DB
+}
+
+// Users — хранилище пользователей в той же базе
+func (s *PostgresStore) Users() *PostgresUserStore {
+	return &PostgresUserStore{db: s.db}
+}
+
+const userColumns = `id, login, password_hash, role, tenant, created_at, updated_at`
+
+func scanUser(row rowScanner) (model.User, error) {
+	var u model.User
+	err := row.Scan(&u.ID, &u.Login, &u.PasswordHash, &u.Role, &u.Tenant, &u.CreatedAt, &u.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.User{}, ErrNotFound
+	}
+	return u, err
+}
+
+func (s *PostgresUserStore) Get(ctx context.Context, id model.UserID) (model.User, error) {
+	return scanUser(s.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE id=$1`, id))
+}
+
+func (s *PostgresUserStore) GetByLogin(ctx context.Context, login string) (model.User, error) {
+	return scanUser(s.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE login=$1`, login))
+}
+
+func (s *PostgresUserStore) Insert(ctx context.Context, u model.User) (model.UserID, error) {
+	var id model.UserID
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO users (login, password_hash, role, tenant, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6)
+		RETURNING id
+	`, u.Login, u.PasswordHash, u.Role, u.Tenant.OrDefault(), u.CreatedAt, u.UpdatedAt).Scan(&id)
+	return id, uniqueViolation(err)
+}
+
+func (s *PostgresUserStore) Update(ctx context.Context, u model.User) error {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE users SET login=$2, password_hash=$3, role=$4, updated_at=$5 WHERE id=$1
+	`, u.ID, u.Login, u.PasswordHash, u.Role, u.UpdatedAt)
+	if err != nil {
+		return uniqueViolation(err)
+	}
+	return expectOneRow(res)
+}
+
+func (s *PostgresUserStore) Count(ctx context.Context) (int, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&n)
+	return n, err
+}
+
+// Tenants — арендаторы, у которых есть пользователи; users не под row-level security
+func (s *PostgresUserStore) Tenants(ctx context.Context) ([]model.TenantID, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT DISTINCT tenant FROM users ORDER BY tenant`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []model.TenantID
+	for rows.Next() {
+		var t model.TenantID
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// uniqueViolation — нарушение UNIQUE превращаем в ErrDuplicate
+func uniqueViolation(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrDuplicate
+	}
+	return err
+}
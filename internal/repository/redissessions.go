@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"todo/internal/model"
+)
+
+// RedisSessionStore — сессии и отозванные токены в Redis; срок жизни ключей
+// совпадает со сроком сессии или токена, так что чистить ничего не нужно
+type RedisSessionStore struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedisSessionStore(addr, password string, db int, prefix string) *RedisSessionStore {
+	return &RedisSessionStore{
+		client: redis.NewClient(&redis.Options{
+			Addr:     addr,
+			Password: password,
+			DB:       db,
+		}),
+		prefix: prefix,
+	}
+}
+
+func (s *RedisSessionStore) sessionKey(id string) string { return s.prefix + ":session:" + id }
+func (s *RedisSessionStore) deniedKey(jti string) string { return s.prefix + ":denied:" + jti }
+
+func (s *RedisSessionStore) CreateSession(ctx context.Context, sess model.Session) error {
+	key := s.sessionKey(sess.ID)
+	pipe := s.client.TxPipeline()
+	pipe.HSet(ctx, key, "user", int64(sess.UserID), "hash", sess.RefreshHash)
+	pipe.ExpireAt(ctx, key, sess.ExpiresAt)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (s *RedisSessionStore) GetSession(ctx context.Context, id string) (model.Session, error) {
+	key := s.sessionKey(id)
+	pipe := s.client.Pipeline()
+	fields := pipe.HGetAll(ctx, key)
+	ttl := pipe.PTTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return model.Session{}, err
+	}
+	m := fields.Val()
+	if len(m) == 0 {
+		return model.Session{}, ErrNotFound
+	}
+	user, err := strconv.ParseInt(m["user"], 10, 64)
+	if err != nil {
+		return model.Session{}, errors.New("corrupted session " + id)
+	}
+	return model.Session{
+		ID:          id,
+		UserID:      model.UserID(user),
+		RefreshHash: m["hash"],
+		ExpiresAt:   time.Now().Add(ttl.Val()),
+	}, nil
+}
+
+// rotateScript — сравнение и замена хеша атомарно на стороне Redis
+var rotateScript = redis.NewScript(`
+if redis.call("HGET", KEYS[1], "hash") ~= ARGV[1] then
+	return 0
+end
+redis.call("HSET", KEYS[1], "hash", ARGV[2])
+redis.call("PEXPIREAT", KEYS[1], ARGV[3])
+return 1
+`)
+
+func (s *RedisSessionStore) RotateSession(ctx context.Context, id, oldHash, newHash string, expiresAt time.Time) error {
+	n, err := rotateScript.Run(ctx, s.client, []string{s.sessionKey(id)}, oldHash, newHash, expiresAt.UnixMilli()).Int()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *RedisSessionStore) DeleteSession(ctx context.Context, id string) error {
+	n, err := s.client.Del(ctx, s.sessionKey(id)).Result()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *RedisSessionStore) DenyToken(ctx context.Context, jti string, until time.Time) error {
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		return nil // уже истёк
+	}
+	return s.client.Set(ctx, s.deniedKey(jti), 1, ttl).Err()
+}
+
+func (s *RedisSessionStore) TokenDenied(ctx context.Context, jti string) (bool, error) {
+	n, err := s.client.Exists(ctx, s.deniedKey(jti)).Result()
+	return n > 0, err
+}
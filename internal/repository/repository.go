@@ -39,9 +39,14 @@ func (s *PostgresStore) UpdateTaskAndLog(ctx context.Context, id model.ID, statu
 	}
 	defer tx.Rollback()
 
+	// без app.tenant политики tenant_isolation не пустят ни к одной строке
+	if _, err = tx.ExecContext(ctx, `SELECT set_config('app.tenant', $1, true)`, string(s.tenant)); err != nil {
+		return err
+	}
+
 	_, err = tx.ExecContext(ctx, `
-		UPDATE tasks SET status=$1, updated_at=now() WHERE id=$2
-	`, status, id)
+		UPDATE tasks SET status=$1, updated_at=now() WHERE id=$2 AND project_id=$3
+	`, status, id, s.project)
 	if err != nil {
 		return err
 	}
@@ -88,6 +93,28 @@ func Distribute(e Entity) {
 	}
 }
 
+// Distributed — копия списка распределённых задач нужного приоритета.
+// Списки дописываются из фоновых горутин, поэтому снаружи читаем только через неё.
+func Distributed(p model.Priority) []*model.Task {
+	var (
+		mu   *sync.Mutex
+		list *[]*model.Task
+	)
+	switch p {
+	case model.PriorityLow:
+		mu, list = &muLow, &LowPriorityTasks
+	case model.PriorityMedium:
+		mu, list = &muMed, &MediumPriorityTasks
+	case model.PriorityHigh:
+		mu, list = &muHigh, &HighPriorityTasks
+	default:
+		return nil
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]*model.Task(nil), (*list)...)
+}
+
 // loadPriorityTasks — читает json‑файл и восстанавливает []*model.Task
 func loadPriorityTasks(path string) []*model.Task {
 	_ = os.MkdirAll(filepath.Dir(path), 0o755)
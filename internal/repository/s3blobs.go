@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// S3Config — подключение к S3-совместимому хранилищу (AWS, MinIO)
+type S3Config struct {
+	Endpoint  string // например http://localhost:9000; пусто — AWS в регионе Region
+	Region    string // по умолчанию us-east-1
+	Bucket    string
+	AccessKey string
+	SecretKey string
+}
+
+// S3Blobs — содержимое вложений в бакете S3. Обходимся без SDK: нужны только PUT, GET и DELETE
+// объекта, запросы подписываем сами (AWS Signature V4). Адреса path-style —
+// <endpoint>/<bucket>/<key>, их понимают и AWS, и MinIO.
+type S3Blobs struct {
+	cfg    S3Config
+	base   *url.URL
+	client *http.Client
+}
+
+// S3BlobsFromEnv — S3Blobs из S3_ENDPOINT, S3_REGION, S3_BUCKET, S3_ACCESS_KEY, S3_SECRET_KEY,
+// бакет создаётся, если его нет. Без S3_BUCKET — nil: вложения лягут на диск.
+func S3BlobsFromEnv(ctx context.Context) (*S3Blobs, error) {
+	if os.Getenv("S3_BUCKET") == "" {
+		return nil, nil
+	}
+	b, err := NewS3Blobs(S3Config{
+		Endpoint:  os.Getenv("S3_ENDPOINT"),
+		Region:    os.Getenv("S3_REGION"),
+		Bucket:    os.Getenv("S3_BUCKET"),
+		AccessKey: os.Getenv("S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("S3_SECRET_KEY"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := b.EnsureBucket(ctx); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func NewS3Blobs(cfg S3Config) (*S3Blobs, error) {
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	if cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, fmt.Errorf("s3: bucket and credentials are required")
+	}
+	base, err := url.Parse(cfg.Endpoint)
+	if err != nil || base.Host == "" {
+		return nil, fmt.Errorf("s3: bad endpoint %q", cfg.Endpoint)
+	}
+	return &S3Blobs{cfg: cfg, base: base, client: &http.Client{}}, nil
+}
+
+// EnsureBucket — создать бакет, если его ещё нет (удобно для локального MinIO)
+func (b *S3Blobs) EnsureBucket(ctx context.Context) error {
+	resp, err := b.do(ctx, http.MethodHead, "", nil, 0)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		return b.fail(http.MethodHead, "", resp)
+	}
+	var body string
+	if b.cfg.Region != "us-east-1" {
+		body = `<CreateBucketConfiguration><LocationConstraint>` + b.cfg.Region + `</LocationConstraint></CreateBucketConfiguration>`
+	}
+	resp, err = b.do(ctx, http.MethodPut, "", strings.NewReader(body), int64(len(body)))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return b.fail(http.MethodPut, "", resp)
+	}
+	return nil
+}
+
+// Put — S3 хочет знать длину заранее, поэтому сначала складываем во временный файл
+func (b *S3Blobs) Put(ctx context.Context, key string, r io.Reader) error {
+	tmp, err := os.CreateTemp("", "todo-blob-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	size, err := io.Copy(tmp, ctxReader{ctx, r})
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	resp, err := b.do(ctx, http.MethodPut, key, tmp, size)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return b.fail(http.MethodPut, key, resp)
+	}
+	return nil
+}
+
+func (b *S3Blobs) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := b.do(ctx, http.MethodGet, key, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	default:
+		defer resp.Body.Close()
+		return nil, b.fail(http.MethodGet, key, resp)
+	}
+}
+
+// Delete — S3 и на отсутствующий объект отвечает 204, 404 тоже считаем успехом
+func (b *S3Blobs) Delete(ctx context.Context, key string) error {
+	resp, err := b.do(ctx, http.MethodDelete, key, nil, 0)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return b.fail(http.MethodDelete, key, resp)
+	}
+}
+
+// do — подписанный запрос к объекту key (пустой key — к самому бакету)
+func (b *S3Blobs) do(ctx context.Context, method, key string, body io.Reader, size int64) (*http.Response, error) {
+	path := "/" + b.cfg.Bucket
+	if key != "" {
+		path += "/" + key
+	}
+	u := *b.base
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+	u.RawPath = s3Escape(u.Path)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	b.sign(req, time.Now().UTC())
+	return b.client.Do(req)
+}
+
+// sign — AWS Signature V4. Тело не хэшируем (UNSIGNED-PAYLOAD): его целостность
+// и так проверяет TLS, а файл пришлось бы читать дважды.
+func (b *S3Blobs) sign(req *http.Request, now time.Time) {
+	const payload = "UNSIGNED-PAYLOAD"
+	stamp := now.Format("20060102T150405Z")
+	day := stamp[:8]
+	req.Header.Set("X-Amz-Date", stamp)
+	req.Header.Set("X-Amz-Content-Sha256", payload)
+
+	const signed = "host;x-amz-content-sha256;x-amz-date"
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + payload + "\n" +
+			"x-amz-date:" + stamp + "\n",
+		signed,
+		payload,
+	}, "\n")
+	scope := day + "/" + b.cfg.Region + "/s3/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + stamp + "\n" + scope + "\n" + sha256Hex(canonical)
+
+	key := hmacSHA256([]byte("AWS4"+b.cfg.SecretKey), day)
+	for _, part := range []string{b.cfg.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		b.cfg.AccessKey, scope, signed, hex.EncodeToString(hmacSHA256(key, toSign))))
+}
+
+// fail — ошибка с кодом и началом ответа S3 (там XML с причиной)
+func (b *S3Blobs) fail(method, key string, resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("s3 %s %s/%s: %s %s", method, b.cfg.Bucket, key, resp.Status, strings.TrimSpace(string(msg)))
+}
+
+// s3Escape — экранирование пути, как его ждёт подпись: всё, кроме A-Za-z0-9-._~ и /
+func s3Escape(path string) string {
+	var sb strings.Builder
+	for _, c := range []byte(path) {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '.', c == '_', c == '~', c == '/':
+			sb.WriteByte(c)
+		default:
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,260 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"todo/internal/model"
+	"todo/internal/repository"
+	"todo/internal/service"
+)
+
+// Изоляцию арендаторов проверяем на живых базах, только если заданы адреса:
+//   - TEST_POSTGRES_CONN — база с миграциями, роль приложения (не суперпользователь
+//     и без BYPASSRLS, иначе row-level security не действует);
+//   - TEST_MONGO_URI — как в docker-compose.
+//
+// Арендаторы каждый раз новые, после теста их данные удаляются.
+
+var ctx = context.Background()
+
+// tenantStore — всё, что у арендатора лежит рядом с задачами
+type tenantStore interface {
+	service.Store
+	service.Searcher
+	service.Commenter
+	service.Attacher
+	service.Archiver
+}
+
+func TestPostgres_TenantIsolation(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_CONN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_CONN is not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var bypass bool
+	if err := db.QueryRowContext(ctx, `SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user`).Scan(&bypass); err != nil {
+		t.Fatal(err)
+	}
+	if bypass {
+		t.Fatal("TEST_POSTGRES_CONN role bypasses row-level security, use the application role")
+	}
+
+	pg, err := repository.NewPostgresStore(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := newTenants()
+	t.Cleanup(func() {
+		for _, tenant := range []model.TenantID{a, b} {
+			if err := dropPostgresTenant(db, tenant); err != nil {
+				t.Errorf("cleanup %s: %v", tenant, err)
+			}
+		}
+	})
+	sa, sb := pg.Tenant(a), pg.Tenant(b)
+	checkTenantIsolation(t, sa, sa.Projects(), sb, sb.Projects())
+}
+
+// dropPostgresTenant — все строки арендатора; под его app.tenant, как и в приложении
+func dropPostgresTenant(db *sql.DB, tenant model.TenantID) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `SELECT set_config('app.tenant', $1, true)`, string(tenant)); err != nil {
+		return err
+	}
+	for _, table := range []string{"task_comments", "task_attachments", "tasks_archive", "task_tags", "tasks", "project_members", "projects"} {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE tenant_id = $1`, string(tenant)); err != nil {
+			return fmt.Errorf("%s: %w", table, err)
+		}
+	}
+	return tx.Commit()
+}
+
+func TestMongo_TenantIsolation(t *testing.T) {
+	uri := os.Getenv("TEST_MONGO_URI")
+	if uri == "" {
+		t.Skip("TEST_MONGO_URI is not set")
+	}
+	const db = "todo_isolation_test"
+	mg, err := repository.NewMongoStore(uri, db, "tasks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := newTenants()
+	t.Cleanup(func() {
+		client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+		if err != nil {
+			t.Errorf("cleanup: %v", err)
+			return
+		}
+		defer client.Disconnect(ctx)
+		for _, tenant := range []model.TenantID{a, b} {
+			if err := client.Database(db + "_" + string(tenant)).Drop(ctx); err != nil {
+				t.Errorf("cleanup %s: %v", tenant, err)
+			}
+		}
+	})
+	sa, sb := mg.Tenant(a), mg.Tenant(b)
+	checkTenantIsolation(t, sa, sa.Projects(), sb, sb.Projects())
+}
+
+// newTenants — два свежих арендатора на прогон
+func newTenants() (a, b model.TenantID) {
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 36)
+	return model.TenantID("iso-a-" + suffix), model.TenantID("iso-b-" + suffix)
+}
+
+func newTaskDTO(t *testing.T, id model.ID, title string, tags ...string) model.TaskDTO {
+	t.Helper()
+	task, err := model.NewTask(title, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	task.SetID(id)
+	for _, tag := range tags {
+		if _, err := task.AddTag(tag); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return task.ToDTO()
+}
+
+// checkTenantIsolation — A пишет задачи, теги, комментарии, вложения и архив, B их не видит
+// и не может тронуть. Номера у обоих одинаковые: отделяет арендатор, а не ID
+func checkTenantIsolation(t *testing.T, a tenantStore, ap service.ProjectStore, b tenantStore, bp service.ProjectStore) {
+	t.Helper()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	for _, ps := range []service.ProjectStore{ap, bp} {
+		if id, err := ps.InsertProject(ctx, model.Project{Name: "default", CreatedAt: now}); err != nil || id != model.DefaultProject {
+			t.Fatalf("default project: %d %v", id, err)
+		}
+	}
+	secretProject, err := ap.InsertProject(ctx, model.Project{Name: "секретный", CreatedAt: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret := newTaskDTO(t, 1, "Секрет арендатора A", "secret")
+	own := newTaskDTO(t, 1, "Задача арендатора B")
+	closed := newTaskDTO(t, 2, "Закрытый секрет", "secret")
+	closed.Status = model.StatusDone
+	for _, w := range []struct {
+		s tenantStore
+		r model.TaskDTO
+	}{{a, secret}, {a, closed}, {b, own}} {
+		if err := w.s.Insert(ctx, w.r); err != nil {
+			t.Fatalf("Insert %q: %v", w.r.Title, err)
+		}
+	}
+	closed.ArchivedAt = &now
+	if err := a.Archive(ctx, []model.TaskDTO{closed}); err != nil {
+		t.Fatalf("Archive: %v", err)
+	}
+	cid, err := a.InsertComment(ctx, model.Comment{TaskID: 1, Body: "секретный комментарий", CreatedAt: now})
+	if err != nil {
+		t.Fatalf("InsertComment: %v", err)
+	}
+	aid, err := a.InsertAttachment(ctx, model.Attachment{
+		TaskID: 1, Name: "secret.txt", Size: 6, ContentType: "text/plain", Checksum: "00",
+		BlobKey: "isolation/" + strconv.FormatInt(now.UnixNano(), 36), CreatedAt: now,
+	})
+	if err != nil {
+		t.Fatalf("InsertAttachment: %v", err)
+	}
+
+	// B читает: под теми же номерами только своё
+	if got, err := b.Get(ctx, 1); err != nil || got.Title != own.Title {
+		t.Fatalf("B Get(1): %q %v", got.Title, err)
+	}
+	if got, err := b.Query(ctx, model.TaskQuery{WithDeleted: true}); err != nil || len(got) != 1 || got[0].Title != own.Title {
+		t.Fatalf("B Query: %+v %v", got, err)
+	}
+	if got, err := b.Query(ctx, model.TaskQuery{Tags: []string{"secret"}}); err != nil || len(got) != 0 {
+		t.Fatalf("B Query by A's tag: %+v %v", got, err)
+	}
+	if got, err := b.Query(ctx, model.TaskQuery{Archived: true}); err != nil || len(got) != 0 {
+		t.Fatalf("B archive: %+v %v", got, err)
+	}
+	if _, err := b.GetArchived(ctx, 2); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("B GetArchived(2): %v", err)
+	}
+	if hits, err := b.Search(ctx, model.SearchQuery{Text: "секрет"}); err != nil || len(hits) != 0 {
+		t.Fatalf("B Search: %+v %v", hits, err)
+	}
+	if hits, err := b.SearchArchive(ctx, model.SearchQuery{Text: "секрет"}); err != nil || len(hits) != 0 {
+		t.Fatalf("B SearchArchive: %+v %v", hits, err)
+	}
+	if got, err := b.ListComments(ctx, 1); err != nil || len(got) != 0 {
+		t.Fatalf("B ListComments: %+v %v", got, err)
+	}
+	if _, err := b.GetComment(ctx, cid); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("B GetComment: %v", err)
+	}
+	if got, err := b.ListAttachments(ctx, 1); err != nil || len(got) != 0 {
+		t.Fatalf("B ListAttachments: %+v %v", got, err)
+	}
+	if _, err := b.GetAttachment(ctx, aid); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("B GetAttachment: %v", err)
+	}
+	if got, err := bp.ListProjects(ctx); err != nil || len(got) != 1 {
+		t.Fatalf("B ListProjects: %+v %v", got, err)
+	}
+	if _, err := bp.GetProject(ctx, secretProject); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("B GetProject(%d): %v", secretProject, err)
+	}
+
+	// B пишет: чужое не задевает
+	_ = b.DeleteComment(ctx, cid)
+	_ = b.DeleteAttachment(ctx, aid)
+	if gone, err := b.DeleteTaskAttachments(ctx, 1); err != nil || len(gone) != 0 {
+		t.Fatalf("B DeleteTaskAttachments: %+v %v", gone, err)
+	}
+	if err := b.DeleteTaskComments(ctx, 1); err != nil {
+		t.Fatalf("B DeleteTaskComments: %v", err)
+	}
+	if _, err := b.Unarchive(ctx, 2); err == nil {
+		t.Fatal("B unarchived A's task")
+	}
+	if err := b.Delete(ctx, 1); err != nil {
+		t.Fatalf("B Delete(1): %v", err)
+	}
+
+	// у A всё на месте
+	if got, err := a.Get(ctx, 1); err != nil || got.Title != secret.Title || len(got.Tags) != 1 {
+		t.Fatalf("A Get(1): %+v %v", got, err)
+	}
+	if _, err := a.GetArchived(ctx, 2); err != nil {
+		t.Fatalf("A GetArchived(2): %v", err)
+	}
+	if got, err := a.ListComments(ctx, 1); err != nil || len(got) != 1 {
+		t.Fatalf("A ListComments: %+v %v", got, err)
+	}
+	if got, err := a.ListAttachments(ctx, 1); err != nil || len(got) != 1 {
+		t.Fatalf("A ListAttachments: %+v %v", got, err)
+	}
+	if hits, err := a.Search(ctx, model.SearchQuery{Text: "секрет"}); err != nil || len(hits) != 1 {
+		t.Fatalf("A Search: %+v %v", hits, err)
+	}
+	if got, err := ap.ListProjects(ctx); err != nil || len(got) != 2 {
+		t.Fatalf("A ListProjects: %+v %v", got, err)
+	}
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"todo/internal/model"
+)
+
+// вес совпадения в заголовке относительно описания
+const titleWeight = 2
+
+// TextIndex — инвертированный индекс по заголовкам и описаниям задач в памяти.
+// Используется там, где у хранилища нет своего полнотекстового поиска (JSON).
+// Не потокобезопасен: владелец сам держит замок.
+type TextIndex struct {
+	postings map[string]map[model.ID]int // слово → задача → вес вхождений
+	docs     map[model.ID]model.TaskDTO
+}
+
+func NewTextIndex() *TextIndex {
+	return &TextIndex{
+		postings: make(map[string]map[model.ID]int),
+		docs:     make(map[model.ID]model.TaskDTO),
+	}
+}
+
+// Put добавляет или переиндексирует задачу
+func (ix *TextIndex) Put(t model.TaskDTO) {
+	ix.Remove(t.ID)
+	ix.docs[t.ID] = t
+	for term, w := range termWeights(t) {
+		p := ix.postings[term]
+		if p == nil {
+			p = make(map[model.ID]int)
+			ix.postings[term] = p
+		}
+		p[t.ID] = w
+	}
+}
+
+// Remove убирает задачу из индекса
+func (ix *TextIndex) Remove(id model.ID) {
+	t, ok := ix.docs[id]
+	if !ok {
+		return
+	}
+	for term := range termWeights(t) {
+		delete(ix.postings[term], id)
+		if len(ix.postings[term]) == 0 {
+			delete(ix.postings, term)
+		}
+	}
+	delete(ix.docs, id)
+}
+
+// Search ищет задачи, содержащие все слова запроса, и ранжирует по tf-idf.
+// Фильтры q отсекаем до лимита
+func (ix *TextIndex) Search(q model.SearchQuery) []model.SearchHit {
+	terms := uniqueTerms(q.Text)
+	if len(terms) == 0 {
+		return nil
+	}
+	scores := make(map[model.ID]float64)
+	for i, term := range terms {
+		p := ix.postings[term]
+		if len(p) == 0 {
+			return nil // слово нигде не встречается — пересечение пустое
+		}
+		idf := math.Log(1 + float64(len(ix.docs))/float64(len(p)))
+		next := make(map[model.ID]float64, len(p))
+		for id, w := range p {
+			if _, ok := scores[id]; i > 0 && !ok {
+				continue
+			}
+			next[id] = scores[id] + float64(w)*idf
+		}
+		scores = next
+	}
+
+	hits := make([]model.SearchHit, 0, len(scores))
+	for id, score := range scores {
+		t := ix.docs[id]
+		if !q.Match(t) {
+			continue
+		}
+		hits = append(hits, model.SearchHit{Task: t, Rank: score, Snippet: snippet(t, terms)})
+	}
+	sortHits(hits)
+	if q.Limit > 0 && len(hits) > q.Limit {
+		hits = hits[:q.Limit]
+	}
+	return hits
+}
+
+// termWeights — слова задачи и их вес (заголовок весит больше описания)
+func termWeights(t model.TaskDTO) map[string]int {
+	w := make(map[string]int)
+	for _, term := range tokenize(t.Title) {
+		w[term] += titleWeight
+	}
+	for _, term := range tokenize(t.Description) {
+		w[term]++
+	}
+	return w
+}
+
+// tokenize режет текст на слова в нижнем регистре
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func uniqueTerms(s string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, t := range tokenize(s) {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// sortHits — по убыванию релевантности, при равенстве по ID
+func sortHits(hits []model.SearchHit) {
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Rank != hits[j].Rank {
+			return hits[i].Rank > hits[j].Rank
+		}
+		return hits[i].Task.ID < hits[j].Task.ID
+	})
+}
+
+// сколько слов вокруг первого совпадения попадает во фрагмент
+const snippetWords = 12
+
+// snippet — кусок заголовка и описания вокруг первого совпадения, совпавшие слова подсвечены
+func snippet(t model.TaskDTO, terms []string) string {
+	want := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		want[term] = true
+	}
+	text := strings.TrimSpace(t.Title + " " + t.Description)
+	words := strings.Fields(text)
+
+	first := -1
+	for i, w := range words {
+		if matchesAny(w, want) {
+			first = i
+			break
+		}
+	}
+	from := 0
+	if first > snippetWords/3 {
+		from = first - snippetWords/3
+	}
+	to := min(from+snippetWords, len(words))
+
+	out := make([]string, 0, to-from+2)
+	if from > 0 {
+		out = append(out, "…")
+	}
+	for _, w := range words[from:to] {
+		// фрагмент отдают как HTML: сначала экранируем текст задачи, потом ставим метки
+		if matchesAny(w, want) {
+			out = append(out, model.HighlightStart+html.EscapeString(w)+model.HighlightStop)
+		} else {
+			out = append(out, html.EscapeString(w))
+		}
+	}
+	if to < len(words) {
+		out = append(out, "…")
+	}
+	return strings.Join(out, " ")
+}
+
+func matchesAny(word string, want map[string]bool) bool {
+	for _, term := range tokenize(word) {
+		if want[term] {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,52 @@
 package repository
 
+import (
+	"errors"
+	"slices"
+
+	"todo/internal/model"
+)
+
 // Entity — общий интерфейс, чтобы можно было работать с чем угодно
 type Entity interface {
 	TypeName() string
-}
\ No newline at end of file
+}
+
+// ErrNotFound — записи с таким ID в хранилище нет
+var ErrNotFound = errors.New("record not found")
+
+// ErrDuplicate — запись с таким уникальным ключом уже есть
+var ErrDuplicate = errors.New("record already exists")
+
+// ErrConflict — запись успели изменить: в хранилище не та версия, которую заменяем
+var ErrConflict = errors.New("record was changed concurrently")
+
+// renumberRecord — запись на новые номера, как model.Task.Renumber:
+// ID, родитель, блокеры и отцепленные в корзине по remap, ссылки на задачи вне remap выкидываем
+func renumberRecord(r model.TaskDTO, remap map[model.ID]model.ID) model.TaskDTO {
+	if id, ok := remap[r.ID]; ok {
+		r.ID = id
+	}
+	r.ParentID = remap[r.ParentID]
+	r.BlockedBy = model.RemapIDs(r.BlockedBy, remap)
+	r.DetachedChildren = model.RemapIDs(r.DetachedChildren, remap)
+	r.DetachedDependents = model.RemapIDs(r.DetachedDependents, remap)
+	return r
+}
+
+// renumbered — поменяла ли перенумерация запись (n — r после renumberRecord)
+func renumbered(r, n model.TaskDTO) bool {
+	return n.ID != r.ID || n.ParentID != r.ParentID || !slices.Equal(n.BlockedBy, r.BlockedBy) ||
+		!slices.Equal(n.DetachedChildren, r.DetachedChildren) || !slices.Equal(n.DetachedDependents, r.DetachedDependents)
+}
+
+// movedIDs — из remap только задачи, чей номер меняется: за ними переезжают комментарии и вложения
+func movedIDs(remap map[model.ID]model.ID) map[model.ID]model.ID {
+	moved := make(map[model.ID]model.ID)
+	for old, id := range remap {
+		if old != id {
+			moved[old] = id
+		}
+	}
+	return moved
+}
@@ -0,0 +1,77 @@
+// Package reqctx — данные запроса, которые едут в context.Context
+// от транспорта (web, gRPC) через сервис до хранилищ и аудита.
+package reqctx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"todo/internal/model"
+)
+
+type ctxKey int
+
+const (
+	traceKey ctxKey = iota
+	userKey
+	requestKey
+)
+
+// User — кто делает запрос (берётся из JWT или ключа API)
+type User struct {
+	ID    model.UserID
+	Login string
+	Role  model.Role
+	// Tenant — чьи данные видит запрос; пусто — model.DefaultTenant
+	Tenant model.TenantID
+	// Scopes — для ключей API: какие действия policy ему разрешены сверх ограничений роли;
+	// nil — запрос по токену, ограничивает только роль
+	Scopes []string
+}
+
+func (u User) Admin() bool {
+	return u.Role == model.RoleAdmin
+}
+
+// NewTraceID генерирует случайный идентификатор запроса
+func NewTraceID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// WithTraceID кладёт идентификатор запроса в контекст
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceKey, id)
+}
+
+// TraceID достаёт идентификатор запроса, пусто — если его нет
+func TraceID(ctx context.Context) string {
+	id, _ := ctx.Value(traceKey).(string)
+	return id
+}
+
+// WithRequest — новый запрос: свой идентификатор, который выдаёт сервер, а не клиент.
+// По нему сервис собирает события одного запроса в один шаг отмены; trace id приходит
+// от клиента (X-Request-ID) и может повторяться, он только для логов
+func WithRequest(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestKey, NewTraceID())
+}
+
+// RequestID — идентификатор запроса из WithRequest, пусто — вызов не из запроса (фоновые задачи)
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestKey).(string)
+	return id
+}
+
+// WithUser кладёт пользователя в контекст
+func WithUser(ctx context.Context, u User) context.Context {
+	return context.WithValue(ctx, userKey, u)
+}
+
+// UserFrom достаёт пользователя; ok=false — запрос анонимный или внутренний
+func UserFrom(ctx context.Context) (User, bool) {
+	u, ok := ctx.Value(userKey).(User)
+	return u, ok
+}
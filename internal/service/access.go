@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+
+	"todo/internal/model"
+	"todo/internal/reqctx"
+)
+
+// viewer — чьими глазами смотрим на задачи. ok=false — видно всё:
+// внутренние вызовы без пользователя (консоль, фоновые горутины) и администраторы.
+// Транспорт (web, gRPC) обязан положить пользователя в контекст, иначе это утечка.
+func viewer(ctx context.Context) (model.UserID, bool) {
+	u, ok := reqctx.UserFrom(ctx)
+	if !ok || u.Admin() {
+		return 0, false
+	}
+	return u.ID, true
+}
+
+// canSee — видит ли вызывающий задачу (см. model.Task.VisibleTo)
+func canSee(ctx context.Context, t *model.Task) bool {
+	u, restricted := viewer(ctx)
+	return !restricted || t.VisibleTo(u)
+}
+
+// visibleTask — задача из кэша, если вызывающий её видит.
+// Чужую задачу не отличаем от несуществующей, чтобы не выдавать её ID.
+func (s *Service) visibleTask(ctx context.Context, id model.ID) (*model.Task, error) {
+	s.mu.RLock()
+	var t *model.Task
+	if e, ok := s.tasks[id]; ok {
+		t = e.task
+	}
+	s.mu.RUnlock()
+	if t == nil || !canSee(ctx, t) {
+		return nil, errNotFound(id)
+	}
+	return t, nil
+}
+
+// withVisible — fn под замком задачи, если вызывающий её видит:
+// удаление и перенумерация задачи не разойдутся с тем, что к ней привязано
+// (комментарии, вложения)
+func (s *Service) withVisible(ctx context.Context, id model.ID, fn func() error) error {
+	s.ops.RLock()
+	defer s.ops.RUnlock()
+
+	e, ok := s.lookup(id)
+	if !ok {
+		return errNotFound(id)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.task == nil || !canSee(ctx, e.task) {
+		return errNotFound(id)
+	}
+	return fn()
+}
+
+// authorOrAdmin — может ли вызывающий менять то, что добавил author.
+// Внутренние вызовы без пользователя могут всё.
+func authorOrAdmin(ctx context.Context, author model.UserID) bool {
+	u, ok := reqctx.UserFrom(ctx)
+	return !ok || u.Admin() || u.ID == author
+}
+
+// visibleOnly — из списка задач только те, что видит вызывающий
+func visibleOnly(ctx context.Context, list []*model.Task) []*model.Task {
+	if _, restricted := viewer(ctx); !restricted {
+		return list
+	}
+	out := make([]*model.Task, 0, len(list))
+	for _, t := range list {
+		if canSee(ctx, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// Assign назначает исполнителя, 0 — снять. Что пользователь существует, проверяет транспорт:
+// сервис про учётные записи не знает.
+func (s *Service) Assign(ctx context.Context, id model.ID, user model.UserID) error {
+	return s.update(ctx, id, "assign", func(t *model.Task) error {
+		if t.Assignee() == user {
+			return errNoChange
+		}
+		t.SetAssignee(user)
+		return nil
+	})
+}
@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"todo/internal/model"
+	"todo/internal/policy"
+	"todo/internal/repository"
+)
+
+var (
+	ErrNotClosed          = errors.New("only done or canceled tasks can be archived")
+	ErrArchiveUnsupported = errors.New("archive is not supported by this store")
+)
+
+// exportPage — по сколько задач Export читает из хранилища за раз
+const exportPage = 500
+
+// ArchiveAfterFromEnv — через сколько после выполнения задача сама уезжает в архив,
+// из ARCHIVE_AFTER (time.ParseDuration, "720h"); пусто или "0" — только вручную
+func ArchiveAfterFromEnv() (time.Duration, error) {
+	raw := os.Getenv("ARCHIVE_AFTER")
+	if raw == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("ARCHIVE_AFTER: want a duration like 720h, got %q", raw)
+	}
+	return d, nil
+}
+
+func (s *Service) archiver() (Archiver, error) {
+	a, ok := s.store.(Archiver)
+	if !ok {
+		return nil, ErrArchiveUnsupported
+	}
+	return a, nil
+}
+
+// Archive убирает закрытую задачу в архив вместе с подзадачами — они тоже должны быть закрыты.
+// Из кэша, списков и дерева задача пропадает, но ищется (Search) и выгружается (Export).
+// Зависимым она больше не мешает: пропавшие блокеры не считаются
+func (s *Service) Archive(ctx context.Context, id model.ID) error {
+	a, err := s.archiver()
+	if err != nil {
+		return err
+	}
+	s.ops.Lock()
+	defer s.ops.Unlock()
+
+	e, ok := s.lookup(id)
+	if !ok || e.task == nil || !canSee(ctx, e.task) {
+		return errNotFound(id)
+	}
+	if err := checkVersion(ctx, e.task); err != nil {
+		return err
+	}
+	if e.task.Open() {
+		return fmt.Errorf("%w: task %d is %s", ErrNotClosed, id, e.task.Status())
+	}
+	if n := s.openDescendants(id); n > 0 {
+		return fmt.Errorf("%w: %d still open", ErrOpenSubtasks, n)
+	}
+	return s.archive(ctx, a, append([]*model.Task{e.task}, s.descendants(id)...), time.Now())
+}
+
+// archive — группа задач (родители первыми) уезжает в архив одним шагом хранилища.
+// Вызывать под s.ops.Lock
+func (s *Service) archive(ctx context.Context, a Archiver, group []*model.Task, at time.Time) error {
+	records := make([]model.TaskDTO, 0, len(group))
+	for _, t := range group {
+		c := t.Clone()
+		c.MoveToArchive(at)
+		records = append(records, c.ToDTO())
+	}
+	if err := a.Archive(ctx, records); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			for _, t := range group {
+				if e, ok := s.lookup(t.ID()); ok {
+					s.refresh(ctx, e)
+				}
+			}
+			return fmt.Errorf("%w: task %d or its subtasks were changed by someone else", ErrVersionMismatch, group[0].ID())
+		}
+		return err
+	}
+
+	s.mu.Lock()
+	for _, t := range group {
+		if e, ok := s.tasks[t.ID()]; ok {
+			e.task = nil
+		}
+		delete(s.tasks, t.ID())
+		s.retag(t, nil)
+	}
+	s.mu.Unlock()
+
+	for i, t := range group {
+		before := t.ToDTO()
+		s.logEvent(ctx, "archive", t.ID(), &before, &records[i])
+	}
+	return nil
+}
+
+// Unarchive возвращает задачу из архива к горячим. Подзадачи, уехавшие вместе с ней,
+// остаются в архиве — их возвращают по одной. Если родителя среди горячих нет,
+// задача встаёт на верхний уровень; пропавшие блокеры отбрасываются. Если хранилище
+// откажет на этой чистке, задача уже среди горячих и видна — ошибка только про чистку.
+func (s *Service) Unarchive(ctx context.Context, id model.ID) error {
+	a, err := s.archiver()
+	if err != nil {
+		return err
+	}
+	s.ops.Lock()
+	defer s.ops.Unlock()
+
+	r, err := a.GetArchived(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return errNotFound(id)
+	}
+	if err != nil {
+		return err
+	}
+	old, err := model.FromDTO(r)
+	if err != nil {
+		return err
+	}
+	if !canSee(ctx, old) {
+		return errNotFound(id)
+	}
+	if err := checkVersion(ctx, old); err != nil {
+		return err
+	}
+	if _, err := a.Unarchive(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return fmt.Errorf("%w: task %d was changed by someone else", ErrVersionMismatch, id)
+		}
+		return err
+	}
+
+	// запись уже среди горячих: сразу в кэш, иначе сбой ниже спрячет её до перезапуска
+	t := old.Clone()
+	t.Unarchive()
+	e := &entry{task: t}
+	s.mu.Lock()
+	s.tasks[id] = e
+	s.retag(nil, t)
+	s.mu.Unlock()
+	before, moved := old.ToDTO(), t.ToDTO()
+
+	c := t.Clone()
+	if p := c.ParentID(); p != 0 {
+		if _, ok := s.lookup(p); !ok {
+			c.SetParent(0)
+		}
+	}
+	for _, b := range c.BlockedBy() {
+		if _, ok := s.lookup(b); !ok {
+			c.RemoveBlocker(b)
+		}
+	}
+	if c.Version() != t.Version() {
+		if err := s.store.Update(ctx, c.ToDTO(), t.Version()); err != nil {
+			// из архива задача всё равно вернулась — это и пишем
+			s.logEvent(ctx, "unarchive", id, &before, &moved)
+			if errors.Is(err, repository.ErrConflict) {
+				s.refresh(ctx, e)
+				return fmt.Errorf("%w: task %d was changed by someone else", ErrVersionMismatch, id)
+			}
+			return fmt.Errorf("task %d is back from the archive, but its links were not cleaned: %w", id, err)
+		}
+		s.mu.Lock()
+		s.retag(t, c)
+		e.task = c
+		s.mu.Unlock()
+	}
+
+	after := c.ToDTO()
+	s.logEvent(ctx, "unarchive", id, &before, &after)
+	return nil
+}
+
+// Archived — выборка из архива: фильтры, сортировка и страницы те же, что у List
+func (s *Service) Archived(ctx context.Context, q model.TaskQuery) (model.TaskPage, error) {
+	if _, err := s.archiver(); err != nil {
+		return model.TaskPage{}, err
+	}
+	q.Archived = true
+	return s.List(ctx, q)
+}
+
+// Export отдаёт fn все видимые задачи проекта по порядку ID: сначала горячие, потом архив.
+// Корзина в выгрузку не попадает. Читаем страницами, чтобы не держать архив в памяти целиком
+func (s *Service) Export(ctx context.Context, fn func(t *model.Task) error) error {
+	if err := policy.Check(ctx, policy.Export); err != nil {
+		return err
+	}
+	parts := []bool{false}
+	if _, ok := s.store.(Archiver); ok {
+		parts = append(parts, true)
+	}
+	for _, archived := range parts {
+		q := model.TaskQuery{Archived: archived, Sort: []model.SortKey{{Field: model.SortID}}, Limit: exportPage}
+		for {
+			page, err := s.List(ctx, q)
+			if err != nil {
+				return err
+			}
+			for _, t := range page.Items {
+				if err := fn(t); err != nil {
+					return err
+				}
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			if q.After, err = model.DecodeCursor(page.NextCursor); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ArchiveDone убирает в архив задачи, сделанные раньше before, вместе с поддеревьями.
+// Ветку, где что-то ещё открыто или сделано позже, не трогаем, пока не дозреет вся;
+// отменённые подзадачи дозревать не мешают. Отдаёт, сколько задач уехало.
+// Хранилище без архива — не ошибка: просто нечего делать
+func (s *Service) ArchiveDone(ctx context.Context, before time.Time) (int, error) {
+	a, ok := s.store.(Archiver)
+	if !ok {
+		return 0, nil
+	}
+	s.ops.Lock()
+	defer s.ops.Unlock()
+
+	all := s.snapshot()
+	kids := make(map[model.ID][]*model.Task)
+	for _, t := range all {
+		if t.ParentID() != 0 {
+			kids[t.ParentID()] = append(kids[t.ParentID()], t)
+		}
+	}
+	doneBefore := func(t *model.Task) bool {
+		return t.Status() == model.StatusDone && t.CompletedAt() != nil && t.CompletedAt().Before(before)
+	}
+	settled := make(map[model.ID]bool)
+	var ripe func(t *model.Task) bool
+	ripe = func(t *model.Task) bool {
+		if v, ok := settled[t.ID()]; ok {
+			return v
+		}
+		settled[t.ID()] = false // цикл в битых данных — такую ветку не трогаем
+		ok := doneBefore(t) || t.Status() == model.StatusCanceled
+		for _, k := range kids[t.ID()] {
+			ok = ripe(k) && ok
+		}
+		settled[t.ID()] = ok
+		return ok
+	}
+
+	var roots []*model.Task
+	depth := make(map[model.ID]int)
+	for _, t := range all {
+		if doneBefore(t) && ripe(t) {
+			roots = append(roots, t)
+			depth[t.ID()] = len(s.ancestors(t.ID()))
+		}
+	}
+	// родители раньше: подзадачи уедут вместе с ними, а не поодиночке
+	sort.SliceStable(roots, func(i, j int) bool { return depth[roots[i].ID()] < depth[roots[j].ID()] })
+
+	now, total := time.Now(), 0
+	for _, t := range roots {
+		if _, ok := s.lookup(t.ID()); !ok {
+			continue // уже уехала с родителем
+		}
+		group := append([]*model.Task{t}, s.descendants(t.ID())...)
+		if err := s.archive(ctx, a, group, now); err != nil {
+			return total, err
+		}
+		total += len(group)
+	}
+	return total, nil
+}
+
+// ArchiveDone — то же для всех проектов арендатора
+func (ps *Projects) ArchiveDone(ctx context.Context, before time.Time) (int, error) {
+	return ps.sweep(ctx, func(svc *Service) (int, error) { return svc.ArchiveDone(ctx, before) })
+}
+
+// ArchiveDone — то же для всех арендаторов (см. Tenants.ListFrom)
+func (ts *Tenants) ArchiveDone(ctx context.Context, before time.Time) (int, error) {
+	return ts.sweep(ctx, func(ps *Projects) (int, error) { return ps.ArchiveDone(ctx, before) })
+}
+
+// ArchiveDonePeriodically — фоновая архивация: задачи, сделанные больше after назад,
+// уезжают в архив. Проверяет раз в час (при коротком сроке — чаще), пока не отменят ctx;
+// after 0 — выключено
+func ArchiveDonePeriodically(ctx context.Context, ts *Tenants, after time.Duration) {
+	if after <= 0 {
+		return
+	}
+	ticker := time.NewTicker(min(time.Hour, max(after/4, time.Second)))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			n, err := ts.ArchiveDone(ctx, time.Now().Add(-after))
+			if err != nil {
+				fmt.Println("[архив] ошибка архивации:", err)
+			}
+			if n > 0 && DebugMode {
+				fmt.Printf("[архив] убрано задач: %d\n", n)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"todo/internal/model"
+	"todo/internal/policy"
+	"todo/internal/repository"
+	"todo/internal/reqctx"
+)
+
+var (
+	ErrAttachmentNotFound     = errors.New("attachment not found")
+	ErrBadAttachment          = errors.New("bad attachment")
+	ErrAttachmentTooLarge     = errors.New("attachment too large")
+	ErrAttachmentsUnsupported = errors.New("attachments are not configured")
+)
+
+// WithBlobs — где лежит содержимое вложений. Без него вложения выключены.
+func WithBlobs(b BlobStore) Option {
+	return func(s *Service) { s.blobs = b }
+}
+
+// Attachments — вложения задачи, старые первыми
+func (s *Service) Attachments(ctx context.Context, id model.ID) ([]model.Attachment, error) {
+	a, err := s.attacher()
+	if err != nil {
+		return nil, err
+	}
+	s.ops.RLock()
+	defer s.ops.RUnlock()
+	if _, err := s.visibleTask(ctx, id); err != nil {
+		return nil, err
+	}
+	return a.ListAttachments(ctx, id)
+}
+
+// Attach — файл от имени вызывающего. Содержимое идёт в BlobStore потоком и без замка задачи:
+// долгая загрузка не должна останавливать работу с ней. Описание записываем уже под замком,
+// и если задачу за это время удалили, файл убираем.
+func (s *Service) Attach(ctx context.Context, id model.ID, name, contentType string, r io.Reader) (model.Attachment, error) {
+	a, err := s.attacher()
+	if err != nil {
+		return model.Attachment{}, err
+	}
+	if name, err = model.NormalizeAttachmentName(name); err != nil {
+		return model.Attachment{}, fmt.Errorf("%w: %v", ErrBadAttachment, err)
+	}
+	if contentType, err = model.NormalizeContentType(contentType, name); err != nil {
+		return model.Attachment{}, fmt.Errorf("%w: %v", ErrBadAttachment, err)
+	}
+	s.ops.RLock()
+	_, err = s.visibleTask(ctx, id)
+	s.ops.RUnlock()
+	if err != nil {
+		return model.Attachment{}, err
+	}
+
+	sum := sha256.New()
+	body := &sizeLimit{r: io.TeeReader(r, sum), max: model.MaxAttachmentSize}
+	key := s.blobKey()
+	if err := s.blobs.Put(ctx, key, body); err != nil {
+		return model.Attachment{}, err
+	}
+	u, _ := reqctx.UserFrom(ctx)
+	att := model.Attachment{
+		TaskID:      id,
+		Name:        name,
+		Size:        body.read,
+		ContentType: contentType,
+		Checksum:    hex.EncodeToString(sum.Sum(nil)),
+		BlobKey:     key,
+		UploadedBy:  u.ID,
+		CreatedAt:   time.Now(),
+	}
+	err = s.withVisible(ctx, id, func() error {
+		att.ID, err = a.InsertAttachment(ctx, att)
+		return err
+	})
+	if err != nil {
+		s.dropBlobs(ctx, att)
+		return model.Attachment{}, err
+	}
+	s.logEvent(ctx, "attachment_add", id, nil, nil)
+	return att, nil
+}
+
+// OpenAttachment — описание и содержимое вложения; закрыть содержимое — забота вызывающего
+func (s *Service) OpenAttachment(ctx context.Context, id model.ID, att model.AttachmentID) (model.Attachment, io.ReadCloser, error) {
+	a, err := s.attacher()
+	if err != nil {
+		return model.Attachment{}, nil, err
+	}
+	s.ops.RLock()
+	m, err := s.taskAttachment(ctx, a, id, att)
+	s.ops.RUnlock()
+	if err != nil {
+		return model.Attachment{}, nil, err
+	}
+	rc, err := s.blobs.Open(ctx, m.BlobKey)
+	if errors.Is(err, repository.ErrNotFound) {
+		return model.Attachment{}, nil, fmt.Errorf("%w: %d: file is gone", ErrAttachmentNotFound, att)
+	}
+	if err != nil {
+		return model.Attachment{}, nil, err
+	}
+	return m, rc, nil
+}
+
+// DeleteAttachment — удалить может только загрузивший (администратор — любое)
+func (s *Service) DeleteAttachment(ctx context.Context, id model.ID, att model.AttachmentID) error {
+	a, err := s.attacher()
+	if err != nil {
+		return err
+	}
+	var m model.Attachment
+	err = s.withVisible(ctx, id, func() error {
+		if m, err = s.taskAttachment(ctx, a, id, att); err != nil {
+			return err
+		}
+		if !authorOrAdmin(ctx, m.UploadedBy) {
+			return fmt.Errorf("%w: not the uploader of attachment %d", policy.ErrForbidden, att)
+		}
+		return a.DeleteAttachment(ctx, att)
+	})
+	if err != nil {
+		return err
+	}
+	s.dropBlobs(ctx, m)
+	s.logEvent(ctx, "attachment_delete", id, nil, nil)
+	return nil
+}
+
+func (s *Service) attacher() (Attacher, error) {
+	a, ok := s.store.(Attacher)
+	if !ok || s.blobs == nil {
+		return nil, ErrAttachmentsUnsupported
+	}
+	return a, nil
+}
+
+// taskAttachment — вложение задачи id, которую видит вызывающий; вызывать под s.ops.
+// Вложение другой задачи — такое же «не найдено», как и несуществующее.
+func (s *Service) taskAttachment(ctx context.Context, a Attacher, id model.ID, att model.AttachmentID) (model.Attachment, error) {
+	if _, err := s.visibleTask(ctx, id); err != nil {
+		return model.Attachment{}, err
+	}
+	m, err := a.GetAttachment(ctx, att)
+	if errors.Is(err, repository.ErrNotFound) || err == nil && m.TaskID != id {
+		return model.Attachment{}, fmt.Errorf("%w: %d", ErrAttachmentNotFound, att)
+	}
+	return m, err
+}
+
+// blobKey — случайный ключ: номер задачи в нём не участвует, так что перенумерация
+// файлы не трогает. Арендатор и проект — только чтобы в хранилище было где разобраться.
+func (s *Service) blobKey() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return fmt.Sprintf("%s/%d/%s", s.tenant.OrDefault(), s.project, hex.EncodeToString(b[:]))
+}
+
+// dropBlobs — убрать файлы уже удалённых вложений. Описаний больше нет, так что ошибку
+// клиенту не отдаём: осиротевший файл никому не мешает, а ответ об ошибке сбил бы с толку.
+func (s *Service) dropBlobs(ctx context.Context, list ...model.Attachment) {
+	if s.blobs == nil {
+		return
+	}
+	for _, a := range list {
+		_ = s.blobs.Delete(context.WithoutCancel(ctx), a.BlobKey)
+	}
+}
+
+// sizeLimit — обрывает чтение ошибкой, как только файл перерос предел
+type sizeLimit struct {
+	r         io.Reader
+	read, max int64
+}
+
+func (l *sizeLimit) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	if l.read > l.max {
+		return n, fmt.Errorf("%w: over %d bytes", ErrAttachmentTooLarge, l.max)
+	}
+	return n, err
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"todo/internal/model"
+)
+
+var (
+	ErrChecklistItemNotFound = errors.New("checklist item not found")
+	ErrBadChecklistItem      = errors.New("bad checklist item")
+)
+
+// AddChecklistItem — пункт в конец чек-листа задачи
+func (s *Service) AddChecklistItem(ctx context.Context, id model.ID, text string) (model.ChecklistItem, error) {
+	var it model.ChecklistItem
+	err := s.update(ctx, id, "checklist_add", func(t *model.Task) (err error) {
+		if it, err = t.AddChecklistItem(text); err != nil {
+			return fmt.Errorf("%w: %v", ErrBadChecklistItem, err)
+		}
+		return nil
+	})
+	return it, err
+}
+
+// ToggleChecklistItem — отметить пункт или снять отметку
+func (s *Service) ToggleChecklistItem(ctx context.Context, id model.ID, item int) (model.ChecklistItem, error) {
+	var it model.ChecklistItem
+	err := s.update(ctx, id, "checklist_toggle", func(t *model.Task) error {
+		var ok bool
+		if it, ok = t.ToggleChecklistItem(item); !ok {
+			return fmt.Errorf("%w: %d", ErrChecklistItemNotFound, item)
+		}
+		return nil
+	})
+	return it, err
+}
+
+// MoveChecklistItem — переставить пункт на место pos (с нуля); если он уже там — ничего не делаем
+func (s *Service) MoveChecklistItem(ctx context.Context, id model.ID, item, pos int) error {
+	return s.update(ctx, id, "checklist_move", func(t *model.Task) error {
+		found, moved := t.MoveChecklistItem(item, pos)
+		switch {
+		case !found:
+			return fmt.Errorf("%w: %d", ErrChecklistItemNotFound, item)
+		case !moved:
+			return errNoChange
+		}
+		return nil
+	})
+}
+
+// RemoveChecklistItem — убрать пункт
+func (s *Service) RemoveChecklistItem(ctx context.Context, id model.ID, item int) error {
+	return s.update(ctx, id, "checklist_remove", func(t *model.Task) error {
+		if !t.RemoveChecklistItem(item) {
+			return fmt.Errorf("%w: %d", ErrChecklistItemNotFound, item)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"todo/internal/model"
+	"todo/internal/policy"
+	"todo/internal/repository"
+	"todo/internal/reqctx"
+)
+
+var (
+	ErrCommentNotFound     = errors.New("comment not found")
+	ErrBadComment          = errors.New("bad comment")
+	ErrCommentsUnsupported = errors.New("comments are not supported by this store")
+)
+
+// Comments — обсуждение задачи, старые комментарии первыми.
+// Кто не видит задачу, не видит и комментариев к ней.
+func (s *Service) Comments(ctx context.Context, id model.ID) ([]model.Comment, error) {
+	c, err := s.commenter()
+	if err != nil {
+		return nil, err
+	}
+	s.ops.RLock()
+	defer s.ops.RUnlock()
+	if _, err := s.visibleTask(ctx, id); err != nil {
+		return nil, err
+	}
+	return c.ListComments(ctx, id)
+}
+
+// AddComment — комментарий от имени вызывающего
+func (s *Service) AddComment(ctx context.Context, id model.ID, body string) (model.Comment, error) {
+	c, err := s.commenter()
+	if err != nil {
+		return model.Comment{}, err
+	}
+	body, err = model.NormalizeCommentBody(body)
+	if err != nil {
+		return model.Comment{}, fmt.Errorf("%w: %v", ErrBadComment, err)
+	}
+	u, _ := reqctx.UserFrom(ctx)
+	cm := model.Comment{TaskID: id, Author: u.ID, Body: body, CreatedAt: time.Now()}
+	err = s.withVisible(ctx, id, func() error {
+		cm.ID, err = c.InsertComment(ctx, cm)
+		return err
+	})
+	if err != nil {
+		return model.Comment{}, err
+	}
+	s.logEvent(ctx, "comment_add", id, nil, nil)
+	return cm, nil
+}
+
+// EditComment — правка текста; править можно только свой комментарий (администратор — любой)
+func (s *Service) EditComment(ctx context.Context, id model.ID, comment model.CommentID, body string) (model.Comment, error) {
+	c, err := s.commenter()
+	if err != nil {
+		return model.Comment{}, err
+	}
+	body, err = model.NormalizeCommentBody(body)
+	if err != nil {
+		return model.Comment{}, fmt.Errorf("%w: %v", ErrBadComment, err)
+	}
+	var cm model.Comment
+	err = s.withVisible(ctx, id, func() error {
+		if cm, err = s.ownComment(ctx, c, id, comment); err != nil || cm.Body == body {
+			return err
+		}
+		now := time.Now()
+		cm.Body, cm.EditedAt = body, &now
+		return c.UpdateComment(ctx, cm)
+	})
+	if err != nil {
+		return model.Comment{}, err
+	}
+	s.logEvent(ctx, "comment_edit", id, nil, nil)
+	return cm, nil
+}
+
+// DeleteComment — удалить можно только свой комментарий (администратор — любой)
+func (s *Service) DeleteComment(ctx context.Context, id model.ID, comment model.CommentID) error {
+	c, err := s.commenter()
+	if err != nil {
+		return err
+	}
+	err = s.withVisible(ctx, id, func() error {
+		if _, err := s.ownComment(ctx, c, id, comment); err != nil {
+			return err
+		}
+		return c.DeleteComment(ctx, comment)
+	})
+	if err != nil {
+		return err
+	}
+	s.logEvent(ctx, "comment_delete", id, nil, nil)
+	return nil
+}
+
+func (s *Service) commenter() (Commenter, error) {
+	c, ok := s.store.(Commenter)
+	if !ok {
+		return nil, ErrCommentsUnsupported
+	}
+	return c, nil
+}
+
+// ownComment — комментарий задачи id, который вызывающему можно менять.
+// Комментарий другой задачи — такой же «не найден», как и несуществующий.
+func (s *Service) ownComment(ctx context.Context, c Commenter, id model.ID, comment model.CommentID) (model.Comment, error) {
+	cm, err := c.GetComment(ctx, comment)
+	if errors.Is(err, repository.ErrNotFound) || err == nil && cm.TaskID != id {
+		return model.Comment{}, fmt.Errorf("%w: %d", ErrCommentNotFound, comment)
+	}
+	if err != nil {
+		return model.Comment{}, err
+	}
+	if !authorOrAdmin(ctx, cm.Author) {
+		return model.Comment{}, fmt.Errorf("%w: not the author of comment %d", policy.ErrForbidden, comment)
+	}
+	return cm, nil
+}
@@ -0,0 +1,190 @@
+package service_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"todo/internal/model"
+)
+
+// Гоняем все операции сразу из множества горутин; смысл теста — в запуске с -race.
+func TestConcurrentMutationsAndReads(t *testing.T) {
+	svc, fs := mustNewService(t, nil)
+
+	const workers = 16
+	const perWorker = 50
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				id, err := svc.Add(ctx, fmt.Sprintf("w%d-%d", w, i), "", model.PriorityMedium, nil)
+				if err != nil {
+					t.Errorf("Add: %v", err)
+					return
+				}
+				_ = svc.SetStatus(ctx, id, model.StatusInProgress)
+				_ = svc.SetPriority(ctx, id, model.PriorityHigh)
+				_ = svc.UpdateTitle(ctx, id, fmt.Sprintf("w%d-%d!", w, i))
+				if i%3 == 0 {
+					if err := svc.Delete(ctx, id); err != nil {
+						t.Errorf("Delete: %v", err)
+					}
+				}
+			}
+		}(w)
+	}
+
+	// читатели параллельно с писателями
+	stop := make(chan struct{})
+	var readers sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				page, err := svc.List(ctx, model.TaskQuery{})
+				if err != nil {
+					t.Errorf("List: %v", err)
+					return
+				}
+				for _, tk := range page.Items {
+					_ = tk.Title()
+					_ = tk.Status()
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(stop)
+	readers.Wait()
+
+	want := workers * (perWorker - (perWorker+2)/3)
+	list := listAll(t, svc)
+	if len(list) != want {
+		t.Fatalf("expected %d tasks, got %d", want, len(list))
+	}
+	if n := fs.live(); n != want {
+		t.Fatalf("store has %d tasks, expected %d", n, want)
+	}
+	seen := make(map[model.ID]bool, len(list))
+	for _, tk := range list {
+		if seen[tk.ID()] {
+			t.Fatalf("duplicate id %d", tk.ID())
+		}
+		seen[tk.ID()] = true
+		r, ok := fs.items[tk.ID()]
+		if !ok || r.Status != tk.Status() || r.Title != tk.Title() {
+			t.Fatalf("cache and store disagree on %d: %+v vs %+v", tk.ID(), r, tk.ToDTO())
+		}
+		if tk.Status() != model.StatusInProgress || tk.Priority() != model.PriorityHigh {
+			t.Fatalf("lost update on %d: %+v", tk.ID(), tk.ToDTO())
+		}
+	}
+}
+
+// Изменения одной и той же задачи не теряются: каждое применяется к свежей версии.
+func TestConcurrentUpdatesSameTask(t *testing.T) {
+	svc, fs := mustNewService(t, nil)
+	id, _ := svc.Add(ctx, "A", "", model.PriorityLow, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			_ = svc.UpdateDesc(ctx, id, fmt.Sprintf("d%d", i))
+		}(i)
+		go func() {
+			defer wg.Done()
+			_ = svc.SetPriority(ctx, id, model.PriorityHigh)
+		}()
+	}
+	wg.Wait()
+
+	if fs.updates != 100 {
+		t.Fatalf("expected 100 updates, got %d", fs.updates)
+	}
+	got := findTaskByID(listAll(t, svc), id)
+	if got.Priority() != model.PriorityHigh || got.Description() == "" {
+		t.Fatalf("lost update: %+v", got.ToDTO())
+	}
+}
+
+// Перенумерация посреди нагрузки не ломает кэш: ID остаются уникальными.
+func TestConcurrentRenumber(t *testing.T) {
+	svc, fs := mustNewService(t, nil)
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				id, _ := svc.Add(ctx, fmt.Sprintf("w%d-%d", w, i), "", model.PriorityLow, nil)
+				_ = svc.SetStatus(ctx, id, model.StatusPaused)
+				if i%5 == 0 {
+					_ = svc.RenumberIDs(ctx)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	list := listAll(t, svc)
+	if len(list) != 160 || len(fs.items) != 160 {
+		t.Fatalf("expected 160 tasks, got %d (store %d)", len(list), len(fs.items))
+	}
+	for _, tk := range list {
+		if _, ok := fs.items[tk.ID()]; !ok {
+			t.Fatalf("task %d missing in store", tk.ID())
+		}
+	}
+}
+
+// Подзадачи добавляются, пока родителей удаляют: сирот в кэше и хранилище не остаётся.
+func TestConcurrentSubtasksAndDeletes(t *testing.T) {
+	svc, fs := mustNewService(t, nil)
+	var parents []model.ID
+	for i := 0; i < 20; i++ {
+		id, _ := svc.Add(ctx, fmt.Sprintf("p%d", i), "", model.PriorityLow, nil)
+		parents = append(parents, id)
+	}
+
+	var wg sync.WaitGroup
+	for _, p := range parents {
+		wg.Add(2)
+		go func(p model.ID) {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				_, _ = svc.AddSubtask(ctx, p, "c", "", model.PriorityLow, nil)
+			}
+		}(p)
+		go func(p model.ID) {
+			defer wg.Done()
+			_ = svc.Delete(ctx, p)
+		}(p)
+	}
+	wg.Wait()
+
+	for _, tk := range listAll(t, svc) {
+		if tk.ParentID() == 0 {
+			continue
+		}
+		if _, err := svc.Get(ctx, tk.ParentID()); err != nil {
+			t.Fatalf("task %d points to deleted parent %d", tk.ID(), tk.ParentID())
+		}
+		if fs.items[tk.ID()].ParentID != tk.ParentID() {
+			t.Fatalf("store and cache disagree on parent of %d", tk.ID())
+		}
+	}
+}
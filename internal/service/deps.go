@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"todo/internal/model"
+)
+
+var (
+	ErrBlocked         = errors.New("task is blocked by unfinished tasks")
+	ErrDependencyCycle = errors.New("dependency would create a cycle")
+)
+
+// AddDependency — задачу id нельзя начать, пока не сделана blocker.
+// Цикл проверяем по всему графу, поэтому идём монопольно.
+func (s *Service) AddDependency(ctx context.Context, id, blocker model.ID) error {
+	s.ops.Lock()
+	defer s.ops.Unlock()
+
+	e, ok := s.lookup(id)
+	if !ok || e.task == nil || !canSee(ctx, e.task) {
+		return errNotFound(id)
+	}
+	if _, err := s.visibleTask(ctx, blocker); err != nil {
+		return err
+	}
+	if id == blocker || s.dependsOn(blocker, id) {
+		return fmt.Errorf("%w: %d -> %d", ErrDependencyCycle, blocker, id)
+	}
+	if e.task.DependsOn(blocker) {
+		return nil
+	}
+	return s.apply(ctx, e, "add_dependency", func(t *model.Task) error {
+		t.AddBlocker(blocker)
+		return nil
+	})
+}
+
+// RemoveDependency снимает блокировку; если её не было — ничего не делаем
+func (s *Service) RemoveDependency(ctx context.Context, id, blocker model.ID) error {
+	s.ops.Lock()
+	defer s.ops.Unlock()
+
+	e, ok := s.lookup(id)
+	if !ok || e.task == nil || !canSee(ctx, e.task) {
+		return errNotFound(id)
+	}
+	if !e.task.DependsOn(blocker) {
+		return nil
+	}
+	return s.apply(ctx, e, "remove_dependency", func(t *model.Task) error {
+		t.RemoveBlocker(blocker)
+		return nil
+	})
+}
+
+// Ready — задачи, которые можно брать в работу: new или paused, все блокеры закрыты
+func (s *Service) Ready(ctx context.Context) ([]*model.Task, error) {
+	var out []*model.Task
+	for _, t := range visibleOnly(ctx, s.snapshot()) {
+		st := t.Status()
+		if (st == model.StatusNew || st == model.StatusPaused) && len(s.unfinishedBlockers(t)) == 0 {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// TopoOrder — открытые задачи в порядке выполнения: блокеры раньше зависимых,
+// из доступных сначала важные, потом старые. Закрытые (сделанные и отменённые) блокеры не мешают.
+func (s *Service) TopoOrder(ctx context.Context) ([]*model.Task, error) {
+	var open []*model.Task
+	for _, t := range visibleOnly(ctx, s.snapshot()) {
+		if t.Open() {
+			open = append(open, t)
+		}
+	}
+	byID := make(map[model.ID]*model.Task, len(open))
+	for _, t := range open {
+		byID[t.ID()] = t
+	}
+	waits := make(map[model.ID]int, len(open)) // сколько открытых блокеров ждёт задача
+	next := make(map[model.ID][]model.ID)      // блокер → кого он держит
+	for _, t := range open {
+		for _, b := range t.BlockedBy() {
+			if _, ok := byID[b]; ok {
+				waits[t.ID()]++
+				next[b] = append(next[b], t.ID())
+			}
+		}
+	}
+
+	var avail, out []*model.Task
+	for _, t := range open {
+		if waits[t.ID()] == 0 {
+			avail = append(avail, t)
+		}
+	}
+	for len(avail) > 0 {
+		sort.SliceStable(avail, func(i, j int) bool {
+			if avail[i].Priority() != avail[j].Priority() {
+				return avail[i].Priority() > avail[j].Priority()
+			}
+			return avail[i].CreatedAt().Before(avail[j].CreatedAt())
+		})
+		t := avail[0]
+		avail = avail[1:]
+		out = append(out, t)
+		for _, d := range next[t.ID()] {
+			if waits[d]--; waits[d] == 0 {
+				avail = append(avail, byID[d])
+			}
+		}
+	}
+	// цикл возможен только в битых данных — такие задачи просто в конец
+	if len(out) < len(open) {
+		for _, t := range open {
+			if waits[t.ID()] > 0 {
+				out = append(out, t)
+			}
+		}
+	}
+	return out, nil
+}
+
+// unfinishedBlockers — блокеры задачи, которые ещё открыты. Отменённый блокер закрыт так же,
+// как сделанный, — как в TopoOrder и в архиве; удалённые не считаются
+func (s *Service) unfinishedBlockers(t *model.Task) []model.ID {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []model.ID
+	for _, b := range t.BlockedBy() {
+		if be, ok := s.tasks[b]; ok && be.task != nil && be.task.Open() {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// dependsOn — зависит ли from от to через цепочку блокеров
+func (s *Service) dependsOn(from, to model.ID) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	seen := map[model.ID]bool{from: true}
+	stack := []model.ID{from}
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		e, ok := s.tasks[cur]
+		if !ok || e.task == nil {
+			continue
+		}
+		for _, b := range e.task.BlockedBy() {
+			if b == to {
+				return true
+			}
+			if !seen[b] {
+				seen[b] = true
+				stack = append(stack, b)
+			}
+		}
+	}
+	return false
+}
+
+// dependents — задачи, которые ждут id
+func (s *Service) dependents(id model.ID) []*model.Task {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []*model.Task
+	for _, e := range s.tasks {
+		if e.task != nil && e.task.DependsOn(id) {
+			out = append(out, e.task)
+		}
+	}
+	return out
+}
+
+// unblockDependents — убирает удаляемую задачу из блокеров и отдаёт, у кого убрала
+// (их вернёт Restore); вызывать под s.ops.Lock
+func (s *Service) unblockDependents(ctx context.Context, id model.ID) ([]model.ID, error) {
+	var unblocked []model.ID
+	for _, d := range s.dependents(id) {
+		de, ok := s.lookup(d.ID())
+		if !ok {
+			continue
+		}
+		if err := s.apply(ctx, de, "remove_dependency", func(t *model.Task) error {
+			t.RemoveBlocker(id)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+		unblocked = append(unblocked, d.ID())
+	}
+	return unblocked, nil
+}
@@ -1,12 +1,13 @@
 package service
 
 import (
-	"todo/internal/model"
 	"context"
-	"todo/internal/repository"
 	"fmt"
 	"math/rand"
 	"time"
+
+	"todo/internal/model"
+	"todo/internal/repository"
 )
 
 var PrintLogs = true // можно включать/выключать подробный вывод
@@ -96,9 +97,9 @@ func LogTaskAdditions(interval time.Duration, ctx context.Context) {
     for {
         select {
         case <-ticker.C:
-            curLow := len(repository.LowPriorityTasks)
-            curMed := len(repository.MediumPriorityTasks)
-            curHigh := len(repository.HighPriorityTasks)
+            curLow := len(repository.Distributed(model.PriorityLow))
+            curMed := len(repository.Distributed(model.PriorityMedium))
+            curHigh := len(repository.Distributed(model.PriorityHigh))
 
             if !DebugMode {
                 //просто обновляем счётчики без вывода
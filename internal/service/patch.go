@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"todo/internal/model"
+)
+
+// ErrBadPatch — в патче недопустимое значение (пустой заголовок, неизвестный статус...)
+var ErrBadPatch = errors.New("bad patch")
+
+// Patch меняет несколько полей задачи атомарно: либо все, либо ни одного.
+// Одна запись в хранилище и одно событие аудита "patch" с задачей до и после.
+func (s *Service) Patch(ctx context.Context, id model.ID, p model.TaskPatch) error {
+	if err := p.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrBadPatch, err)
+	}
+	if p.Empty() {
+		_, err := s.visibleTask(ctx, id)
+		return err
+	}
+	next, err := s.patch(ctx, id, p)
+	return s.recur(ctx, id, next, err)
+}
+
+// patch — сам патч под замками. Перенос под другого родителя меняет дерево,
+// поэтому с ParentID, как и SetParent, берём s.ops монопольно
+func (s *Service) patch(ctx context.Context, id model.ID, p model.TaskPatch) (next *model.Task, err error) {
+	if p.ParentID != nil {
+		s.ops.Lock()
+		defer s.ops.Unlock()
+	} else {
+		s.ops.RLock()
+		defer s.ops.RUnlock()
+	}
+
+	e, ok := s.lookup(id)
+	if !ok {
+		return nil, errNotFound(id)
+	}
+	if p.ParentID == nil {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+	}
+	if e.task == nil || !canSee(ctx, e.task) {
+		return nil, errNotFound(id)
+	}
+	if p.ParentID != nil && *p.ParentID != e.task.ParentID() {
+		if err := s.checkParent(ctx, id, *p.ParentID); err != nil {
+			return nil, err
+		}
+	}
+	err = s.apply(ctx, e, "patch", func(t *model.Task) error {
+		if p.Title != nil {
+			if err := t.SetTitle(*p.Title); err != nil {
+				return fmt.Errorf("%w: %v", ErrBadPatch, err)
+			}
+		}
+		if p.Description != nil {
+			t.SetDescription(*p.Description)
+		}
+		if p.Priority != nil {
+			if err := t.SetPriority(*p.Priority); err != nil {
+				return fmt.Errorf("%w: %v", ErrBadPatch, err)
+			}
+		}
+		if p.ParentID != nil && *p.ParentID != t.ParentID() {
+			t.SetParent(*p.ParentID)
+		}
+		switch {
+		case p.DueAt != nil:
+			t.SetDueAt(*p.DueAt)
+		case p.ClearDue:
+			t.ClearDue()
+		}
+		// после срока: месячное правило запоминает число из него
+		switch {
+		case p.Recurrence != nil:
+			if err := t.SetRecurrence(p.Recurrence); err != nil {
+				return fmt.Errorf("%w: %v", ErrBadPatch, err)
+			}
+		case p.ClearRecurrence:
+			_ = t.SetRecurrence(nil)
+		}
+		if p.Assignee != nil {
+			t.SetAssignee(*p.Assignee)
+		}
+		// статус последним: закрытие повторяющейся задачи видит уже новые срок и правило
+		if p.Status != nil {
+			var err error
+			next, err = s.changeStatus(t, *p.Status)
+			return err
+		}
+		return nil
+	})
+	return next, err
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"todo/internal/model"
@@ -9,32 +10,168 @@ import (
 
 // Store — абстракция хранилища для задач.
 // Специально оставляем DTO, чтобы адаптер JSON был тонким.
+// Операции поштучные: изменение одной задачи трогает только её запись.
+// Контекст приходит от запроса: отмена и дедлайн прерывают работу с базой.
+// Update условный: пишет, только если в хранилище лежит версия version,
+// иначе repository.ErrConflict. Проверка и запись — одна атомарная операция.
+// Renumber — исключение из поштучности: все задачи проекта (с корзиной и архивом) разом
+// переезжают на номера remap вместе с родителями, блокерами, комментариями и вложениями,
+// и либо целиком, либо никак.
 type Store interface {
-	Load() ([]model.TaskDTO, error)
-	Save([]model.TaskDTO) error
+	Get(ctx context.Context, id model.ID) (model.TaskDTO, error)
+	Insert(ctx context.Context, t model.TaskDTO) error
+	Update(ctx context.Context, t model.TaskDTO, version int64) error
+	Delete(ctx context.Context, id model.ID) error
+	Query(ctx context.Context, q model.TaskQuery) ([]model.TaskDTO, error)
+	Renumber(ctx context.Context, remap map[model.ID]model.ID) error
 }
 
-// TaskUseCase — контракт бизнес-логики для веба/гRPC.
+// Searcher — полнотекстовый поиск. Необязательная часть Store:
+// каждое хранилище ищет своими средствами (tsvector, text index, индекс в памяти)
+// и само отсекает удалённое и невидимое до лимита (см. model.SearchQuery).
+type Searcher interface {
+	Search(ctx context.Context, q model.SearchQuery) ([]model.SearchHit, error)
+}
+
+// Commenter — комментарии к задачам. Необязательная часть Store, как и Searcher:
+// комментарии лежат рядом с задачами проекта, в том же хранилище. ID выдаёт хранилище.
+type Commenter interface {
+	ListComments(ctx context.Context, task model.ID) ([]model.Comment, error) // старые первыми
+	GetComment(ctx context.Context, id model.CommentID) (model.Comment, error)
+	InsertComment(ctx context.Context, c model.Comment) (model.CommentID, error)
+	UpdateComment(ctx context.Context, c model.Comment) error // текст и время правки
+	DeleteComment(ctx context.Context, id model.CommentID) error
+	// DeleteTaskComments — вслед за удалением задачи; при перенумерации комментарии переносит Store.Renumber
+	DeleteTaskComments(ctx context.Context, task model.ID) error
+}
+
+// Attacher — описания вложений. Необязательная часть Store, как и Commenter;
+// само содержимое файлов лежит в BlobStore.
+type Attacher interface {
+	ListAttachments(ctx context.Context, task model.ID) ([]model.Attachment, error) // старые первыми
+	GetAttachment(ctx context.Context, id model.AttachmentID) (model.Attachment, error)
+	InsertAttachment(ctx context.Context, a model.Attachment) (model.AttachmentID, error)
+	DeleteAttachment(ctx context.Context, id model.AttachmentID) error
+	// DeleteTaskAttachments отдаёт удалённые описания: по ним сервис убирает файлы
+	DeleteTaskAttachments(ctx context.Context, task model.ID) ([]model.Attachment, error)
+}
+
+// Archiver — холодный архив закрытых задач. Необязательная часть Store, как и Searcher:
+// архив лежит отдельно от горячих задач (таблица или коллекция tasks_archive, файл tasks_archive.json),
+// и при загрузке сервис его не читает. Выборка из архива — Query с TaskQuery.Archived.
+type Archiver interface {
+	// Archive переносит задачи из горячих в архив одним шагом. ArchivedAt уже проставлен,
+	// версия — та, что лежит среди горячих; не совпала — repository.ErrConflict
+	Archive(ctx context.Context, tasks []model.TaskDTO) error
+	// Unarchive — обратно к горячим; отдаёт вернувшуюся запись
+	Unarchive(ctx context.Context, id model.ID) (model.TaskDTO, error)
+	GetArchived(ctx context.Context, id model.ID) (model.TaskDTO, error)
+	SearchArchive(ctx context.Context, q model.SearchQuery) ([]model.SearchHit, error)
+}
+
+// BlobStore — содержимое вложений (локальный диск, S3). Ключи выдаёт сервис,
+// хранилище их не разбирает. Put пишет всё или ничего.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error) // нет файла — repository.ErrNotFound
+	Delete(ctx context.Context, key string) error                // нет файла — не ошибка
+}
+
+// ProjectStore — хранилище проектов и их участников.
+// ID выдаёт само хранилище; имя уникально (дубль — repository.ErrDuplicate).
+type ProjectStore interface {
+	GetProject(ctx context.Context, id model.ProjectID) (model.Project, error)
+	ListProjects(ctx context.Context) ([]model.Project, error)
+	InsertProject(ctx context.Context, p model.Project) (model.ProjectID, error)
+	UpdateProject(ctx context.Context, p model.Project) error // имя и участники
+}
+
+// TaskUseCase — контракт бизнес-логики для веба/гRPC: задачи одного проекта (см. Projects).
 type TaskUseCase interface {
-	Add(title, desc string, p model.Priority, due *time.Time) (model.ID, error)
-	RenumberIDs() error
-	List(filter *model.Status) []*model.Task
-	UpdateTitle(id model.ID, title string) error
-	UpdateDesc(id model.ID, desc string) error
-	SetStatus(id model.ID, st model.Status) error
-	SetPriority(id model.ID, p model.Priority) error
-	SetDue(id model.ID, due time.Time) error
-	ClearDue(id model.ID) error
-	Delete(id model.ID) error
+	Add(ctx context.Context, title, desc string, p model.Priority, due *time.Time) (model.ID, error)
+	Create(ctx context.Context, d model.TaskDraft) (model.ID, error) // со всеми полями разом, атомарно
+	RenumberIDs(ctx context.Context) error
+	List(ctx context.Context, q model.TaskQuery) (model.TaskPage, error)
+	Get(ctx context.Context, id model.ID) (*model.Task, error)
+	Search(ctx context.Context, query string, limit int) ([]model.SearchHit, error)
+	UpdateTitle(ctx context.Context, id model.ID, title string) error
+	UpdateDesc(ctx context.Context, id model.ID, desc string) error
+	SetStatus(ctx context.Context, id model.ID, st model.Status) error
+	SetPriority(ctx context.Context, id model.ID, p model.Priority) error
+	SetDue(ctx context.Context, id model.ID, due time.Time) error
+	ClearDue(ctx context.Context, id model.ID) error
+	SetRecurrence(ctx context.Context, id model.ID, r *model.Recurrence) error
+	Patch(ctx context.Context, id model.ID, p model.TaskPatch) error // несколько полей разом, атомарно
+	Delete(ctx context.Context, id model.ID) error                   // в корзину
+
+	// Корзина
+	Trash(ctx context.Context) ([]*model.Task, error)
+	Restore(ctx context.Context, id model.ID) error
+
+	// Архив закрытых задач
+	Archive(ctx context.Context, id model.ID) error
+	Unarchive(ctx context.Context, id model.ID) error
+	Archived(ctx context.Context, q model.TaskQuery) (model.TaskPage, error)
+	Export(ctx context.Context, fn func(t *model.Task) error) error // горячие, потом архив
+
+	// Отмена и повтор своих операций
+	Undo(ctx context.Context, n int) ([]UndoStep, error)
+	Redo(ctx context.Context, n int) ([]UndoStep, error)
+
+	// Подзадачи и дерево
+	AddSubtask(ctx context.Context, parent model.ID, title, desc string, p model.Priority, due *time.Time) (model.ID, error)
+	SetParent(ctx context.Context, id, parent model.ID) error
+	DeleteTree(ctx context.Context, id model.ID) error
+	Children(ctx context.Context, id model.ID) ([]*model.Task, error)
+	Ancestors(ctx context.Context, id model.ID) ([]*model.Task, error)
+	Progress(ctx context.Context, id model.ID) (model.Progress, error)
+
+	// Зависимости между задачами
+	AddDependency(ctx context.Context, id, blocker model.ID) error
+	RemoveDependency(ctx context.Context, id, blocker model.ID) error
+	Ready(ctx context.Context) ([]*model.Task, error)
+	TopoOrder(ctx context.Context) ([]*model.Task, error)
+
+	// Теги
+	AddTag(ctx context.Context, id model.ID, tag string) error
+	RemoveTag(ctx context.Context, id model.ID, tag string) error
+	TagCounts(ctx context.Context) ([]model.TagCount, error)
+
+	// Исполнитель
+	Assign(ctx context.Context, id model.ID, user model.UserID) error
+
+	// Комментарии
+	Comments(ctx context.Context, id model.ID) ([]model.Comment, error)
+	AddComment(ctx context.Context, id model.ID, body string) (model.Comment, error)
+	EditComment(ctx context.Context, id model.ID, comment model.CommentID, body string) (model.Comment, error)
+	DeleteComment(ctx context.Context, id model.ID, comment model.CommentID) error
+
+	// Чек-лист
+	AddChecklistItem(ctx context.Context, id model.ID, text string) (model.ChecklistItem, error)
+	ToggleChecklistItem(ctx context.Context, id model.ID, item int) (model.ChecklistItem, error)
+	MoveChecklistItem(ctx context.Context, id model.ID, item, pos int) error
+	RemoveChecklistItem(ctx context.Context, id model.ID, item int) error
+
+	// Вложения
+	Attachments(ctx context.Context, id model.ID) ([]model.Attachment, error)
+	Attach(ctx context.Context, id model.ID, name, contentType string, r io.Reader) (model.Attachment, error)
+	OpenAttachment(ctx context.Context, id model.ID, att model.AttachmentID) (model.Attachment, io.ReadCloser, error)
+	DeleteAttachment(ctx context.Context, id model.ID, att model.AttachmentID) error
 }
 
 // Событие аудита для Redis
 type Event struct {
-	Op     string         `json:"op"`
-	TaskID model.ID       `json:"task_id,omitempty"`
-	At     time.Time      `json:"at"`
-	Before *model.TaskDTO `json:"before,omitempty"`
-	After  *model.TaskDTO `json:"after,omitempty"`
+	Op      string          `json:"op"`
+	Tenant  model.TenantID  `json:"tenant,omitempty"`
+	Project model.ProjectID `json:"project,omitempty"`
+	TaskID  model.ID        `json:"task_id,omitempty"`
+	At      time.Time       `json:"at"`
+	TraceID string          `json:"trace_id,omitempty"`
+	User    string          `json:"user,omitempty"`
+	Before  *model.TaskDTO  `json:"before,omitempty"`
+	After   *model.TaskDTO  `json:"after,omitempty"`
+	// Remap — у перенумерации: старый номер -> новый
+	Remap map[model.ID]model.ID `json:"remap,omitempty"`
 }
 
 type AuditLogger interface {
@@ -42,4 +179,4 @@ type AuditLogger interface {
 }
 
 // Глобально настраиваемый логгер (опционально)
-var Logger AuditLogger
\ No newline at end of file
+var Logger AuditLogger
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"todo/internal/model"
+	"todo/internal/policy"
+	"todo/internal/repository"
+	"todo/internal/reqctx"
+)
+
+var (
+	ErrProjectNotFound = errors.New("project not found")
+	ErrProjectExists   = errors.New("project name already taken")
+	ErrBadProjectName  = errors.New("bad project name")
+)
+
+// Projects — проекты и их сервисы задач. У каждого проекта свой Service
+// со своим кэшем и нумерацией задач, а хранилище задач отфильтровано по проекту,
+// так что чужие задачи сервису просто не видны.
+// Сервис проекта поднимается при первом обращении и дальше живёт в памяти.
+type Projects struct {
+	store ProjectStore
+	tasks func(model.ProjectID) Store // хранилище задач проекта
+	opts  []Option
+
+	mu       sync.Mutex
+	projects map[model.ProjectID]model.Project
+	services map[model.ProjectID]*Service
+}
+
+// NewProjects загружает проекты; в пустом хранилище заводит общий (model.DefaultProject)
+func NewProjects(ctx context.Context, store ProjectStore, tasks func(model.ProjectID) Store, opts ...Option) (*Projects, error) {
+	list, err := store.ListProjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ps := &Projects{
+		store:    store,
+		tasks:    tasks,
+		opts:     opts,
+		projects: make(map[model.ProjectID]model.Project, len(list)),
+		services: make(map[model.ProjectID]*Service),
+	}
+	for _, p := range list {
+		ps.projects[p.ID] = p
+	}
+	if _, ok := ps.projects[model.DefaultProject]; !ok {
+		p := model.Project{Name: "default", CreatedAt: time.Now()}
+		if p.ID, err = store.InsertProject(ctx, p); err != nil {
+			return nil, fmt.Errorf("default project: %w", err)
+		}
+		if p.ID != model.DefaultProject {
+			return nil, fmt.Errorf("default project got id %d, want %d", p.ID, model.DefaultProject)
+		}
+		ps.projects[p.ID] = p
+	}
+	return ps, nil
+}
+
+// canEnter — пускаем ли вызывающего в проект: общий открыт всем,
+// остальные — участникам и администраторам; внутренние вызовы — везде
+func canEnter(ctx context.Context, p model.Project) bool {
+	u, ok := reqctx.UserFrom(ctx)
+	return !ok || u.Admin() || p.Open() || p.HasMember(u.ID)
+}
+
+// Get — проект, если вызывающий в него входит; чужой не отличаем от несуществующего
+func (ps *Projects) Get(ctx context.Context, id model.ProjectID) (model.Project, error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return ps.get(ctx, id)
+}
+
+func (ps *Projects) get(ctx context.Context, id model.ProjectID) (model.Project, error) {
+	p, ok := ps.projects[id]
+	if !ok || !canEnter(ctx, p) {
+		return model.Project{}, fmt.Errorf("%w: %d", ErrProjectNotFound, id)
+	}
+	return p, nil
+}
+
+// List — проекты, доступные вызывающему, по ID
+func (ps *Projects) List(ctx context.Context) []model.Project {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	out := make([]model.Project, 0, len(ps.projects))
+	for _, p := range ps.projects {
+		if canEnter(ctx, p) {
+			out = append(out, p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Create заводит проект; создатель сразу становится участником
+func (ps *Projects) Create(ctx context.Context, name string) (model.Project, error) {
+	if err := policy.Check(ctx, policy.ManageProjects); err != nil {
+		return model.Project{}, err
+	}
+	name, err := model.NormalizeProjectName(name)
+	if err != nil {
+		return model.Project{}, fmt.Errorf("%w: %v", ErrBadProjectName, err)
+	}
+	p := model.Project{Name: name, CreatedAt: time.Now()}
+	if u, ok := reqctx.UserFrom(ctx); ok {
+		p.CreatedBy = u.ID
+		p.Members = []model.UserID{u.ID}
+	}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	p.ID, err = ps.store.InsertProject(ctx, p)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return model.Project{}, fmt.Errorf("%w: %s", ErrProjectExists, name)
+	}
+	if err != nil {
+		return model.Project{}, err
+	}
+	ps.projects[p.ID] = p
+	return p, nil
+}
+
+// AddMember — пустить пользователя в проект. Что он существует, проверяет транспорт.
+func (ps *Projects) AddMember(ctx context.Context, id model.ProjectID, user model.UserID) (model.Project, error) {
+	return ps.updateMembers(ctx, id, func(p *model.Project) bool {
+		if p.HasMember(user) {
+			return false
+		}
+		p.Members = append(p.Members, user)
+		return true
+	})
+}
+
+// RemoveMember — убрать из проекта; задачи, которые он там создал, остаются в проекте
+func (ps *Projects) RemoveMember(ctx context.Context, id model.ProjectID, user model.UserID) (model.Project, error) {
+	return ps.updateMembers(ctx, id, func(p *model.Project) bool {
+		i := slices.Index(p.Members, user)
+		if i < 0 {
+			return false
+		}
+		p.Members = slices.Delete(p.Members, i, i+1)
+		return true
+	})
+}
+
+// updateMembers — fn правит копию списка участников; false — менять нечего
+func (ps *Projects) updateMembers(ctx context.Context, id model.ProjectID, fn func(p *model.Project) bool) (model.Project, error) {
+	if err := policy.Check(ctx, policy.ManageProjects); err != nil {
+		return model.Project{}, err
+	}
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	p, err := ps.get(ctx, id)
+	if err != nil {
+		return model.Project{}, err
+	}
+	p.Members = slices.Clone(p.Members)
+	if !fn(&p) {
+		return p, nil
+	}
+	if err := ps.store.UpdateProject(ctx, p); err != nil {
+		return model.Project{}, err
+	}
+	ps.projects[id] = p
+	return p, nil
+}
+
+// sweep — фоновый проход fn по всем проектам арендатора, по порядку ID;
+// отдаёт сумму того, что насчитал fn. Первая ошибка останавливает проход
+func (ps *Projects) sweep(ctx context.Context, fn func(svc *Service) (int, error)) (int, error) {
+	ps.mu.Lock()
+	ids := make([]model.ProjectID, 0, len(ps.projects))
+	for id := range ps.projects {
+		ids = append(ids, id)
+	}
+	ps.mu.Unlock()
+	slices.Sort(ids)
+
+	total := 0
+	for _, id := range ids {
+		svc, err := ps.Tasks(ctx, id)
+		if err != nil {
+			return total, err
+		}
+		n, err := fn(svc)
+		total += n
+		if err != nil {
+			return total, fmt.Errorf("project %d: %w", id, err)
+		}
+	}
+	return total, nil
+}
+
+// Tasks — сервис задач проекта, если вызывающий в него входит
+func (ps *Projects) Tasks(ctx context.Context, id model.ProjectID) (*Service, error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if _, err := ps.get(ctx, id); err != nil {
+		return nil, err
+	}
+	if svc, ok := ps.services[id]; ok {
+		return svc, nil
+	}
+	// сервис переживёт запрос, поэтому его отмену загрузке не передаём
+	svc, err := New(context.WithoutCancel(ctx), ps.tasks(id), append(slices.Clone(ps.opts), InProject(id))...)
+	if err != nil {
+		return nil, fmt.Errorf("project %d: %w", id, err)
+	}
+	ps.services[id] = svc
+	return svc, nil
+}
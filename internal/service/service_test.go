@@ -1,52 +1,197 @@
 package service_test
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"todo/internal/model"
+	"todo/internal/policy"
+	"todo/internal/repository"
+	"todo/internal/reqctx"
 	"todo/internal/service"
 )
 
+// общий контекст для тестов, где отмена не важна
+var ctx = context.Background()
+
 // фейковое хранилище для тестов
 type fakeStore struct {
-	items    []model.TaskDTO
-	loadErr  error
-	saveErr  error
-	saveCall int
+	mu          sync.Mutex
+	items       map[model.ID]model.TaskDTO
+	loadErr     error
+	insertErr   error
+	updateErr   error
+	renumberErr error
+	inserts     int
+	updates     int
+	deletes     int
+	renumbers   int
+}
+
+func newFakeStore(initial []model.TaskDTO) *fakeStore {
+	fs := &fakeStore{items: make(map[model.ID]model.TaskDTO)}
+	for _, r := range initial {
+		fs.items[r.ID] = r
+	}
+	return fs
+}
+
+func (f *fakeStore) Get(ctx context.Context, id model.ID) (model.TaskDTO, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return model.TaskDTO{}, err
+	}
+	r, ok := f.items[id]
+	if !ok {
+		return model.TaskDTO{}, repository.ErrNotFound
+	}
+	return r, nil
+}
+
+func (f *fakeStore) Insert(ctx context.Context, r model.TaskDTO) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.inserts++
+	if f.insertErr != nil {
+		return f.insertErr
+	}
+	if _, ok := f.items[r.ID]; ok {
+		return errors.New("duplicate id")
+	}
+	f.items[r.ID] = r
+	return nil
+}
+
+func (f *fakeStore) Update(ctx context.Context, r model.TaskDTO, version int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.updates++
+	if f.updateErr != nil {
+		return f.updateErr
+	}
+	prev, ok := f.items[r.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if prev.Version != version {
+		return repository.ErrConflict
+	}
+	f.items[r.ID] = r
+	return nil
+}
+
+// live — сколько записей не в корзине
+func (f *fakeStore) live() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, r := range f.items {
+		if r.DeletedAt == nil {
+			n++
+		}
+	}
+	return n
+}
+
+func (f *fakeStore) Delete(ctx context.Context, id model.ID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.deletes++
+	if _, ok := f.items[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(f.items, id)
+	return nil
 }
 
-func (f *fakeStore) Load() ([]model.TaskDTO, error) {
+func (f *fakeStore) Query(ctx context.Context, q model.TaskQuery) ([]model.TaskDTO, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	if f.loadErr != nil {
 		return nil, f.loadErr
 	}
-	cp := make([]model.TaskDTO, len(f.items))
-	copy(cp, f.items)
-	return cp, nil
+	out := make([]model.TaskDTO, 0, len(f.items))
+	for _, r := range f.items {
+		out = append(out, r)
+	}
+	return q.Apply(out), nil
 }
 
-func (f *fakeStore) Save(items []model.TaskDTO) error {
-	f.saveCall++
-	if f.saveErr != nil {
-		return f.saveErr
+// Renumber — как у настоящих хранилищ: все записи разом или ни одной
+func (f *fakeStore) Renumber(ctx context.Context, remap map[model.ID]model.ID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
 	}
-	cp := make([]model.TaskDTO, len(items))
-	copy(cp, items)
-	f.items = cp
+	f.renumbers++
+	if f.renumberErr != nil {
+		return f.renumberErr
+	}
+	next := make(map[model.ID]model.TaskDTO, len(f.items))
+	for _, r := range f.items {
+		t, err := model.FromDTO(r)
+		if err != nil {
+			return err
+		}
+		t.Renumber(remap)
+		if _, ok := next[t.ID()]; ok {
+			return errors.New("duplicate id")
+		}
+		next[t.ID()] = t.ToDTO()
+	}
+	f.items = next
 	return nil
 }
 
 func mustNewService(t *testing.T, initial []model.TaskDTO) (*service.Service, *fakeStore) {
 	t.Helper()
-	fs := &fakeStore{items: initial}
-	svc, err := service.New(fs)
+	fs := newFakeStore(initial)
+	svc, err := service.New(ctx, fs)
 	if err != nil {
 		t.Fatalf("service.New error: %v", err)
 	}
 	return svc, fs
 }
 
+// listAll — все задачи по порядку создания
+func listAll(t *testing.T, svc *service.Service) []*model.Task {
+	t.Helper()
+	page, err := svc.List(ctx, model.TaskQuery{})
+	if err != nil {
+		t.Fatalf("List error: %v", err)
+	}
+	return page.Items
+}
+
 func findTaskByID(list []*model.Task, id model.ID) *model.Task {
 	for _, t := range list {
 		if t.ID() == id {
@@ -57,8 +202,9 @@ func findTaskByID(list []*model.Task, id model.ID) *model.Task {
 }
 
 func TestNew_LoadError(t *testing.T) {
-	fs := &fakeStore{loadErr: errors.New("boom")}
-	_, err := service.New(fs)
+	fs := newFakeStore(nil)
+	fs.loadErr = errors.New("boom")
+	_, err := service.New(ctx, fs)
 	if err == nil {
 		t.Fatal("expected error on load, got nil")
 	}
@@ -67,14 +213,14 @@ func TestNew_LoadError(t *testing.T) {
 func TestAdd_And_List(t *testing.T) {
 	svc, fs := mustNewService(t, nil)
 
-	id, err := svc.Add("A", "desc", model.PriorityHigh, nil)
+	id, err := svc.Add(ctx, "A", "desc", model.PriorityHigh, nil)
 	if err != nil {
 		t.Fatalf("Add error: %v", err)
 	}
-	if fs.saveCall != 1 {
-		t.Fatalf("expected 1 save, got %d", fs.saveCall)
+	if fs.inserts != 1 || fs.updates != 0 {
+		t.Fatalf("expected 1 insert and no updates, got %d/%d", fs.inserts, fs.updates)
 	}
-	got := svc.List(nil)
+	got := listAll(t, svc)
 	if len(got) != 1 {
 		t.Fatalf("expected 1 task, got %d", len(got))
 	}
@@ -85,26 +231,69 @@ func TestAdd_And_List(t *testing.T) {
 
 func TestAdd_EmptyTitle(t *testing.T) {
 	svc, fs := mustNewService(t, nil)
-	_, err := svc.Add("", "", model.PriorityMedium, nil)
+	_, err := svc.Add(ctx, "", "", model.PriorityMedium, nil)
 	if err == nil {
 		t.Fatal("expected error for empty title, got nil")
 	}
-	if fs.saveCall != 0 {
-		t.Fatalf("expected 0 saves, got %d", fs.saveCall)
+	if fs.inserts != 0 {
+		t.Fatalf("expected 0 inserts, got %d", fs.inserts)
+	}
+}
+
+func TestCreate_AllOrNothing(t *testing.T) {
+	logs := withFakeLogger(t)
+	svc, fs := mustNewService(t, nil)
+	parent, _ := svc.Add(ctx, "Проект", "", model.PriorityLow, nil)
+	due := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	inserts, events := fs.inserts, len(logs.events)
+
+	// плохой тег, приоритет или правило — ни задачи, ни записи в аудите
+	for _, d := range []model.TaskDraft{
+		{Title: "Отчёт", Tags: []string{"ok", "плохой тег!"}},
+		{Title: "Отчёт", Priority: 7},
+		{Title: "Отчёт", Recurrence: &model.Recurrence{Freq: "HOURLY"}},
+		{Title: "  "},
+	} {
+		if _, err := svc.Create(ctx, d); !errors.Is(err, service.ErrBadTask) {
+			t.Fatalf("%+v: expected ErrBadTask, got %v", d, err)
+		}
+	}
+	if _, err := svc.Create(ctx, model.TaskDraft{Title: "Отчёт", ParentID: 999}); !errors.Is(err, service.ErrNotFound) {
+		t.Fatalf("missing parent: %v", err)
+	}
+	if fs.inserts != inserts || len(logs.events) != events || len(listAll(t, svc)) != 1 {
+		t.Fatalf("failed creates wrote %d inserts, %d events", fs.inserts-inserts, len(logs.events)-events)
+	}
+
+	// удачный: одна вставка и одно событие со всеми полями сразу
+	id, err := svc.Create(ctx, model.TaskDraft{
+		Title: "Отчёт", Priority: model.PriorityHigh, DueAt: &due, ParentID: parent,
+		Recurrence: mustRule(t, "FREQ=MONTHLY"), Tags: []string{"#Работа", "отчёты"}, Assignee: 7,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fs.inserts != inserts+1 || fs.updates != 0 || len(logs.events) != events+1 {
+		t.Fatalf("expected 1 insert and 1 event, got %d/%d/%d", fs.inserts-inserts, fs.updates, len(logs.events)-events)
+	}
+	got, _ := svc.Get(ctx, id)
+	if got.ParentID() != parent || got.Priority() != model.PriorityHigh || got.DueAt() == nil ||
+		got.Recurrence().MonthDay != 31 || !slices.Equal(got.Tags(), []string{"отчёты", "работа"}) || got.Assignee() != 7 {
+		t.Fatalf("created: %+v", got.ToDTO())
 	}
 }
 
 func TestUpdateTitle_Desc(t *testing.T) {
 	svc, _ := mustNewService(t, nil)
-	id, _ := svc.Add("A", "x", model.PriorityMedium, nil)
+	id, _ := svc.Add(ctx, "A", "x", model.PriorityMedium, nil)
 
-	if err := svc.UpdateTitle(id, "B"); err != nil {
+	if err := svc.UpdateTitle(ctx, id, "B"); err != nil {
 		t.Fatalf("UpdateTitle err: %v", err)
 	}
-	if err := svc.UpdateDesc(id, "Y"); err != nil {
+	if err := svc.UpdateDesc(ctx, id, "Y"); err != nil {
 		t.Fatalf("UpdateDesc err: %v", err)
 	}
-	got := findTaskByID(svc.List(nil), id)
+	got := findTaskByID(listAll(t, svc), id)
 	if got == nil || got.Title() != "B" || got.Description() != "Y" {
 		t.Fatalf("unexpected task: %+v", got)
 	}
@@ -112,35 +301,48 @@ func TestUpdateTitle_Desc(t *testing.T) {
 
 func TestUpdateTitle_Empty(t *testing.T) {
 	svc, _ := mustNewService(t, nil)
-	id, _ := svc.Add("A", "", model.PriorityMedium, nil)
-	if err := svc.UpdateTitle(id, ""); err == nil {
+	id, _ := svc.Add(ctx, "A", "", model.PriorityMedium, nil)
+	if err := svc.UpdateTitle(ctx, id, ""); err == nil {
 		t.Fatal("expected error, got nil")
 	}
 }
 
 func TestSetStatus_Transitions(t *testing.T) {
 	svc, _ := mustNewService(t, nil)
-	id, _ := svc.Add("A", "", model.PriorityLow, nil)
+	id, _ := svc.Add(ctx, "A", "", model.PriorityLow, nil)
 
 	// invalid
-	if err := svc.SetStatus(id, model.Status("bad")); err == nil {
+	if err := svc.SetStatus(ctx, id, model.Status("bad")); err == nil {
 		t.Fatal("expected error for invalid status")
 	}
 
+	// new -> done напрямую нельзя
+	err := svc.SetStatus(ctx, id, model.StatusDone)
+	var terr *model.TransitionError
+	if !errors.As(err, &terr) || terr.From != model.StatusNew || terr.To != model.StatusDone {
+		t.Fatalf("expected TransitionError new->done, got %v", err)
+	}
+	if got := findTaskByID(listAll(t, svc), id).Status(); got != model.StatusNew {
+		t.Fatalf("status changed despite error: %s", got)
+	}
+
 	// done -> CompletedAt выставлен
-	if err := svc.SetStatus(id, model.StatusDone); err != nil {
+	if err := svc.SetStatus(ctx, id, model.StatusInProgress); err != nil {
+		t.Fatalf("SetStatus in_progress err: %v", err)
+	}
+	if err := svc.SetStatus(ctx, id, model.StatusDone); err != nil {
 		t.Fatalf("SetStatus done err: %v", err)
 	}
-	tk := findTaskByID(svc.List(nil), id)
+	tk := findTaskByID(listAll(t, svc), id)
 	if tk.CompletedAt() == nil {
 		t.Fatal("expected CompletedAt to be set")
 	}
 
 	// выход из done -> CompletedAt очищен
-	if err := svc.SetStatus(id, model.StatusInProgress); err != nil {
+	if err := svc.SetStatus(ctx, id, model.StatusInProgress); err != nil {
 		t.Fatalf("SetStatus in_progress err: %v", err)
 	}
-	tk = findTaskByID(svc.List(nil), id)
+	tk = findTaskByID(listAll(t, svc), id)
 	if tk.CompletedAt() != nil {
 		t.Fatal("expected CompletedAt to be nil after leaving done")
 	}
@@ -148,59 +350,63 @@ func TestSetStatus_Transitions(t *testing.T) {
 
 func TestSetPriority(t *testing.T) {
 	svc, _ := mustNewService(t, nil)
-	id, _ := svc.Add("A", "", model.PriorityLow, nil)
+	id, _ := svc.Add(ctx, "A", "", model.PriorityLow, nil)
 
-	if err := svc.SetPriority(id, model.PriorityHigh); err != nil {
+	if err := svc.SetPriority(ctx, id, model.PriorityHigh); err != nil {
 		t.Fatalf("SetPriority err: %v", err)
 	}
-	if got := findTaskByID(svc.List(nil), id).Priority(); got != model.PriorityHigh {
+	if got := findTaskByID(listAll(t, svc), id).Priority(); got != model.PriorityHigh {
 		t.Fatalf("expected high, got %v", got)
 	}
 
-	if err := svc.SetPriority(id, 0); err == nil {
+	if err := svc.SetPriority(ctx, id, 0); err == nil {
 		t.Fatal("expected error for invalid priority")
 	}
 }
 
 func TestDue_ClearDue(t *testing.T) {
 	svc, _ := mustNewService(t, nil)
-	id, _ := svc.Add("A", "", model.PriorityMedium, nil)
+	id, _ := svc.Add(ctx, "A", "", model.PriorityMedium, nil)
 
 	d := time.Now().Add(24 * time.Hour).Truncate(24 * time.Hour)
-	if err := svc.SetDue(id, d); err != nil {
+	if err := svc.SetDue(ctx, id, d); err != nil {
 		t.Fatalf("SetDue err: %v", err)
 	}
-	tk := findTaskByID(svc.List(nil), id)
+	tk := findTaskByID(listAll(t, svc), id)
 	if tk.DueAt() == nil || !tk.DueAt().Equal(d) {
 		t.Fatalf("unexpected due: %v", tk.DueAt())
 	}
-	if err := svc.ClearDue(id); err != nil {
+	if err := svc.ClearDue(ctx, id); err != nil {
 		t.Fatalf("ClearDue err: %v", err)
 	}
-	if findTaskByID(svc.List(nil), id).DueAt() != nil {
+	if findTaskByID(listAll(t, svc), id).DueAt() != nil {
 		t.Fatal("expected due to be nil after ClearDue")
 	}
 }
 
 func TestDelete_NotFound(t *testing.T) {
 	svc, _ := mustNewService(t, nil)
-	if err := svc.Delete(42); err == nil {
+	if err := svc.Delete(ctx, 42); err == nil {
 		t.Fatal("expected not found")
 	}
 }
 
 func TestList_Filter(t *testing.T) {
 	svc, _ := mustNewService(t, nil)
-	id1, _ := svc.Add("A", "", model.PriorityLow, nil)
-	id2, _ := svc.Add("B", "", model.PriorityLow, nil)
-	_ = svc.SetStatus(id2, model.StatusDone)
+	id1, _ := svc.Add(ctx, "A", "", model.PriorityLow, nil)
+	id2, _ := svc.Add(ctx, "B", "", model.PriorityLow, nil)
+	_ = svc.SetStatus(ctx, id2, model.StatusInProgress)
+	_ = svc.SetStatus(ctx, id2, model.StatusDone)
 
-	all := svc.List(nil)
+	all := listAll(t, svc)
 	if len(all) != 2 {
 		t.Fatalf("expected 2, got %d", len(all))
 	}
-	f := model.StatusDone
-	onlyDone := svc.List(&f)
+	page, err := svc.List(ctx, model.TaskQuery{Statuses: []model.Status{model.StatusDone}})
+	if err != nil {
+		t.Fatalf("List err: %v", err)
+	}
+	onlyDone := page.Items
 	if len(onlyDone) != 1 || onlyDone[0].ID() != id2 {
 		t.Fatalf("expected only done id=%d, got %+v", id2, onlyDone)
 	}
@@ -229,13 +435,16 @@ func TestRenumberIDs(t *testing.T) {
 	}
 	svc, fs := mustNewService(t, initial)
 
-	if err := svc.RenumberIDs(); err != nil {
+	if err := svc.RenumberIDs(ctx); err != nil {
 		t.Fatalf("RenumberIDs err: %v", err)
 	}
-	if fs.saveCall == 0 {
-		t.Fatal("expected persist on renumber")
+	if fs.renumbers != 1 || fs.deletes != 0 || fs.inserts != 0 {
+		t.Fatalf("expected one atomic store renumber, got %d renumbers/%d deletes/%d inserts", fs.renumbers, fs.deletes, fs.inserts)
+	}
+	if _, ok := fs.items[1]; !ok {
+		t.Fatal("expected id=1 in store after renumber")
 	}
-	list := svc.List(nil)
+	list := listAll(t, svc)
 	if len(list) != 2 {
 		t.Fatalf("expected 2, got %d", len(list))
 	}
@@ -247,12 +456,2237 @@ func TestRenumberIDs(t *testing.T) {
 	}
 }
 
+func TestRenumberIDs_StoreFailureKeepsOldNumbers(t *testing.T) {
+	now := time.Now()
+	svc, fs := mustNewService(t, []model.TaskDTO{
+		{ID: 10, Title: "parent", Status: model.StatusNew, Priority: model.PriorityMedium, CreatedAt: now.Add(-2 * time.Hour), UpdatedAt: now},
+		{ID: 7, Title: "child", Status: model.StatusNew, Priority: model.PriorityMedium, ParentID: 10, CreatedAt: now.Add(-time.Hour), UpdatedAt: now},
+	})
+
+	fs.renumberErr = errors.New("tx aborted")
+	if err := svc.RenumberIDs(ctx); err == nil {
+		t.Fatal("expected error from store renumber")
+	}
+	if fs.deletes != 0 || fs.inserts != 0 || len(fs.items) != 2 {
+		t.Fatalf("store touched outside Renumber: %d deletes/%d inserts, %d items", fs.deletes, fs.inserts, len(fs.items))
+	}
+	child, err := svc.Get(ctx, 7)
+	if err != nil || child.ParentID() != 10 {
+		t.Fatalf("cache must keep old numbers after failed renumber: %v, %v", child, err)
+	}
+	if _, err := svc.Get(ctx, 1); err == nil {
+		t.Fatal("new number published before store commit")
+	}
+
+	fs.renumberErr = nil
+	if err := svc.RenumberIDs(ctx); err != nil {
+		t.Fatalf("RenumberIDs: %v", err)
+	}
+	child, err = svc.Get(ctx, 2)
+	if err != nil || child.ParentID() != 1 || fs.items[2].ParentID != 1 {
+		t.Fatalf("renumber after retry: %v, %v, store %+v", child, err, fs.items[2])
+	}
+}
+
+// JSON-хранилище пишет перенумерацию четырёх файлов через журнал: если после коммита
+// файлы не разложились, store не отдаёт старые номера, а следующая загрузка доигрывает журнал
+func TestRenumber_JSONJournalSurvivesCrash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	st := repository.NewJSONStore(path)
+	now := time.Now().UTC()
+	done := now.Add(-time.Hour)
+	for _, r := range []model.TaskDTO{
+		{ID: 10, Title: "parent", Status: model.StatusNew, Priority: model.PriorityLow, CreatedAt: now.Add(-3 * time.Hour), UpdatedAt: now},
+		{ID: 20, Title: "child", Status: model.StatusNew, Priority: model.PriorityLow, ParentID: 10, CreatedAt: now.Add(-2 * time.Hour), UpdatedAt: now},
+		{ID: 30, Title: "closed", Status: model.StatusDone, Priority: model.PriorityLow, BlockedBy: []model.ID{20}, CreatedAt: now.Add(-time.Hour), UpdatedAt: now, CompletedAt: &done},
+	} {
+		if err := st.Insert(ctx, r); err != nil {
+			t.Fatalf("Insert %d: %v", r.ID, err)
+		}
+	}
+	closed, _ := st.Get(ctx, 30)
+	closed.ArchivedAt = &now
+	if err := st.Archive(ctx, []model.TaskDTO{closed}); err != nil {
+		t.Fatalf("Archive: %v", err)
+	}
+	if _, err := st.InsertComment(ctx, model.Comment{TaskID: 20, Body: "hi", CreatedAt: now}); err != nil {
+		t.Fatalf("InsertComment: %v", err)
+	}
+
+	// каталог на месте временного файла задач: журнал запишется, а разложить его не выйдет
+	if err := os.Mkdir(path+".tmp", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Renumber(ctx, map[model.ID]model.ID{10: 1, 20: 2, 30: 3}); err != nil {
+		t.Fatalf("Renumber after commit must succeed: %v", err)
+	}
+	if _, err := st.Get(ctx, 2); err == nil {
+		t.Fatal("store must refuse reads while the journal is not settled")
+	}
+
+	// «перезапуск»: новый store на тех же файлах доигрывает журнал
+	if err := os.Remove(path + ".tmp"); err != nil {
+		t.Fatal(err)
+	}
+	reopened := repository.NewJSONStore(path)
+	child, err := reopened.Get(ctx, 2)
+	if err != nil || child.ParentID != 1 {
+		t.Fatalf("child after replay: %+v, %v", child, err)
+	}
+	if _, err := reopened.Get(ctx, 20); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("old number still there: %v", err)
+	}
+	archived, err := reopened.GetArchived(ctx, 3)
+	if err != nil || !slices.Equal(archived.BlockedBy, []model.ID{2}) {
+		t.Fatalf("archive after replay: %+v, %v", archived, err)
+	}
+	if comments, _ := reopened.ListComments(ctx, 2); len(comments) != 1 {
+		t.Fatalf("comment must follow its task: %+v", comments)
+	}
+	if _, err := os.Stat(reopened.RenumberPath()); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("journal must be removed after replay: %v", err)
+	}
+	// и первый store, как только диск ожил, дописывает свой журнал сам
+	if got, err := st.Get(ctx, 1); err != nil || got.Title != "parent" {
+		t.Fatalf("original store after settle: %+v, %v", got, err)
+	}
+}
+
 func TestPersistErrorsBubbleUp(t *testing.T) {
-	fs := &fakeStore{}
-	svc, _ := service.New(fs)
+	fs := newFakeStore(nil)
+	svc, _ := service.New(ctx, fs)
+
+	fs.insertErr = errors.New("insert failed")
+	if _, err := svc.Add(ctx, "A", "", model.PriorityLow, nil); err == nil {
+		t.Fatal("expected error from Insert")
+	}
+	if len(listAll(t, svc)) != 0 {
+		t.Fatal("task must not appear after failed insert")
+	}
+}
+
+func TestMutationsTouchOnlyChangedRecord(t *testing.T) {
+	svc, fs := mustNewService(t, nil)
+	id1, _ := svc.Add(ctx, "A", "", model.PriorityLow, nil)
+	id2, _ := svc.Add(ctx, "B", "", model.PriorityLow, nil)
+	before := fs.items[id2]
+
+	if err := svc.SetStatus(ctx, id1, model.StatusInProgress); err != nil {
+		t.Fatalf("SetStatus err: %v", err)
+	}
+	if fs.updates != 1 || fs.inserts != 2 || fs.deletes != 0 {
+		t.Fatalf("expected exactly one update, got %d updates/%d inserts/%d deletes",
+			fs.updates, fs.inserts, fs.deletes)
+	}
+	if fs.items[id1].Status != model.StatusInProgress {
+		t.Fatalf("store not updated: %+v", fs.items[id1])
+	}
+	if !reflect.DeepEqual(fs.items[id2], before) {
+		t.Fatal("untouched task was rewritten")
+	}
+
+	// удаление — тоже одна запись: задача уходит в корзину
+	if err := svc.Delete(ctx, id2); err != nil {
+		t.Fatalf("Delete err: %v", err)
+	}
+	if fs.updates != 2 || fs.deletes != 0 || fs.items[id2].DeletedAt == nil || fs.live() != 1 {
+		t.Fatalf("expected one update to trash, got %d updates/%d deletes (live=%d)", fs.updates, fs.deletes, fs.live())
+	}
+}
+
+// фейковый аудит, запоминает события
+type fakeLogger struct {
+	mu     sync.Mutex
+	events []service.Event
+}
+
+func (l *fakeLogger) LogEvent(ctx context.Context, e service.Event) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	l.events = append(l.events, e)
+	return nil
+}
+
+func withFakeLogger(t *testing.T) *fakeLogger {
+	t.Helper()
+	l := &fakeLogger{}
+	prev := service.Logger
+	service.Logger = l
+	t.Cleanup(func() { service.Logger = prev })
+	return l
+}
+
+func TestCanceledContextStopsStoreWork(t *testing.T) {
+	svc, fs := mustNewService(t, nil)
+	id, _ := svc.Add(ctx, "A", "", model.PriorityLow, nil)
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	if _, err := svc.Add(cctx, "B", "", model.PriorityLow, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled from Add, got %v", err)
+	}
+	if err := svc.UpdateTitle(cctx, id, "B"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled from UpdateTitle, got %v", err)
+	}
+	if err := svc.Delete(cctx, id); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled from Delete, got %v", err)
+	}
+	if got := findTaskByID(listAll(t, svc), id); got == nil || got.Title() != "A" {
+		t.Fatalf("canceled calls must not change the task: %+v", got)
+	}
+	if len(fs.items) != 1 {
+		t.Fatalf("expected 1 task in store, got %d", len(fs.items))
+	}
+}
+
+func TestAuditEventCarriesRequestInfo(t *testing.T) {
+	logs := withFakeLogger(t)
+	svc, _ := mustNewService(t, nil)
+
+	rctx := reqctx.WithTraceID(ctx, "trace-1")
+	rctx = reqctx.WithUser(rctx, reqctx.User{Login: "alice"})
+	// аудит пишется уже после сохранения, поэтому отмена запроса ему не мешает
+	rctx, cancel := context.WithCancel(rctx)
+	id, err := svc.Add(rctx, "A", "", model.PriorityLow, nil)
+	if err != nil {
+		t.Fatalf("Add err: %v", err)
+	}
+	cancel()
+	_ = id
+
+	if len(logs.events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(logs.events))
+	}
+	e := logs.events[0]
+	if e.Op != "add" || e.TraceID != "trace-1" || e.User != "alice" {
+		t.Fatalf("unexpected event: %+v", e)
+	}
+}
+
+func TestList_QueryFilters(t *testing.T) {
+	svc, _ := mustNewService(t, nil)
+	day := func(n int) *time.Time {
+		d := time.Date(2030, 1, n, 0, 0, 0, 0, time.UTC)
+		return &d
+	}
+	idLow, _ := svc.Add(ctx, "Купить молоко", "", model.PriorityLow, day(5))
+	idHigh, _ := svc.Add(ctx, "Отчёт", "квартальный отчёт для молочного завода", model.PriorityHigh, day(10))
+	idMed, _ := svc.Add(ctx, "Звонок", "", model.PriorityMedium, nil)
+	_ = svc.SetStatus(ctx, idHigh, model.StatusInProgress)
+
+	cases := []struct {
+		name string
+		q    model.TaskQuery
+		want []model.ID
+	}{
+		{"status set", model.TaskQuery{Statuses: []model.Status{model.StatusNew, model.StatusDone}}, []model.ID{idLow, idMed}},
+		{"priority range", model.TaskQuery{MinPriority: model.PriorityMedium}, []model.ID{idHigh, idMed}},
+		{"due before", model.TaskQuery{DueBefore: day(10)}, []model.ID{idLow}},
+		{"due after", model.TaskQuery{DueAfter: day(6)}, []model.ID{idHigh}},
+		{"text in title or description", model.TaskQuery{Text: "МОЛО"}, []model.ID{idLow, idHigh}},
+		{"sort by due desc, no due first", model.TaskQuery{Sort: []model.SortKey{{Field: model.SortDue, Desc: true}}}, []model.ID{idMed, idHigh, idLow}},
+		{"sort by priority", model.TaskQuery{Sort: []model.SortKey{{Field: model.SortPriority}}}, []model.ID{idLow, idMed, idHigh}},
+	}
+	for _, c := range cases {
+		page, err := svc.List(ctx, c.q)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		var got []model.ID
+		for _, tk := range page.Items {
+			got = append(got, tk.ID())
+		}
+		if len(got) != len(c.want) {
+			t.Fatalf("%s: expected %v, got %v", c.name, c.want, got)
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Fatalf("%s: expected %v, got %v", c.name, c.want, got)
+			}
+		}
+	}
+}
+
+func TestList_CursorPagination(t *testing.T) {
+	now := time.Now()
+	var initial []model.TaskDTO
+	for i := 1; i <= 7; i++ {
+		initial = append(initial, model.TaskDTO{
+			ID:        model.ID(i),
+			Title:     "t",
+			Status:    model.StatusNew,
+			Priority:  model.Priority(i%3 + 1),
+			CreatedAt: now.Add(time.Duration(i) * time.Minute),
+			UpdatedAt: now,
+		})
+	}
+	svc, _ := mustNewService(t, initial)
+
+	q := model.TaskQuery{
+		Sort:  []model.SortKey{{Field: model.SortPriority, Desc: true}, {Field: model.SortCreated}},
+		Limit: 3,
+	}
+	var got []model.ID
+	pages := 0
+	for {
+		page, err := svc.List(ctx, q)
+		if err != nil {
+			t.Fatalf("List err: %v", err)
+		}
+		pages++
+		for _, tk := range page.Items {
+			got = append(got, tk.ID())
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		if q.After, err = model.DecodeCursor(page.NextCursor); err != nil {
+			t.Fatalf("DecodeCursor err: %v", err)
+		}
+	}
+	// priority 3: 2,5; priority 2: 1,4,7; priority 1: 3,6
+	want := []model.ID{2, 5, 1, 4, 7, 3, 6}
+	if pages != 3 || len(got) != len(want) {
+		t.Fatalf("expected %v in 3 pages, got %v in %d", want, got, pages)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	svc, _ := mustNewService(t, nil)
+	id, _ := svc.Add(ctx, "A", "", model.PriorityLow, nil)
+	got, err := svc.Get(ctx, id)
+	if err != nil || got.Title() != "A" {
+		t.Fatalf("unexpected Get result: %v, %v", got, err)
+	}
+	if _, err := svc.Get(ctx, id+1); err == nil {
+		t.Fatal("expected not found")
+	}
+}
+
+// Поиск на JSON-хранилище идёт через индекс в памяти: ранжирование, подсветка,
+// и индекс следит за изменениями и удалениями.
+func TestSearch_JSONStore(t *testing.T) {
+	path := t.TempDir() + "/tasks.json"
+	svc, err := service.New(ctx, repository.NewJSONStore(path))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	idA, _ := svc.Add(ctx, "Купить молоко", "и хлеб к ужину", model.PriorityLow, nil)
+	idB, _ := svc.Add(ctx, "Позвонить маме", "спросить про молоко", model.PriorityLow, nil)
+	idC, _ := svc.Add(ctx, "Отчёт", "квартальный", model.PriorityLow, nil)
+
+	hits, err := svc.Search(ctx, "МОЛОКО", 0)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(hits) != 2 || hits[0].Task.ID != idA || hits[1].Task.ID != idB {
+		t.Fatalf("expected title match first, got %+v", hits)
+	}
+	if hits[0].Rank <= hits[1].Rank {
+		t.Fatalf("title match must rank higher: %v vs %v", hits[0].Rank, hits[1].Rank)
+	}
+	if want := "Купить <b>молоко</b> и хлеб к ужину"; hits[0].Snippet != want {
+		t.Fatalf("snippet = %q, want %q", hits[0].Snippet, want)
+	}
+
+	// все слова запроса обязательны
+	if hits, _ := svc.Search(ctx, "молоко хлеб", 0); len(hits) != 1 || hits[0].Task.ID != idA {
+		t.Fatalf("expected only %d, got %+v", idA, hits)
+	}
+
+	_ = svc.UpdateDesc(ctx, idC, "молоко закончилось")
+	_ = svc.Delete(ctx, idA)
+	hits, _ = svc.Search(ctx, "молоко", 0)
+	if len(hits) != 2 || hits[0].Task.ID != idB || hits[1].Task.ID != idC {
+		t.Fatalf("index is stale: %+v", hits)
+	}
+
+	// после перезапуска индекс строится из файла
+	svc2, err := service.New(ctx, repository.NewJSONStore(path))
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if hits, _ := svc2.Search(ctx, "закончилось", 1); len(hits) != 1 || hits[0].Task.ID != idC {
+		t.Fatalf("index not rebuilt on load: %+v", hits)
+	}
+}
+
+// Чужие задачи отсекает само хранилище, до лимита: иначе они съедают места в выдаче
+func TestSearch_HiddenTasksDoNotEatLimit(t *testing.T) {
+	svc, err := service.New(ctx, repository.NewJSONStore(filepath.Join(t.TempDir(), "tasks.json")))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	alice := reqctx.WithUser(ctx, reqctx.User{ID: 1, Login: "alice", Role: model.RoleMember})
+	bob := reqctx.WithUser(ctx, reqctx.User{ID: 2, Login: "bob", Role: model.RoleMember})
+	admin := reqctx.WithUser(ctx, reqctx.User{ID: 3, Login: "root", Role: model.RoleAdmin})
+
+	// у bob совпадения в заголовке — ранг выше, чем у задачи alice с совпадением в описании
+	for i := range 5 {
+		if _, err := svc.Add(bob, fmt.Sprintf("Молоко %d", i), "", model.PriorityLow, nil); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+	mine, _ := svc.Add(alice, "Покупки", "не забыть молоко", model.PriorityLow, nil)
+
+	hits, err := svc.Search(alice, "молоко", 2)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(hits) != 1 || hits[0].Task.ID != mine {
+		t.Fatalf("alice must find her own task despite bob's better matches: %+v", hits)
+	}
+	if hits, _ := svc.Search(admin, "молоко", 2); len(hits) != 2 || hits[0].Task.CreatedBy != 2 {
+		t.Fatalf("admin sees everything, best first: %+v", hits)
+	}
+}
+
+func TestSearch_TrashDoesNotEatLimit(t *testing.T) {
+	svc, err := service.New(ctx, repository.NewJSONStore(filepath.Join(t.TempDir(), "tasks.json")))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	// удалённые совпадают в заголовке и ранжируются выше живой задачи
+	for i := range 5 {
+		id, _ := svc.Add(ctx, fmt.Sprintf("Молоко %d", i), "", model.PriorityLow, nil)
+		if err := svc.Delete(ctx, id); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+	}
+	live, _ := svc.Add(ctx, "Покупки", "не забыть молоко", model.PriorityLow, nil)
+
+	hits, err := svc.Search(ctx, "молоко", 2)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(hits) != 1 || hits[0].Task.ID != live {
+		t.Fatalf("trashed tasks must not take the limit: %+v", hits)
+	}
+}
+
+func TestSearch_SnippetIsEscaped(t *testing.T) {
+	svc, err := service.New(ctx, repository.NewJSONStore(filepath.Join(t.TempDir(), "tasks.json")))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	svc.Add(ctx, `<script>alert(1)</script> молоко`, `купить "молоко" & <img src=x onerror=alert(2)>`, model.PriorityLow, nil)
+
+	hits, err := svc.Search(ctx, "молоко", 0)
+	if err != nil || len(hits) != 1 {
+		t.Fatalf("Search: %v %+v", err, hits)
+	}
+	want := `&lt;script&gt;alert(1)&lt;/script&gt; <b>молоко</b> купить <b>&#34;молоко&#34;</b> &amp; &lt;img src=x onerror=alert(2)&gt;`
+	if got := hits[0].Snippet; got != want {
+		t.Fatalf("snippet must be escaped before highlighting:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestSearch_Errors(t *testing.T) {
+	svc, _ := mustNewService(t, nil)
+	if _, err := svc.Search(ctx, "  ", 0); !errors.Is(err, service.ErrEmptyQuery) {
+		t.Fatalf("expected ErrEmptyQuery, got %v", err)
+	}
+	// у фейкового хранилища поиска нет
+	if _, err := svc.Search(ctx, "x", 0); !errors.Is(err, service.ErrSearchUnsupported) {
+		t.Fatalf("expected ErrSearchUnsupported, got %v", err)
+	}
+}
+
+// Своя таблица переходов заменяет стандартную целиком.
+func TestWithTransitions(t *testing.T) {
+	tr := model.Transitions{
+		model.StatusNew:  {model.StatusDone},
+		model.StatusDone: {},
+	}
+	svc, err := service.New(ctx, newFakeStore(nil), service.WithTransitions(tr))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	id, _ := svc.Add(ctx, "A", "", model.PriorityLow, nil)
+
+	var terr *model.TransitionError
+	if err := svc.SetStatus(ctx, id, model.StatusInProgress); !errors.As(err, &terr) {
+		t.Fatalf("expected TransitionError, got %v", err)
+	}
+	if err := svc.SetStatus(ctx, id, model.StatusDone); err != nil {
+		t.Fatalf("new->done must be allowed by custom table: %v", err)
+	}
+	// done конечный: переоткрыть нельзя
+	if err := svc.SetStatus(ctx, id, model.StatusNew); !errors.As(err, &terr) || len(terr.Allowed) != 0 {
+		t.Fatalf("expected final-status error, got %v", err)
+	}
+}
+
+func TestDefaultTransitions_Reopen(t *testing.T) {
+	svc, _ := mustNewService(t, nil)
+	id, _ := svc.Add(ctx, "A", "", model.PriorityLow, nil)
+
+	for _, st := range []model.Status{model.StatusPaused, model.StatusCanceled, model.StatusNew, model.StatusInProgress} {
+		if err := svc.SetStatus(ctx, id, st); err != nil {
+			t.Fatalf("-> %s: %v", st, err)
+		}
+	}
+	// отменённую задачу сразу в done не закрыть
+	_ = svc.SetStatus(ctx, id, model.StatusCanceled)
+	var terr *model.TransitionError
+	if err := svc.SetStatus(ctx, id, model.StatusDone); !errors.As(err, &terr) {
+		t.Fatalf("canceled->done must fail, got %v", err)
+	}
+}
+
+func TestErrNotFound(t *testing.T) {
+	svc, _ := mustNewService(t, nil)
+	if err := svc.SetStatus(ctx, 7, model.StatusInProgress); !errors.Is(err, service.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := svc.Get(ctx, 7); !errors.Is(err, service.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestSubtasks_TreeQueries(t *testing.T) {
+	svc, fs := mustNewService(t, nil)
+	epic, _ := svc.Add(ctx, "Epic", "", model.PriorityHigh, nil)
+	step1, _ := svc.AddSubtask(ctx, epic, "Step 1", "", model.PriorityLow, nil)
+	step2, _ := svc.AddSubtask(ctx, epic, "Step 2", "", model.PriorityLow, nil)
+	sub, _ := svc.AddSubtask(ctx, step1, "Step 1.1", "", model.PriorityLow, nil)
+
+	if _, err := svc.AddSubtask(ctx, 999, "orphan", "", model.PriorityLow, nil); !errors.Is(err, service.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for missing parent, got %v", err)
+	}
+	if fs.items[sub].ParentID != step1 {
+		t.Fatalf("parent not persisted: %+v", fs.items[sub])
+	}
+
+	kids, err := svc.Children(ctx, epic)
+	if err != nil || len(kids) != 2 || kids[0].ID() != step1 || kids[1].ID() != step2 {
+		t.Fatalf("unexpected children: %v %v", kids, err)
+	}
+	anc, _ := svc.Ancestors(ctx, sub)
+	if len(anc) != 2 || anc[0].ID() != step1 || anc[1].ID() != epic {
+		t.Fatalf("unexpected ancestors: %v", anc)
+	}
+
+	_ = svc.SetStatus(ctx, sub, model.StatusInProgress)
+	_ = svc.SetStatus(ctx, sub, model.StatusDone)
+	_ = svc.SetStatus(ctx, step2, model.StatusCanceled)
+	p, _ := svc.Progress(ctx, epic)
+	if p.Total != 2 || p.Done != 1 || p.Percent() != 50 {
+		t.Fatalf("unexpected progress: %+v", p)
+	}
+
+	// консольный вывод: родитель, за ним подзадачи с глубиной
+	var order []model.ID
+	var depths []int
+	for _, n := range model.Tree(listAll(t, svc)) {
+		order = append(order, n.Task.ID())
+		depths = append(depths, n.Depth)
+	}
+	if fmt.Sprint(order) != fmt.Sprint([]model.ID{epic, step1, sub, step2}) || fmt.Sprint(depths) != "[0 1 2 1]" {
+		t.Fatalf("unexpected tree order %v depths %v", order, depths)
+	}
+}
+
+// Закрыть родителя, пока в поддереве есть открытые задачи, нельзя.
+func TestSubtasks_CompleteParentWithOpenChildren(t *testing.T) {
+	svc, _ := mustNewService(t, nil)
+	parent, _ := svc.Add(ctx, "P", "", model.PriorityLow, nil)
+	child, _ := svc.AddSubtask(ctx, parent, "C", "", model.PriorityLow, nil)
+	grand, _ := svc.AddSubtask(ctx, child, "G", "", model.PriorityLow, nil)
+	_ = svc.SetStatus(ctx, parent, model.StatusInProgress)
 
-	fs.saveErr = errors.New("save failed")
-	if _, err := svc.Add("A", "", model.PriorityLow, nil); err == nil {
-		t.Fatal("expected error from Save")
+	if err := svc.SetStatus(ctx, parent, model.StatusDone); !errors.Is(err, service.ErrOpenSubtasks) {
+		t.Fatalf("expected ErrOpenSubtasks, got %v", err)
+	}
+	_ = svc.SetStatus(ctx, child, model.StatusCanceled)
+	// внук всё ещё открыт
+	if err := svc.SetStatus(ctx, parent, model.StatusDone); !errors.Is(err, service.ErrOpenSubtasks) {
+		t.Fatalf("expected ErrOpenSubtasks for open grandchild, got %v", err)
 	}
-}
\ No newline at end of file
+	_ = svc.SetStatus(ctx, grand, model.StatusCanceled)
+	if err := svc.SetStatus(ctx, parent, model.StatusDone); err != nil {
+		t.Fatalf("all subtasks closed, done must pass: %v", err)
+	}
+}
+
+func TestSubtasks_DeleteReparentsOrCascades(t *testing.T) {
+	svc, fs := mustNewService(t, nil)
+	root, _ := svc.Add(ctx, "Root", "", model.PriorityLow, nil)
+	mid, _ := svc.AddSubtask(ctx, root, "Mid", "", model.PriorityLow, nil)
+	leaf1, _ := svc.AddSubtask(ctx, mid, "Leaf 1", "", model.PriorityLow, nil)
+	leaf2, _ := svc.AddSubtask(ctx, mid, "Leaf 2", "", model.PriorityLow, nil)
+
+	// обычное удаление: дети поднимаются к деду
+	if err := svc.Delete(ctx, mid); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	for _, id := range []model.ID{leaf1, leaf2} {
+		if got, _ := svc.Get(ctx, id); got.ParentID() != root || fs.items[id].ParentID != root {
+			t.Fatalf("task %d must move under %d", id, root)
+		}
+	}
+
+	// каскадное: уходит всё поддерево
+	if err := svc.DeleteTree(ctx, root); err != nil {
+		t.Fatalf("DeleteTree: %v", err)
+	}
+	if len(listAll(t, svc)) != 0 || fs.live() != 0 {
+		t.Fatalf("expected empty after cascade, got %d (store %d)", len(listAll(t, svc)), fs.live())
+	}
+}
+
+func TestSetParent_RejectsCycles(t *testing.T) {
+	svc, _ := mustNewService(t, nil)
+	a, _ := svc.Add(ctx, "A", "", model.PriorityLow, nil)
+	b, _ := svc.AddSubtask(ctx, a, "B", "", model.PriorityLow, nil)
+	c, _ := svc.AddSubtask(ctx, b, "C", "", model.PriorityLow, nil)
+
+	for _, parent := range []model.ID{a, c} {
+		if err := svc.SetParent(ctx, a, parent); !errors.Is(err, service.ErrParentCycle) {
+			t.Fatalf("SetParent(a, %d): expected ErrParentCycle, got %v", parent, err)
+		}
+	}
+	if err := svc.SetParent(ctx, c, a); err != nil {
+		t.Fatalf("SetParent: %v", err)
+	}
+	if err := svc.SetParent(ctx, c, 0); err != nil {
+		t.Fatalf("detach: %v", err)
+	}
+	if got, _ := svc.Get(ctx, c); got.ParentID() != 0 {
+		t.Fatalf("expected top-level, got parent %d", got.ParentID())
+	}
+}
+
+// При перенумерации ссылки на родителя переезжают вместе с ID.
+func TestRenumberIDs_RemapsParents(t *testing.T) {
+	now := time.Now()
+	initial := []model.TaskDTO{
+		{ID: 10, Title: "P", Status: model.StatusNew, Priority: model.PriorityLow, CreatedAt: now.Add(-3 * time.Minute)},
+		{ID: 2, Title: "C", Status: model.StatusNew, Priority: model.PriorityLow, CreatedAt: now.Add(-2 * time.Minute), ParentID: 10},
+		{ID: 30, Title: "G", Status: model.StatusNew, Priority: model.PriorityLow, CreatedAt: now.Add(-time.Minute), ParentID: 2},
+	}
+	svc, fs := mustNewService(t, initial)
+	if err := svc.RenumberIDs(ctx); err != nil {
+		t.Fatalf("RenumberIDs: %v", err)
+	}
+	// P=1, C=2 (ID не поменялся, но родитель — да), G=3
+	if fs.items[2].ParentID != 1 || fs.items[3].ParentID != 2 || fs.items[1].ParentID != 0 {
+		t.Fatalf("parents not remapped in store: %+v", fs.items)
+	}
+	if anc, _ := svc.Ancestors(ctx, 3); len(anc) != 2 || anc[1].ID() != 1 {
+		t.Fatalf("cache parents not remapped: %v", anc)
+	}
+}
+
+func TestDependencies_BlockStartAndDetectCycles(t *testing.T) {
+	svc, fs := mustNewService(t, nil)
+	a, _ := svc.Add(ctx, "A", "", model.PriorityLow, nil)
+	b, _ := svc.Add(ctx, "B", "", model.PriorityLow, nil)
+	c, _ := svc.Add(ctx, "C", "", model.PriorityLow, nil)
+
+	// c ждёт a и b
+	for _, blocker := range []model.ID{a, b} {
+		if err := svc.AddDependency(ctx, c, blocker); err != nil {
+			t.Fatalf("AddDependency: %v", err)
+		}
+	}
+	if got := fs.items[c].BlockedBy; fmt.Sprint(got) != fmt.Sprint([]model.ID{a, b}) {
+		t.Fatalf("blockers not persisted: %v", got)
+	}
+	if err := svc.AddDependency(ctx, c, 999); !errors.Is(err, service.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	// циклы: прямой, через цепочку и на себя
+	for _, edge := range [][2]model.ID{{a, c}, {c, c}} {
+		if err := svc.AddDependency(ctx, edge[0], edge[1]); !errors.Is(err, service.ErrDependencyCycle) {
+			t.Fatalf("AddDependency(%d, %d): expected cycle, got %v", edge[0], edge[1], err)
+		}
+	}
+	_ = svc.AddDependency(ctx, b, a)
+	if err := svc.AddDependency(ctx, a, c); !errors.Is(err, service.ErrDependencyCycle) {
+		t.Fatalf("expected transitive cycle, got %v", err)
+	}
+
+	if err := svc.SetStatus(ctx, c, model.StatusInProgress); !errors.Is(err, service.ErrBlocked) {
+		t.Fatalf("expected ErrBlocked, got %v", err)
+	}
+	for _, id := range []model.ID{a, b} {
+		_ = svc.SetStatus(ctx, id, model.StatusInProgress)
+		_ = svc.SetStatus(ctx, id, model.StatusDone)
+	}
+	if err := svc.SetStatus(ctx, c, model.StatusInProgress); err != nil {
+		t.Fatalf("blockers done, start must pass: %v", err)
+	}
+}
+
+func TestDependencies_ReadyAndTopoOrder(t *testing.T) {
+	svc, _ := mustNewService(t, nil)
+	low, _ := svc.Add(ctx, "low", "", model.PriorityLow, nil)
+	high, _ := svc.Add(ctx, "high", "", model.PriorityHigh, nil)
+	dep, _ := svc.Add(ctx, "dep", "", model.PriorityHigh, nil)
+	last, _ := svc.Add(ctx, "last", "", model.PriorityHigh, nil)
+	_ = svc.AddDependency(ctx, dep, low)
+	_ = svc.AddDependency(ctx, last, dep)
+	_ = svc.AddDependency(ctx, last, high)
+
+	ready, _ := svc.Ready(ctx)
+	if ids := taskIDs(ready); fmt.Sprint(ids) != fmt.Sprint([]model.ID{low, high}) {
+		t.Fatalf("unexpected ready set: %v", ids)
+	}
+	order, _ := svc.TopoOrder(ctx)
+	if ids := taskIDs(order); fmt.Sprint(ids) != fmt.Sprint([]model.ID{high, low, dep, last}) {
+		t.Fatalf("unexpected order: %v", ids)
+	}
+
+	// снятая зависимость освобождает задачу
+	_ = svc.RemoveDependency(ctx, dep, low)
+	ready, _ = svc.Ready(ctx)
+	if len(ready) != 3 {
+		t.Fatalf("expected 3 ready after RemoveDependency, got %v", taskIDs(ready))
+	}
+}
+
+// Отменённый блокер закрыт: Ready, TopoOrder и смена статуса считают одинаково
+func TestDependencies_CanceledBlockerDoesNotBlock(t *testing.T) {
+	svc, _ := mustNewService(t, nil)
+	blocker, _ := svc.Add(ctx, "blocker", "", model.PriorityLow, nil)
+	dep, _ := svc.Add(ctx, "dep", "", model.PriorityHigh, nil)
+	if err := svc.AddDependency(ctx, dep, blocker); err != nil {
+		t.Fatal(err)
+	}
+	if ready, _ := svc.Ready(ctx); slices.Contains(taskIDs(ready), dep) {
+		t.Fatal("dep is ready while its blocker is open")
+	}
+
+	if err := svc.SetStatus(ctx, blocker, model.StatusCanceled); err != nil {
+		t.Fatal(err)
+	}
+	if ready, _ := svc.Ready(ctx); !slices.Equal(taskIDs(ready), []model.ID{dep}) {
+		t.Fatalf("ready after cancel: %v", taskIDs(ready))
+	}
+	if order, _ := svc.TopoOrder(ctx); !slices.Equal(taskIDs(order), []model.ID{dep}) {
+		t.Fatalf("order after cancel: %v", taskIDs(order))
+	}
+	for _, st := range []model.Status{model.StatusInProgress, model.StatusDone} {
+		if err := svc.SetStatus(ctx, dep, st); err != nil {
+			t.Fatalf("%s with canceled blocker: %v", st, err)
+		}
+	}
+}
+
+// Удалённый блокер пропадает из зависимых, перенумерация переводит ссылки.
+func TestDependencies_DeleteAndRenumber(t *testing.T) {
+	now := time.Now()
+	initial := []model.TaskDTO{
+		{ID: 5, Title: "A", Status: model.StatusNew, Priority: model.PriorityLow, CreatedAt: now.Add(-3 * time.Minute)},
+		{ID: 9, Title: "B", Status: model.StatusNew, Priority: model.PriorityLow, CreatedAt: now.Add(-2 * time.Minute)},
+		{ID: 12, Title: "C", Status: model.StatusNew, Priority: model.PriorityLow, CreatedAt: now.Add(-time.Minute), BlockedBy: []model.ID{5, 9}},
+	}
+	svc, fs := mustNewService(t, initial)
+	if err := svc.RenumberIDs(ctx); err != nil {
+		t.Fatalf("RenumberIDs: %v", err)
+	}
+	if got := fs.items[3].BlockedBy; fmt.Sprint(got) != "[1 2]" {
+		t.Fatalf("blockers not remapped: %v", got)
+	}
+
+	if err := svc.Delete(ctx, 1); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if got, _ := svc.Get(ctx, 3); fmt.Sprint(got.BlockedBy()) != "[2]" || fmt.Sprint(fs.items[3].BlockedBy) != "[2]" {
+		t.Fatalf("deleted blocker still referenced: %v / %v", got.BlockedBy(), fs.items[3].BlockedBy)
+	}
+}
+
+func taskIDs(list []*model.Task) []model.ID {
+	ids := make([]model.ID, 0, len(list))
+	for _, t := range list {
+		ids = append(ids, t.ID())
+	}
+	return ids
+}
+
+func mustRule(t *testing.T, s string) *model.Recurrence {
+	t.Helper()
+	r, err := model.ParseRecurrence(s)
+	if err != nil {
+		t.Fatalf("ParseRecurrence(%q): %v", s, err)
+	}
+	return r
+}
+
+func TestRecurrence_Next(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 9, 0, 0, 0, time.UTC) }
+	cases := []struct {
+		rule string
+		from time.Time
+		want time.Time
+	}{
+		{"FREQ=DAILY", day(2026, 3, 1), day(2026, 3, 2)},
+		{"FREQ=DAILY;INTERVAL=3", day(2026, 3, 1), day(2026, 3, 4)},
+		{"FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", day(2026, 3, 6), day(2026, 3, 9)}, // пятница -> понедельник
+		{"FREQ=WEEKLY", day(2026, 3, 2), day(2026, 3, 9)},
+		{"FREQ=WEEKLY;BYDAY=MO,TH", day(2026, 3, 2), day(2026, 3, 5)},             // пн -> чт той же недели
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", day(2026, 3, 5), day(2026, 3, 16)}, // чт -> пн через неделю
+		{"FREQ=MONTHLY", day(2026, 1, 15), day(2026, 2, 15)},
+		{"FREQ=MONTHLY;BYMONTHDAY=31", day(2026, 1, 31), day(2026, 2, 28)},
+		{"FREQ=MONTHLY;BYMONTHDAY=31", day(2026, 2, 28), day(2026, 3, 31)},
+		{"FREQ=YEARLY", day(2028, 2, 29), day(2029, 2, 28)},
+	}
+	for _, c := range cases {
+		got, ok := mustRule(t, c.rule).Next(c.from)
+		if !ok || !got.Equal(c.want) {
+			t.Errorf("%s from %s: got %s (%v), want %s", c.rule, c.from.Format("2006-01-02"), got.Format("2006-01-02"), ok, c.want.Format("2006-01-02"))
+		}
+	}
+	if _, ok := mustRule(t, "FREQ=DAILY;UNTIL=2026-03-01").Next(day(2026, 3, 1)); ok {
+		t.Fatal("UNTIL must stop the series")
+	}
+	for _, bad := range []string{"", "FREQ=HOURLY", "FREQ=DAILY;INTERVAL=0", "FREQ=DAILY;COUNT=2;UNTIL=2026-01-01", "FREQ=MONTHLY;BYDAY=MO", "FREQ=WEEKLY;BYDAY=XX"} {
+		if _, err := model.ParseRecurrence(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+	if got := mustRule(t, "rrule:byday=th,mo;freq=weekly;count=3").String(); got != "FREQ=WEEKLY;BYDAY=MO,TH;COUNT=3" {
+		t.Fatalf("unexpected canonical form %q", got)
+	}
+}
+
+// DAILY с шагом, кратным неделе, день недели не меняет: с BYDAY такое правило
+// либо лишнее, либо не совпадёт никогда — парсер его не пускает
+func TestRecurrence_DailyIntervalWithByday(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 9, 0, 0, 0, time.UTC) }
+	cases := []struct {
+		rule string
+		from time.Time // 2026-03-03 — вторник
+		want time.Time // ноль — правило отвергнуто
+	}{
+		{"FREQ=DAILY;INTERVAL=7", day(2026, 3, 3), day(2026, 3, 10)},
+		{"FREQ=DAILY;INTERVAL=14", day(2026, 3, 3), day(2026, 3, 17)},
+		{"FREQ=DAILY;INTERVAL=7;BYDAY=MO", day(2026, 3, 3), time.Time{}},
+		{"FREQ=DAILY;INTERVAL=14;BYDAY=TU", day(2026, 3, 3), time.Time{}},
+		{"FREQ=DAILY;INTERVAL=14;BYDAY=MO,FR", day(2026, 3, 3), time.Time{}},
+		{"FREQ=DAILY;INTERVAL=3;BYDAY=MO", day(2026, 3, 3), day(2026, 3, 9)},   // вт -> пт -> пн
+		{"FREQ=DAILY;INTERVAL=8;BYDAY=SU", day(2026, 3, 3), day(2026, 4, 12)},  // +8 сдвигает день на один: вт, ср, ... вс
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO", day(2026, 3, 3), day(2026, 3, 16)}, // то, что имелось в виду
+	}
+	for _, c := range cases {
+		r, err := model.ParseRecurrence(c.rule)
+		if c.want.IsZero() {
+			if err == nil {
+				t.Errorf("%s: expected parse error", c.rule)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", c.rule, err)
+			continue
+		}
+		if got, ok := r.Next(c.from); !ok || !got.Equal(c.want) {
+			t.Errorf("%s: got %s (%v), want %s", c.rule, got.Format("2006-01-02"), ok, c.want.Format("2006-01-02"))
+		}
+	}
+
+	// такое правило, сохранённое до проверки, задачу не теряет: она поднимается,
+	// а подходящего дня нет — серия кончается, а не врёт
+	stored, err := model.NewTask("Старая серия", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored.SetID(1)
+	dto := stored.ToDTO()
+	dto.Recurrence = "FREQ=DAILY;INTERVAL=14;BYDAY=MO"
+	legacy, err := model.FromDTO(dto)
+	if err != nil {
+		t.Fatalf("stored legacy rule: %v", err)
+	}
+	if got, ok := legacy.Recurrence().Next(day(2026, 3, 3)); ok {
+		t.Fatalf("legacy rule must not land on %s (%s)", got.Format("2006-01-02"), got.Weekday())
+	}
+}
+
+// Закрытие повторяющейся задачи создаёт следующую со сдвинутым сроком,
+// правило переезжает на неё, COUNT убывает и серия заканчивается.
+func TestRecurrence_SpawnsNextOnDone(t *testing.T) {
+	svc, fs := mustNewService(t, nil)
+	due := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	id, _ := svc.Add(ctx, "Standup prep", "notes", model.PriorityHigh, &due)
+	if err := svc.SetRecurrence(ctx, id, mustRule(t, "FREQ=WEEKLY;COUNT=2")); err != nil {
+		t.Fatalf("SetRecurrence: %v", err)
+	}
+	if fs.items[id].Recurrence != "FREQ=WEEKLY;COUNT=2" {
+		t.Fatalf("rule not persisted: %q", fs.items[id].Recurrence)
+	}
+
+	complete := func(id model.ID) {
+		t.Helper()
+		_ = svc.SetStatus(ctx, id, model.StatusInProgress)
+		if err := svc.SetStatus(ctx, id, model.StatusDone); err != nil {
+			t.Fatalf("SetStatus done: %v", err)
+		}
+	}
+	complete(id)
+
+	list := listAll(t, svc)
+	if len(list) != 2 {
+		t.Fatalf("expected next occurrence, got %d tasks", len(list))
+	}
+	next := list[1]
+	if next.Title() != "Standup prep" || next.Status() != model.StatusNew || next.Priority() != model.PriorityHigh {
+		t.Fatalf("unexpected next occurrence: %+v", next.ToDTO())
+	}
+	if want := due.AddDate(0, 0, 7); next.DueAt() == nil || !next.DueAt().Equal(want) {
+		t.Fatalf("due not rolled forward: %v", next.DueAt())
+	}
+	if next.Recurrence().String() != "FREQ=WEEKLY;COUNT=1" || findTaskByID(list, id).Recurrence() != nil {
+		t.Fatal("rule must move to the next occurrence")
+	}
+
+	// переоткрыть и снова закрыть — дубля нет
+	_ = svc.SetStatus(ctx, id, model.StatusInProgress)
+	complete(id)
+	// последнее повторение серии новых не создаёт
+	complete(next.ID())
+	if n := len(listAll(t, svc)); n != 2 {
+		t.Fatalf("expected series to end with 2 tasks, got %d", n)
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	got, err := model.NormalizeTags([]string{" #Backend ", "code review", "backend", "Срочно"})
+	if err != nil {
+		t.Fatalf("NormalizeTags: %v", err)
+	}
+	if want := []string{"backend", "code-review", "срочно"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for _, bad := range []string{"", "#", "a/b", "x,y", strings.Repeat("a", model.MaxTagLen+1)} {
+		if _, err := model.NormalizeTag(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestTags_AddRemoveAndFilter(t *testing.T) {
+	svc, fs := mustNewService(t, nil)
+	a, _ := svc.Add(ctx, "A", "", model.PriorityLow, nil)
+	b, _ := svc.Add(ctx, "B", "", model.PriorityLow, nil)
+	c, _ := svc.Add(ctx, "C", "", model.PriorityLow, nil)
+
+	for _, op := range []struct {
+		id  model.ID
+		tag string
+	}{{a, "Work"}, {a, "urgent"}, {b, "work"}, {c, "home"}, {c, "#Urgent"}} {
+		if err := svc.AddTag(ctx, op.id, op.tag); err != nil {
+			t.Fatalf("AddTag(%d, %q): %v", op.id, op.tag, err)
+		}
+	}
+	if !reflect.DeepEqual(fs.items[a].Tags, []string{"urgent", "work"}) {
+		t.Fatalf("tags not persisted: %v", fs.items[a].Tags)
+	}
+
+	// повторное добавление и снятие отсутствующего тега в хранилище не пишут
+	updates := fs.updates
+	if err := svc.AddTag(ctx, a, "WORK"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.RemoveTag(ctx, b, "home"); err != nil {
+		t.Fatal(err)
+	}
+	if fs.updates != updates {
+		t.Fatalf("no-op tag changes wrote to store")
+	}
+	if err := svc.AddTag(ctx, a, "bad/tag"); err == nil {
+		t.Fatal("expected error for bad tag")
+	}
+	if err := svc.AddTag(ctx, 999, "x"); !errors.Is(err, service.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	query := func(q model.TaskQuery) []model.ID {
+		t.Helper()
+		page, err := svc.List(ctx, q)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		return taskIDs(page.Items)
+	}
+	if got := query(model.TaskQuery{Tags: []string{"work", "urgent"}}); !reflect.DeepEqual(got, []model.ID{a}) {
+		t.Fatalf("AND filter: got %v", got)
+	}
+	if got := query(model.TaskQuery{AnyTags: []string{"work", "home"}}); !reflect.DeepEqual(got, []model.ID{a, b, c}) {
+		t.Fatalf("OR filter: got %v", got)
+	}
+	if got := query(model.TaskQuery{Tags: []string{"urgent"}, AnyTags: []string{"home"}}); !reflect.DeepEqual(got, []model.ID{c}) {
+		t.Fatalf("AND+OR filter: got %v", got)
+	}
+
+	if err := svc.RemoveTag(ctx, a, "Urgent"); err != nil {
+		t.Fatal(err)
+	}
+	if got := query(model.TaskQuery{Tags: []string{"urgent"}}); !reflect.DeepEqual(got, []model.ID{c}) {
+		t.Fatalf("after RemoveTag: got %v", got)
+	}
+}
+
+func TestTags_Counts(t *testing.T) {
+	svc, _ := mustNewService(t, []model.TaskDTO{
+		{ID: 5, Title: "A", Status: model.StatusNew, Priority: model.PriorityLow, CreatedAt: time.Now().Add(-2 * time.Hour), Tags: []string{"work", "Urgent"}},
+		{ID: 9, Title: "B", Status: model.StatusNew, Priority: model.PriorityLow, CreatedAt: time.Now().Add(-time.Hour), Tags: []string{"work"}},
+	})
+	counts := func() []model.TagCount {
+		t.Helper()
+		c, err := svc.TagCounts(ctx)
+		if err != nil {
+			t.Fatalf("TagCounts: %v", err)
+		}
+		return c
+	}
+	want := []model.TagCount{{Tag: "work", Count: 2}, {Tag: "urgent", Count: 1}}
+	if got := counts(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	// индекс переживает перенумерацию и следит за удалением
+	if err := svc.RenumberIDs(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := counts(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("after renumber: got %v, want %v", got, want)
+	}
+	if err := svc.Delete(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := counts(); !reflect.DeepEqual(got, []model.TagCount{{Tag: "work", Count: 1}}) {
+		t.Fatalf("after delete: got %v", got)
+	}
+}
+
+func TestOwnership_Visibility(t *testing.T) {
+	svc, _ := mustNewService(t, []model.TaskDTO{
+		{ID: 1, Title: "общая", Status: model.StatusNew, Priority: model.PriorityLow, CreatedAt: time.Now().Add(-3 * time.Hour), Tags: []string{"x"}},
+	})
+	alice := reqctx.WithUser(ctx, reqctx.User{ID: 1, Login: "alice"})
+	bob := reqctx.WithUser(ctx, reqctx.User{ID: 2, Login: "bob"})
+	admin := reqctx.WithUser(ctx, reqctx.User{ID: 3, Login: "root", Role: model.RoleAdmin})
+
+	mine, err := svc.Add(alice, "алисина", "", model.PriorityLow, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := svc.Get(ctx, mine)
+	if got.CreatedBy() != 1 || got.Assignee() != 0 {
+		t.Fatalf("creator not taken from ctx: %d/%d", got.CreatedBy(), got.Assignee())
+	}
+	if err := svc.AddTag(alice, mine, "x"); err != nil {
+		t.Fatal(err)
+	}
+
+	visible := func(c context.Context) []model.ID {
+		t.Helper()
+		page, err := svc.List(c, model.TaskQuery{})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		return taskIDs(page.Items)
+	}
+	if ids := visible(bob); !reflect.DeepEqual(ids, []model.ID{1}) {
+		t.Fatalf("bob sees %v", ids)
+	}
+	if _, err := svc.Get(bob, mine); !errors.Is(err, service.ErrNotFound) {
+		t.Fatalf("bob Get: %v", err)
+	}
+	if err := svc.UpdateTitle(bob, mine, "чужое"); !errors.Is(err, service.ErrNotFound) {
+		t.Fatalf("bob UpdateTitle: %v", err)
+	}
+	if err := svc.Delete(bob, mine); !errors.Is(err, service.ErrNotFound) {
+		t.Fatalf("bob Delete: %v", err)
+	}
+	if _, err := svc.AddSubtask(bob, mine, "под чужую", "", model.PriorityLow, nil); !errors.Is(err, service.ErrNotFound) {
+		t.Fatalf("bob AddSubtask: %v", err)
+	}
+	counts, _ := svc.TagCounts(bob)
+	if !reflect.DeepEqual(counts, []model.TagCount{{Tag: "x", Count: 1}}) {
+		t.Fatalf("bob tag counts: %v", counts)
+	}
+
+	// назначили — увидел и может работать
+	if err := svc.Assign(alice, mine, 2); err != nil {
+		t.Fatal(err)
+	}
+	if ids := visible(bob); !reflect.DeepEqual(ids, []model.ID{1, mine}) {
+		t.Fatalf("bob after assign sees %v", ids)
+	}
+	if err := svc.SetStatus(bob, mine, model.StatusInProgress); err != nil {
+		t.Fatalf("assignee SetStatus: %v", err)
+	}
+	page, err := svc.List(alice, model.TaskQuery{Assignee: 2})
+	if err != nil || !reflect.DeepEqual(taskIDs(page.Items), []model.ID{mine}) {
+		t.Fatalf("assigned to bob: %v, %v", page.Items, err)
+	}
+
+	// администратор и внутренние вызовы без пользователя видят всё
+	bobs, _ := svc.Add(bob, "бобова", "", model.PriorityLow, nil)
+	for name, c := range map[string]context.Context{"admin": admin, "internal": ctx} {
+		if ids := visible(c); len(ids) != 3 {
+			t.Fatalf("%s sees %v", name, ids)
+		}
+	}
+	if ids := visible(alice); !reflect.DeepEqual(ids, []model.ID{1, mine}) {
+		t.Fatalf("alice sees %v (bob's task is %d)", ids, bobs)
+	}
+}
+
+func TestRenumberIDs_AdminOnly(t *testing.T) {
+	svc, _ := mustNewService(t, []model.TaskDTO{
+		{ID: 7, Title: "A", Status: model.StatusNew, Priority: model.PriorityLow, CreatedAt: time.Now()},
+	})
+	member := reqctx.WithUser(ctx, reqctx.User{ID: 1, Login: "alice", Role: model.RoleMember})
+	if err := svc.RenumberIDs(member); !errors.Is(err, policy.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+	if _, err := svc.Get(ctx, 7); err != nil {
+		t.Fatalf("forbidden renumber must not touch tasks: %v", err)
+	}
+	admin := reqctx.WithUser(ctx, reqctx.User{ID: 2, Login: "root", Role: model.RoleAdmin})
+	if err := svc.RenumberIDs(admin); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Get(ctx, 1); err != nil {
+		t.Fatalf("after renumber: %v", err)
+	}
+}
+
+func TestProjects_IsolationAndMembership(t *testing.T) {
+	dir := t.TempDir()
+	ps, err := service.NewProjects(ctx, repository.NewJSONProjectStore(dir+"/projects.json"),
+		func(p model.ProjectID) service.Store {
+			return repository.NewJSONStore(repository.JSONProjectPath(dir+"/tasks.json", p))
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin := reqctx.WithUser(ctx, reqctx.User{ID: 1, Login: "root", Role: model.RoleAdmin})
+	alice := reqctx.WithUser(ctx, reqctx.User{ID: 2, Login: "alice", Role: model.RoleMember})
+	bob := reqctx.WithUser(ctx, reqctx.User{ID: 3, Login: "bob", Role: model.RoleMember})
+
+	if _, err := ps.Create(alice, "team"); !errors.Is(err, policy.ErrForbidden) {
+		t.Fatalf("member creates project: %v", err)
+	}
+	team, err := ps.Create(admin, "  team ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if team.Name != "team" || !team.HasMember(1) {
+		t.Fatalf("created %+v", team)
+	}
+	if _, err := ps.Create(admin, "team"); !errors.Is(err, service.ErrProjectExists) {
+		t.Fatalf("duplicate name: %v", err)
+	}
+	if _, err := ps.AddMember(admin, team.ID, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	// ID задач у каждого проекта свои
+	def, err := ps.Tasks(alice, model.DefaultProject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tm, err := ps.Tasks(alice, team.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id1, _ := def.Add(alice, "default task", "", model.PriorityLow, nil)
+	id2, _ := tm.Add(alice, "team task", "", model.PriorityLow, nil)
+	if id1 != 1 || id2 != 1 {
+		t.Fatalf("ids %d, %d: want 1 in both projects", id1, id2)
+	}
+	if got, _ := tm.Get(alice, 1); got.Title() != "team task" {
+		t.Fatalf("team project sees %q", got.Title())
+	}
+
+	// не участник проекта не видит ни его, ни его задач
+	if _, err := ps.Tasks(bob, team.ID); !errors.Is(err, service.ErrProjectNotFound) {
+		t.Fatalf("outsider enters project: %v", err)
+	}
+	if list := ps.List(bob); len(list) != 1 || list[0].ID != model.DefaultProject {
+		t.Fatalf("outsider lists %+v", list)
+	}
+	if _, err := ps.RemoveMember(admin, team.ID, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ps.Tasks(alice, team.ID); !errors.Is(err, service.ErrProjectNotFound) {
+		t.Fatalf("removed member enters project: %v", err)
+	}
+
+	// реестр перечитывается с диска
+	ps2, err := service.NewProjects(ctx, repository.NewJSONProjectStore(dir+"/projects.json"),
+		func(p model.ProjectID) service.Store {
+			return repository.NewJSONStore(repository.JSONProjectPath(dir+"/tasks.json", p))
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list := ps2.List(admin); len(list) != 2 {
+		t.Fatalf("after reload: %+v", list)
+	}
+}
+
+// фоновые проходы берут арендаторов у хранилища пользователей: после перезапуска
+// чистят и архивируют и тех, к кому ещё никто не обращался
+func TestTenants_SweepReachesUnloadedTenants(t *testing.T) {
+	dir := t.TempDir()
+	newTenants := func() *service.Tenants {
+		return service.NewTenants(func(tn model.TenantID) (service.ProjectStore, func(model.ProjectID) service.Store) {
+			tasksPath := repository.JSONTenantPath(dir+"/tasks.json", tn)
+			return repository.NewJSONProjectStore(repository.JSONTenantPath(dir+"/projects.json", tn)),
+				func(p model.ProjectID) service.Store {
+					return repository.NewJSONStore(repository.JSONProjectPath(tasksPath, p))
+				}
+		})
+	}
+	users := repository.NewJSONUserStore(dir + "/users.json")
+	if _, err := users.Insert(ctx, model.User{Login: "alice", Role: model.RoleAdmin, Tenant: "acme"}); err != nil {
+		t.Fatal(err)
+	}
+	acme := reqctx.WithUser(ctx, reqctx.User{ID: 1, Login: "alice", Role: model.RoleAdmin, Tenant: "acme"})
+
+	ps, err := newTenants().Projects(acme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc, err := ps.Tasks(acme, model.DefaultProject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gone, _ := svc.Add(acme, "В корзину", "", model.PriorityLow, nil)
+	done, _ := svc.Add(acme, "Сделано", "", model.PriorityLow, nil)
+	if err := svc.Delete(acme, gone); err != nil {
+		t.Fatal(err)
+	}
+	for _, st := range []model.Status{model.StatusInProgress, model.StatusDone} {
+		if err := svc.SetStatus(acme, done, st); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// «перезапуск»: acme ещё никто не поднимал
+	tenants := newTenants()
+	later := time.Now().Add(time.Minute)
+	if n, err := tenants.PurgeTrash(ctx, later); err != nil || n != 0 {
+		t.Fatalf("without lister: purged %d, %v", n, err)
+	}
+	tenants.ListFrom(users)
+	if n, err := tenants.PurgeTrash(ctx, later); err != nil || n != 1 {
+		t.Fatalf("purged %d, %v", n, err)
+	}
+	if n, err := tenants.ArchiveDone(ctx, later); err != nil || n != 1 {
+		t.Fatalf("archived %d, %v", n, err)
+	}
+}
+
+func TestTenants_Isolation(t *testing.T) {
+	dir := t.TempDir()
+	tenants := service.NewTenants(func(tn model.TenantID) (service.ProjectStore, func(model.ProjectID) service.Store) {
+		tasksPath := repository.JSONTenantPath(dir+"/tasks.json", tn)
+		return repository.NewJSONProjectStore(repository.JSONTenantPath(dir+"/projects.json", tn)),
+			func(p model.ProjectID) service.Store {
+				return repository.NewJSONStore(repository.JSONProjectPath(tasksPath, p))
+			}
+	})
+	acme := reqctx.WithUser(ctx, reqctx.User{ID: 1, Login: "alice", Role: model.RoleAdmin, Tenant: "acme"})
+	globex := reqctx.WithUser(ctx, reqctx.User{ID: 2, Login: "bob", Role: model.RoleAdmin, Tenant: "globex"})
+
+	tasks := func(ctx context.Context) *service.Service {
+		t.Helper()
+		ps, err := tenants.Projects(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		svc, err := ps.Tasks(ctx, model.DefaultProject)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return svc
+	}
+	a, g := tasks(acme), tasks(globex)
+	if a == g {
+		t.Fatal("tenants share a service")
+	}
+	idA, _ := a.Add(acme, "acme secret", "", model.PriorityHigh, nil)
+	if idA != 1 {
+		t.Fatalf("acme id %d", idA)
+	}
+
+	// чужая задача не видна ни списком, ни поиском, ни по ID
+	if list, _ := g.List(globex, model.TaskQuery{}); len(list.Items) != 0 {
+		t.Fatalf("globex lists %d tasks", len(list.Items))
+	}
+	if hits, _ := g.Search(globex, "secret", 10); len(hits) != 0 {
+		t.Fatalf("globex finds %d tasks", len(hits))
+	}
+	if _, err := g.Get(globex, idA); !errors.Is(err, service.ErrNotFound) {
+		t.Fatalf("globex gets acme task: %v", err)
+	}
+	// и не меняется: у globex свой счётчик, его задача 1 — это его задача
+	if err := g.UpdateTitle(globex, idA, "hacked"); !errors.Is(err, service.ErrNotFound) {
+		t.Fatalf("globex updates acme task: %v", err)
+	}
+	if err := g.Delete(globex, idA); !errors.Is(err, service.ErrNotFound) {
+		t.Fatalf("globex deletes acme task: %v", err)
+	}
+	idG, _ := g.Add(globex, "globex task", "", model.PriorityLow, nil)
+	if idG != 1 {
+		t.Fatalf("globex id %d", idG)
+	}
+	if got, err := a.Get(acme, idA); err != nil || got.Title() != "acme secret" {
+		t.Fatalf("acme task after globex writes: %v, %v", got, err)
+	}
+
+	// проекты тоже у каждого свои
+	ps, _ := tenants.Projects(globex)
+	if _, err := ps.Create(globex, "team"); err != nil {
+		t.Fatal(err)
+	}
+	psA, _ := tenants.Projects(acme)
+	if list := psA.List(acme); len(list) != 1 {
+		t.Fatalf("acme sees globex projects: %+v", list)
+	}
+
+	// на диске данные лежат раздельно, общий арендатор их не видит
+	def := tasks(ctx)
+	if list, _ := def.List(ctx, model.TaskQuery{}); len(list.Items) != 0 {
+		t.Fatalf("default tenant sees %d tasks", len(list.Items))
+	}
+	reloaded, err := service.New(ctx, repository.NewJSONStore(repository.JSONTenantPath(dir+"/tasks.json", "acme")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := reloaded.List(ctx, model.TaskQuery{}); len(list.Items) != 1 || list.Items[0].Title() != "acme secret" {
+		t.Fatalf("acme file holds %+v", list.Items)
+	}
+}
+
+func TestComments_ThreadAndAuthorship(t *testing.T) {
+	path := t.TempDir() + "/tasks.json"
+	svc, err := service.New(ctx, repository.NewJSONStore(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice := reqctx.WithUser(ctx, reqctx.User{ID: 1, Login: "alice", Role: model.RoleMember})
+	bob := reqctx.WithUser(ctx, reqctx.User{ID: 2, Login: "bob", Role: model.RoleMember})
+	admin := reqctx.WithUser(ctx, reqctx.User{ID: 3, Login: "root", Role: model.RoleAdmin})
+
+	gap, _ := svc.Add(ctx, "будет удалена", "", model.PriorityLow, nil)
+	shared, _ := svc.Add(ctx, "общая", "", model.PriorityLow, nil)
+	private, _ := svc.Add(alice, "алисина", "", model.PriorityLow, nil)
+
+	first, err := svc.AddComment(alice, shared, "  **первый**  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Author != 1 || first.Body != "**первый**" || first.EditedAt != nil {
+		t.Fatalf("added: %+v", first)
+	}
+	second, _ := svc.AddComment(bob, shared, "второй")
+	if _, err := svc.AddComment(bob, shared, " \n "); !errors.Is(err, service.ErrBadComment) {
+		t.Fatalf("empty body: %v", err)
+	}
+	if _, err := svc.AddComment(bob, private, "чужая"); !errors.Is(err, service.ErrNotFound) {
+		t.Fatalf("comment on invisible task: %v", err)
+	}
+	if _, err := svc.Comments(bob, private); !errors.Is(err, service.ErrNotFound) {
+		t.Fatalf("thread of invisible task: %v", err)
+	}
+
+	// править и удалять — только автор или администратор
+	if _, err := svc.EditComment(bob, shared, first.ID, "исправил"); !errors.Is(err, policy.ErrForbidden) {
+		t.Fatalf("bob edits alice's comment: %v", err)
+	}
+	edited, err := svc.EditComment(alice, shared, first.ID, "исправила")
+	if err != nil || edited.Body != "исправила" || edited.EditedAt == nil {
+		t.Fatalf("edit by author: %+v, %v", edited, err)
+	}
+	if _, err := svc.EditComment(alice, private, first.ID, "не туда"); !errors.Is(err, service.ErrCommentNotFound) {
+		t.Fatalf("comment of another task: %v", err)
+	}
+	if err := svc.DeleteComment(alice, shared, second.ID); !errors.Is(err, policy.ErrForbidden) {
+		t.Fatalf("alice deletes bob's comment: %v", err)
+	}
+	if err := svc.DeleteComment(admin, shared, second.ID); err != nil {
+		t.Fatalf("admin delete: %v", err)
+	}
+	list, err := svc.Comments(bob, shared)
+	if err != nil || len(list) != 1 || list[0].ID != first.ID || list[0].Body != "исправила" {
+		t.Fatalf("thread: %+v, %v", list, err)
+	}
+
+	// перенумерация уносит комментарии за задачей, удаление задачи — вместе с ними
+	if _, err := svc.AddComment(alice, private, "к своей"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Delete(ctx, gap); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.RenumberIDs(admin); err != nil {
+		t.Fatal(err)
+	}
+	list, _ = svc.Comments(ctx, shared-1)
+	if len(list) != 1 || list[0].TaskID != shared-1 {
+		t.Fatalf("comments after renumber: %+v", list)
+	}
+	if err := svc.Delete(ctx, private-1); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := service.New(ctx, repository.NewJSONStore(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := reopened.Add(ctx, "на месте удалённой", "", model.PriorityLow, nil)
+	if list, _ := reopened.Comments(ctx, again); len(list) != 0 {
+		t.Fatalf("comments of deleted task survived: %+v", list)
+	}
+	if list, _ := reopened.Comments(ctx, shared-1); len(list) != 1 {
+		t.Fatalf("comments not persisted: %+v", list)
+	}
+}
+
+// fakeS3 — заменитель MinIO: объекты в памяти, подпись только проверяем на месте
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=test/") || r.Header.Get("X-Amz-Date") == "" {
+		http.Error(w, "unsigned", http.StatusForbidden)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.objects[r.URL.Path]
+	switch {
+	case r.Method == http.MethodHead && r.URL.Path == "/bucket":
+	case r.Method == http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		if int64(len(body)) != r.ContentLength {
+			http.Error(w, "length mismatch", http.StatusBadRequest)
+			return
+		}
+		f.objects[r.URL.Path] = body
+	case r.Method == http.MethodGet && ok:
+		w.Write(data)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "NoSuchKey", http.StatusNotFound)
+	}
+}
+
+func TestAttachments_UploadDownloadAndGC(t *testing.T) {
+	backends := map[string]func(t *testing.T) (service.BlobStore, func() int){
+		"fs": func(t *testing.T) (service.BlobStore, func() int) {
+			dir := t.TempDir()
+			return repository.NewFSBlobs(dir), func() int {
+				n := 0
+				filepath.WalkDir(dir, func(_ string, d fs.DirEntry, _ error) error {
+					if d != nil && !d.IsDir() {
+						n++
+					}
+					return nil
+				})
+				return n
+			}
+		},
+		"s3": func(t *testing.T) (service.BlobStore, func() int) {
+			fake := &fakeS3{objects: make(map[string][]byte)}
+			srv := httptest.NewServer(fake)
+			t.Cleanup(srv.Close)
+			b, err := repository.NewS3Blobs(repository.S3Config{Endpoint: srv.URL, Bucket: "bucket", AccessKey: "test", SecretKey: "secret"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := b.EnsureBucket(ctx); err != nil {
+				t.Fatal(err)
+			}
+			return b, func() int {
+				fake.mu.Lock()
+				defer fake.mu.Unlock()
+				return len(fake.objects)
+			}
+		},
+	}
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			blobs, stored := backend(t)
+			svc, err := service.New(ctx, repository.NewJSONStore(t.TempDir()+"/tasks.json"), service.WithBlobs(blobs))
+			if err != nil {
+				t.Fatal(err)
+			}
+			alice := reqctx.WithUser(ctx, reqctx.User{ID: 1, Login: "alice", Role: model.RoleMember})
+			bob := reqctx.WithUser(ctx, reqctx.User{ID: 2, Login: "bob", Role: model.RoleMember})
+
+			gap, _ := svc.Add(ctx, "будет удалена", "", model.PriorityLow, nil)
+			id, _ := svc.Add(ctx, "общая", "", model.PriorityLow, nil)
+			private, _ := svc.Add(alice, "алисина", "", model.PriorityLow, nil)
+
+			data := strings.Repeat("PNG", 50000)
+			a, err := svc.Attach(alice, id, `C:\shots\screen.png`, "", strings.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			sum := sha256.Sum256([]byte(data))
+			if a.Name != "screen.png" || a.ContentType != "image/png" || a.Size != int64(len(data)) ||
+				a.Checksum != hex.EncodeToString(sum[:]) || a.UploadedBy != 1 {
+				t.Fatalf("attached: %+v", a)
+			}
+			if _, err := svc.Attach(bob, private, "x.txt", "", strings.NewReader("x")); !errors.Is(err, service.ErrNotFound) {
+				t.Fatalf("attach to invisible task: %v", err)
+			}
+			if _, err := svc.Attach(alice, id, "  ", "", strings.NewReader("x")); !errors.Is(err, service.ErrBadAttachment) {
+				t.Fatalf("empty name: %v", err)
+			}
+			big := io.LimitReader(zeros{}, model.MaxAttachmentSize+1)
+			if _, err := svc.Attach(alice, id, "huge.bin", "", big); !errors.Is(err, service.ErrAttachmentTooLarge) {
+				t.Fatalf("too large: %v", err)
+			}
+			if n := stored(); n != 1 {
+				t.Fatalf("%d blobs stored, want 1", n)
+			}
+
+			meta, body, err := svc.OpenAttachment(bob, id, a.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _ := io.ReadAll(body)
+			body.Close()
+			if string(got) != data || meta.Checksum != a.Checksum {
+				t.Fatalf("downloaded %d bytes, meta %+v", len(got), meta)
+			}
+			if _, _, err := svc.OpenAttachment(alice, private, a.ID); !errors.Is(err, service.ErrAttachmentNotFound) {
+				t.Fatalf("attachment of another task: %v", err)
+			}
+			if err := svc.DeleteAttachment(bob, id, a.ID); !errors.Is(err, policy.ErrForbidden) {
+				t.Fatalf("bob deletes alice's attachment: %v", err)
+			}
+
+			// перенумерация вложения переносит, удаление задачи убирает и файлы
+			own, err := svc.Attach(bob, id, "log.txt", "text/plain; charset=utf-8", strings.NewReader("line\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := svc.DeleteAttachment(bob, id, own.ID); err != nil {
+				t.Fatalf("uploader delete: %v", err)
+			}
+			if _, err := svc.Attach(alice, private, "todo.md", "", strings.NewReader("# todo")); err != nil {
+				t.Fatal(err)
+			}
+			svc.Delete(ctx, gap)
+			if err := svc.RenumberIDs(ctx); err != nil {
+				t.Fatal(err)
+			}
+			list, _ := svc.Attachments(ctx, id-1)
+			if len(list) != 1 || list[0].ID != a.ID || list[0].TaskID != id-1 {
+				t.Fatalf("attachments after renumber: %+v", list)
+			}
+			if n := stored(); n != 2 {
+				t.Fatalf("%d blobs stored, want 2", n)
+			}
+			for _, task := range []model.ID{id - 1, private - 1} {
+				if err := svc.Delete(ctx, task); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// из корзины задачу ещё можно достать, поэтому файлы на месте до очистки
+			if n := stored(); n != 2 {
+				t.Fatalf("%d blobs stored while tasks are in trash, want 2", n)
+			}
+			if _, err := svc.PurgeTrash(ctx, time.Now().Add(time.Second)); err != nil {
+				t.Fatal(err)
+			}
+			if n := stored(); n != 0 {
+				t.Fatalf("%d blobs left after purging tasks", n)
+			}
+		})
+	}
+
+	plain, _ := mustNewService(t, nil)
+	if _, err := plain.Attachments(ctx, 1); !errors.Is(err, service.ErrAttachmentsUnsupported) {
+		t.Fatalf("without blob store: %v", err)
+	}
+}
+
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestChecklist_OrderToggleAndAudit(t *testing.T) {
+	logs := withFakeLogger(t)
+	svc, fs := mustNewService(t, nil)
+	id, _ := svc.Add(ctx, "Релиз", "", model.PriorityLow, nil)
+
+	var items []model.ChecklistItem
+	for _, text := range []string{"  тесты  ", "changelog", "тег"} {
+		it, err := svc.AddChecklistItem(ctx, id, text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		items = append(items, it)
+	}
+	if items[0].Text != "тесты" || items[2].ID != 3 {
+		t.Fatalf("added: %+v", items)
+	}
+	if _, err := svc.AddChecklistItem(ctx, id, " "); !errors.Is(err, service.ErrBadChecklistItem) {
+		t.Fatalf("empty item: %v", err)
+	}
+	if it, err := svc.ToggleChecklistItem(ctx, id, 2); err != nil || !it.Done {
+		t.Fatalf("toggle: %+v, %v", it, err)
+	}
+	if err := svc.MoveChecklistItem(ctx, id, 3, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.RemoveChecklistItem(ctx, id, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.RemoveChecklistItem(ctx, id, 1); !errors.Is(err, service.ErrChecklistItemNotFound) {
+		t.Fatalf("remove twice: %v", err)
+	}
+
+	got, _ := svc.Get(ctx, id)
+	want := []model.ChecklistItem{{ID: 3, Text: "тег"}, {ID: 2, Text: "changelog", Done: true}}
+	if !reflect.DeepEqual(got.Checklist(), want) {
+		t.Fatalf("checklist: %+v", got.Checklist())
+	}
+	if p := got.ChecklistProgress(); p.Done != 1 || p.Total != 2 || p.Percent() != 50 {
+		t.Fatalf("progress: %+v", p)
+	}
+	if !reflect.DeepEqual(fs.items[id].Checklist, want) {
+		t.Fatalf("stored checklist: %+v", fs.items[id].Checklist)
+	}
+
+	// на месте — не изменение: ни записи, ни события
+	before := len(logs.events)
+	if err := svc.MoveChecklistItem(ctx, id, 3, 0); err != nil {
+		t.Fatal(err)
+	}
+	if len(logs.events) != before {
+		t.Fatalf("no-op move logged %d events", len(logs.events)-before)
+	}
+	var ops []string
+	for _, e := range logs.events[1:] {
+		if e.Before == nil || e.After == nil {
+			t.Fatalf("%s without before/after", e.Op)
+		}
+		ops = append(ops, e.Op)
+	}
+	wantOps := []string{"checklist_add", "checklist_add", "checklist_add", "checklist_toggle", "checklist_move", "checklist_remove"}
+	if !reflect.DeepEqual(ops, wantOps) {
+		t.Fatalf("audit ops: %v", ops)
+	}
+	last := logs.events[len(logs.events)-1]
+	if len(last.Before.Checklist) != 3 || len(last.After.Checklist) != 2 {
+		t.Fatalf("remove event: before %+v, after %+v", last.Before.Checklist, last.After.Checklist)
+	}
+}
+
+func TestVersions_ExpectedVersionAndStoreConflict(t *testing.T) {
+	svc, fs := mustNewService(t, nil)
+	id, _ := svc.Add(ctx, "Отчёт", "", model.PriorityLow, nil)
+	created, _ := svc.Get(ctx, id)
+	v := created.Version()
+	if v < 1 {
+		t.Fatalf("new task version = %d", v)
+	}
+	if err := svc.UpdateTitle(ctx, id, "Отчёт за май"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := svc.Get(ctx, id); got.Version() != v+1 || fs.items[id].Version != v+1 {
+		t.Fatalf("version after update = %d, stored %d", got.Version(), fs.items[id].Version)
+	}
+
+	// устаревший ETag
+	stale := service.ExpectVersion(ctx, id, v)
+	if err := svc.UpdateTitle(stale, id, "чужое"); !errors.Is(err, service.ErrVersionMismatch) {
+		t.Fatalf("stale update: %v", err)
+	}
+	if err := svc.Delete(stale, id); !errors.Is(err, service.ErrVersionMismatch) {
+		t.Fatalf("stale delete: %v", err)
+	}
+
+	// несколько изменений в одном запросе идут по цепочке версий
+	fresh := service.ExpectVersion(ctx, id, v+1)
+	if err := svc.UpdateTitle(fresh, id, "Отчёт за июнь"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.SetPriority(fresh, id, model.PriorityHigh); err != nil {
+		t.Fatal(err)
+	}
+	// а чужое изменение посередине цепочку рвёт
+	if err := svc.UpdateDesc(ctx, id, "вклинились"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.SetPriority(fresh, id, model.PriorityLow); !errors.Is(err, service.ErrVersionMismatch) {
+		t.Fatalf("interleaved update: %v", err)
+	}
+
+	// задачу поменяли в обход кэша (другой экземпляр): условная запись не проходит,
+	// кэш перечитывается, и повтор уже удаётся
+	r := fs.items[id]
+	r.Title, r.Version = "Отчёт с другого сервера", r.Version+1
+	fs.items[id] = r
+	if err := svc.UpdateDesc(ctx, id, "моё"); !errors.Is(err, service.ErrVersionMismatch) {
+		t.Fatalf("store conflict: %v", err)
+	}
+	got, _ := svc.Get(ctx, id)
+	if got.Title() != "Отчёт с другого сервера" || got.Version() != r.Version {
+		t.Fatalf("cache not refreshed: %q v%d", got.Title(), got.Version())
+	}
+	if err := svc.UpdateDesc(service.ExpectVersion(ctx, id, got.Version()), id, "моё"); err != nil {
+		t.Fatal(err)
+	}
+	if fs.items[id].Description != "моё" || fs.items[id].Version != r.Version+1 {
+		t.Fatalf("stored after retry: %+v", fs.items[id])
+	}
+}
+
+func TestPatch_AllOrNothing(t *testing.T) {
+	logs := withFakeLogger(t)
+	svc, fs := mustNewService(t, nil)
+	id, _ := svc.Add(ctx, "Черновик", "старое описание", model.PriorityLow, nil)
+	ptr := func(s string) *string { return &s }
+	status := func(s model.Status) *model.Status { return &s }
+	prio := model.PriorityHigh
+	due := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	// хороший заголовок и недопустимый переход статуса: не меняется ничего
+	updates, events := fs.updates, len(logs.events)
+	err := svc.Patch(ctx, id, model.TaskPatch{Title: ptr("Новое"), Priority: &prio, Status: status(model.StatusDone)})
+	if !service.IsConflict(err) {
+		t.Fatalf("new -> done: %v", err)
+	}
+	if err := svc.Patch(ctx, id, model.TaskPatch{Title: ptr("Новое"), Status: status("bogus")}); !errors.Is(err, service.ErrBadPatch) {
+		t.Fatalf("bad status: %v", err)
+	}
+	self := id
+	if err := svc.Patch(ctx, id, model.TaskPatch{Title: ptr("Новое"), ParentID: &self}); !errors.Is(err, service.ErrParentCycle) {
+		t.Fatalf("own parent: %v", err)
+	}
+	if got, _ := svc.Get(ctx, id); got.Title() != "Черновик" || got.Priority() != model.PriorityLow {
+		t.Fatalf("half-applied patch: %q %v", got.Title(), got.Priority())
+	}
+	if fs.updates != updates || len(logs.events) != events {
+		t.Fatalf("failed patches wrote %d updates, %d events", fs.updates-updates, len(logs.events)-events)
+	}
+
+	// удачный: одна запись, одно событие, пустое описание очищает
+	err = svc.Patch(ctx, id, model.TaskPatch{
+		Title: ptr("Отчёт"), Description: ptr(""), Priority: &prio, DueAt: &due,
+		Recurrence: mustRule(t, "FREQ=MONTHLY"), Status: status(model.StatusInProgress),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := svc.Get(ctx, id)
+	if got.Title() != "Отчёт" || got.Description() != "" || got.Priority() != prio ||
+		got.Status() != model.StatusInProgress || got.DueAt() == nil || got.Recurrence().MonthDay != 31 {
+		t.Fatalf("patched: %+v", got.ToDTO())
+	}
+	if fs.updates != updates+1 || len(logs.events) != events+1 {
+		t.Fatalf("patch wrote %d updates, %d events", fs.updates-updates, len(logs.events)-events)
+	}
+	if e := logs.events[len(logs.events)-1]; e.Op != "patch" || e.Before.Title != "Черновик" || e.After.Title != "Отчёт" {
+		t.Fatalf("audit event: %+v", e)
+	}
+
+	// закрытие в том же патче, что и срок: следующий раз считается от нового срока
+	due = due.AddDate(0, 1, -1) // 30 апреля
+	if err := svc.Patch(ctx, id, model.TaskPatch{DueAt: &due, Status: status(model.StatusDone)}); err != nil {
+		t.Fatal(err)
+	}
+	list, _ := svc.List(ctx, model.TaskQuery{})
+	if len(list.Items) != 2 {
+		t.Fatalf("want next occurrence, got %d tasks", len(list.Items))
+	}
+	next := list.Items[1]
+	if d := next.DueAt(); d == nil || d.Month() != time.May || d.Day() != 31 {
+		t.Fatalf("next occurrence due %v", next.DueAt())
+	}
+}
+
+// flakyJSONStore — JSON-хранилище, у которого Update можно сломать
+type flakyJSONStore struct {
+	*repository.JSONStore
+	updateErr error
+}
+
+func (f *flakyJSONStore) Update(ctx context.Context, r model.TaskDTO, version int64) error {
+	if f.updateErr != nil {
+		return f.updateErr
+	}
+	return f.JSONStore.Update(ctx, r, version)
+}
+
+// задача уже вернулась из архива, а чистка связей не записалась: она всё равно видна,
+// конфликт версий — ErrVersionMismatch, как у Archive
+func TestArchive_UnarchiveCleanupFails(t *testing.T) {
+	store := &flakyJSONStore{JSONStore: repository.NewJSONStore(t.TempDir() + "/tasks.json")}
+	svc, err := service.New(ctx, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	epic, _ := svc.Add(ctx, "Эпик", "", model.PriorityLow, nil)
+	step, _ := svc.AddSubtask(ctx, epic, "Шаг", "", model.PriorityLow, nil)
+	for _, id := range []model.ID{step, epic} {
+		for _, st := range []model.Status{model.StatusInProgress, model.StatusDone} {
+			if err := svc.SetStatus(ctx, id, st); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := svc.Archive(ctx, epic); err != nil {
+		t.Fatal(err)
+	}
+
+	// родитель в архиве — шаг надо поднять наверх, а запись не проходит
+	store.updateErr = repository.ErrConflict
+	if err := svc.Unarchive(ctx, step); !errors.Is(err, service.ErrVersionMismatch) {
+		t.Fatalf("unarchive over a conflict: %v", err)
+	}
+	if _, err := svc.Get(ctx, step); err != nil {
+		t.Fatalf("task is hot but not in the cache: %v", err)
+	}
+	if page, _ := svc.Archived(ctx, model.TaskQuery{}); len(page.Items) != 1 || page.Items[0].ID() != epic {
+		t.Fatalf("archive after unarchive: %v", taskIDs(page.Items))
+	}
+}
+
+// запись в корзине поменяли в обход кэша: Restore отвечает ErrVersionMismatch,
+// а не голой ошибкой хранилища, и перечитывает её — повтор проходит
+func TestTrash_RestoreStoreConflict(t *testing.T) {
+	svc, fs := mustNewService(t, nil)
+	epic, _ := svc.Add(ctx, "Эпик", "", model.PriorityLow, nil)
+	step, _ := svc.AddSubtask(ctx, epic, "Шаг", "", model.PriorityLow, nil)
+	if err := svc.DeleteTree(ctx, epic); err != nil {
+		t.Fatal(err)
+	}
+	r := fs.items[step]
+	r.Title, r.Version = "Шаг с другого сервера", r.Version+1
+	fs.items[step] = r
+
+	// ветка не атомарна: эпик вернулся, шаг остался в корзине
+	if err := svc.Restore(ctx, epic); !errors.Is(err, service.ErrVersionMismatch) {
+		t.Fatalf("restore over a conflict: %v", err)
+	}
+	if _, err := svc.Get(ctx, epic); err != nil {
+		t.Fatalf("epic restored before the conflict: %v", err)
+	}
+	trash, _ := svc.Trash(ctx)
+	if len(trash) != 1 || trash[0].ID() != step || trash[0].Title() != r.Title {
+		t.Fatalf("trash not refreshed: %+v", trash)
+	}
+	if err := svc.Restore(ctx, step); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := svc.Get(ctx, step); got.ParentID() != epic || got.Title() != r.Title {
+		t.Fatalf("retry: parent %d %q", got.ParentID(), got.Title())
+	}
+}
+
+func TestTrash_RestoreAndPurge(t *testing.T) {
+	logs := withFakeLogger(t)
+	svc, fs := mustNewService(t, nil)
+	epic, _ := svc.Add(ctx, "Epic", "", model.PriorityHigh, nil)
+	step1, _ := svc.AddSubtask(ctx, epic, "Step 1", "", model.PriorityLow, nil)
+	step2, _ := svc.AddSubtask(ctx, epic, "Step 2", "", model.PriorityLow, nil)
+	sub, _ := svc.AddSubtask(ctx, step1, "Step 1.1", "", model.PriorityLow, nil)
+	other, _ := svc.Add(ctx, "Other", "", model.PriorityLow, nil)
+	if err := svc.AddDependency(ctx, other, step2); err != nil {
+		t.Fatal(err)
+	}
+
+	// ветка уходит в корзину: записи остаются, но задач как будто нет
+	if err := svc.DeleteTree(ctx, step1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Get(ctx, sub); !errors.Is(err, service.ErrNotFound) {
+		t.Fatalf("trashed task visible: %v", err)
+	}
+	if n := len(listAll(t, svc)); n != 3 || fs.live() != 3 || len(fs.items) != 5 {
+		t.Fatalf("after DeleteTree: list %d, live %d, stored %d", n, fs.live(), len(fs.items))
+	}
+	if e := logs.events[len(logs.events)-1]; e.Op != "delete" || e.After == nil || e.After.DeletedAt == nil {
+		t.Fatalf("audit event: %+v", e)
+	}
+
+	// родитель удалён отдельно: step2 поднимается наверх, блокер у other остаётся
+	if err := svc.Delete(ctx, epic); err != nil {
+		t.Fatal(err)
+	}
+	trash, err := svc.Trash(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash) != 3 || trash[0].ID() != epic {
+		t.Fatalf("trash: %v", taskIDs(trash))
+	}
+
+	// step1 возвращается вместе с подзадачей, но уже на верхний уровень
+	if err := svc.Restore(ctx, step1); err != nil {
+		t.Fatal(err)
+	}
+	got, err := svc.Get(ctx, step1)
+	if err != nil || got.ParentID() != 0 || got.DeletedAt() != nil {
+		t.Fatalf("restored step1: %v %+v", err, got)
+	}
+	if kids, _ := svc.Children(ctx, step1); len(kids) != 1 || kids[0].ID() != sub {
+		t.Fatalf("restored subtree: %v", taskIDs(kids))
+	}
+	if err := svc.Restore(ctx, step1); !errors.Is(err, service.ErrNotFound) {
+		t.Fatalf("restore twice: %v", err)
+	}
+
+	// очистка: старше порога — насовсем, вернуть уже нельзя
+	if n, err := svc.PurgeTrash(ctx, time.Now().Add(-time.Hour)); err != nil || n != 0 {
+		t.Fatalf("purge fresh trash: %d %v", n, err)
+	}
+	if n, err := svc.PurgeTrash(ctx, time.Now().Add(time.Second)); err != nil || n != 1 {
+		t.Fatalf("purge: %d %v", n, err)
+	}
+	if _, ok := fs.items[epic]; ok {
+		t.Fatal("purged task still stored")
+	}
+	if err := svc.Restore(ctx, epic); !errors.Is(err, service.ErrNotFound) {
+		t.Fatalf("restore purged: %v", err)
+	}
+	if e := logs.events[len(logs.events)-1]; e.Op != "purge" || e.TaskID != epic {
+		t.Fatalf("audit event: %+v", e)
+	}
+}
+
+// Delete запоминает, что отцепил, и Restore возвращает это на место — даже после
+// перезапуска; перенесённое с тех пор и то, что дало бы цикл, остаётся как есть
+func TestTrash_RestoreReattachesChildrenAndDependents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	svc, err := service.New(ctx, repository.NewJSONStore(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	prep, _ := svc.Add(ctx, "Подготовка", "", model.PriorityLow, nil)
+	epic, _ := svc.Add(ctx, "Эпик", "", model.PriorityHigh, nil)
+	step1, _ := svc.AddSubtask(ctx, epic, "Шаг 1", "", model.PriorityLow, nil)
+	step2, _ := svc.AddSubtask(ctx, epic, "Шаг 2", "", model.PriorityLow, nil)
+	moved, _ := svc.AddSubtask(ctx, epic, "Шаг 3", "", model.PriorityLow, nil)
+	review, _ := svc.Add(ctx, "Ревью", "", model.PriorityLow, nil)
+	other, _ := svc.Add(ctx, "Другое", "", model.PriorityLow, nil)
+	for _, d := range [][2]model.ID{{epic, prep}, {review, epic}, {other, epic}} {
+		if err := svc.AddDependency(ctx, d[0], d[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := svc.Delete(ctx, epic); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := svc.Get(ctx, step1); got.ParentID() != 0 {
+		t.Fatalf("step1 not lifted: parent %d", got.ParentID())
+	}
+	if got, _ := svc.Get(ctx, review); got.DependsOn(epic) {
+		t.Fatal("review still blocked by trashed epic")
+	}
+	// пока эпик в корзине: шаг 3 переносят, а подготовка начинает ждать «Другое»
+	if err := svc.SetParent(ctx, moved, step1); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.AddDependency(ctx, prep, other); err != nil {
+		t.Fatal(err)
+	}
+
+	// что отцепили — лежит в хранилище, не только в памяти
+	svc, err = service.New(ctx, repository.NewJSONStore(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Restore(ctx, epic); err != nil {
+		t.Fatal(err)
+	}
+	if kids, _ := svc.Children(ctx, epic); !slices.Equal(taskIDs(kids), []model.ID{step1, step2}) {
+		t.Fatalf("children back under epic: %v", taskIDs(kids))
+	}
+	if got, _ := svc.Get(ctx, moved); got.ParentID() != step1 {
+		t.Fatalf("moved subtask must stay where it was moved: parent %d", got.ParentID())
+	}
+	if got, _ := svc.Get(ctx, review); !got.DependsOn(epic) {
+		t.Fatal("review must wait for epic again")
+	}
+	// other → epic → prep → other: такую связь не возвращаем
+	if got, _ := svc.Get(ctx, other); got.DependsOn(epic) {
+		t.Fatal("restoring other's blocker would close a cycle")
+	}
+	if got, _ := svc.Get(ctx, epic); !got.DependsOn(prep) {
+		t.Fatal("epic lost its own blocker")
+	} else if c, d := got.Detached(); c != nil || d != nil {
+		t.Fatalf("restored task still remembers detached links: %v %v", c, d)
+	}
+}
+
+func TestArchive_ManualAutoSearchExport(t *testing.T) {
+	logs := withFakeLogger(t)
+	path := t.TempDir() + "/tasks.json"
+	svc, err := service.New(ctx, repository.NewJSONStore(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	closeTask := func(svc *service.Service, id model.ID) {
+		t.Helper()
+		if err := svc.SetStatus(ctx, id, model.StatusInProgress); err != nil {
+			t.Fatal(err)
+		}
+		if err := svc.SetStatus(ctx, id, model.StatusDone); err != nil {
+			t.Fatal(err)
+		}
+	}
+	report, _ := svc.Add(ctx, "Отчёт за квартал", "", model.PriorityHigh, nil)
+	check, _ := svc.AddSubtask(ctx, report, "Сверка счетов", "", model.PriorityLow, nil)
+	publish, _ := svc.Add(ctx, "Публикация", "", model.PriorityLow, nil)
+	move, _ := svc.Add(ctx, "Переезд", "", model.PriorityLow, nil)
+	pack, _ := svc.AddSubtask(ctx, move, "Упаковать книги", "", model.PriorityLow, nil)
+	if err := svc.AddDependency(ctx, publish, report); err != nil {
+		t.Fatal(err)
+	}
+
+	// открытую задачу в архив нельзя
+	if err := svc.Archive(ctx, report); !service.IsConflict(err) {
+		t.Fatalf("archive open task: %v", err)
+	}
+	closeTask(svc, check)
+	closeTask(svc, report)
+	if err := svc.Archive(ctx, report); err != nil {
+		t.Fatal(err)
+	}
+	if e := logs.events[len(logs.events)-1]; e.Op != "archive" || e.After == nil || e.After.ArchivedAt == nil {
+		t.Fatalf("audit event: %+v", e)
+	}
+
+	// из горячих пропала вместе с подзадачей, но в архиве, поиске и выгрузке есть
+	if _, err := svc.Get(ctx, check); !errors.Is(err, service.ErrNotFound) {
+		t.Fatalf("archived subtask visible: %v", err)
+	}
+	if got := taskIDs(listAll(t, svc)); len(got) != 3 {
+		t.Fatalf("hot list: %v", got)
+	}
+	if ready, _ := svc.Ready(ctx); findTaskByID(ready, publish) == nil {
+		t.Fatalf("archived blocker still blocks: %v", taskIDs(ready))
+	}
+	page, err := svc.Archived(ctx, model.TaskQuery{})
+	if err != nil || len(page.Items) != 2 || page.Items[0].ID() != report || page.Items[1].ArchivedAt() == nil {
+		t.Fatalf("archived: %v %v", err, taskIDs(page.Items))
+	}
+	hits, err := svc.Search(ctx, "квартал", 0)
+	if err != nil || len(hits) != 1 || hits[0].Task.ID != report || hits[0].Task.ArchivedAt == nil {
+		t.Fatalf("search archive: %v %+v", err, hits)
+	}
+	exported := 0
+	if err := svc.Export(ctx, func(*model.Task) error { exported++; return nil }); err != nil || exported != 5 {
+		t.Fatalf("export: %v, %d tasks", err, exported)
+	}
+
+	// после перезапуска архив не грузится, но номера его заняты
+	svc, err = service.New(ctx, repository.NewJSONStore(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(listAll(t, svc)); n != 3 {
+		t.Fatalf("reloaded hot tasks: %d", n)
+	}
+	fresh, _ := svc.Add(ctx, "Новая", "", model.PriorityLow, nil)
+	if fresh <= pack {
+		t.Fatalf("new task took id %d", fresh)
+	}
+
+	// возврат: родитель остался в архиве — задача встаёт на верхний уровень
+	if err := svc.Unarchive(ctx, check); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := svc.Get(ctx, check); err != nil || got.ParentID() != 0 || got.ArchivedAt() != nil {
+		t.Fatalf("unarchived: %v %+v", err, got)
+	}
+
+	// авто: свежие не трогаем; подзадача открытого родителя уезжает одна
+	closeTask(svc, pack)
+	if n, err := svc.ArchiveDone(ctx, time.Now().Add(-time.Hour)); err != nil || n != 0 {
+		t.Fatalf("archive fresh: %d %v", n, err)
+	}
+	if n, err := svc.ArchiveDone(ctx, time.Now().Add(time.Second)); err != nil || n != 2 {
+		t.Fatalf("archive done: %d %v", n, err)
+	}
+	if kids, _ := svc.Children(ctx, move); len(kids) != 0 {
+		t.Fatalf("children of open parent: %v", taskIDs(kids))
+	}
+
+	// перенумерация идёт и по архиву: горячие 1..3, архив следом, ссылки туда же
+	if err := svc.RenumberIDs(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := taskIDs(listAll(t, svc)); !slices.Equal(got, []model.ID{1, 2, 3}) {
+		t.Fatalf("renumbered hot: %v", got)
+	}
+	page, _ = svc.Archived(ctx, model.TaskQuery{})
+	if got := taskIDs(page.Items); !slices.Equal(got, []model.ID{4, 5, 6}) {
+		t.Fatalf("renumbered archive: %v", got)
+	}
+	if packed := findTaskByID(page.Items, 6); packed == nil || packed.Title() != "Упаковать книги" || packed.ParentID() != 2 {
+		t.Fatalf("renumbered subtask: %+v", packed)
+	}
+}
+
+func TestUndoRedo_PerUserWithConflictsAndRenumber(t *testing.T) {
+	svc, _ := mustNewService(t, nil)
+	alice := reqctx.WithUser(ctx, reqctx.User{ID: 1, Login: "alice", Role: model.RoleMember})
+	bob := reqctx.WithUser(ctx, reqctx.User{ID: 2, Login: "bob", Role: model.RoleMember})
+	admin := reqctx.WithUser(ctx, reqctx.User{ID: 3, Login: "root", Role: model.RoleAdmin})
+	// каждое действие — свой запрос со своим trace id, как в вебе
+	req := func(c context.Context) context.Context { return reqctx.WithRequest(c) }
+
+	draft, _ := svc.Add(req(admin), "Draft", "", model.PriorityLow, nil)
+	report, _ := svc.Add(req(alice), "Report", "", model.PriorityLow, nil)
+	slides, _ := svc.Add(req(alice), "Slides", "", model.PriorityLow, nil)
+	if err := svc.UpdateTitle(req(alice), report, "Quarterly report"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Delete(req(alice), slides); err != nil {
+		t.Fatal(err)
+	}
+	title := func(id model.ID) string {
+		t.Helper()
+		got, err := svc.Get(ctx, id)
+		if err != nil {
+			t.Fatalf("get %d: %v", id, err)
+		}
+		return got.Title()
+	}
+
+	// по одной назад: удаление, потом переименование
+	steps, err := svc.Undo(req(alice), 2)
+	if err != nil || len(steps) != 2 || !slices.Equal(steps[0].Ops, []string{"delete"}) {
+		t.Fatalf("undo: %v %+v", err, steps)
+	}
+	if title(slides) != "Slides" || title(report) != "Report" {
+		t.Fatalf("after undo: %q %q", title(report), title(slides))
+	}
+	if _, err := svc.Redo(req(alice), 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Get(ctx, slides); !errors.Is(err, service.ErrNotFound) || title(report) != "Quarterly report" {
+		t.Fatalf("after redo: %v %q", err, title(report))
+	}
+	if _, err := svc.Undo(req(alice), 1); err != nil || title(slides) != "Slides" {
+		t.Fatalf("undo delete again: %v", err)
+	}
+
+	// у каждого своя история
+	if _, err := svc.Undo(req(bob), 1); !errors.Is(err, service.ErrNothingToUndo) {
+		t.Fatalf("bob undo: %v", err)
+	}
+	// задачу после операции поменял другой — отменять её нельзя
+	if err := svc.UpdateTitle(req(admin), report, "Report v2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Undo(req(alice), 1); !errors.Is(err, service.ErrUndoConflict) || !service.IsConflict(err) {
+		t.Fatalf("undo over later change: %v", err)
+	}
+	if title(report) != "Report v2" {
+		t.Fatalf("conflicting undo changed task: %q", title(report))
+	}
+
+	// перенумерация: история alice переезжает на новые номера, сама перенумерация отменяется
+	if err := svc.Delete(req(admin), draft); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.PurgeTrash(ctx, time.Now().Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.RenumberIDs(req(admin)); err != nil {
+		t.Fatal(err)
+	}
+	if title(1) != "Report v2" || title(2) != "Slides" {
+		t.Fatalf("renumbered: %q %q", title(1), title(2))
+	}
+	if _, err := svc.Redo(req(alice), 1); err != nil {
+		t.Fatal(err)
+	}
+	if trash, _ := svc.Trash(ctx); len(trash) != 1 || trash[0].ID() != 2 {
+		t.Fatalf("redo delete after renumber: %v", taskIDs(trash))
+	}
+	if _, err := svc.Undo(req(admin), 1); err != nil {
+		t.Fatal(err)
+	}
+	if title(report) != "Report v2" {
+		t.Fatalf("renumber undone: %q", title(report))
+	}
+	if trash, _ := svc.Trash(ctx); len(trash) != 1 || trash[0].ID() != slides {
+		t.Fatalf("trash after renumber undone: %v", taskIDs(trash))
+	}
+	if _, err := svc.Undo(req(alice), 1); err != nil || title(slides) != "Slides" {
+		t.Fatalf("undo delete after renumber undone: %v", err)
+	}
+
+	// отмена создания уносит задачу в корзину; ветка, удалённая одним запросом, — один шаг
+	epic, _ := svc.Add(req(alice), "Epic", "", model.PriorityLow, nil)
+	task, _ := svc.AddSubtask(req(alice), epic, "Task", "", model.PriorityLow, nil)
+	if steps, err := svc.Undo(req(alice), 1); err != nil || !slices.Equal(steps[0].TaskIDs, []model.ID{task}) {
+		t.Fatalf("undo add: %v %+v", err, steps)
+	}
+	if trash, _ := svc.Trash(ctx); findTaskByID(trash, task) == nil {
+		t.Fatalf("undone add not in trash: %v", taskIDs(trash))
+	}
+	if _, err := svc.Redo(req(alice), 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.DeleteTree(req(alice), epic); err != nil {
+		t.Fatal(err)
+	}
+	if steps, err := svc.Undo(req(alice), 1); err != nil || len(steps[0].TaskIDs) != 2 {
+		t.Fatalf("undo delete tree: %v %+v", err, steps)
+	}
+	if kids, _ := svc.Children(ctx, epic); len(kids) != 1 {
+		t.Fatalf("tree after undo: %v", taskIDs(kids))
+	}
+	// новая операция сбрасывает повтор; фоновые (без trace id) в историю не попадают
+	if err := svc.SetPriority(req(alice), epic, model.PriorityHigh); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Redo(req(alice), 1); !errors.Is(err, service.ErrNothingToRedo) {
+		t.Fatalf("redo after new op: %v", err)
+	}
+	if _, err := svc.Add(alice, "Background", "", model.PriorityLow, nil); err != nil {
+		t.Fatal(err)
+	}
+	if steps, err := svc.Undo(req(alice), 1); err != nil || !slices.Equal(steps[0].Ops, []string{"set_priority"}) {
+		t.Fatalf("undo skips background: %v %+v", err, steps)
+	}
+
+	// клиент шлёт один и тот же X-Request-ID — запросы всё равно разные шаги
+	fixed := func() context.Context { return reqctx.WithRequest(reqctx.WithTraceID(alice, "same")) }
+	first, _ := svc.Add(fixed(), "First", "", model.PriorityLow, nil)
+	second, _ := svc.Add(fixed(), "Second", "", model.PriorityLow, nil)
+	if steps, err := svc.Undo(fixed(), 1); err != nil || !slices.Equal(steps[0].TaskIDs, []model.ID{second}) {
+		t.Fatalf("undo with a reused trace id: %v %+v", err, steps)
+	}
+	if _, err := svc.Get(alice, first); err != nil {
+		t.Fatalf("first request undone with the second: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+
+	"todo/internal/model"
+)
+
+// tagIndex — какие задачи помечены каждым тегом. Живёт рядом с кэшем под s.mu
+// и обновляется там же, где публикуется новая версия задачи.
+type tagIndex map[string]map[model.ID]struct{}
+
+func (ix tagIndex) add(t *model.Task) {
+	for _, tag := range t.Tags() {
+		ids, ok := ix[tag]
+		if !ok {
+			ids = make(map[model.ID]struct{})
+			ix[tag] = ids
+		}
+		ids[t.ID()] = struct{}{}
+	}
+}
+
+func (ix tagIndex) remove(t *model.Task) {
+	for _, tag := range t.Tags() {
+		delete(ix[tag], t.ID())
+		if len(ix[tag]) == 0 {
+			delete(ix, tag)
+		}
+	}
+}
+
+// retag — версию before заменили на after (любая может быть nil); вызывать под s.mu.Lock
+func (s *Service) retag(before, after *model.Task) {
+	if before != nil {
+		s.tags.remove(before)
+	}
+	if after != nil {
+		s.tags.add(after)
+	}
+}
+
+// AddTag помечает задачу тегом; тег нормализуется, повторно не добавляется
+func (s *Service) AddTag(ctx context.Context, id model.ID, tag string) error {
+	return s.update(ctx, id, "add_tag", func(t *model.Task) error {
+		added, err := t.AddTag(tag)
+		if err == nil && !added {
+			return errNoChange
+		}
+		return err
+	})
+}
+
+// RemoveTag снимает тег; если его не было — ничего не делаем
+func (s *Service) RemoveTag(ctx context.Context, id model.ID, tag string) error {
+	return s.update(ctx, id, "remove_tag", func(t *model.Task) error {
+		if !t.RemoveTag(tag) {
+			return errNoChange
+		}
+		return nil
+	})
+}
+
+// TagCounts — все теги и число задач с каждым, частые первыми.
+// Обычному пользователю считаем только по видимым ему задачам, мимо индекса.
+func (s *Service) TagCounts(ctx context.Context) ([]model.TagCount, error) {
+	if _, restricted := viewer(ctx); restricted {
+		byTag := make(map[string]int)
+		for _, t := range visibleOnly(ctx, s.snapshot()) {
+			for _, tag := range t.Tags() {
+				byTag[tag]++
+			}
+		}
+		counts := make([]model.TagCount, 0, len(byTag))
+		for tag, n := range byTag {
+			counts = append(counts, model.TagCount{Tag: tag, Count: n})
+		}
+		model.SortTagCounts(counts)
+		return counts, nil
+	}
+
+	s.mu.RLock()
+	counts := make([]model.TagCount, 0, len(s.tags))
+	for tag, ids := range s.tags {
+		counts = append(counts, model.TagCount{Tag: tag, Count: len(ids)})
+	}
+	s.mu.RUnlock()
+
+	model.SortTagCounts(counts)
+	return counts, nil
+}
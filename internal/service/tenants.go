@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"todo/internal/model"
+	"todo/internal/reqctx"
+)
+
+// TenantStores — хранилища арендатора: его проекты и задачи его проекта.
+// Реализация обязана отдавать хранилища, которые физически не видят чужих данных
+// (своя база, свой каталог, строки под row-level security), — на фильтры в запросах не надеемся.
+type TenantStores func(t model.TenantID) (ProjectStore, func(model.ProjectID) Store)
+
+// Tenants — проекты по арендаторам. Арендатор берётся только у пользователя запроса
+// (из JWT или ключа API), выбрать чужого через API нельзя.
+// Проекты арендатора поднимаются при первом обращении и дальше живут в памяти.
+type Tenants struct {
+	stores TenantStores
+	opts   []Option
+
+	mu       sync.Mutex
+	projects map[model.TenantID]*Projects
+	known    TenantLister
+}
+
+// TenantLister — все арендаторы, о которых знает хранилище (у кого есть пользователи),
+// а не только поднятые с запуска. Умеют хранилища пользователей из repository
+type TenantLister interface {
+	Tenants(ctx context.Context) ([]model.TenantID, error)
+}
+
+func NewTenants(stores TenantStores, opts ...Option) *Tenants {
+	return &Tenants{stores: stores, opts: opts, projects: make(map[model.TenantID]*Projects)}
+}
+
+// ListFrom — фоновые проходы (корзина, автоархив) берут арендаторов из l:
+// не поднятого ещё арендатора поднимают, а не ждут, пока к нему обратятся
+func (ts *Tenants) ListFrom(l TenantLister) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.known = l
+}
+
+// sweep — fn по всем известным арендаторам, по порядку; ошибка одного не мешает остальным
+func (ts *Tenants) sweep(ctx context.Context, fn func(ps *Projects) (int, error)) (int, error) {
+	var errs []error
+	ts.mu.Lock()
+	known := ts.known
+	ts.mu.Unlock()
+	if known != nil {
+		list, err := known.Tenants(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("list tenants: %w", err))
+		}
+		for _, t := range list {
+			if _, err := ts.Tenant(ctx, t); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	ts.mu.Lock()
+	ids := make([]model.TenantID, 0, len(ts.projects))
+	for t := range ts.projects {
+		ids = append(ids, t)
+	}
+	ts.mu.Unlock()
+	slices.Sort(ids)
+
+	total := 0
+	for _, t := range ids {
+		ts.mu.Lock()
+		ps := ts.projects[t]
+		ts.mu.Unlock()
+		n, err := fn(ps)
+		total += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", t, err))
+		}
+	}
+	return total, errors.Join(errs...)
+}
+
+// Projects — проекты арендатора того, кто делает запрос; внутренние вызовы — общий арендатор
+func (ts *Tenants) Projects(ctx context.Context) (*Projects, error) {
+	u, _ := reqctx.UserFrom(ctx)
+	return ts.Tenant(ctx, u.Tenant.OrDefault())
+}
+
+// Tenant — проекты арендатора t без оглядки на пользователя: для запуска и консоли
+func (ts *Tenants) Tenant(ctx context.Context, t model.TenantID) (*Projects, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ps, ok := ts.projects[t]; ok {
+		return ps, nil
+	}
+	projects, tasks := ts.stores(t)
+	// проекты переживут запрос, поэтому его отмену загрузке не передаём
+	ps, err := NewProjects(context.WithoutCancel(ctx), projects, tasks, append(slices.Clone(ts.opts), InTenant(t))...)
+	if err != nil {
+		return nil, fmt.Errorf("tenant %s: %w", t, err)
+	}
+	ts.projects[t] = ps
+	return ps, nil
+}
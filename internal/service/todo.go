@@ -2,45 +2,107 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"todo/internal/model"
+	"todo/internal/policy"
+	"todo/internal/repository"
+	"todo/internal/reqctx"
 )
 
-func logEvent(op string, id model.ID, before, after *model.TaskDTO) {
+// logEvent пишет событие об изменении задачи id в историю отмен и в аудит
+func (s *Service) logEvent(ctx context.Context, op string, id model.ID, before, after *model.TaskDTO) {
+	s.emit(ctx, Event{Op: op, TaskID: id, Before: before, After: after})
+}
+
+// emit дополняет событие и пишет его в историю отмен и в аудит. Изменение к этому
+// моменту уже сохранено, поэтому отмену запроса не наследуем, а трассировку и пользователя — берём.
+func (s *Service) emit(ctx context.Context, e Event) {
+	e.Tenant, e.Project = s.tenant, s.project
+	e.At = time.Now()
+	e.TraceID = reqctx.TraceID(ctx)
+	if u, ok := reqctx.UserFrom(ctx); ok {
+		e.User = u.Login
+	}
+	s.remember(ctx, e)
 	if Logger == nil {
 		return
 	}
-	_ = Logger.LogEvent(context.Background(), Event{
-		Op:     op,
-		TaskID: id,
-		At:     time.Now(),
-		Before: before,
-		After:  after,
-	})
+	_ = Logger.LogEvent(context.WithoutCancel(ctx), e)
 }
 
+// entry — задача в кэше плюс замок, который выстраивает её изменения в очередь.
+// Опубликованная *model.Task больше не меняется: писатель правит копию
+// и подменяет указатель, поэтому читатели спокойно держат старую версию.
+type entry struct {
+	mu   sync.Mutex
+	task *model.Task // nil — задачу удалили, пока ждали замок
+}
+
+// Service безопасен для одновременного вызова из веба, gRPC и фоновых горутин.
+// Порядок захвата замков: ops → entry.mu → mu.
 type Service struct {
-	store  Store
-	tasks  map[model.ID]*model.Task
+	store       Store
+	transitions model.Transitions
+	tenant      model.TenantID  // для аудита, как и project
+	project     model.ProjectID // для аудита; задачи проекта — весь store
+	blobs       BlobStore       // содержимое вложений, nil — вложения выключены
+
+	ops    sync.RWMutex // обычные операции берут RLock, перенумерация — Lock
+	mu     sync.RWMutex // защищает tasks, trash, nextID, tags и указатели entry.task
+	tasks  map[model.ID]*entry
+	trash  map[model.ID]*model.Task // удалённые, ждут Restore или PurgeTrash; в tags их нет
+	tags   tagIndex
 	nextID model.ID
+
+	hmu       sync.Mutex // защищает histories; под ним ничего не берём
+	histories map[model.UserID]*history
+}
+
+// Option — необязательная настройка сервиса при создании
+type Option func(*Service)
+
+// InProject — сервис задач проекта p (store уже отфильтрован по нему, см. Projects)
+func InProject(p model.ProjectID) Option {
+	return func(s *Service) { s.project = p }
+}
+
+// InTenant — сервис задач арендатора t (store его, см. Tenants)
+func InTenant(t model.TenantID) Option {
+	return func(s *Service) { s.tenant = t }
 }
 
-func New(store Store) (*Service, error) {
+// WithTransitions — своя таблица переходов статусов вместо model.DefaultTransitions
+func WithTransitions(tr model.Transitions) Option {
+	return func(s *Service) { s.transitions = tr }
+}
+
+func New(ctx context.Context, store Store, opts ...Option) (*Service, error) {
 	s := &Service{
-		store: store,
-		tasks: make(map[model.ID]*model.Task),
+		store:       store,
+		transitions: model.DefaultTransitions,
+		tasks:       make(map[model.ID]*entry),
+		trash:       make(map[model.ID]*model.Task),
+		tags:        make(tagIndex),
+		histories:   make(map[model.UserID]*history),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
-	if err := s.load(); err != nil {
+	if err := s.load(ctx); err != nil {
 		return nil, err
 	}
 	return s, nil
 }
 
-func (s *Service) load() error {
-	records, err := s.store.Load()
+func (s *Service) load(ctx context.Context) error {
+	records, err := s.store.Query(ctx, model.TaskQuery{WithDeleted: true})
 	if err != nil {
 		return err
 	}
@@ -50,10 +112,26 @@ func (s *Service) load() error {
 		if err != nil {
 			continue
 		}
-		s.tasks[t.ID()] = t
+		// номера задач в корзине заняты, пока их не вычистят
 		if t.ID() > maxID {
 			maxID = t.ID()
 		}
+		if t.DeletedAt() != nil {
+			s.trash[t.ID()] = t
+			continue
+		}
+		s.tasks[t.ID()] = &entry{task: t}
+		s.tags.add(t)
+	}
+	// архив при загрузке не читаем, но и его номера заняты
+	if _, ok := s.store.(Archiver); ok {
+		last, err := s.store.Query(ctx, model.TaskQuery{Archived: true, Sort: []model.SortKey{{Field: model.SortID, Desc: true}}, Limit: 1})
+		if err != nil {
+			return err
+		}
+		if len(last) > 0 && last[0].ID > maxID {
+			maxID = last[0].ID
+		}
 	}
 	if maxID < 1 {
 		s.nextID = 1
@@ -63,185 +141,511 @@ func (s *Service) load() error {
 	return nil
 }
 
-func (s *Service) persist() error {
-	all := make([]model.TaskDTO, 0, len(s.tasks))
-	for _, t := range s.tasks {
-		all = append(all, t.ToDTO())
+// lookup — достаёт запись кэша по ID
+func (s *Service) lookup(id model.ID) (*entry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	e, ok := s.tasks[id]
+	return e, ok
+}
+
+// update — общий путь для изменения одной задачи: меняем копию под замком задачи,
+// сохраняем в хранилище и только после успеха публикуем новую версию.
+func (s *Service) update(ctx context.Context, id model.ID, op string, fn func(t *model.Task) error) error {
+	s.ops.RLock()
+	defer s.ops.RUnlock()
+
+	e, ok := s.lookup(id)
+	if !ok {
+		return errNotFound(id)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.task == nil || !canSee(ctx, e.task) {
+		return errNotFound(id)
+	}
+	return s.apply(ctx, e, op, fn)
+}
+
+// errNoChange — fn в apply сообщает, что менять нечего: ничего не пишем и не логируем
+var errNoChange = errors.New("no change")
+
+// apply — сама правка: копия, хранилище, публикация, аудит.
+// Вызывать под замком задачи или под s.ops.Lock.
+func (s *Service) apply(ctx context.Context, e *entry, op string, fn func(t *model.Task) error) error {
+	if err := checkVersion(ctx, e.task); err != nil {
+		return err
 	}
-	sort.Slice(all, func(i, j int) bool {
-		return all[i].CreatedAt.Before(all[j].CreatedAt)
-	})
-	return s.store.Save(all)
+	t := e.task.Clone()
+	if err := fn(t); err != nil {
+		if errors.Is(err, errNoChange) {
+			return nil
+		}
+		return err
+	}
+	after := t.ToDTO()
+	if err := s.store.Update(ctx, after, e.task.Version()); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			s.refresh(ctx, e)
+			return fmt.Errorf("%w: task %d was changed by someone else", ErrVersionMismatch, t.ID())
+		}
+		return err
+	}
+	before := e.task.ToDTO()
+
+	s.mu.Lock()
+	s.retag(e.task, t)
+	e.task = t
+	s.mu.Unlock()
+	expectNext(ctx, t)
+
+	s.logEvent(ctx, op, t.ID(), &before, &after)
+	return nil
+}
+
+func (s *Service) Add(ctx context.Context, title, desc string, p model.Priority, due *time.Time) (model.ID, error) {
+	return s.Create(ctx, model.TaskDraft{Title: title, Description: desc, Priority: p, DueAt: due})
 }
 
-func (s *Service) Add(title, desc string, p model.Priority, due *time.Time) (model.ID, error) {
-	t, err := model.NewTask(title, desc)
+// ErrBadTask — в черновике новой задачи недопустимое значение
+var ErrBadTask = errors.New("bad task")
+
+// Create создаёт задачу от имени вызывающего сразу со всеми полями черновика:
+// одна вставка в хранилище и одна запись аудита, без полусозданных задач.
+// ParentID != 0 — подзадача существующей задачи
+func (s *Service) Create(ctx context.Context, d model.TaskDraft) (model.ID, error) {
+	if d.ParentID != 0 {
+		if _, err := s.visibleTask(ctx, d.ParentID); err != nil {
+			return 0, err
+		}
+	}
+	t, err := d.Build()
 	if err != nil {
-		return 0, err
+		return 0, fmt.Errorf("%w: %v", ErrBadTask, err)
+	}
+	if u, ok := reqctx.UserFrom(ctx); ok {
+		t.SetCreatedBy(u.ID)
 	}
-	_ = t.SetPriority(p)
-	if due != nil {
-		t.SetDueAt(*due)
+	return s.insert(ctx, t, "add")
+}
+
+// insert выдаёт задаче ID, сохраняет и публикует её
+func (s *Service) insert(ctx context.Context, t *model.Task, op string) (model.ID, error) {
+	s.ops.RLock()
+	defer s.ops.RUnlock()
+
+	if parent := t.ParentID(); parent != 0 {
+		// держим замок родителя: его не удалят и не закроют, пока добавляем подзадачу
+		pe, ok := s.lookup(parent)
+		if !ok {
+			return 0, errNotFound(parent)
+		}
+		pe.mu.Lock()
+		defer pe.mu.Unlock()
+		if pe.task == nil {
+			return 0, errNotFound(parent)
+		}
 	}
+
+	s.mu.Lock()
 	t.SetID(s.nextID)
-	s.tasks[t.ID()] = t
 	s.nextID++
-	if err := s.persist(); err != nil {
+	s.mu.Unlock()
+
+	after := t.ToDTO()
+	if err := s.store.Insert(ctx, after); err != nil {
 		return 0, err
 	}
-	after := t.ToDTO()
-	logEvent("add", t.ID(), nil, &after)
+
+	s.mu.Lock()
+	s.tasks[t.ID()] = &entry{task: t}
+	s.retag(nil, t)
+	s.mu.Unlock()
+
+	s.logEvent(ctx, op, t.ID(), nil, &after)
 	return t.ID(), nil
 }
 
 // RenumberIDs — перенумеровывает все задачи в порядке CreatedAt: 1..N
-func (s *Service) RenumberIDs() error {
-	list := s.List(nil) // уже отсортировано по CreatedAt
-	newMap := make(map[model.ID]*model.Task, len(list))
-	var id model.ID = 1
-	for _, t := range list {
-		t.SetID(id)
-		newMap[id] = t
-		id++
+// В хранилище трогаем только задачи, у которых номер реально поменялся:
+// сначала удаляем старые записи, потом вставляем под новыми ID.
+// Ссылки на родителя и блокеры переводим на новые номера.
+// Задачи из корзины получают номера после живых: Restore вернёт их уже под новыми.
+// На время перенумерации все остальные изменения ждут.
+// Только для администраторов: у всех пользователей меняются ссылки на задачи.
+func (s *Service) RenumberIDs(ctx context.Context) error {
+	if err := policy.Check(ctx, policy.Renumber); err != nil {
+		return err
 	}
-	s.tasks = newMap
-	s.nextID = id
-	if err := s.persist(); err != nil {
+	s.ops.Lock()
+	defer s.ops.Unlock()
+
+	list, err := s.allTasks(ctx)
+	if err != nil {
+		return err
+	}
+	remap := make(map[model.ID]model.ID, len(list))
+	for i, t := range list {
+		remap[t.ID()] = model.ID(i + 1)
+	}
+	changed, err := s.renumber(ctx, list, remap)
+	if err != nil {
 		return err
 	}
-	logEvent("renumber_ids", 0, nil, nil)
+	s.emit(ctx, Event{Op: "renumber_ids", Remap: changed})
 	return nil
 }
 
-func (s *Service) List(filter *model.Status) []*model.Task {
-	result := make([]*model.Task, 0, len(s.tasks))
-	for _, t := range s.tasks {
-		if filter != nil && t.Status() != *filter {
-			continue
+// allTasks — горячие задачи, корзина и архив, каждая часть по CreatedAt. Вызывать под s.ops.Lock
+func (s *Service) allTasks(ctx context.Context) ([]*model.Task, error) {
+	list := append(s.snapshot(), s.trashSnapshot()...) // каждая часть уже по CreatedAt
+	// архив нумеруется вместе со всеми: ссылки на него есть и у горячих задач
+	if _, ok := s.store.(Archiver); ok {
+		records, err := s.store.Query(ctx, model.TaskQuery{Archived: true})
+		if err != nil {
+			return nil, err
 		}
+		for _, r := range records {
+			if t, err := model.FromDTO(r); err == nil {
+				list = append(list, t)
+			}
+		}
+	}
+	return list, nil
+}
+
+// renumber переводит задачи list на номера из remap (там должны быть все задачи list)
+// вместе со ссылками, обсуждениями, вложениями и историей отмен. Хранилище переезжает
+// одним Store.Renumber, и только после него — кэш: сбой оставляет всё на старых номерах.
+// Отдаёт, чьи номера поменялись: старый -> новый. Вызывать под s.ops.Lock
+func (s *Service) renumber(ctx context.Context, list []*model.Task, remap map[model.ID]model.ID) (map[model.ID]model.ID, error) {
+	newMap := make(map[model.ID]*entry, len(list))
+	newTrash := make(map[model.ID]*model.Task)
+	newTags := make(tagIndex)
+	var maxID model.ID
+	for _, t := range list {
+		c := t.Clone()
+		c.Renumber(remap)
+		maxID = max(maxID, c.ID())
+		switch {
+		case c.ArchivedAt() != nil:
+			// архив не в кэше, его переносит тот же Store.Renumber
+		case c.DeletedAt() != nil:
+			newTrash[c.ID()] = c
+		default:
+			newMap[c.ID()] = &entry{task: c}
+			newTags.add(c)
+		}
+	}
+	if err := s.store.Renumber(ctx, remap); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.tasks = newMap
+	s.trash = newTrash
+	s.tags = newTags
+	s.nextID = maxID + 1
+	s.mu.Unlock()
+	s.renumberHistory(remap)
+
+	changed := make(map[model.ID]model.ID)
+	for old, id := range remap {
+		if old != id {
+			changed[old] = id
+		}
+	}
+	return changed, nil
+}
+
+// snapshot — все задачи из кэша по порядку создания.
+// Задачи — неизменяемые снимки, их можно читать из любой горутины.
+func (s *Service) snapshot() []*model.Task {
+	s.mu.RLock()
+	result := make([]*model.Task, 0, len(s.tasks))
+	for _, e := range s.tasks {
+		result = append(result, e.task)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt().Before(result[j].CreatedAt())
+	})
+	return result
+}
+
+// trashSnapshot — задачи из корзины по порядку создания
+func (s *Service) trashSnapshot() []*model.Task {
+	s.mu.RLock()
+	result := make([]*model.Task, 0, len(s.trash))
+	for _, t := range s.trash {
 		result = append(result, t)
 	}
+	s.mu.RUnlock()
+
 	sort.Slice(result, func(i, j int) bool {
 		return result[i].CreatedAt().Before(result[j].CreatedAt())
 	})
 	return result
 }
 
-func (s *Service) UpdateTitle(id model.ID, title string) error {
-	t, ok := s.tasks[id]
-	if !ok {
-		return errNotFound(id)
+// List — выборка задач по фильтрам с сортировкой и постраничной выдачей.
+// Фильтры выполняет само хранилище (SQL/BSON), поэтому читаем оттуда, а не из кэша.
+// Обычный пользователь видит только свои, назначенные ему и общие задачи.
+func (s *Service) List(ctx context.Context, q model.TaskQuery) (model.TaskPage, error) {
+	if u, restricted := viewer(ctx); restricted {
+		q.VisibleTo = u
 	}
-	before := t.ToDTO()
-	if err := t.SetTitle(title); err != nil {
-		return err
+	fetch := q
+	if q.Limit > 0 {
+		fetch.Limit = q.Limit + 1 // лишняя запись — признак, что есть следующая страница
 	}
-	if err := s.persist(); err != nil {
-		return err
+	records, err := s.store.Query(ctx, fetch)
+	if err != nil {
+		return model.TaskPage{}, err
 	}
-	after := t.ToDTO()
-	logEvent("update_title", id, &before, &after)
-	return nil
-}
 
-func (s *Service) UpdateDesc(id model.ID, desc string) error {
-	t, ok := s.tasks[id]
-	if !ok {
-		return errNotFound(id)
+	var page model.TaskPage
+	if q.Limit > 0 && len(records) > q.Limit {
+		records = records[:q.Limit]
+		page.NextCursor = model.CursorFor(records[len(records)-1], q.SortKeys()).Encode()
 	}
-	before := t.ToDTO()
-	t.SetDescription(desc)
-	if err := s.persist(); err != nil {
-		return err
+	page.Items = make([]*model.Task, 0, len(records))
+	for _, r := range records {
+		t, err := model.FromDTO(r)
+		if err != nil {
+			continue
+		}
+		page.Items = append(page.Items, t)
 	}
-	after := t.ToDTO()
-	logEvent("update_desc", id, &before, &after)
-	return nil
+	return page, nil
+}
+
+// Get — одна задача по ID (снимок, менять нельзя)
+func (s *Service) Get(ctx context.Context, id model.ID) (*model.Task, error) {
+	return s.visibleTask(ctx, id)
 }
 
-func (s *Service) SetStatus(id model.ID, st model.Status) error {
-	t, ok := s.tasks[id]
+// Лимиты выдачи поиска
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+var (
+	ErrEmptyQuery        = errors.New("empty search query")
+	ErrSearchUnsupported = errors.New("full-text search is not supported by this store")
+)
+
+// Search — полнотекстовый поиск по заголовкам и описаниям, лучшие совпадения первыми
+func (s *Service) Search(ctx context.Context, query string, limit int) ([]model.SearchHit, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, ErrEmptyQuery
+	}
+	searcher, ok := s.store.(Searcher)
 	if !ok {
-		return errNotFound(id)
+		return nil, ErrSearchUnsupported
 	}
-	before := t.ToDTO()
-	if err := t.SetStatus(st); err != nil {
-		return err
+	if limit <= 0 {
+		limit = DefaultSearchLimit
 	}
-	if err := s.persist(); err != nil {
-		return err
+	u, _ := viewer(ctx)
+	q := model.SearchQuery{Text: query, VisibleTo: u, Limit: min(limit, MaxSearchLimit)}
+	visible, err := searcher.Search(ctx, q)
+	if err != nil {
+		return nil, err
 	}
-	after := t.ToDTO()
-	logEvent("set_status", id, &before, &after)
-	return nil
+	// архив ищется тут же, его задачи помечены archived_at
+	if a, ok := s.store.(Archiver); ok {
+		old, err := a.SearchArchive(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		visible = append(visible, old...)
+		sort.SliceStable(visible, func(i, j int) bool { return visible[i].Rank > visible[j].Rank })
+		if len(visible) > q.Limit {
+			visible = visible[:q.Limit]
+		}
+	}
+	return visible, nil
 }
 
-func (s *Service) SetPriority(id model.ID, p model.Priority) error {
-	t, ok := s.tasks[id]
-	if !ok {
-		return errNotFound(id)
-	}
-	before := t.ToDTO()
-	if err := t.SetPriority(p); err != nil {
+func (s *Service) UpdateTitle(ctx context.Context, id model.ID, title string) error {
+	return s.update(ctx, id, "update_title", func(t *model.Task) error {
+		return t.SetTitle(title)
+	})
+}
+
+func (s *Service) UpdateDesc(ctx context.Context, id model.ID, desc string) error {
+	return s.update(ctx, id, "update_desc", func(t *model.Task) error {
+		t.SetDescription(desc)
+		return nil
+	})
+}
+
+// SetStatus меняет статус по таблице переходов. Когда закрывают повторяющуюся задачу,
+// правило переезжает на новую задачу со сдвинутым сроком — так повторное
+// закрытие (после переоткрытия) не наплодит дублей.
+func (s *Service) SetStatus(ctx context.Context, id model.ID, st model.Status) error {
+	var next *model.Task
+	err := s.update(ctx, id, "set_status", func(t *model.Task) (err error) {
+		next, err = s.changeStatus(t, st)
 		return err
+	})
+	return s.recur(ctx, id, next, err)
+}
+
+// changeStatus — смена статуса копии t со всеми проверками. next — следующий раз
+// повторяющейся задачи: его сохраняет recur, когда замки уже отпущены
+func (s *Service) changeStatus(t *model.Task, st model.Status) (next *model.Task, err error) {
+	if st == model.StatusDone {
+		if open := s.openDescendants(t.ID()); open > 0 {
+			return nil, fmt.Errorf("%w: %d left", ErrOpenSubtasks, open)
+		}
 	}
-	if err := s.persist(); err != nil {
-		return err
+	if st == model.StatusInProgress && t.Status() != model.StatusInProgress {
+		if waiting := s.unfinishedBlockers(t); len(waiting) > 0 {
+			return nil, fmt.Errorf("%w: %v", ErrBlocked, waiting)
+		}
 	}
-	after := t.ToDTO()
-	logEvent("set_priority", id, &before, &after)
-	return nil
+	completing := st == model.StatusDone && t.Status() != model.StatusDone
+	if err := t.Transition(s.transitions, st); err != nil {
+		return nil, err
+	}
+	if completing && t.Recurrence() != nil {
+		next, _ = t.NextOccurrence(time.Now())
+		return next, t.SetRecurrence(nil)
+	}
+	return nil, nil
 }
 
-func (s *Service) SetDue(id model.ID, due time.Time) error {
-	t, ok := s.tasks[id]
-	if !ok {
-		return errNotFound(id)
-	}
-	before := t.ToDTO()
-	t.SetDueAt(due)
-	if err := s.persist(); err != nil {
+// recur — сохранить следующий раз задачи id после успешного закрытия
+func (s *Service) recur(ctx context.Context, id model.ID, next *model.Task, err error) error {
+	if err != nil || next == nil {
 		return err
 	}
-	after := t.ToDTO()
-	logEvent("set_due", id, &before, &after)
+	if _, err := s.insert(ctx, next, "recur"); err != nil {
+		return fmt.Errorf("task %d done, but next occurrence not created: %w", id, err)
+	}
 	return nil
 }
 
-func (s *Service) ClearDue(id model.ID) error {
-	t, ok := s.tasks[id]
-	if !ok {
+// SetRecurrence задаёт правило повторения, nil — убрать
+func (s *Service) SetRecurrence(ctx context.Context, id model.ID, r *model.Recurrence) error {
+	return s.update(ctx, id, "set_recurrence", func(t *model.Task) error {
+		return t.SetRecurrence(r)
+	})
+}
+
+func (s *Service) SetPriority(ctx context.Context, id model.ID, p model.Priority) error {
+	return s.update(ctx, id, "set_priority", func(t *model.Task) error {
+		return t.SetPriority(p)
+	})
+}
+
+func (s *Service) SetDue(ctx context.Context, id model.ID, due time.Time) error {
+	return s.update(ctx, id, "set_due", func(t *model.Task) error {
+		t.SetDueAt(due)
+		return nil
+	})
+}
+
+func (s *Service) ClearDue(ctx context.Context, id model.ID) error {
+	return s.update(ctx, id, "clear_due", func(t *model.Task) error {
+		t.ClearDue()
+		return nil
+	})
+}
+
+// Delete переносит задачу в корзину (см. Restore), её подзадачи поднимаются на уровень выше,
+// а из зависимых задач она пропадает как блокер. И то и другое запоминается у задачи в корзине.
+// Задачу без подзадач и зависимых удаляем как обычное изменение, иначе — монопольно:
+// сначала правим соседей и только потом удаляем.
+func (s *Service) Delete(ctx context.Context, id model.ID) error {
+	if done, err := s.deleteLeaf(ctx, id); done || err != nil {
+		return err
+	}
+
+	s.ops.Lock()
+	defer s.ops.Unlock()
+
+	e, ok := s.lookup(id)
+	if !ok || e.task == nil || !canSee(ctx, e.task) {
 		return errNotFound(id)
 	}
-	before := t.ToDTO()
-	t.ClearDue()
-	if err := s.persist(); err != nil {
+	if err := checkVersion(ctx, e.task); err != nil {
 		return err
 	}
-	after := t.ToDTO()
-	logEvent("clear_due", id, &before, &after)
-	return nil
+	var lifted []model.ID
+	for _, c := range s.children(id) {
+		ce, ok := s.lookup(c.ID())
+		if !ok {
+			continue
+		}
+		if err := s.reparent(ctx, ce, e.task.ParentID()); err != nil {
+			return err
+		}
+		lifted = append(lifted, c.ID())
+	}
+	unblocked, err := s.unblockDependents(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.discard(ctx, e, time.Now(), lifted, unblocked)
 }
 
-func (s *Service) Delete(id model.ID) error {
-	t, ok := s.tasks[id]
+// deleteLeaf — удаление задачи, на которую никто не ссылается;
+// false — есть подзадачи или зависимые, ничего не трогали
+func (s *Service) deleteLeaf(ctx context.Context, id model.ID) (bool, error) {
+	s.ops.RLock()
+	defer s.ops.RUnlock()
+
+	e, ok := s.lookup(id)
 	if !ok {
-		return errNotFound(id)
+		return false, errNotFound(id)
 	}
-	before := t.ToDTO()
-	delete(s.tasks, id)
-	if err := s.persist(); err != nil {
-		return err
+	// новые подзадачи добавляются под замком родителя, а зависимости — монопольно,
+	// так что ссылки на задачу тут не появятся
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.task == nil || !canSee(ctx, e.task) {
+		return false, errNotFound(id)
 	}
-	logEvent("delete", id, &before, nil)
-	return nil
+	if err := checkVersion(ctx, e.task); err != nil {
+		return false, err
+	}
+	if len(s.children(id)) > 0 || len(s.dependents(id)) > 0 {
+		return false, nil
+	}
+	return true, s.discard(ctx, e, time.Now(), nil, nil)
 }
 
+// ErrNotFound — задачи с таким ID нет; проверять через errors.Is
+var ErrNotFound = errors.New("task not found")
+
 type notFound struct{ id model.ID }
 
 func (e notFound) Error() string { return "task not found: " + strconv.FormatInt(int64(e.id), 10) }
 
+func (e notFound) Is(target error) bool { return target == ErrNotFound }
+
 func errNotFound(id model.ID) error { return notFound{id: id} }
 
+// IsConflict — операция противоречит текущему состоянию задач:
+// запрещённый переход статуса, открытые подзадачи, блокеры, цикл в дереве или зависимостях,
+// архивация открытой задачи, отмена поверх чужих изменений или когда отменять нечего
+func IsConflict(err error) bool {
+	var terr *model.TransitionError
+	return errors.As(err, &terr) ||
+		errors.Is(err, ErrOpenSubtasks) ||
+		errors.Is(err, ErrParentCycle) ||
+		errors.Is(err, ErrBlocked) ||
+		errors.Is(err, ErrDependencyCycle) ||
+		errors.Is(err, ErrNotClosed) ||
+		errors.Is(err, ErrUndoConflict) ||
+		errors.Is(err, ErrNothingToUndo) ||
+		errors.Is(err, ErrNothingToRedo)
+}
+
 // Гарантируем, что Service реализует TaskUseCase
-var _ TaskUseCase = (*Service)(nil)
\ No newline at end of file
+var _ TaskUseCase = (*Service)(nil)
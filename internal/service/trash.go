@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"todo/internal/model"
+	"todo/internal/repository"
+)
+
+// DefaultTrashRetention — сколько задача лежит в корзине, если TRASH_RETENTION не задан
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// TrashRetentionFromEnv — срок хранения корзины из TRASH_RETENTION (time.ParseDuration, "720h");
+// "0" — не чистить вовсе
+func TrashRetentionFromEnv() (time.Duration, error) {
+	raw := os.Getenv("TRASH_RETENTION")
+	if raw == "" {
+		return DefaultTrashRetention, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("TRASH_RETENTION: want a duration like 720h, got %q", raw)
+	}
+	return d, nil
+}
+
+// discard — задачу в корзину: запись остаётся в хранилище с deletedAt и тем, что от неё
+// отцепили (lifted, unblocked), из живых задач кэша уходит. Вызывать под замком задачи или s.ops.Lock
+func (s *Service) discard(ctx context.Context, e *entry, at time.Time, lifted, unblocked []model.ID) error {
+	t := e.task.Clone()
+	t.MoveToTrash(at, lifted, unblocked)
+	before, after := e.task.ToDTO(), t.ToDTO()
+	if err := s.store.Update(ctx, after, before.Version); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			s.refresh(ctx, e)
+			return fmt.Errorf("%w: task %d was changed by someone else", ErrVersionMismatch, t.ID())
+		}
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.tasks, t.ID())
+	s.retag(e.task, nil)
+	e.task = nil
+	s.trash[t.ID()] = t
+	s.mu.Unlock()
+
+	s.logEvent(ctx, "delete", t.ID(), &before, &after)
+	return nil
+}
+
+// purge — стереть задачу из корзины насовсем; вызывать под s.ops.Lock
+func (s *Service) purge(ctx context.Context, t *model.Task) error {
+	if err := s.store.Delete(ctx, t.ID()); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	s.mu.Lock()
+	delete(s.trash, t.ID())
+	s.mu.Unlock()
+
+	before := t.ToDTO()
+	s.logEvent(ctx, "purge", t.ID(), &before, nil)
+	// обсуждение и вложения уходят вместе с задачей, иначе достанутся той, что получит её номер
+	if c, ok := s.store.(Commenter); ok {
+		if err := c.DeleteTaskComments(ctx, t.ID()); err != nil {
+			return err
+		}
+	}
+	if a, ok := s.store.(Attacher); ok {
+		gone, err := a.DeleteTaskAttachments(ctx, t.ID())
+		if err != nil {
+			return err
+		}
+		s.dropBlobs(ctx, gone...)
+	}
+	return nil
+}
+
+// Trash — задачи в корзине, которые видит вызывающий; недавно удалённые первыми
+func (s *Service) Trash(ctx context.Context) ([]*model.Task, error) {
+	s.mu.RLock()
+	list := make([]*model.Task, 0, len(s.trash))
+	for _, t := range s.trash {
+		if canSee(ctx, t) {
+			list = append(list, t)
+		}
+	}
+	s.mu.RUnlock()
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i].DeletedAt(), list[j].DeletedAt()
+		if !a.Equal(*b) {
+			return a.After(*b)
+		}
+		return list[i].ID() < list[j].ID()
+	})
+	return list, nil
+}
+
+// Restore достаёт задачу из корзины вместе с подзадачами, удалёнными вместе с ней (DeleteTree).
+// Подзадачи, которые при удалении поднялись на уровень выше, возвращаются под неё, а зависимые
+// снова ждут её как блокер — если с тех пор их не переносили и цикла не выйдет.
+// Если родителя уже нет, задача встаёт на верхний уровень; пропавшие блокеры отбрасываются.
+// Ветка возвращается по одной задаче, не атомарно: если хранилище откажет посередине
+// (например, запись изменил другой экземпляр — ErrVersionMismatch), уже возвращённые
+// задачи остаются живыми, а остальные — в корзине, их можно вернуть Restore по одной.
+func (s *Service) Restore(ctx context.Context, id model.ID) error {
+	s.ops.Lock()
+	defer s.ops.Unlock()
+
+	s.mu.RLock()
+	root, ok := s.trash[id]
+	s.mu.RUnlock()
+	if !ok || !canSee(ctx, root) {
+		return errNotFound(id)
+	}
+	if err := checkVersion(ctx, root); err != nil {
+		return err
+	}
+	group := s.deletedWith(root)
+	back := make(map[model.ID]bool, len(group))
+	for _, t := range group {
+		back[t.ID()] = true
+	}
+	alive := func(id model.ID) bool {
+		if back[id] {
+			return true
+		}
+		_, ok := s.lookup(id)
+		return ok
+	}
+	// родители раньше подзадач: если хранилище споткнётся, дерево останется связным
+	for _, t := range group {
+		c := t.Clone()
+		c.Restore()
+		if p := c.ParentID(); p != 0 && !alive(p) {
+			c.SetParent(0)
+		}
+		for _, b := range c.BlockedBy() {
+			if !alive(b) {
+				c.RemoveBlocker(b)
+			}
+		}
+		before, after := t.ToDTO(), c.ToDTO()
+		if err := s.store.Update(ctx, after, before.Version); err != nil {
+			if errors.Is(err, repository.ErrConflict) {
+				s.refreshTrashed(ctx, t.ID())
+				return fmt.Errorf("%w: task %d was changed by someone else", ErrVersionMismatch, t.ID())
+			}
+			return err
+		}
+
+		s.mu.Lock()
+		delete(s.trash, c.ID())
+		s.tasks[c.ID()] = &entry{task: c}
+		s.retag(nil, c)
+		s.mu.Unlock()
+
+		s.logEvent(ctx, "restore", c.ID(), &before, &after)
+	}
+	for _, t := range group {
+		if err := s.reattach(ctx, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshTrashed — как refresh, но для задачи из корзины: перечитываем запись и кладём
+// туда, где она теперь (в корзине, среди живых или нигде — стёрта). Вызывать под s.ops.Lock
+func (s *Service) refreshTrashed(ctx context.Context, id model.ID) {
+	r, err := s.store.Get(ctx, id)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return
+	}
+	var t *model.Task
+	if err == nil {
+		if t, err = model.FromDTO(r); err != nil {
+			return
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.trash, id)
+	switch {
+	case t == nil:
+	case t.DeletedAt() != nil:
+		s.trash[id] = t
+	default:
+		if _, ok := s.tasks[id]; !ok {
+			s.tasks[id] = &entry{task: t}
+			s.retag(nil, t)
+		}
+	}
+}
+
+// reattach — вернуть восстановленной задаче то, что Delete от неё отцепил (t — её запись
+// из корзины). Подзадачу, которую с тех пор перенесли, и удалённые задачи не трогаем.
+// Вызывать под s.ops.Lock
+func (s *Service) reattach(ctx context.Context, t *model.Task) error {
+	children, dependents := t.Detached()
+	for _, id := range children {
+		ce, ok := s.lookup(id)
+		// подняли её к родителю t: если она всё ещё там, цикла под t не будет
+		if !ok || ce.task == nil || ce.task.ParentID() != t.ParentID() {
+			continue
+		}
+		if err := s.reparent(ctx, ce, t.ID()); err != nil {
+			return err
+		}
+	}
+	for _, id := range dependents {
+		de, ok := s.lookup(id)
+		if !ok || de.task == nil || de.task.DependsOn(t.ID()) || s.dependsOn(t.ID(), id) {
+			continue
+		}
+		if err := s.apply(ctx, de, "add_dependency", func(d *model.Task) error {
+			d.AddBlocker(t.ID())
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deletedWith — root и его подзадачи из корзины, удалённые тем же вызовом, родители первыми
+func (s *Service) deletedWith(root *model.Task) []*model.Task {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	group := []*model.Task{root}
+	for i := 0; i < len(group); i++ {
+		for _, t := range s.trash {
+			if t.ParentID() == group[i].ID() && t.DeletedAt().Equal(*root.DeletedAt()) {
+				group = append(group, t)
+			}
+		}
+	}
+	return group
+}
+
+// PurgeTrash стирает насовсем задачи, попавшие в корзину раньше before; отдаёт, сколько стёрто
+func (s *Service) PurgeTrash(ctx context.Context, before time.Time) (int, error) {
+	s.ops.Lock()
+	defer s.ops.Unlock()
+
+	s.mu.RLock()
+	var old []*model.Task
+	for _, t := range s.trash {
+		if t.DeletedAt().Before(before) {
+			old = append(old, t)
+		}
+	}
+	s.mu.RUnlock()
+
+	for i, t := range old {
+		if err := s.purge(ctx, t); err != nil {
+			return i, err
+		}
+	}
+	return len(old), nil
+}
+
+// PurgeTrash — то же для всех проектов арендатора
+func (ps *Projects) PurgeTrash(ctx context.Context, before time.Time) (int, error) {
+	return ps.sweep(ctx, func(svc *Service) (int, error) { return svc.PurgeTrash(ctx, before) })
+}
+
+// PurgeTrash — то же для всех арендаторов (см. Tenants.ListFrom)
+func (ts *Tenants) PurgeTrash(ctx context.Context, before time.Time) (int, error) {
+	return ts.sweep(ctx, func(ps *Projects) (int, error) { return ps.PurgeTrash(ctx, before) })
+}
+
+// PurgeTrashPeriodically — фоновая очистка корзины: задачи старше retention стираются насовсем.
+// Проверяет раз в час (при коротком сроке — чаще), пока не отменят ctx; retention 0 — выключено
+func PurgeTrashPeriodically(ctx context.Context, ts *Tenants, retention time.Duration) {
+	if retention <= 0 {
+		return
+	}
+	ticker := time.NewTicker(min(time.Hour, max(retention/4, time.Second)))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			n, err := ts.PurgeTrash(ctx, time.Now().Add(-retention))
+			if err != nil {
+				fmt.Println("[корзина] ошибка очистки:", err)
+			}
+			if n > 0 && DebugMode {
+				fmt.Printf("[корзина] стёрто задач: %d\n", n)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
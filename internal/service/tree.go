@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"todo/internal/model"
+)
+
+var (
+	ErrOpenSubtasks = errors.New("task has open subtasks")
+	ErrParentCycle  = errors.New("task cannot be moved under itself or its subtask")
+)
+
+// AddSubtask — новая задача внутри parent
+func (s *Service) AddSubtask(ctx context.Context, parent model.ID, title, desc string, p model.Priority, due *time.Time) (model.ID, error) {
+	if parent == 0 {
+		return 0, errNotFound(parent)
+	}
+	return s.Create(ctx, model.TaskDraft{Title: title, Description: desc, Priority: p, DueAt: due, ParentID: parent})
+}
+
+// SetParent переносит задачу под другого родителя, parent == 0 — на верхний уровень.
+// Проверка на цикл смотрит на всё дерево, поэтому идём монопольно.
+func (s *Service) SetParent(ctx context.Context, id, parent model.ID) error {
+	s.ops.Lock()
+	defer s.ops.Unlock()
+
+	e, ok := s.lookup(id)
+	if !ok || e.task == nil || !canSee(ctx, e.task) {
+		return errNotFound(id)
+	}
+	if err := s.checkParent(ctx, id, parent); err != nil {
+		return err
+	}
+	if e.task.ParentID() == parent {
+		return nil
+	}
+	return s.reparent(ctx, e, parent)
+}
+
+// checkParent — можно ли перенести задачу id под parent: родитель виден
+// и не лежит в её же поддереве. Вызывать под s.ops.Lock
+func (s *Service) checkParent(ctx context.Context, id, parent model.ID) error {
+	if parent == id {
+		return ErrParentCycle
+	}
+	if parent == 0 {
+		return nil
+	}
+	if _, err := s.visibleTask(ctx, parent); err != nil {
+		return err
+	}
+	for _, a := range s.ancestors(parent) {
+		if a.ID() == id {
+			return ErrParentCycle
+		}
+	}
+	return nil
+}
+
+// reparent — сохранить задачу с новым родителем; вызывать под s.ops.Lock
+func (s *Service) reparent(ctx context.Context, e *entry, parent model.ID) error {
+	return s.apply(ctx, e, "set_parent", func(t *model.Task) error {
+		t.SetParent(parent)
+		return nil
+	})
+}
+
+// DeleteTree переносит в корзину задачу вместе со всеми подзадачами: у всех одно время
+// удаления, и Restore вернёт их вместе. Сначала листья: если хранилище споткнётся,
+// дерево останется связным.
+func (s *Service) DeleteTree(ctx context.Context, id model.ID) error {
+	s.ops.Lock()
+	defer s.ops.Unlock()
+
+	e, ok := s.lookup(id)
+	if !ok || e.task == nil || !canSee(ctx, e.task) {
+		return errNotFound(id)
+	}
+	now := time.Now()
+	subtree := s.descendants(id)
+	for i := len(subtree) - 1; i >= 0; i-- {
+		ce, ok := s.lookup(subtree[i].ID())
+		if !ok {
+			continue
+		}
+		unblocked, err := s.unblockDependents(ctx, ce.task.ID())
+		if err != nil {
+			return err
+		}
+		if err := s.discard(ctx, ce, now, nil, unblocked); err != nil {
+			return err
+		}
+	}
+	unblocked, err := s.unblockDependents(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.discard(ctx, e, now, nil, unblocked)
+}
+
+// Children — прямые подзадачи по порядку создания (только видимые вызывающему)
+func (s *Service) Children(ctx context.Context, id model.ID) ([]*model.Task, error) {
+	if _, err := s.visibleTask(ctx, id); err != nil {
+		return nil, err
+	}
+	return visibleOnly(ctx, s.children(id)), nil
+}
+
+// Ancestors — цепочка родителей от ближайшего до корня (только видимые вызывающему)
+func (s *Service) Ancestors(ctx context.Context, id model.ID) ([]*model.Task, error) {
+	if _, err := s.visibleTask(ctx, id); err != nil {
+		return nil, err
+	}
+	return visibleOnly(ctx, s.ancestors(id)), nil
+}
+
+// Progress — сколько подзадач во всём поддереве сделано; чужие подзадачи не считаем
+func (s *Service) Progress(ctx context.Context, id model.ID) (model.Progress, error) {
+	if _, err := s.visibleTask(ctx, id); err != nil {
+		return model.Progress{}, err
+	}
+	var p model.Progress
+	for _, t := range visibleOnly(ctx, s.descendants(id)) {
+		switch t.Status() {
+		case model.StatusCanceled:
+		case model.StatusDone:
+			p.Total++
+			p.Done++
+		default:
+			p.Total++
+		}
+	}
+	return p, nil
+}
+
+// children — прямые подзадачи из кэша
+func (s *Service) children(id model.ID) []*model.Task {
+	s.mu.RLock()
+	var out []*model.Task
+	for _, e := range s.tasks {
+		if e.task != nil && e.task.ParentID() == id {
+			out = append(out, e.task)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].CreatedAt().Before(out[j].CreatedAt())
+	})
+	return out
+}
+
+// descendants — всё поддерево без самой задачи, родители раньше детей
+func (s *Service) descendants(id model.ID) []*model.Task {
+	// индекс родитель → дети строим один раз, а не сканируем кэш на каждом уровне
+	s.mu.RLock()
+	kids := make(map[model.ID][]*model.Task)
+	for _, e := range s.tasks {
+		if e.task != nil && e.task.ParentID() != 0 {
+			kids[e.task.ParentID()] = append(kids[e.task.ParentID()], e.task)
+		}
+	}
+	s.mu.RUnlock()
+
+	var out []*model.Task
+	seen := map[model.ID]bool{id: true}
+	queue := []model.ID{id}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, c := range kids[cur] {
+			if seen[c.ID()] {
+				continue
+			}
+			seen[c.ID()] = true
+			out = append(out, c)
+			queue = append(queue, c.ID())
+		}
+	}
+	return out
+}
+
+// ancestors — родители вверх по дереву; на битых данных с циклом не зацикливаемся
+func (s *Service) ancestors(id model.ID) []*model.Task {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out []*model.Task
+	seen := map[model.ID]bool{id: true}
+	e, ok := s.tasks[id]
+	for ok && e.task != nil && e.task.ParentID() != 0 && !seen[e.task.ParentID()] {
+		seen[e.task.ParentID()] = true
+		e, ok = s.tasks[e.task.ParentID()]
+		if ok && e.task != nil {
+			out = append(out, e.task)
+		}
+	}
+	return out
+}
+
+// openDescendants — сколько незакрытых задач в поддереве
+func (s *Service) openDescendants(id model.ID) int {
+	n := 0
+	for _, t := range s.descendants(id) {
+		if t.Open() {
+			n++
+		}
+	}
+	return n
+}
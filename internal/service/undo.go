@@ -0,0 +1,580 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"todo/internal/model"
+	"todo/internal/policy"
+	"todo/internal/repository"
+	"todo/internal/reqctx"
+)
+
+var (
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrNothingToRedo = errors.New("nothing to redo")
+	ErrUndoConflict  = errors.New("later changes conflict with undo")
+)
+
+// undoDepth — сколько последних операций каждого пользователя можно отменить
+const undoDepth = 50
+
+// UndoStep — отменённая или повторённая операция: из каких событий она состояла и какие задачи задела
+type UndoStep struct {
+	Ops     []string   `json:"ops"`                // события по порядку, без повторов: "set_status", "recur"
+	At      time.Time  `json:"at"`                 // когда операцию сделали
+	TaskIDs []model.ID `json:"task_ids,omitempty"` // у перенумерации пусто
+}
+
+// change — задача в шаге истории: какой была до операции и какой стала после.
+// before nil — операция задачу создала
+type change struct {
+	id            model.ID
+	before, after *model.TaskDTO
+}
+
+// step — одна операция пользователя: события одного запроса к API или одного пункта
+// меню консоли (reqctx.RequestID; не trace id — его задаёт клиент). У перенумерации вместо changes — remap
+type step struct {
+	request string
+	ops     []string
+	at      time.Time
+	changes []change
+	remap   map[model.ID]model.ID
+	stale   bool // задачи с тех пор перенумеровали или шаг применился не до конца — больше не трогаем
+}
+
+func (st *step) view() UndoStep {
+	v := UndoStep{Ops: slices.Clone(st.ops), At: st.at}
+	for _, ch := range st.changes {
+		v.TaskIDs = append(v.TaskIDs, ch.id)
+	}
+	return v
+}
+
+// history — стеки отмены и повтора одного пользователя, последние операции в конце
+type history struct {
+	undo, redo []*step
+}
+
+// remember — событие в историю отмен его автора (консоль — пользователь 0).
+// Не попадают: фоновые задачи (вне запроса) — отменять их некому; сами отмены и повторы;
+// стирание из корзины — его не вернуть; события без снимков (комментарии, вложения)
+func (s *Service) remember(ctx context.Context, e Event) {
+	request := reqctx.RequestID(ctx)
+	switch {
+	case request == "", e.Op == "undo", e.Op == "redo", e.Op == "purge":
+		return
+	case e.Before == nil && e.After == nil && len(e.Remap) == 0:
+		return
+	}
+	u, _ := reqctx.UserFrom(ctx)
+
+	s.hmu.Lock()
+	defer s.hmu.Unlock()
+	h := s.historyOf(u.ID)
+	h.redo = nil // новая операция — повторять отменённое уже поздно
+	var top *step
+	if n := len(h.undo); n > 0 {
+		top = h.undo[n-1]
+	}
+	if top == nil || top.request != request || top.remap != nil || len(e.Remap) > 0 {
+		top = &step{request: request, at: e.At, remap: e.Remap}
+		h.undo = push(h.undo, top)
+	}
+	if !slices.Contains(top.ops, e.Op) {
+		top.ops = append(top.ops, e.Op)
+	}
+	if len(e.Remap) > 0 {
+		return
+	}
+	for i := range top.changes {
+		if top.changes[i].id == e.TaskID {
+			top.changes[i].after = e.After
+			return
+		}
+	}
+	top.changes = append(top.changes, change{id: e.TaskID, before: e.Before, after: e.After})
+}
+
+// historyOf — история пользователя u; вызывать под s.hmu
+func (s *Service) historyOf(u model.UserID) *history {
+	h, ok := s.histories[u]
+	if !ok {
+		h = &history{}
+		s.histories[u] = h
+	}
+	return h
+}
+
+// push — шаг на верх стека; самые старые сверх undoDepth забываем
+func push(stack []*step, st *step) []*step {
+	stack = append(stack, st)
+	if len(stack) > undoDepth {
+		stack = slices.Delete(stack, 0, len(stack)-undoDepth)
+	}
+	return stack
+}
+
+// Undo отменяет последние n операций вызывающего (n < 1 — одну), новые первыми.
+// Каждая задача операции возвращается к снимку «до» из её событий аудита: удалённая —
+// из корзины, убранная в архив — из архива, созданная — в корзину; перенумерация —
+// к прежним номерам. Если задачу с тех пор меняли (другая версия, лежит уже не там)
+// или отмена порвёт дерево и зависимости, отказываем с ErrUndoConflict и операцию не трогаем.
+// Отдаёт отменённые операции, на ошибке — те, что успели до неё.
+// История живёт в памяти сервиса и с перезапуском пропадает
+func (s *Service) Undo(ctx context.Context, n int) ([]UndoStep, error) {
+	return s.replay(ctx, n, true)
+}
+
+// Redo повторяет последние n отменённых операций. Любая новая операция
+// пользователя стек повтора сбрасывает; проверки те же, что у Undo
+func (s *Service) Redo(ctx context.Context, n int) ([]UndoStep, error) {
+	return s.replay(ctx, n, false)
+}
+
+// replay — общий ход Undo и Redo. Идём монопольно, как перенумерация:
+// проверяем весь граф задач, а заодно никто не вклинится между проверкой и записью
+func (s *Service) replay(ctx context.Context, n int, undo bool) ([]UndoStep, error) {
+	if err := policy.Check(ctx, policy.Update); err != nil {
+		return nil, err
+	}
+	u, _ := reqctx.UserFrom(ctx)
+	s.ops.Lock()
+	defer s.ops.Unlock()
+
+	var done []UndoStep
+	for range max(n, 1) {
+		st := s.topStep(u.ID, undo)
+		switch {
+		case st == nil && len(done) > 0:
+			return done, nil
+		case st == nil && undo:
+			return nil, ErrNothingToUndo
+		case st == nil:
+			return nil, ErrNothingToRedo
+		case st.stale:
+			return done, fmt.Errorf("%w: tasks were renumbered or changed by a failed undo since %s",
+				ErrUndoConflict, st.at.Local().Format("2006-01-02 15:04"))
+		}
+		var err error
+		if st.remap != nil {
+			err = s.replayRenumber(ctx, st, undo)
+		} else {
+			err = s.replayChanges(ctx, st, undo)
+		}
+		if err != nil {
+			return done, err
+		}
+		s.flipStep(u.ID, st, undo)
+		done = append(done, st.view())
+	}
+	return done, nil
+}
+
+// topStep — верх стека отмены (undo) или повтора пользователя u, nil — пусто
+func (s *Service) topStep(u model.UserID, undo bool) *step {
+	s.hmu.Lock()
+	defer s.hmu.Unlock()
+	h := s.historyOf(u)
+	stack := h.redo
+	if undo {
+		stack = h.undo
+	}
+	if len(stack) == 0 {
+		return nil
+	}
+	return stack[len(stack)-1]
+}
+
+// flipStep — шаг применён: переезжает с верха одного стека на другой
+func (s *Service) flipStep(u model.UserID, st *step, undo bool) {
+	s.hmu.Lock()
+	defer s.hmu.Unlock()
+	h := s.historyOf(u)
+	from, to := &h.redo, &h.undo
+	if undo {
+		from, to = &h.undo, &h.redo
+	}
+	if i := slices.Index(*from, st); i >= 0 {
+		*from = slices.Delete(*from, i, i+1)
+	}
+	*to = push(*to, st)
+}
+
+// markStale — шаг записался не до конца: часть задач уже в новых версиях
+func (s *Service) markStale(st *step) {
+	s.hmu.Lock()
+	st.stale = true
+	s.hmu.Unlock()
+}
+
+// placement — где лежит задача
+type placement int
+
+const (
+	inHot placement = iota
+	inTrash
+	inArchive
+)
+
+func where(deleted, archived *time.Time) placement {
+	switch {
+	case archived != nil:
+		return inArchive
+	case deleted != nil:
+		return inTrash
+	default:
+		return inHot
+	}
+}
+
+// replayMove — задача переходит из состояния from (его и ждём сейчас) в to
+type replayMove struct {
+	ch       *change
+	from, to *model.TaskDTO
+}
+
+// replayChanges — задачи шага к снимкам «до» (undo) или «после» (redo).
+// Сначала проверяем всё разом, потом пишем в обратном порядке событий для отмены
+// и в прямом для повтора: так каждое промежуточное состояние уже встречалось
+func (s *Service) replayChanges(ctx context.Context, st *step, undo bool) error {
+	now := time.Now().UTC()
+	moves := make([]replayMove, 0, len(st.changes))
+	for i := range st.changes {
+		ch := &st.changes[i]
+		from, to := ch.before, ch.after
+		if undo {
+			from, to = ch.after, ch.before
+		}
+		if from == nil {
+			return fmt.Errorf("%w: task %d has no state to start from", ErrUndoConflict, ch.id)
+		}
+		if to == nil {
+			// отмена создания: в корзину, а не насовсем — задачу могли уже обсуждать
+			d := *from
+			d.DeletedAt = &now
+			to = &d
+		}
+		moves = append(moves, replayMove{ch: ch, from: from, to: to})
+	}
+	if undo {
+		slices.Reverse(moves)
+	}
+	if err := s.checkReplay(ctx, moves); err != nil {
+		return err
+	}
+
+	op := "redo"
+	if undo {
+		op = "undo"
+	}
+	for _, m := range moves {
+		written, err := s.moveTo(ctx, m.from, m.to)
+		if err != nil {
+			s.markStale(st)
+			return err
+		}
+		if undo {
+			m.ch.before = written
+		} else {
+			m.ch.after = written
+		}
+		s.logEvent(ctx, op, m.ch.id, m.from, written)
+	}
+	return nil
+}
+
+// checkReplay — можно ли перевести задачи в состояния moves: каждая сейчас ровно в from
+// и видна вызывающему, а горячие задачи после перехода образуют целое дерево без циклов
+// и никто не зависит от задачи, уходящей в корзину. Вызывать под s.ops.Lock
+func (s *Service) checkReplay(ctx context.Context, moves []replayMove) error {
+	for _, m := range moves {
+		if where(m.from.DeletedAt, m.from.ArchivedAt) == inTrash || where(m.to.DeletedAt, m.to.ArchivedAt) == inTrash {
+			if err := policy.Check(ctx, policy.Delete); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	parent := make(map[model.ID]model.ID)
+	blockers := make(map[model.ID][]model.ID)
+	for _, t := range s.snapshot() {
+		parent[t.ID()], blockers[t.ID()] = t.ParentID(), t.BlockedBy()
+	}
+	leaving := make(map[model.ID]placement)
+	for _, m := range moves {
+		id := m.from.ID
+		cur, err := s.locate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if cur == nil || !canSee(ctx, cur) {
+			return fmt.Errorf("%w: task %d is gone", ErrUndoConflict, id)
+		}
+		if cur.Version() != m.from.Version || where(cur.DeletedAt(), cur.ArchivedAt()) != where(m.from.DeletedAt, m.from.ArchivedAt) {
+			return fmt.Errorf("%w: task %d was changed after this operation", ErrUndoConflict, id)
+		}
+		delete(parent, id)
+		delete(blockers, id)
+		if p := where(m.to.DeletedAt, m.to.ArchivedAt); p != inHot {
+			leaving[id] = p
+			continue
+		}
+		parent[id], blockers[id] = m.to.ParentID, m.to.BlockedBy
+	}
+
+	for id, p := range parent {
+		if _, ok := leaving[p]; ok {
+			return fmt.Errorf("%w: task %d has subtask %d", ErrUndoConflict, p, id)
+		}
+		for _, b := range blockers[id] {
+			if leaving[b] == inTrash {
+				return fmt.Errorf("%w: task %d depends on task %d", ErrUndoConflict, id, b)
+			}
+		}
+	}
+	for _, m := range moves {
+		id := m.to.ID
+		if _, ok := leaving[id]; ok {
+			continue
+		}
+		if p := parent[id]; p != 0 {
+			if _, ok := parent[p]; !ok {
+				return fmt.Errorf("%w: parent %d of task %d is gone", ErrUndoConflict, p, id)
+			}
+		}
+		seen := make(map[model.ID]bool)
+		for x := id; x != 0; x = parent[x] {
+			if seen[x] {
+				return fmt.Errorf("%w: task %d would end up under its own subtask", ErrUndoConflict, id)
+			}
+			seen[x] = true
+		}
+		if reaches(blockers, id, id) {
+			return fmt.Errorf("%w: dependencies of task %d would form a cycle", ErrUndoConflict, id)
+		}
+	}
+	return nil
+}
+
+// reaches — ведёт ли цепочка блокеров от задачи from к target
+func reaches(blockers map[model.ID][]model.ID, from, target model.ID) bool {
+	seen := make(map[model.ID]bool)
+	stack := slices.Clone(blockers[from])
+	for len(stack) > 0 {
+		b := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if b == target {
+			return true
+		}
+		if !seen[b] {
+			seen[b] = true
+			stack = append(stack, blockers[b]...)
+		}
+	}
+	return false
+}
+
+// locate — задача id где бы она ни лежала: в кэше, в корзине или в архиве; nil — нигде
+func (s *Service) locate(ctx context.Context, id model.ID) (*model.Task, error) {
+	s.mu.RLock()
+	e, hot := s.tasks[id]
+	trashed := s.trash[id]
+	s.mu.RUnlock()
+	switch {
+	case hot && e.task != nil:
+		return e.task, nil
+	case trashed != nil:
+		return trashed, nil
+	}
+	a, ok := s.store.(Archiver)
+	if !ok {
+		return nil, nil
+	}
+	r, err := a.GetArchived(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return model.FromDTO(r)
+}
+
+// moveTo — переход задачи из from в to: правка на месте, в корзину и обратно,
+// в архив и обратно. Отдаёт то, что записали. Вызывать под s.ops.Lock после checkReplay
+func (s *Service) moveTo(ctx context.Context, from, to *model.TaskDTO) (*model.TaskDTO, error) {
+	id := from.ID
+	fp, tp := where(from.DeletedAt, from.ArchivedAt), where(to.DeletedAt, to.ArchivedAt)
+	switch {
+	case fp == inArchive && tp == inHot:
+		a, err := s.archiver()
+		if err != nil {
+			return nil, err
+		}
+		r, err := a.Unarchive(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return s.rewrite(ctx, r, to)
+	case fp == inHot && tp == inArchive:
+		a, err := s.archiver()
+		if err != nil {
+			return nil, err
+		}
+		// в архив уезжают без новой версии, как и в Archive
+		rec := *to
+		rec.Version = from.Version
+		if err := a.Archive(ctx, []model.TaskDTO{rec}); err != nil {
+			return nil, err
+		}
+		s.settle(id, nil)
+		return &rec, nil
+	case fp != inArchive && tp != inArchive:
+		return s.rewrite(ctx, *from, to)
+	default:
+		return nil, fmt.Errorf("%w: task %d cannot move between trash and archive", ErrUndoConflict, id)
+	}
+}
+
+// rewrite — задача cur (горячая или из корзины) получает содержимое to новой версией
+func (s *Service) rewrite(ctx context.Context, cur model.TaskDTO, to *model.TaskDTO) (*model.TaskDTO, error) {
+	t, err := model.FromDTO(cur)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.RevertTo(*to); err != nil {
+		return nil, err
+	}
+	r := t.ToDTO()
+	if err := s.store.Update(ctx, r, cur.Version); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("%w: task %d was changed by someone else", ErrUndoConflict, r.ID)
+		}
+		return nil, err
+	}
+	s.settle(r.ID, t)
+	return &r, nil
+}
+
+// settle — задача id в кэше на новом месте: t горячая или в корзине, nil — уехала в архив
+func (s *Service) settle(id model.ID, t *model.Task) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var old *model.Task
+	e, hot := s.tasks[id]
+	if hot {
+		old = e.task
+	}
+	delete(s.trash, id)
+	if t != nil && t.DeletedAt() == nil {
+		if hot {
+			e.task = t
+		} else {
+			s.tasks[id] = &entry{task: t}
+		}
+		s.retag(old, t)
+		return
+	}
+	if hot {
+		e.task = nil
+		delete(s.tasks, id)
+		s.retag(old, nil)
+	}
+	if t != nil {
+		s.trash[id] = t
+	}
+}
+
+// replayRenumber — перенумерация шага назад (undo) или снова (redo). Задачи, появившиеся
+// после неё, номера не меняют; если кто-то из них занял нужный номер — конфликт
+func (s *Service) replayRenumber(ctx context.Context, st *step, undo bool) error {
+	if err := policy.Check(ctx, policy.Renumber); err != nil {
+		return err
+	}
+	want := st.remap
+	if undo {
+		want = make(map[model.ID]model.ID, len(st.remap))
+		for old, id := range st.remap {
+			want[id] = old
+		}
+	}
+	list, err := s.allTasks(ctx)
+	if err != nil {
+		return err
+	}
+	remap := make(map[model.ID]model.ID, len(list))
+	for _, t := range list {
+		if id, ok := want[t.ID()]; ok {
+			remap[t.ID()] = id
+		} else {
+			remap[t.ID()] = t.ID()
+		}
+	}
+	owner := make(map[model.ID]model.ID, len(remap))
+	for old, id := range remap {
+		if other, ok := owner[id]; ok {
+			return fmt.Errorf("%w: tasks %d and %d would both get number %d", ErrUndoConflict, min(old, other), max(old, other), id)
+		}
+		owner[id] = old
+	}
+
+	changed, err := s.renumber(ctx, list, remap)
+	if err != nil {
+		return err // хранилище и кэш остались на прежних номерах, шаг можно повторить
+	}
+	// renumber пометил устаревшими все перенумерации в истории, но эта как раз актуальна
+	s.hmu.Lock()
+	st.stale = false
+	s.hmu.Unlock()
+
+	op := "redo"
+	if undo {
+		op = "undo"
+	}
+	s.emit(ctx, Event{Op: op, Remap: changed})
+	return nil
+}
+
+// renumberHistory — история отмен вслед за перенумерацией: снимки переводим на новые номера.
+// Прежние перенумерации и операции над задачами, которых уже нет, отменить больше нельзя
+func (s *Service) renumberHistory(remap map[model.ID]model.ID) {
+	s.hmu.Lock()
+	defer s.hmu.Unlock()
+	for _, h := range s.histories {
+		for _, st := range slices.Concat(h.undo, h.redo) {
+			if st.remap != nil {
+				st.stale = true
+				continue
+			}
+			for i := range st.changes {
+				ch := &st.changes[i]
+				id, ok := remap[ch.id]
+				if !ok {
+					st.stale = true
+					continue
+				}
+				ch.id = id
+				ch.before, ch.after = renumberSnapshot(ch.before, remap), renumberSnapshot(ch.after, remap)
+			}
+		}
+	}
+}
+
+func renumberSnapshot(r *model.TaskDTO, remap map[model.ID]model.ID) *model.TaskDTO {
+	if r == nil {
+		return nil
+	}
+	t, err := model.FromDTO(*r)
+	if err != nil {
+		return r
+	}
+	t.Renumber(remap)
+	c := t.ToDTO()
+	return &c
+}
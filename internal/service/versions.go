@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"todo/internal/model"
+)
+
+// ErrVersionMismatch — задачу успели изменить: ожидаемая версия (If-Match,
+// expected_version) не совпала с текущей. Проверять через errors.Is
+var ErrVersionMismatch = errors.New("task version mismatch")
+
+type expectKey struct{}
+
+// expectation — какую версию задачи ждёт запрос. Меняется по ходу запроса:
+// после каждого изменения задача уже в новой версии, и следующее ждёт её.
+// Замка нет: контекст принадлежит одному запросу и одной горутине
+type expectation struct {
+	id      model.ID
+	version int64
+}
+
+// ExpectVersion — изменения задачи id в этом контексте пройдут, только если
+// она всё ещё в версии v. PUT и PATCH — один Patch и одна проверка; если же в одном
+// контексте задачу меняют несколько раз подряд, проверка идёт по цепочке версий,
+// так что чужая правка посередине тоже не пройдёт.
+// Другие задачи, которые операция задевает (подзадачи, зависимые), не проверяются.
+// Цепочка хранится в самом контексте, поэтому отдавать его в несколько горутин нельзя:
+// для параллельных изменений — свой ExpectVersion на каждое.
+func ExpectVersion(ctx context.Context, id model.ID, v int64) context.Context {
+	return context.WithValue(ctx, expectKey{}, &expectation{id: id, version: v})
+}
+
+// checkVersion — совпадает ли t с ожидаемой версией, если запрос её задал
+func checkVersion(ctx context.Context, t *model.Task) error {
+	exp, ok := ctx.Value(expectKey{}).(*expectation)
+	if !ok || exp.id != t.ID() || exp.version == t.Version() {
+		return nil
+	}
+	return fmt.Errorf("%w: task %d is at version %d, not %d", ErrVersionMismatch, t.ID(), t.Version(), exp.version)
+}
+
+// expectNext — задача t сохранена, дальше запрос ждёт её новую версию
+func expectNext(ctx context.Context, t *model.Task) {
+	if exp, ok := ctx.Value(expectKey{}).(*expectation); ok && exp.id == t.ID() {
+		exp.version = t.Version()
+	}
+}
+
+// refresh — хранилище отказало по версии: задачу изменили в обход кэша
+// (другой экземпляр сервера). Перечитываем её, чтобы следующая попытка
+// шла от свежей версии. Вызывать под замком задачи или s.ops.Lock
+func (s *Service) refresh(ctx context.Context, e *entry) {
+	r, err := s.store.Get(ctx, e.task.ID())
+	if err != nil {
+		return
+	}
+	t, err := model.FromDTO(r)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	s.retag(e.task, t)
+	e.task = t
+	s.mu.Unlock()
+}
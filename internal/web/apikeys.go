@@ -0,0 +1,134 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"todo/internal/auth"
+	"todo/internal/model"
+	"todo/internal/policy"
+	"todo/internal/reqctx"
+)
+
+// APIKeyRequest — тело запроса при выпуске ключа
+type APIKeyRequest struct {
+	Name      string   `json:"name"`
+	UserID    int64    `json:"user_id"`    // от чьего имени; 0 — от своего
+	Scopes    []string `json:"scopes"`     // действия policy: read, create, update, ...
+	ExpiresAt string   `json:"expires_at"` // RFC 3339, пусто — бессрочный
+}
+
+// APIKeyResponse — выпущенный ключ; сам key показывается только здесь
+type APIKeyResponse struct {
+	Key string `json:"key"`
+	auth.KeyInfo
+}
+
+// Ключи API: список и выпуск
+// handleAPIKeys godoc
+// @Summary      API keys
+// @Description  GET lists all keys (without secrets), POST issues a new one. The key acts as its user but only within its scopes; send it as Authorization: Bearer <key>. Admins only
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        data body APIKeyRequest false "New key (POST)"
+// @Success      200 {object} APIKeyResponse "POST: the key itself is shown only once"
+// @Success      200 {array}  auth.KeyInfo "GET"
+// @Failure      400 {string} string "bad name, scope or expiry"
+// @Failure      403 {string} string "admins only"
+// @Failure      404 {string} string "user not found"
+// @Security     BearerAuth
+// @Router       /keys [get]
+// @Router       /keys [post]
+func (s *Server) handleAPIKeys(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		keys, err := s.keys.List(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		out := make([]auth.KeyInfo, 0, len(keys))
+		for _, k := range keys {
+			out = append(out, auth.KeyInfoOf(k))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(out)
+	case http.MethodPost:
+		var dto APIKeyRequest
+		if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+			http.Error(w, "invalid json", http.StatusBadRequest)
+			return
+		}
+		var expires *time.Time
+		if dto.ExpiresAt != "" {
+			t, err := time.Parse(time.RFC3339, dto.ExpiresAt)
+			if err != nil {
+				http.Error(w, "bad expires_at: want RFC 3339", http.StatusBadRequest)
+				return
+			}
+			expires = &t
+		}
+		owner := model.UserID(dto.UserID)
+		if owner == 0 {
+			me, _ := reqctx.UserFrom(r.Context())
+			owner = me.ID
+		}
+		scopes := make([]policy.Action, 0, len(dto.Scopes))
+		for _, sc := range dto.Scopes {
+			scopes = append(scopes, policy.Action(strings.ToLower(strings.TrimSpace(sc))))
+		}
+		raw, key, err := s.keys.Create(r.Context(), dto.Name, owner, scopes, expires)
+		if err != nil {
+			keyError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(APIKeyResponse{Key: raw, KeyInfo: auth.KeyInfoOf(key)})
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Отзыв ключа API
+// handleRevokeAPIKey godoc
+// @Summary      Revoke API key
+// @Description  The key stops working at once; it stays in the list with revoked_at. Admins only
+// @Tags         auth
+// @Param        id path string true "Key ID"
+// @Success      200
+// @Failure      403 {string} string "admins only"
+// @Failure      404 {string} string "api key not found"
+// @Security     BearerAuth
+// @Router       /keys/{id} [delete]
+func (s *Server) handleRevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/api/keys/")
+	if id == "" || strings.Contains(id, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodDelete {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := s.keys.Revoke(r.Context(), id); err != nil {
+		keyError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// keyError — ошибки auth.APIKeys в коды HTTP
+func keyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, auth.ErrKeyNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, auth.ErrBadKeyName), errors.Is(err, auth.ErrBadScope), errors.Is(err, auth.ErrBadExpiry):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		userError(w, err)
+	}
+}
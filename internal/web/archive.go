@@ -0,0 +1,161 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"todo/internal/model"
+	"todo/internal/policy"
+	"todo/internal/reqctx"
+)
+
+// Архив: закрытые задачи, убранные из горячих списков
+
+// archiveAction — список архива читают, возврат из него — изменение
+func archiveAction(r *http.Request) policy.Action {
+	if r.Method == http.MethodGet {
+		return policy.Read
+	}
+	return policy.Update
+}
+
+// Перенос задачи в архив
+// handleArchiveItem godoc
+// @Summary      Archive task
+// @Description  Moves a done or canceled task with its subtasks (all must be closed) to the archive.
+// @Description  Archived tasks leave lists and the tree but are still found by /search and included in /export.
+// @Tags         archive
+// @Param        id path int true "Task ID"
+// @Param        If-Match header string false "ETag from GET: archive only that version"
+// @Success      200
+// @Failure      404 {string} string "not found"
+// @Failure      409 {string} string "task or its subtasks are still open"
+// @Failure      412 {string} string "task version mismatch"
+// @Failure      501 {string} string "archive not supported"
+// @Security     BearerAuth
+// @Router       /item/{id}/archive [post]
+func (s *Server) handleArchiveItem(w http.ResponseWriter, r *http.Request, id model.ID) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := s.tasks(r).Archive(r.Context(), id); err != nil {
+		httpError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Страница архива и возврат задачи из него
+// handleArchive godoc
+// @Summary      Archived tasks
+// @Description  GET /archive: a page of archived tasks with the same filters, sorting and cursor as /items.
+// @Description  POST /archive/{id}/restore: brings a task back; its subtasks stay archived, a parent that is not back makes it top-level.
+// @Tags         archive
+// @Produce      json
+// @Param        id     path  int    true  "Task ID (restore)"
+// @Param        sort   query string false "Sort keys, e.g. priority:desc,due_at"
+// @Param        limit  query int    false "Page size (default 50, max 500)"
+// @Param        cursor query string false "next_cursor from the previous page"
+// @Success      200 {object} TaskListResponse
+// @Failure      400 {string} string "bad query"
+// @Failure      404 {string} string "not in archive"
+// @Failure      501 {string} string "archive not supported"
+// @Security     BearerAuth
+// @Router       /archive [get]
+// @Security     BearerAuth
+// @Router       /archive/{id}/restore [post]
+func (s *Server) handleArchive(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/archive"), "/")
+	if path == "" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		me, _ := reqctx.UserFrom(r.Context())
+		q, err := parseTaskQuery(r.URL.Query(), me.ID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		page, err := s.tasks(r).Archived(r.Context(), q)
+		if err != nil {
+			httpError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(pageResponse(page))
+		return
+	}
+
+	idRaw, sub, _ := strings.Cut(path, "/")
+	idNum, err := strconv.ParseInt(idRaw, 10, 64)
+	if err != nil || sub != "restore" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id := model.ID(idNum)
+	if r, err = withIfMatch(r, id); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.tasks(r).Unarchive(r.Context(), id); err != nil {
+		httpError(w, err)
+		return
+	}
+	t, err := s.tasks(r).Get(r.Context(), id)
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	w.Header().Set("ETag", etag(t))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t.ToDTO())
+}
+
+// Выгрузка всех задач проекта
+// handleExport godoc
+// @Summary      Export tasks
+// @Description  Streams every visible task of the project, hot ones first and then the archive, one JSON object per line (NDJSON).
+// @Description  The trash is not exported. An error in the middle of the stream just cuts it short.
+// @Tags         archive
+// @Produce      application/x-ndjson
+// @Success      200 {object} model.TaskDTO "one per line"
+// @Failure      403 {string} string "forbidden"
+// @Security     BearerAuth
+// @Router       /export [get]
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	started := false
+	err := s.tasks(r).Export(r.Context(), func(t *model.Task) error {
+		if !started {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			started = true
+		}
+		if err := enc.Encode(t.ToDTO()); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+	switch {
+	case err != nil && !started:
+		httpError(w, err)
+	case !started:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,132 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"todo/internal/model"
+)
+
+// Вложения задачи
+// handleAttachments godoc
+// @Summary      Task attachments
+// @Description  GET lists attachments (name, size, content type, SHA-256), POST uploads a file as multipart/form-data (field "file", up to 25 MiB). GET /item/{id}/attachments/{aid} streams the file back, DELETE removes it: only the uploader or an admin may do that
+// @Tags         attachments
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id   path     int  true  "Task ID"
+// @Param        aid  path     int  false "Attachment ID (GET one, DELETE)"
+// @Param        file formData file false "File (POST)"
+// @Success      200 {array}  model.Attachment "GET list"
+// @Success      201 {object} model.Attachment "POST"
+// @Failure      400 {string} string "bad attachment"
+// @Failure      403 {string} string "not the uploader"
+// @Failure      404 {string} string "task or attachment not found"
+// @Failure      413 {string} string "file too large"
+// @Security     BearerAuth
+// @Router       /item/{id}/attachments [get]
+// @Router       /item/{id}/attachments [post]
+// @Router       /item/{id}/attachments/{aid} [get]
+// @Router       /item/{id}/attachments/{aid} [delete]
+func (s *Server) handleAttachments(w http.ResponseWriter, r *http.Request, id model.ID, rest string) {
+	var att model.AttachmentID
+	if rest != "" {
+		aid, err := strconv.ParseInt(rest, 10, 64)
+		if err != nil || aid <= 0 {
+			http.Error(w, "bad attachment id", http.StatusBadRequest)
+			return
+		}
+		att = model.AttachmentID(aid)
+	}
+	switch {
+	case r.Method == http.MethodGet && att == 0:
+		list, err := s.tasks(r).Attachments(r.Context(), id)
+		if err != nil {
+			httpError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+
+	case r.Method == http.MethodGet:
+		s.downloadAttachment(w, r, id, att)
+
+	case r.Method == http.MethodPost && att == 0:
+		s.uploadAttachment(w, r, id)
+
+	case r.Method == http.MethodDelete && att != 0:
+		if err := s.tasks(r).DeleteAttachment(r.Context(), id, att); err != nil {
+			httpError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// uploadAttachment — multipart читаем потоком, без ParseMultipartForm:
+// файл не ложится ни в память, ни во временный каталог сервера
+func (s *Server) uploadAttachment(w http.ResponseWriter, r *http.Request, id model.ID) {
+	r.Body = http.MaxBytesReader(w, r.Body, model.MaxAttachmentSize+1<<20) // запас на заголовки частей
+	mr, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, "multipart/form-data expected", http.StatusBadRequest)
+		return
+	}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			http.Error(w, `missing "file" part`, http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			uploadError(w, err)
+			return
+		}
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+		a, err := s.tasks(r).Attach(r.Context(), id, part.FileName(), part.Header.Get("Content-Type"), part)
+		part.Close()
+		if err != nil {
+			uploadError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(a)
+		return
+	}
+}
+
+func (s *Server) downloadAttachment(w http.ResponseWriter, r *http.Request, id model.ID, att model.AttachmentID) {
+	a, body, err := s.tasks(r).OpenAttachment(r.Context(), id, att)
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	defer body.Close()
+	w.Header().Set("Content-Type", a.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(a.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Name}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("ETag", `"`+a.Checksum+`"`)
+	io.Copy(w, body)
+}
+
+// uploadError — тело оборвалось на пределе MaxBytesReader — это тоже «слишком большой файл»
+func uploadError(w http.ResponseWriter, err error) {
+	var tooBig *http.MaxBytesError
+	if errors.As(err, &tooBig) {
+		http.Error(w, "attachment too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	httpError(w, err)
+}
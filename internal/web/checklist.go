@@ -0,0 +1,94 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"todo/internal/model"
+)
+
+// ChecklistItemRequest — тело запроса: text при добавлении, position при перестановке
+type ChecklistItemRequest struct {
+	Text     string `json:"text"`
+	Position int    `json:"position"` // с нуля
+}
+
+// ChecklistResponse — чек-лист задачи с отметками и долей сделанного
+type ChecklistResponse struct {
+	Items    []model.ChecklistItem `json:"items"`
+	Progress model.Progress        `json:"progress"`
+	Percent  int                   `json:"percent"`
+}
+
+// Чек-лист задачи
+// handleChecklist godoc
+// @Summary      Task checklist
+// @Description  GET returns the ordered checklist with done/total. POST adds an item to the end, POST /item/{id}/checklist/{cid}/toggle checks or unchecks it, PUT /item/{id}/checklist/{cid} moves it to "position" (from zero), DELETE removes it. Every call answers with the whole checklist
+// @Tags         checklist
+// @Accept       json
+// @Produce      json
+// @Param        id   path int                  true  "Task ID"
+// @Param        cid  path int                  false "Checklist item ID"
+// @Param        data body ChecklistItemRequest false "Item text (POST) or position (PUT)"
+// @Success      200 {object} ChecklistResponse
+// @Failure      400 {string} string "bad checklist item"
+// @Failure      404 {string} string "task or item not found"
+// @Security     BearerAuth
+// @Router       /item/{id}/checklist [get]
+// @Router       /item/{id}/checklist [post]
+// @Router       /item/{id}/checklist/{cid}/toggle [post]
+// @Router       /item/{id}/checklist/{cid} [put]
+// @Router       /item/{id}/checklist/{cid} [delete]
+func (s *Server) handleChecklist(w http.ResponseWriter, r *http.Request, id model.ID, rest string) {
+	raw, action, _ := strings.Cut(rest, "/")
+	var item int
+	if raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 {
+			http.Error(w, "bad checklist item id", http.StatusBadRequest)
+			return
+		}
+		item = v
+	}
+	svc := s.tasks(r)
+	var err error
+	switch {
+	case r.Method == http.MethodGet && item == 0:
+	case r.Method == http.MethodPost && item == 0, r.Method == http.MethodPut && item != 0 && action == "":
+		var dto ChecklistItemRequest
+		if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+			http.Error(w, "invalid json", http.StatusBadRequest)
+			return
+		}
+		if item == 0 {
+			_, err = svc.AddChecklistItem(r.Context(), id, dto.Text)
+		} else {
+			err = svc.MoveChecklistItem(r.Context(), id, item, dto.Position)
+		}
+	case r.Method == http.MethodPost && item != 0 && action == "toggle":
+		_, err = svc.ToggleChecklistItem(r.Context(), id, item)
+	case r.Method == http.MethodDelete && item != 0 && action == "":
+		err = svc.RemoveChecklistItem(r.Context(), id, item)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	t, err := svc.Get(r.Context(), id)
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	p := t.ChecklistProgress()
+	resp := ChecklistResponse{Items: t.Checklist(), Progress: p, Percent: p.Percent()}
+	if resp.Items == nil {
+		resp.Items = []model.ChecklistItem{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
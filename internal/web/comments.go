@@ -0,0 +1,80 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"todo/internal/model"
+)
+
+// CommentRequest — тело запроса при добавлении и правке комментария
+type CommentRequest struct {
+	Body string `json:"body"` // markdown, 1..10000 символов
+}
+
+// Комментарии задачи
+// handleComments godoc
+// @Summary      Task comments
+// @Description  GET lists the thread (oldest first), POST adds a comment from the caller. PUT and DELETE /item/{id}/comments/{cid} edit or remove a comment: only its author or an admin may do that. Body is markdown, stored as is
+// @Tags         comments
+// @Accept       json
+// @Produce      json
+// @Param        id   path int            true  "Task ID"
+// @Param        cid  path int            false "Comment ID (PUT, DELETE)"
+// @Param        data body CommentRequest false "Comment (POST, PUT)"
+// @Success      200 {array}  model.Comment "GET"
+// @Success      200 {object} model.Comment "POST, PUT"
+// @Failure      400 {string} string "bad comment"
+// @Failure      403 {string} string "not the author"
+// @Failure      404 {string} string "task or comment not found"
+// @Security     BearerAuth
+// @Router       /item/{id}/comments [get]
+// @Router       /item/{id}/comments [post]
+// @Router       /item/{id}/comments/{cid} [put]
+// @Router       /item/{id}/comments/{cid} [delete]
+func (s *Server) handleComments(w http.ResponseWriter, r *http.Request, id model.ID, rest string) {
+	var comment model.CommentID
+	if rest != "" {
+		cid, err := strconv.ParseInt(rest, 10, 64)
+		if err != nil || cid <= 0 {
+			http.Error(w, "bad comment id", http.StatusBadRequest)
+			return
+		}
+		comment = model.CommentID(cid)
+	}
+	var (
+		out any
+		err error
+	)
+	switch {
+	case r.Method == http.MethodGet && comment == 0:
+		out, err = s.tasks(r).Comments(r.Context(), id)
+	case r.Method == http.MethodPost && comment == 0, r.Method == http.MethodPut && comment != 0:
+		var dto CommentRequest
+		if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+			http.Error(w, "invalid json", http.StatusBadRequest)
+			return
+		}
+		if comment == 0 {
+			out, err = s.tasks(r).AddComment(r.Context(), id, dto.Body)
+		} else {
+			out, err = s.tasks(r).EditComment(r.Context(), id, comment, dto.Body)
+		}
+	case r.Method == http.MethodDelete && comment != 0:
+		err = s.tasks(r).DeleteComment(r.Context(), id, comment)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	if out == nil {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
@@ -10,16 +10,18 @@
 package web
 
 import (
-	"todo/internal/model"
+	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
-	"os"
 	"strconv"
 	"strings"
 	"time"
-
-	"github.com/golang-jwt/jwt/v5"
+	"todo/internal/auth"
+	"todo/internal/model"
+	"todo/internal/policy"
+	"todo/internal/reqctx"
+	"todo/internal/service"
 )
 
 // LoginRequest — тело запроса для авторизации
@@ -28,12 +30,27 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+// RefreshRequest — тело запроса при обновлении токенов
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenResponse — пара токенов; token — то же, что access_token, для старых клиентов
+type TokenResponse struct {
+	Token string `json:"token"`
+	auth.TokenPair
+}
+
 // TaskCreateRequest — тело запроса при создании задачи
 type TaskCreateRequest struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Priority    int    `json:"priority"`
-	DueAt       string `json:"due_at"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Priority    int      `json:"priority"`
+	DueAt       string   `json:"due_at"`
+	ParentID    int64    `json:"parent_id"`  // необязательно: создать как подзадачу
+	Recurrence  string   `json:"recurrence"` // необязательно: RRULE, например FREQ=WEEKLY;BYDAY=MO
+	Tags        []string `json:"tags"`       // необязательно
+	Assignee    int64    `json:"assignee"`   // необязательно: ID исполнителя
 }
 
 // TaskUpdateRequest — тело запроса при обновлении задачи
@@ -43,91 +60,189 @@ type TaskUpdateRequest struct {
 	Status      string `json:"status"`
 	Priority    int    `json:"priority"`
 	DueAt       string `json:"due_at"`
+	ParentID    *int64 `json:"parent_id"`  // 0 — вынести на верхний уровень
+	Recurrence  string `json:"recurrence"` // RRULE, "-" — убрать повторение
+	Assignee    *int64 `json:"assignee"`   // ID исполнителя, 0 — снять
 }
 
-// Авторизация пользователя (возвращает JWT‑токен)
+// Авторизация пользователя (возвращает JWT‑токен и refresh-токен)
 // handleLogin godoc
 // @Summary      User login
-// @Description  Authenticates user and returns JWT
+// @Description  Authenticates user and starts a session: a short-lived JWT (subject is the user ID) and a one-time refresh token
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Param        credentials body LoginRequest true "User credentials"
-// @Success      200 {object} map[string]string "token"
+// @Success      200 {object} TokenResponse
 // @Failure      400 {string} string "invalid json"
 // @Failure      401 {string} string "unauthorized"
 // @Router       /login [post]
 func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 	var creds LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
 		http.Error(w, "invalid json", http.StatusBadRequest)
 		return
 	}
 
-	login := os.Getenv("LOGIN")
-	pass := os.Getenv("PASSWORD")
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "default_secret"
+	pair, err := s.sessions.Login(r.Context(), creds.Login, creds.Password)
+	if errors.Is(err, auth.ErrBadCredentials) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
 	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeTokens(w, pair)
+}
 
-	if creds.Login != login || creds.Password != pass {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+// Обновление токенов: старый refresh-токен сгорает, повторное его использование закрывает сессию
+// handleRefresh godoc
+// @Summary      Refresh tokens
+// @Description  Exchanges a refresh token for a new pair. Each refresh token works once; reusing it ends the session
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        data body RefreshRequest true "Refresh token"
+// @Success      200 {object} TokenResponse
+// @Failure      400 {string} string "invalid json"
+// @Failure      401 {string} string "invalid token"
+// @Router       /refresh [post]
+func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var dto RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
 		return
 	}
+	pair, err := s.sessions.Refresh(r.Context(), dto.RefreshToken)
+	if errors.Is(err, auth.ErrBadToken) {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeTokens(w, pair)
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"login": creds.Login,
-		"exp":   time.Now().Add(2 * time.Hour).Unix(),
-	})
-	tokenStr, err := token.SignedString([]byte(secret))
+// Выход: сессия закрывается, текущий токен отзывается
+// handleLogout godoc
+// @Summary      Logout
+// @Description  Ends the session of the token: its refresh token stops working and the access token is revoked
+// @Tags         auth
+// @Success      200
+// @Failure      401 {string} string "invalid token"
+// @Security     BearerAuth
+// @Router       /logout [post]
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	raw, _ := bearer(r)
+	err := s.sessions.Logout(r.Context(), raw)
+	if errors.Is(err, auth.ErrBadToken) {
+		// ключ API сессии не имеет, его отзывают через DELETE /api/keys/{id}
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
 	if err != nil {
-		http.Error(w, "token error", http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	w.WriteHeader(http.StatusOK)
+}
 
+func writeTokens(w http.ResponseWriter, pair auth.TokenPair) {
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"token": tokenStr})
+	json.NewEncoder(w).Encode(TokenResponse{Token: pair.AccessToken, TokenPair: pair})
+}
+
+// bearer — токен из заголовка Authorization: Bearer <token>
+func bearer(r *http.Request) (string, bool) {
+	return strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
 }
 
-// Middleware‑проверка JWT перед изменением данных
+// Middleware‑проверка JWT: подпись, срок и отзыв; пользователь из токена едет дальше в reqctx.
+// Вместо JWT можно передать ключ API (todo_...) — тогда пользователь — владелец ключа.
 func (s *Server) withJWTAuth(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		auth := r.Header.Get("Authorization")
-		if !strings.HasPrefix(auth, "Bearer ") {
+		raw, ok := bearer(r)
+		if !ok {
 			http.Error(w, "missing token", http.StatusUnauthorized)
 			return
 		}
-		tokenStr := strings.TrimPrefix(auth, "Bearer ")
-		secret := os.Getenv("JWT_SECRET")
-		if secret == "" {
-			secret = "default_secret"
+		verify := s.sessions.Verify
+		if auth.IsAPIKey(raw) {
+			verify = s.keys.Verify
 		}
-
-		token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method")
-			}
-			return []byte(secret), nil
-		})
-		if err != nil || !token.Valid {
+		u, err := verify(r.Context(), raw)
+		if errors.Is(err, auth.ErrBadToken) {
 			http.Error(w, "invalid token", http.StatusUnauthorized)
 			return
 		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		next(w, r.WithContext(reqctx.WithUser(r.Context(), u)))
+	}
+}
+
+// withAccess — токен и права: действие для запроса определяет act, разрешает ли его роль — policy
+func (s *Server) withAccess(act func(r *http.Request) policy.Action, next http.HandlerFunc) http.HandlerFunc {
+	return s.withJWTAuth(func(w http.ResponseWriter, r *http.Request) {
+		u, _ := reqctx.UserFrom(r.Context())
+		if err := policy.Authorize(u, act(r)); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
 		next(w, r)
+	})
+}
+
+// only — одно действие на весь маршрут
+func only(a policy.Action) func(*http.Request) policy.Action {
+	return func(*http.Request) policy.Action { return a }
+}
+
+// itemAction — действие для /api/item/{id}[/...]: чтение, удаление самой задачи
+// и возврат её из корзины, всё остальное (PUT, PATCH, теги, блокеры, архив) — изменение
+func itemAction(r *http.Request) policy.Action {
+	path := strings.TrimPrefix(r.URL.Path, "/api/item/")
+	switch {
+	case r.Method == http.MethodGet:
+		return policy.Read
+	case r.Method == http.MethodDelete && !strings.Contains(path, "/"):
+		return policy.Delete
+	case strings.HasSuffix(path, "/restore"):
+		return policy.Delete
+	default:
+		return policy.Update
 	}
 }
 
 // Создание новой задачи
 // handleCreateItem godoc
 // @Summary      Create task
-// @Description  Creates new task with optional due date
+// @Description  Creates new task with optional due date; the caller becomes its creator
 // @Tags         tasks
 // @Accept       json
 // @Produce      json
 // @Param        data body TaskCreateRequest true "Task data"
 // @Success      200 {object} map[string]interface{} "Created task ID"
-// @Failure      400 {string} string "invalid json"
+// @Failure      400 {string} string "invalid json, bad due_at, tag, priority or recurrence"
+// @Failure      401 {string} string "unauthorized"
+// @Failure      404 {string} string "parent or assignee not found"
 // @Failure      500 {string} string "server error"
 // @Security     BearerAuth
 // @Router       /item [post]
@@ -150,75 +265,226 @@ func (s *Server) handleCreateItem(w http.ResponseWriter, r *http.Request) {
 
 	var due *time.Time
 	if dto.DueAt != "" {
-		if t, err := time.Parse("2006-01-02", dto.DueAt); err == nil {
-			due = &t
+		t, err := time.Parse("2006-01-02", dto.DueAt)
+		if err != nil {
+			http.Error(w, "bad due_at: want YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		due = &t
+	}
+	var rec *model.Recurrence
+	if dto.Recurrence != "" {
+		var err error
+		if rec, err = model.ParseRecurrence(dto.Recurrence); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
 	}
 
-	id, err := s.svc.Add(dto.Title, dto.Description, model.Priority(dto.Priority), due)
+	assignee := model.UserID(dto.Assignee)
+	if assignee != 0 && !s.userExists(w, r, assignee) {
+		return
+	}
+
+	// задача собирается целиком и вставляется одним махом: недопустимый тег
+	// или правило дают 400, а не задачу без них
+	id, err := s.tasks(r).Create(r.Context(), model.TaskDraft{
+		Title:       dto.Title,
+		Description: dto.Description,
+		Priority:    model.Priority(dto.Priority),
+		DueAt:       due,
+		ParentID:    model.ID(dto.ParentID),
+		Recurrence:  rec,
+		Tags:        dto.Tags,
+		Assignee:    assignee,
+	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		httpError(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]any{"id": id})
 }
 
-// Возвращает список всех задач
+// Возвращает страницу задач с фильтрами и сортировкой
 // handleListItems godoc
 // @Summary      List tasks
-// @Description  Returns all tasks
+// @Description  Returns a page of tasks visible to the caller: created by them, assigned to them or shared (no creator).
+// @Description  Admins see everything. Filters are combined with AND; dates are YYYY-MM-DD.
 // @Tags         tasks
 // @Produce      json
-// @Success      200 {array} model.TaskDTO
+// @Param        status        query string false "Statuses, comma separated (new,in_progress,...)"
+// @Param        priority_min  query int    false "Minimal priority (1..3)"
+// @Param        priority_max  query int    false "Maximal priority (1..3)"
+// @Param        due_after     query string false "Due at or after date"
+// @Param        due_before    query string false "Due strictly before date"
+// @Param        created_from  query string false "Created at or after date"
+// @Param        created_to    query string false "Created strictly before date"
+// @Param        q             query string false "Substring of title or description"
+// @Param        tag           query string false "Tags, comma separated or repeated: task must have all of them"
+// @Param        any_tag       query string false "Tags, comma separated or repeated: task must have at least one"
+// @Param        created_by    query string false "Creator: user ID or me"
+// @Param        assignee      query string false "Assignee: user ID or me"
+// @Param        sort          query string false "Sort keys, e.g. priority:desc,due_at"
+// @Param        limit         query int    false "Page size (default 50, max 500)"
+// @Param        cursor        query string false "next_cursor from the previous page"
+// @Success      200 {object} TaskListResponse
+// @Failure      400 {string} string "bad query"
+// @Failure      401 {string} string "unauthorized"
+// @Security     BearerAuth
 // @Router       /items [get]
 func (s *Server) handleListItems(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	list := s.svc.List(nil)
+	me, _ := reqctx.UserFrom(r.Context())
+	q, err := parseTaskQuery(r.URL.Query(), me.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	page, err := s.tasks(r).List(r.Context(), q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pageResponse(page))
+}
+
+// Полнотекстовый поиск по заголовкам и описаниям
+// handleSearch godoc
+// @Summary      Search tasks
+// @Description  Full-text search with ranking; snippet is HTML-escaped with matches wrapped in <b></b>
+// @Tags         tasks
+// @Produce      json
+// @Param        q      query string true  "Search words (all must match)"
+// @Param        limit  query int    false "Max results (default 20, max 100)"
+// @Success      200 {object} SearchResponse
+// @Failure      400 {string} string "bad query"
+// @Failure      401 {string} string "unauthorized"
+// @Failure      501 {string} string "search not supported"
+// @Security     BearerAuth
+// @Router       /search [get]
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var limit int
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			http.Error(w, "bad limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	hits, err := s.tasks(r).Search(r.Context(), r.URL.Query().Get("q"), limit)
+	switch {
+	case errors.Is(err, service.ErrEmptyQuery):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, service.ErrSearchUnsupported):
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if hits == nil {
+		hits = []model.SearchHit{}
+	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(list)
+	json.NewEncoder(w).Encode(SearchResponse{Items: hits})
 }
 
 // Работа с одной задачей по ID (просмотр, обновление, удаление)
 // handleItemByID godoc
 // @Summary      Task by ID
-// @Description  Get, update or delete single task
+// @Description  Get, update or delete single task. Tasks the caller can't see are reported as not found.
+// @Description  DELETE moves the task to the trash (see /trash and /item/{id}/restore).
+// @Description  GET returns the task version in ETag; PUT, PATCH and DELETE with If-Match fail with 412 if the task has changed since.
+// @Description  PUT and PATCH change all given fields at once or none of them. PATCH takes a JSON Merge Patch (RFC 7396): null clears description, due_at, parent_id, recurrence and assignee.
 // @Tags         tasks
 // @Accept       json
 // @Produce      json
 // @Param        id path int true "Task ID"
-// @Param        data body TaskUpdateRequest true "Fields to update"
+// @Param        data body TaskUpdateRequest true "Fields to update (PUT); for PATCH the same fields, null clears"
+// @Param        If-Match header string false "ETag from GET: change only that version"
+// @Param        cascade query bool false "DELETE: remove subtasks too (otherwise they move one level up)"
 // @Success      200 {object} model.TaskDTO
+// @Header       200 {string} ETag "task version"
 // @Failure      400 {string} string "bad id"
+// @Failure      401 {string} string "unauthorized"
 // @Failure      404 {string} string "not found"
+// @Failure      409 {string} string "status transition not allowed"
+// @Failure      412 {string} string "task version mismatch"
+// @Security     BearerAuth
 // @Router       /item/{id} [get]
 // @Security     BearerAuth
 // @Router       /item/{id} [put]
 // @Security     BearerAuth
+// @Router       /item/{id} [patch]
+// @Security     BearerAuth
 // @Router       /item/{id} [delete]
 func (s *Server) handleItemByID(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/api/item/")
-	idRaw := strings.TrimSpace(path)
+	idRaw, sub, _ := strings.Cut(strings.TrimSpace(path), "/")
 	idNum, err := strconv.ParseInt(idRaw, 10, 64)
 	if err != nil {
 		http.Error(w, "bad id", http.StatusBadRequest)
 		return
 	}
 	id := model.ID(idNum)
+	if r, err = withIfMatch(r, id); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if rest, ok := strings.CutPrefix(sub, "blockers"); ok {
+		s.handleBlockers(w, r, id, strings.TrimPrefix(rest, "/"))
+		return
+	}
+	if rest, ok := strings.CutPrefix(sub, "tags"); ok {
+		s.handleItemTags(w, r, id, strings.TrimPrefix(rest, "/"))
+		return
+	}
+	if rest, ok := strings.CutPrefix(sub, "comments"); ok {
+		s.handleComments(w, r, id, strings.TrimPrefix(rest, "/"))
+		return
+	}
+	if rest, ok := strings.CutPrefix(sub, "checklist"); ok {
+		s.handleChecklist(w, r, id, strings.TrimPrefix(rest, "/"))
+		return
+	}
+	if rest, ok := strings.CutPrefix(sub, "attachments"); ok {
+		s.handleAttachments(w, r, id, strings.TrimPrefix(rest, "/"))
+		return
+	}
+	if sub == "restore" {
+		s.handleRestore(w, r, id)
+		return
+	}
+	if sub == "archive" {
+		s.handleArchiveItem(w, r, id)
+		return
+	}
+	if sub != "" {
+		s.handleItemTree(w, r, id, sub)
+		return
+	}
 
 	switch r.Method {
 	case http.MethodGet:
-		for _, t := range s.svc.List(nil) {
-			if t.ID() == id {
-				w.Header().Set("Content-Type", "application/json")
-				json.NewEncoder(w).Encode(t.ToDTO())
-				return
-			}
+		t, err := s.tasks(r).Get(r.Context(), id)
+		if err != nil {
+			httpError(w, err)
+			return
 		}
-		http.NotFound(w, r)
+		w.Header().Set("ETag", etag(t))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(t.ToDTO())
 
 	case http.MethodPut:
 		var dto TaskUpdateRequest
@@ -226,33 +492,40 @@ func (s *Server) handleItemByID(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "invalid json", http.StatusBadRequest)
 			return
 		}
-		if dto.Title != "" {
-			if err := s.svc.UpdateTitle(id, dto.Title); err != nil {
-				http.Error(w, err.Error(), http.StatusNotFound)
-				return
-			}
+		p, err := dto.patch()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
-		if dto.Description != "" {
-			s.svc.UpdateDesc(id, dto.Description)
+		if !s.patchTask(w, r, id, p) {
+			return
 		}
-		if dto.Status != "" {
-			s.svc.SetStatus(id, model.Status(dto.Status))
+		w.WriteHeader(http.StatusOK)
+
+	case http.MethodPatch:
+		p, err := decodeMergePatch(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
-		if dto.Priority > 0 {
-			s.svc.SetPriority(id, model.Priority(dto.Priority))
+		if !s.patchTask(w, r, id, p) {
+			return
 		}
-		if dto.DueAt != "" {
-			if dto.DueAt == "-" {
-				s.svc.ClearDue(id)
-			} else if t, err := time.Parse("2006-01-02", dto.DueAt); err == nil {
-				s.svc.SetDue(id, t)
-			}
+		t, err := s.tasks(r).Get(r.Context(), id)
+		if err != nil {
+			httpError(w, err)
+			return
 		}
-		w.WriteHeader(http.StatusOK)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(t.ToDTO())
 
 	case http.MethodDelete:
-		if err := s.svc.Delete(id); err != nil {
-			http.Error(w, err.Error(), http.StatusNotFound)
+		del := s.tasks(r).Delete
+		if r.URL.Query().Get("cascade") == "true" {
+			del = s.tasks(r).DeleteTree
+		}
+		if err := del(r.Context(), id); err != nil {
+			httpError(w, err)
 			return
 		}
 		w.WriteHeader(http.StatusOK)
@@ -260,4 +533,290 @@ func (s *Server) handleItemByID(w http.ResponseWriter, r *http.Request) {
 	default:
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 	}
-}
\ No newline at end of file
+}
+
+// etag — версия задачи для заголовка ETag
+func etag(t *model.Task) string {
+	return `"` + strconv.FormatInt(t.Version(), 10) + `"`
+}
+
+// withIfMatch — If-Match из запроса к задаче id превращается в ожидаемую версию
+// для сервиса: изменение чужой версии закончится 412. Без заголовка и с "*" не проверяем
+func withIfMatch(r *http.Request, id model.ID) (*http.Request, error) {
+	raw := strings.TrimSpace(r.Header.Get("If-Match"))
+	if raw == "" || raw == "*" || r.Method == http.MethodGet {
+		return r, nil
+	}
+	v, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(raw, "W/"), `"`), 10, 64)
+	if err != nil || v < 0 {
+		return r, errors.New("bad If-Match: want a task version from ETag")
+	}
+	return r.WithContext(service.ExpectVersion(r.Context(), id, v)), nil
+}
+
+// Дерево задач: подзадачи, родители и прогресс поддерева
+// handleItemTree godoc
+// @Summary      Task tree
+// @Description  children — direct subtasks, ancestors — parents up to the root, progress — done/total in the whole subtree
+// @Tags         tasks
+// @Produce      json
+// @Param        id   path int    true "Task ID"
+// @Param        what path string true "children | ancestors | progress"
+// @Success      200 {array}  model.TaskDTO
+// @Success      200 {object} model.Progress
+// @Failure      404 {string} string "not found"
+// @Security     BearerAuth
+// @Router       /item/{id}/{what} [get]
+func (s *Server) handleItemTree(w http.ResponseWriter, r *http.Request, id model.ID, sub string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var (
+		list []*model.Task
+		resp any
+		err  error
+	)
+	switch sub {
+	case "children":
+		list, err = s.tasks(r).Children(r.Context(), id)
+	case "ancestors":
+		list, err = s.tasks(r).Ancestors(r.Context(), id)
+	case "progress":
+		resp, err = s.tasks(r).Progress(r.Context(), id)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	if resp == nil {
+		writeTasks(w, list)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// DependencyRequest — тело запроса при добавлении блокера
+type DependencyRequest struct {
+	ID int64 `json:"id"` // задача, которую надо сделать раньше
+}
+
+// Блокеры задачи: добавить и снять
+// handleBlockers godoc
+// @Summary      Task dependencies
+// @Description  POST adds a blocker (409 if it would create a cycle), DELETE /item/{id}/blockers/{blocker} removes it
+// @Tags         dependencies
+// @Accept       json
+// @Param        id   path int true "Task ID"
+// @Param        data body DependencyRequest true "Blocker"
+// @Success      200
+// @Failure      404 {string} string "not found"
+// @Failure      409 {string} string "dependency cycle"
+// @Security     BearerAuth
+// @Router       /item/{id}/blockers [post]
+// @Router       /item/{id}/blockers/{blocker} [delete]
+func (s *Server) handleBlockers(w http.ResponseWriter, r *http.Request, id model.ID, rest string) {
+	var err error
+	switch {
+	case r.Method == http.MethodPost && rest == "":
+		var dto DependencyRequest
+		if err := json.NewDecoder(r.Body).Decode(&dto); err != nil || dto.ID == 0 {
+			http.Error(w, "invalid json", http.StatusBadRequest)
+			return
+		}
+		err = s.tasks(r).AddDependency(r.Context(), id, model.ID(dto.ID))
+	case r.Method == http.MethodDelete && rest != "":
+		blocker, perr := strconv.ParseInt(rest, 10, 64)
+		if perr != nil {
+			http.Error(w, "bad id", http.StatusBadRequest)
+			return
+		}
+		err = s.tasks(r).RemoveDependency(r.Context(), id, model.ID(blocker))
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// TagRequest — тело запроса при добавлении тега
+type TagRequest struct {
+	Tag string `json:"tag"`
+}
+
+// Теги задачи: добавить и снять
+// handleItemTags godoc
+// @Summary      Task tags
+// @Description  POST adds a tag (normalized: lowercase, spaces become dashes), DELETE /item/{id}/tags/{tag} removes it
+// @Tags         tags
+// @Accept       json
+// @Param        id   path int true "Task ID"
+// @Param        data body TagRequest true "Tag"
+// @Success      200
+// @Failure      400 {string} string "bad tag"
+// @Failure      404 {string} string "not found"
+// @Security     BearerAuth
+// @Router       /item/{id}/tags [post]
+// @Router       /item/{id}/tags/{tag} [delete]
+func (s *Server) handleItemTags(w http.ResponseWriter, r *http.Request, id model.ID, rest string) {
+	var tag string
+	switch {
+	case r.Method == http.MethodPost && rest == "":
+		var dto TagRequest
+		if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+			http.Error(w, "invalid json", http.StatusBadRequest)
+			return
+		}
+		tag = dto.Tag
+	case r.Method == http.MethodDelete && rest != "":
+		tag = rest
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, err := model.NormalizeTag(tag); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var err error
+	if r.Method == http.MethodPost {
+		err = s.tasks(r).AddTag(r.Context(), id, tag)
+	} else {
+		err = s.tasks(r).RemoveTag(r.Context(), id, tag)
+	}
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Все теги с числом задач
+// handleTags godoc
+// @Summary      Tag counts
+// @Description  Every tag in use with the number of tasks, most used first
+// @Tags         tags
+// @Produce      json
+// @Success      200 {object} TagCountsResponse
+// @Security     BearerAuth
+// @Router       /tags [get]
+func (s *Server) handleTags(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	counts, err := s.tasks(r).TagCounts(r.Context())
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TagCountsResponse{Items: counts})
+}
+
+// Задачи, которые можно брать в работу прямо сейчас
+// handleReady godoc
+// @Summary      Ready tasks
+// @Description  New or paused tasks whose blockers are all done
+// @Tags         dependencies
+// @Produce      json
+// @Success      200 {array} model.TaskDTO
+// @Security     BearerAuth
+// @Router       /items/ready [get]
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	s.serveTaskList(w, r, s.tasks(r).Ready)
+}
+
+// Открытые задачи в порядке выполнения
+// handleTopoOrder godoc
+// @Summary      Execution order
+// @Description  Open tasks topologically sorted: blockers first, then by priority and age
+// @Tags         dependencies
+// @Produce      json
+// @Success      200 {array} model.TaskDTO
+// @Security     BearerAuth
+// @Router       /items/order [get]
+func (s *Server) handleTopoOrder(w http.ResponseWriter, r *http.Request) {
+	s.serveTaskList(w, r, s.tasks(r).TopoOrder)
+}
+
+// Перенумерация ID всех задач
+// handleRenumber godoc
+// @Summary      Renumber tasks
+// @Description  Renumbers all tasks 1..N in creation order; parents and blockers follow. Admins only
+// @Tags         admin
+// @Success      200
+// @Failure      403 {string} string "forbidden"
+// @Security     BearerAuth
+// @Router       /renumber [post]
+func (s *Server) handleRenumber(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := s.tasks(r).RenumberIDs(r.Context()); err != nil {
+		httpError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) serveTaskList(w http.ResponseWriter, r *http.Request, get func(ctx context.Context) ([]*model.Task, error)) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	list, err := get(r.Context())
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	writeTasks(w, list)
+}
+
+// writeTasks — список задач массивом DTO
+func writeTasks(w http.ResponseWriter, list []*model.Task) {
+	items := make([]model.TaskDTO, 0, len(list))
+	for _, t := range list {
+		items = append(items, t.ToDTO())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+// httpError — ошибка сервиса с подходящим кодом: нет задачи — 404, не хватает прав — 403,
+// конфликт с состоянием задач (см. service.IsConflict) — 409, остальное — 500
+func httpError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotFound), errors.Is(err, service.ErrProjectNotFound),
+		errors.Is(err, service.ErrCommentNotFound), errors.Is(err, service.ErrAttachmentNotFound),
+		errors.Is(err, service.ErrChecklistItemNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrBadProjectName), errors.Is(err, service.ErrBadComment), errors.Is(err, service.ErrBadPatch),
+		errors.Is(err, service.ErrBadTask), errors.Is(err, service.ErrBadAttachment), errors.Is(err, service.ErrBadChecklistItem):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrProjectExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, policy.ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case service.IsConflict(err):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, service.ErrVersionMismatch):
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+	case errors.Is(err, service.ErrAttachmentTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	case errors.Is(err, service.ErrCommentsUnsupported), errors.Is(err, service.ErrAttachmentsUnsupported),
+		errors.Is(err, service.ErrArchiveUnsupported):
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,167 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"todo/internal/model"
+)
+
+// patch — тело PUT в набор изменений: пустые поля не трогаем, "-" очищает срок и повторение
+func (dto TaskUpdateRequest) patch() (model.TaskPatch, error) {
+	var p model.TaskPatch
+	if dto.Title != "" {
+		p.Title = &dto.Title
+	}
+	if dto.Description != "" {
+		p.Description = &dto.Description
+	}
+	if dto.Status != "" {
+		st := model.Status(dto.Status)
+		if !st.Valid() {
+			return p, errors.New("bad status")
+		}
+		p.Status = &st
+	}
+	if dto.Priority != 0 {
+		pr := model.Priority(dto.Priority)
+		if !pr.Valid() {
+			return p, errors.New("bad priority")
+		}
+		p.Priority = &pr
+	}
+	if dto.ParentID != nil {
+		parent := model.ID(*dto.ParentID)
+		p.ParentID = &parent
+	}
+	switch dto.DueAt {
+	case "":
+	case "-":
+		p.ClearDue = true
+	default:
+		due, err := time.Parse("2006-01-02", dto.DueAt)
+		if err != nil {
+			return p, errors.New("bad due_at: want YYYY-MM-DD")
+		}
+		p.DueAt = &due
+	}
+	switch dto.Recurrence {
+	case "":
+	case "-":
+		p.ClearRecurrence = true
+	default:
+		rec, err := model.ParseRecurrence(dto.Recurrence)
+		if err != nil {
+			return p, err
+		}
+		p.Recurrence = rec
+	}
+	if dto.Assignee != nil {
+		u := model.UserID(*dto.Assignee)
+		p.Assignee = &u
+	}
+	return p, nil
+}
+
+// decodeMergePatch — тело PATCH (JSON Merge Patch, RFC 7396) в набор изменений.
+// Поля — как у TaskUpdateRequest; null (и пустая строка) убирает значение, где это имеет смысл.
+// Неизвестное поле — ошибка, а не молчаливый пропуск.
+func decodeMergePatch(body io.Reader) (model.TaskPatch, error) {
+	var p model.TaskPatch
+	var raw map[string]json.RawMessage
+	if err := json.NewDecoder(body).Decode(&raw); err != nil || raw == nil {
+		return p, errors.New("invalid json: want an object")
+	}
+	for key, val := range raw {
+		null := bytes.Equal(bytes.TrimSpace(val), []byte("null"))
+		if null && (key == "title" || key == "status" || key == "priority") {
+			return p, fmt.Errorf("%s cannot be null", key)
+		}
+		var err error
+		switch key {
+		case "title":
+			p.Title = new(string)
+			err = json.Unmarshal(val, p.Title)
+		case "description":
+			var desc string
+			if !null {
+				err = json.Unmarshal(val, &desc)
+			}
+			p.Description = &desc
+		case "status":
+			p.Status = new(model.Status)
+			err = json.Unmarshal(val, p.Status)
+		case "priority":
+			p.Priority = new(model.Priority)
+			err = json.Unmarshal(val, p.Priority)
+		case "due_at":
+			var s string
+			if !null {
+				err = json.Unmarshal(val, &s)
+			}
+			switch {
+			case err != nil:
+			case s == "":
+				p.ClearDue = true
+			default:
+				var due time.Time
+				if due, err = time.Parse("2006-01-02", s); err == nil {
+					p.DueAt = &due
+				}
+			}
+		case "parent_id":
+			var parent model.ID
+			if null || json.Unmarshal(val, &parent) == nil {
+				p.ParentID = &parent
+			} else {
+				err = errors.New("want a task id")
+			}
+		case "recurrence":
+			var s string
+			if !null {
+				err = json.Unmarshal(val, &s)
+			}
+			switch {
+			case err != nil:
+			case s == "":
+				p.ClearRecurrence = true
+			default:
+				p.Recurrence, err = model.ParseRecurrence(s)
+			}
+		case "assignee":
+			var u model.UserID
+			if null || json.Unmarshal(val, &u) == nil {
+				p.Assignee = &u
+			} else {
+				err = errors.New("want a user id")
+			}
+		default:
+			return p, fmt.Errorf("unknown field %q", key)
+		}
+		if err != nil {
+			return p, fmt.Errorf("bad %s: %v", key, err)
+		}
+	}
+	return p, nil
+}
+
+// patchTask — проверка исполнителя и сам патч; false — ответ об ошибке уже отправлен.
+// В ответ кладём ETag новой версии
+func (s *Server) patchTask(w http.ResponseWriter, r *http.Request, id model.ID, p model.TaskPatch) bool {
+	if p.Assignee != nil && *p.Assignee != 0 && !s.userExists(w, r, *p.Assignee) {
+		return false
+	}
+	if err := s.tasks(r).Patch(r.Context(), id, p); err != nil {
+		httpError(w, err)
+		return false
+	}
+	if t, err := s.tasks(r).Get(r.Context(), id); err == nil {
+		w.Header().Set("ETag", etag(t))
+	}
+	return true
+}
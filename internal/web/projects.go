@@ -0,0 +1,215 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"todo/internal/model"
+	"todo/internal/policy"
+	"todo/internal/service"
+)
+
+// ProjectRequest — тело запроса при создании проекта
+type ProjectRequest struct {
+	Name string `json:"name"`
+}
+
+type ctxKey int
+
+const (
+	projectKey ctxKey = iota // ID проекта из /api/projects/{p}/...
+	tasksKey                 // service.TaskUseCase проекта, кладёт withTasks
+)
+
+// withTasks — withAccess плюс сервис задач проекта: из /api/projects/{p}/...
+// или общего, если маршрут без проекта. Чужой проект — 404, как и чужая задача.
+func (s *Server) withTasks(act func(r *http.Request) policy.Action, next http.HandlerFunc) http.HandlerFunc {
+	return s.withAccess(act, func(w http.ResponseWriter, r *http.Request) {
+		p, ok := r.Context().Value(projectKey).(model.ProjectID)
+		if !ok {
+			p = model.DefaultProject
+		}
+		ps, ok := s.projects(w, r)
+		if !ok {
+			return
+		}
+		uc, err := ps.Tasks(r.Context(), p)
+		if err != nil {
+			httpError(w, err)
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), tasksKey, uc)))
+	})
+}
+
+// projects — проекты арендатора пользователя запроса; не вышло — уже ответили
+func (s *Server) projects(w http.ResponseWriter, r *http.Request) (*service.Projects, bool) {
+	ps, err := s.tenants.Projects(r.Context())
+	if err != nil {
+		httpError(w, err)
+		return nil, false
+	}
+	return ps, true
+}
+
+// tasks — сервис задач запроса; есть только за withTasks
+func (s *Server) tasks(r *http.Request) service.TaskUseCase {
+	return r.Context().Value(tasksKey).(service.TaskUseCase)
+}
+
+// projectsAction — смотреть список может любой, заводить проекты — по policy
+func projectsAction(r *http.Request) policy.Action {
+	if r.Method == http.MethodGet {
+		return policy.Read
+	}
+	return policy.ManageProjects
+}
+
+// Проекты: список доступных и создание
+// handleProjects godoc
+// @Summary      Projects
+// @Description  GET lists projects the caller can enter: the shared default project (ID 1) and the ones they are a member of; admins see all. POST creates a project, its creator becomes a member (admins only)
+// @Tags         projects
+// @Accept       json
+// @Produce      json
+// @Param        data body ProjectRequest false "New project (POST)"
+// @Success      200 {array}  model.Project
+// @Failure      400 {string} string "bad project name"
+// @Failure      403 {string} string "forbidden"
+// @Failure      409 {string} string "project name already taken"
+// @Security     BearerAuth
+// @Router       /projects [get]
+// @Router       /projects [post]
+func (s *Server) handleProjects(w http.ResponseWriter, r *http.Request) {
+	ps, ok := s.projects(w, r)
+	if !ok {
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ps.List(r.Context()))
+	case http.MethodPost:
+		var dto ProjectRequest
+		if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+			http.Error(w, "invalid json", http.StatusBadRequest)
+			return
+		}
+		p, err := ps.Create(r.Context(), dto.Name)
+		if err != nil {
+			httpError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(p)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// projectRoutes — /api/projects/{p}[/...]: сам проект, его участники
+// и все маршруты задач (/api/projects/{p}/items и т.д.) — в tasks
+func (s *Server) projectRoutes(tasks http.Handler) http.HandlerFunc {
+	info := s.withAccess(only(policy.Read), s.handleProject)
+	members := s.withAccess(only(policy.ManageProjects), s.handleProjectMember)
+	return func(w http.ResponseWriter, r *http.Request) {
+		raw, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/projects/"), "/")
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			http.NotFound(w, r)
+			return
+		}
+		r = r.Clone(context.WithValue(r.Context(), projectKey, model.ProjectID(id)))
+		switch {
+		case rest == "":
+			info(w, r)
+		case strings.HasPrefix(rest, "members/"):
+			members(w, r)
+		default:
+			// дальше те же обработчики, что и у маршрутов без проекта
+			r.URL.Path = "/api/" + rest
+			r.URL.RawPath = ""
+			tasks.ServeHTTP(w, r)
+		}
+	}
+}
+
+// Один проект
+// handleProject godoc
+// @Summary      Get project
+// @Description  Tasks of the project live under /api/projects/{p}/... with the same routes as the default project: items, item/{id}, search, tags, renumber
+// @Tags         projects
+// @Produce      json
+// @Param        p path int true "Project ID"
+// @Success      200 {object} model.Project
+// @Failure      404 {string} string "project not found"
+// @Security     BearerAuth
+// @Router       /projects/{p} [get]
+func (s *Server) handleProject(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ps, ok := s.projects(w, r)
+	if !ok {
+		return
+	}
+	p, err := ps.Get(r.Context(), r.Context().Value(projectKey).(model.ProjectID))
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// Участники проекта
+// handleProjectMember godoc
+// @Summary      Add or remove project member
+// @Description  PUT lets the user into the project, DELETE removes them; tasks they created stay in the project. Admins only
+// @Tags         projects
+// @Produce      json
+// @Param        p    path int true "Project ID"
+// @Param        user path int true "User ID"
+// @Success      200 {object} model.Project
+// @Failure      403 {string} string "forbidden"
+// @Failure      404 {string} string "project or user not found"
+// @Security     BearerAuth
+// @Router       /projects/{p}/members/{user} [put]
+// @Router       /projects/{p}/members/{user} [delete]
+func (s *Server) handleProjectMember(w http.ResponseWriter, r *http.Request) {
+	_, raw, _ := strings.Cut(r.URL.Path, "/members/")
+	uid, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || uid <= 0 {
+		http.NotFound(w, r)
+		return
+	}
+	user := model.UserID(uid)
+	project := r.Context().Value(projectKey).(model.ProjectID)
+	ps, ok := s.projects(w, r)
+	if !ok {
+		return
+	}
+	var p model.Project
+	switch r.Method {
+	case http.MethodPut:
+		if !s.userExists(w, r, user) {
+			return
+		}
+		p, err = ps.AddMember(r.Context(), project, user)
+	case http.MethodDelete:
+		p, err = ps.RemoveMember(r.Context(), project, user)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
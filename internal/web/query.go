@@ -0,0 +1,164 @@
+package web
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo/internal/model"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// TaskListResponse — страница задач и курсор на следующую
+type TaskListResponse struct {
+	Items      []model.TaskDTO `json:"items"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+func pageResponse(page model.TaskPage) TaskListResponse {
+	resp := TaskListResponse{
+		Items:      make([]model.TaskDTO, 0, len(page.Items)),
+		NextCursor: page.NextCursor,
+	}
+	for _, t := range page.Items {
+		resp.Items = append(resp.Items, t.ToDTO())
+	}
+	return resp
+}
+
+// TagCountsResponse — все теги с числом задач, частые первыми
+type TagCountsResponse struct {
+	Items []model.TagCount `json:"items"`
+}
+
+// SearchResponse — результаты полнотекстового поиска, лучшие первыми
+type SearchResponse struct {
+	Items []model.SearchHit `json:"items"`
+}
+
+// parseTaskQuery собирает TaskQuery из параметров /api/items; me — кто спрашивает (для «me»)
+func parseTaskQuery(v url.Values, me model.UserID) (model.TaskQuery, error) {
+	q := model.TaskQuery{Limit: defaultPageSize}
+
+	for _, raw := range v["status"] {
+		for _, part := range strings.Split(raw, ",") {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			st := model.Status(part)
+			if !st.Valid() {
+				return q, fmt.Errorf("bad status %q", part)
+			}
+			q.Statuses = append(q.Statuses, st)
+		}
+	}
+
+	var err error
+	if q.MinPriority, err = parsePriority(v.Get("priority_min")); err != nil {
+		return q, err
+	}
+	if q.MaxPriority, err = parsePriority(v.Get("priority_max")); err != nil {
+		return q, err
+	}
+	if q.DueAfter, err = parseDateParam(v, "due_after"); err != nil {
+		return q, err
+	}
+	if q.DueBefore, err = parseDateParam(v, "due_before"); err != nil {
+		return q, err
+	}
+	if q.CreatedFrom, err = parseDateParam(v, "created_from"); err != nil {
+		return q, err
+	}
+	if q.CreatedTo, err = parseDateParam(v, "created_to"); err != nil {
+		return q, err
+	}
+	q.Text = strings.TrimSpace(v.Get("q"))
+	if q.Tags, err = parseTags(v["tag"]); err != nil {
+		return q, err
+	}
+	if q.AnyTags, err = parseTags(v["any_tag"]); err != nil {
+		return q, err
+	}
+	if q.CreatedBy, err = parseUserParam(v, "created_by", me); err != nil {
+		return q, err
+	}
+	if q.Assignee, err = parseUserParam(v, "assignee", me); err != nil {
+		return q, err
+	}
+
+	if q.Sort, err = model.ParseSort(v.Get("sort")); err != nil {
+		return q, err
+	}
+	if raw := v.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			return q, fmt.Errorf("bad limit %q", raw)
+		}
+		q.Limit = min(n, maxPageSize)
+	}
+	if raw := v.Get("cursor"); raw != "" {
+		if q.After, err = model.DecodeCursor(raw); err != nil {
+			return q, err
+		}
+	}
+	return q, nil
+}
+
+// parseTags — теги из повторяющегося параметра и/или через запятую, нормализованные
+func parseTags(raw []string) ([]string, error) {
+	var parts []string
+	for _, r := range raw {
+		for _, part := range strings.Split(r, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				parts = append(parts, part)
+			}
+		}
+	}
+	return model.NormalizeTags(parts)
+}
+
+// parseUserParam — ID пользователя из параметра; «me» — вызывающий, 0 если параметра нет
+func parseUserParam(v url.Values, name string, me model.UserID) (model.UserID, error) {
+	raw := v.Get(name)
+	switch raw {
+	case "":
+		return 0, nil
+	case "me":
+		return me, nil
+	}
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("bad %s %q: want user id or me", name, raw)
+	}
+	return model.UserID(n), nil
+}
+
+func parsePriority(raw string) (model.Priority, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || !model.Priority(n).Valid() {
+		return 0, fmt.Errorf("bad priority %q", raw)
+	}
+	return model.Priority(n), nil
+}
+
+// parseDateParam — дата YYYY-MM-DD из параметра, nil если его нет
+func parseDateParam(v url.Values, name string) (*time.Time, error) {
+	raw := v.Get(name)
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return nil, fmt.Errorf("bad %s: want YYYY-MM-DD", name)
+	}
+	return &t, nil
+}
@@ -1,32 +1,82 @@
 package web
 
 import (
-	"todo/internal/service"
 	"fmt"
 	"net/http"
+	"todo/internal/auth"
+	"todo/internal/policy"
+	"todo/internal/reqctx"
+	"todo/internal/service"
 
 	"github.com/swaggo/http-swagger"
 )
 
 type Server struct {
-	svc service.TaskUseCase
+	tenants  *service.Tenants
+	users    *auth.Users
+	sessions *auth.Sessions
+	keys     *auth.APIKeys
 }
 
-func New(uc service.TaskUseCase) *Server {
-	return &Server{svc: uc}
+func New(tenants *service.Tenants, users *auth.Users, sessions *auth.Sessions, keys *auth.APIKeys) *Server {
+	return &Server{tenants: tenants, users: users, sessions: sessions, keys: keys}
 }
 
 func (s *Server) Start(port int) error {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/api/login", s.handleLogin)
-	mux.HandleFunc("/api/item", s.handleCreateItem)        // POST
-	mux.HandleFunc("/api/items", s.handleListItems)        // GET all
-	mux.HandleFunc("/api/item/", s.handleItemByID)         // GET, PUT, DELETE (/api/item/{id})
-	
+	mux.HandleFunc("/api/refresh", s.handleRefresh)                                           // POST: новая пара токенов
+	mux.HandleFunc("/api/logout", s.withJWTAuth(s.handleLogout))                              // POST: закрыть сессию
+	mux.HandleFunc("/api/users", s.withAccess(only(policy.ManageUsers), s.handleUsers))       // POST
+	mux.HandleFunc("/api/users/", s.withAccess(only(policy.ManageUsers), s.handleUserRole))   // PUT /api/users/{id}/role
+	mux.HandleFunc("/api/users/me", s.withJWTAuth(s.handleMe))                                // GET: любая роль
+	mux.HandleFunc("/api/users/me/password", s.withJWTAuth(s.handlePassword))                 // PUT: любая роль
+	mux.HandleFunc("/api/keys", s.withAccess(only(policy.ManageKeys), s.handleAPIKeys))       // GET, POST
+	mux.HandleFunc("/api/keys/", s.withAccess(only(policy.ManageKeys), s.handleRevokeAPIKey)) // DELETE /api/keys/{id}
+	mux.HandleFunc("/api/projects", s.withAccess(projectsAction, s.handleProjects))           // GET, POST
+	// задачи без проекта в пути — общий проект, с /api/projects/{p}/... — проект p
+	s.taskRoutes(mux)
+	projectTasks := http.NewServeMux()
+	s.taskRoutes(projectTasks)
+	mux.HandleFunc("/api/projects/", s.projectRoutes(projectTasks)) // GET /api/projects/{p}, участники, задачи проекта
+
 	mux.Handle("/swagger/", httpSwagger.WrapHandler)
 
 	addr := fmt.Sprintf(":%d", port)
 	fmt.Println("[Web] Веб сервер стартовал на ", addr)
-	return http.ListenAndServe(addr, mux)
-}
\ No newline at end of file
+	return http.ListenAndServe(addr, withTrace(mux))
+}
+
+// taskRoutes — маршруты задач. Только с токеном: что можно роли, решает policy,
+// в какой проект пускать и что кому видно — сервис.
+func (s *Server) taskRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/api/item", s.withTasks(only(policy.Create), s.handleCreateItem))     // POST
+	mux.HandleFunc("/api/items", s.withTasks(only(policy.Read), s.handleListItems))       // GET: видимые вызывающему
+	mux.HandleFunc("/api/items/ready", s.withTasks(only(policy.Read), s.handleReady))     // GET: можно брать в работу
+	mux.HandleFunc("/api/items/order", s.withTasks(only(policy.Read), s.handleTopoOrder)) // GET: порядок выполнения
+	mux.HandleFunc("/api/item/", s.withTasks(itemAction, s.handleItemByID))               // GET, PUT, PATCH, DELETE (/api/item/{id})
+	mux.HandleFunc("/api/search", s.withTasks(only(policy.Read), s.handleSearch))         // GET ?q=
+	mux.HandleFunc("/api/tags", s.withTasks(only(policy.Read), s.handleTags))             // GET: теги и число задач
+	mux.HandleFunc("/api/trash", s.withTasks(only(policy.Read), s.handleTrash))           // GET: удалённые задачи
+	mux.HandleFunc("/api/archive", s.withTasks(only(policy.Read), s.handleArchive))       // GET: страница архива
+	mux.HandleFunc("/api/archive/", s.withTasks(archiveAction, s.handleArchive))          // POST /api/archive/{id}/restore
+	mux.HandleFunc("/api/export", s.withTasks(only(policy.Export), s.handleExport))       // GET: NDJSON, горячие и архив
+	mux.HandleFunc("/api/undo", s.withTasks(only(policy.Update), s.handleUndo))           // POST ?steps=N: свои операции
+	mux.HandleFunc("/api/redo", s.withTasks(only(policy.Update), s.handleRedo))           // POST ?steps=N
+	mux.HandleFunc("/api/renumber", s.withTasks(only(policy.Renumber), s.handleRenumber)) // POST: только админ
+}
+
+// withTrace — каждому запросу свой trace id: берём из X-Request-ID или генерируем;
+// для отмены операций — ещё и свой серверный идентификатор (reqctx.WithRequest).
+// Отменяется запрос вместе с r.Context(), когда клиент отваливается.
+func withTrace(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if id == "" {
+			id = reqctx.NewTraceID()
+		}
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r.WithContext(reqctx.WithRequest(reqctx.WithTraceID(r.Context(), id))))
+	})
+}
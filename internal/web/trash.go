@@ -0,0 +1,55 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"todo/internal/model"
+)
+
+// Корзина: удалённые задачи, которые ещё можно вернуть
+// handleTrash godoc
+// @Summary      Trash
+// @Description  Deleted tasks visible to the caller, most recently deleted first. They are purged for good after TRASH_RETENTION.
+// @Tags         trash
+// @Produce      json
+// @Success      200 {array} model.TaskDTO
+// @Security     BearerAuth
+// @Router       /trash [get]
+func (s *Server) handleTrash(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.serveTaskList(w, r, s.tasks(r).Trash)
+}
+
+// Восстановление задачи из корзины
+// handleRestore godoc
+// @Summary      Restore task
+// @Description  Brings a task back from the trash together with the subtasks deleted with it. A parent that is gone makes it top-level; missing blockers are dropped.
+// @Tags         trash
+// @Produce      json
+// @Param        id path int true "Task ID"
+// @Success      200 {object} model.TaskDTO
+// @Failure      404 {string} string "not in trash"
+// @Security     BearerAuth
+// @Router       /item/{id}/restore [post]
+func (s *Server) handleRestore(w http.ResponseWriter, r *http.Request, id model.ID) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := s.tasks(r).Restore(r.Context(), id); err != nil {
+		httpError(w, err)
+		return
+	}
+	t, err := s.tasks(r).Get(r.Context(), id)
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	w.Header().Set("ETag", etag(t))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t.ToDTO())
+}
@@ -0,0 +1,78 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"todo/internal/service"
+)
+
+// UndoResponse — отменённые или повторённые операции, последние первыми.
+// Error — почему остановились раньше, чем просили
+type UndoResponse struct {
+	Items []service.UndoStep `json:"items"`
+	Error string             `json:"error,omitempty"`
+}
+
+// Отмена своих последних операций
+// handleUndo godoc
+// @Summary      Undo
+// @Description  Reverts the caller's last operations in this project, newest first: edits, deletes (back from the trash), creation (to the trash), archiving and renumbering.
+// @Description  Refused with 409 when a task was changed after the operation or reverting would break the tree or dependencies. History is kept in memory for the last 50 operations.
+// @Tags         undo
+// @Produce      json
+// @Param        steps query int false "How many operations to revert (default 1)"
+// @Success      200 {object} UndoResponse
+// @Failure      400 {string} string "bad steps"
+// @Failure      409 {string} string "nothing to undo or later changes conflict"
+// @Security     BearerAuth
+// @Router       /undo [post]
+func (s *Server) handleUndo(w http.ResponseWriter, r *http.Request) {
+	s.serveReplay(w, r, s.tasks(r).Undo)
+}
+
+// Повтор отменённого
+// handleRedo godoc
+// @Summary      Redo
+// @Description  Applies again the operations reverted by /undo. Any new change by the caller drops them.
+// @Tags         undo
+// @Produce      json
+// @Param        steps query int false "How many operations to redo (default 1)"
+// @Success      200 {object} UndoResponse
+// @Failure      400 {string} string "bad steps"
+// @Failure      409 {string} string "nothing to redo or later changes conflict"
+// @Security     BearerAuth
+// @Router       /redo [post]
+func (s *Server) handleRedo(w http.ResponseWriter, r *http.Request) {
+	s.serveReplay(w, r, s.tasks(r).Redo)
+}
+
+func (s *Server) serveReplay(w http.ResponseWriter, r *http.Request, replay func(ctx context.Context, n int) ([]service.UndoStep, error)) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	n := 1
+	if raw := r.URL.Query().Get("steps"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 1 {
+			http.Error(w, "bad steps", http.StatusBadRequest)
+			return
+		}
+		n = v
+	}
+	steps, err := replay(r.Context(), n)
+	if err != nil && len(steps) == 0 {
+		httpError(w, err)
+		return
+	}
+	// часть успела до конфликта — отдаём её вместе с причиной остановки
+	resp := UndoResponse{Items: steps}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
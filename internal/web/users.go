@@ -0,0 +1,203 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"todo/internal/auth"
+	"todo/internal/model"
+	"todo/internal/policy"
+	"todo/internal/reqctx"
+)
+
+// RegisterRequest — тело запроса при создании пользователя
+type RegisterRequest struct {
+	Login    string `json:"login"`
+	Password string `json:"password"` // 8..72 байта
+	Role     string `json:"role"`     // viewer, member (по умолчанию) или admin
+	Tenant   string `json:"tenant"`   // пусто — свой арендатор; чужой — только из общего
+}
+
+// RoleRequest — тело запроса при смене роли
+type RoleRequest struct {
+	Role string `json:"role"`
+}
+
+// PasswordChangeRequest — тело запроса при смене пароля
+type PasswordChangeRequest struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+}
+
+// Регистрация пользователя администратором
+// handleUsers godoc
+// @Summary      Register user
+// @Description  Creates a user account with a role (viewer, member or admin) in the caller's tenant. Admins of the default tenant may set another tenant, e.g. to create its first admin. Admins only; the first admin is created at startup from ADMIN_LOGIN/ADMIN_PASSWORD
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        data body RegisterRequest true "New user"
+// @Success      200 {object} auth.Profile
+// @Failure      400 {string} string "bad login, password or tenant"
+// @Failure      403 {string} string "admins only, or a foreign tenant"
+// @Failure      409 {string} string "login already taken"
+// @Security     BearerAuth
+// @Router       /users [post]
+func (s *Server) handleUsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var dto RegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	role, err := model.ParseRole(dto.Role)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var u model.User
+	if dto.Tenant == "" {
+		u, err = s.users.Register(r.Context(), dto.Login, dto.Password, role)
+	} else {
+		u, err = s.users.RegisterIn(r.Context(), model.TenantID(dto.Tenant), dto.Login, dto.Password, role)
+	}
+	if err != nil {
+		userError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(auth.ProfileOf(u))
+}
+
+// Смена роли пользователя администратором
+// handleUserRole godoc
+// @Summary      Change user role
+// @Description  Admins only. Tokens issued before keep the old role until they expire
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        id   path int         true "User ID"
+// @Param        data body RoleRequest true "New role"
+// @Success      200 {object} auth.Profile
+// @Failure      400 {string} string "bad role"
+// @Failure      403 {string} string "forbidden"
+// @Failure      404 {string} string "user not found"
+// @Security     BearerAuth
+// @Router       /users/{id}/role [put]
+func (s *Server) handleUserRole(w http.ResponseWriter, r *http.Request) {
+	idRaw, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/api/users/"), "/role")
+	id, err := strconv.ParseInt(idRaw, 10, 64)
+	if !ok || err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPut {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var dto RoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	role, err := model.ParseRole(dto.Role)
+	if err != nil || dto.Role == "" {
+		http.Error(w, "bad role", http.StatusBadRequest)
+		return
+	}
+	u, err := s.users.SetRole(r.Context(), model.UserID(id), role)
+	if err != nil {
+		userError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(auth.ProfileOf(u))
+}
+
+// Текущий пользователь
+// handleMe godoc
+// @Summary      Current user
+// @Tags         auth
+// @Produce      json
+// @Success      200 {object} auth.Profile
+// @Failure      401 {string} string "invalid token"
+// @Security     BearerAuth
+// @Router       /users/me [get]
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	me, _ := reqctx.UserFrom(r.Context())
+	u, err := s.users.Get(r.Context(), me.ID)
+	if err != nil {
+		userError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(auth.ProfileOf(u))
+}
+
+// Смена своего пароля
+// handlePassword godoc
+// @Summary      Change password
+// @Description  Requires the current password; tokens issued before stay valid until they expire
+// @Tags         auth
+// @Accept       json
+// @Param        data body PasswordChangeRequest true "Old and new password"
+// @Success      200
+// @Failure      400 {string} string "weak password"
+// @Failure      401 {string} string "wrong password"
+// @Security     BearerAuth
+// @Router       /users/me/password [put]
+func (s *Server) handlePassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var dto PasswordChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	me, _ := reqctx.UserFrom(r.Context())
+	if err := s.users.ChangePassword(r.Context(), me.ID, dto.OldPassword, dto.NewPassword); err != nil {
+		userError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// userExists — есть ли такой пользователь (для назначения исполнителя); нет — уже ответили 404
+func (s *Server) userExists(w http.ResponseWriter, r *http.Request, id model.UserID) bool {
+	if _, err := s.users.Get(r.Context(), id); err != nil {
+		userError(w, err)
+		return false
+	}
+	return true
+}
+
+// userError — ошибки auth.Users в коды HTTP
+func userError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, auth.ErrBadCredentials):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, auth.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, auth.ErrLoginTaken):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, policy.ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, auth.ErrWeakPassword), errors.Is(err, auth.ErrBadLogin), errors.Is(err, auth.ErrBadRole),
+		errors.Is(err, auth.ErrBadTenant):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
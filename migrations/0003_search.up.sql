@@ -0,0 +1,7 @@
+ALTER TABLE tasks ADD COLUMN search tsvector
+  GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(description, '')), 'B')
+  ) STORED;
+
+CREATE INDEX idx_tasks_search ON tasks USING GIN (search);
@@ -0,0 +1,4 @@
+-- без внешнего ключа: целостность дерева держит сервис (в том числе при перенумерации)
+ALTER TABLE tasks ADD COLUMN parent_id INT;
+
+CREATE INDEX idx_tasks_parent ON tasks(parent_id);
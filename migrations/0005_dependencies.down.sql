@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_tasks_blocked_by;
+ALTER TABLE tasks DROP COLUMN IF EXISTS blocked_by;
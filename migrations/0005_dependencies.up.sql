@@ -0,0 +1,4 @@
+-- ID задач, которые должны быть сделаны раньше; граф и циклы проверяет сервис
+ALTER TABLE tasks ADD COLUMN blocked_by BIGINT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_tasks_blocked_by ON tasks USING GIN (blocked_by);
@@ -0,0 +1,2 @@
+-- правило повторения в виде RRULE (FREQ=WEEKLY;BYDAY=MO), NULL — разовая задача
+ALTER TABLE tasks ADD COLUMN recurrence TEXT;
@@ -0,0 +1,9 @@
+-- теги задач, уже нормализованные (см. model.NormalizeTag); при удалении задачи уходят вместе с ней
+CREATE TABLE IF NOT EXISTS task_tags (
+    task_id INT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (task_id, tag)
+);
+
+-- фильтр и подсчёт по тегу идут от тега к задачам
+CREATE INDEX idx_task_tags_tag ON task_tags(tag, task_id);
@@ -0,0 +1 @@
+ALTER TABLE tasks DROP COLUMN IF EXISTS assignee, DROP COLUMN IF EXISTS created_by;
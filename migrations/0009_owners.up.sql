@@ -0,0 +1,8 @@
+-- автор и исполнитель задачи; NULL — общая задача (создана до пользователей или консолью).
+-- Удалённый пользователь не уносит задачи с собой, ссылка просто обнуляется
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS assignee INT REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_created_by ON tasks(created_by);
+CREATE INDEX IF NOT EXISTS idx_tasks_assignee ON tasks(assignee);
@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS admin BOOLEAN NOT NULL DEFAULT false;
+UPDATE users SET admin = (role = 'admin');
+ALTER TABLE users DROP COLUMN IF EXISTS role;
@@ -0,0 +1,5 @@
+-- роли вместо флага admin: viewer — только чтение, member — работа с задачами, admin — всё
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'member'
+    CHECK (role IN ('viewer', 'member', 'admin'));
+UPDATE users SET role = 'admin' WHERE admin;
+ALTER TABLE users DROP COLUMN IF EXISTS admin;
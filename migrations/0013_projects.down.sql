@@ -0,0 +1,22 @@
+-- обратно в один общий список: задачи не из общего проекта удаляются,
+-- иначе их ID пересекутся с задачами общего
+DELETE FROM tasks WHERE project_id <> 1;
+
+ALTER TABLE task_tags DROP CONSTRAINT IF EXISTS task_tags_project_id_task_id_fkey;
+ALTER TABLE task_tags DROP CONSTRAINT IF EXISTS task_tags_pkey;
+DROP INDEX IF EXISTS idx_task_tags_tag;
+ALTER TABLE task_tags DROP COLUMN IF EXISTS project_id;
+
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_pkey;
+ALTER TABLE tasks DROP COLUMN IF EXISTS project_id;
+ALTER TABLE tasks ADD PRIMARY KEY (id);
+CREATE SEQUENCE IF NOT EXISTS tasks_id_seq OWNED BY tasks.id;
+SELECT setval('tasks_id_seq', COALESCE((SELECT MAX(id) FROM tasks), 0) + 1, false);
+ALTER TABLE tasks ALTER COLUMN id SET DEFAULT nextval('tasks_id_seq');
+
+ALTER TABLE task_tags ADD PRIMARY KEY (task_id, tag);
+ALTER TABLE task_tags ADD FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS idx_task_tags_tag ON task_tags(tag, task_id);
+
+DROP TABLE IF EXISTS project_members;
+DROP TABLE IF EXISTS projects;
@@ -0,0 +1,35 @@
+-- проекты: у каждого свои задачи и своя нумерация ID;
+-- проект 1 — общий, в него попадают все задачи, созданные до проектов
+CREATE TABLE IF NOT EXISTS projects (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+INSERT INTO projects (id, name) VALUES (1, 'default') ON CONFLICT (id) DO NOTHING;
+SELECT setval(pg_get_serial_sequence('projects', 'id'), (SELECT MAX(id) FROM projects));
+
+CREATE TABLE IF NOT EXISTS project_members (
+    project_id INT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (project_id, user_id)
+);
+
+-- ключ задачи становится составным: (project_id, id)
+ALTER TABLE task_tags DROP CONSTRAINT IF EXISTS task_tags_task_id_fkey;
+ALTER TABLE task_tags DROP CONSTRAINT IF EXISTS task_tags_pkey;
+DROP INDEX IF EXISTS idx_task_tags_tag;
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS project_id INT NOT NULL DEFAULT 1 REFERENCES projects(id);
+ALTER TABLE tasks ALTER COLUMN project_id DROP DEFAULT;
+-- ID выдаёт сервис в пределах проекта, общий счётчик больше не нужен
+ALTER TABLE tasks ALTER COLUMN id DROP DEFAULT;
+DROP SEQUENCE IF EXISTS tasks_id_seq;
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_pkey;
+ALTER TABLE tasks ADD PRIMARY KEY (project_id, id);
+
+ALTER TABLE task_tags ADD COLUMN IF NOT EXISTS project_id INT NOT NULL DEFAULT 1;
+ALTER TABLE task_tags ALTER COLUMN project_id DROP DEFAULT;
+ALTER TABLE task_tags ADD PRIMARY KEY (project_id, task_id, tag);
+ALTER TABLE task_tags ADD FOREIGN KEY (project_id, task_id) REFERENCES tasks(project_id, id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS idx_task_tags_tag ON task_tags(project_id, tag, task_id);
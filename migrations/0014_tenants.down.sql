@@ -0,0 +1,51 @@
+-- обратно к одному арендатору: данные остальных удаляются,
+-- иначе их проекты и задачи пересекутся по ключам с общими
+DROP POLICY IF EXISTS tenant_isolation ON task_tags;
+DROP POLICY IF EXISTS tenant_isolation ON tasks;
+DROP POLICY IF EXISTS tenant_isolation ON project_members;
+DROP POLICY IF EXISTS tenant_isolation ON projects;
+ALTER TABLE task_tags NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE task_tags DISABLE ROW LEVEL SECURITY;
+ALTER TABLE tasks NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE tasks DISABLE ROW LEVEL SECURITY;
+ALTER TABLE project_members NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE project_members DISABLE ROW LEVEL SECURITY;
+ALTER TABLE projects NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE projects DISABLE ROW LEVEL SECURITY;
+
+DELETE FROM task_tags WHERE tenant_id <> 'default';
+DELETE FROM tasks WHERE tenant_id <> 'default';
+DELETE FROM project_members WHERE tenant_id <> 'default';
+DELETE FROM projects WHERE tenant_id <> 'default';
+
+ALTER TABLE task_tags DROP CONSTRAINT IF EXISTS task_tags_tenant_id_project_id_task_id_fkey;
+ALTER TABLE task_tags DROP CONSTRAINT IF EXISTS task_tags_pkey;
+DROP INDEX IF EXISTS idx_task_tags_tag;
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_tenant_id_project_id_fkey;
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_pkey;
+ALTER TABLE project_members DROP CONSTRAINT IF EXISTS project_members_tenant_id_project_id_fkey;
+ALTER TABLE project_members DROP CONSTRAINT IF EXISTS project_members_pkey;
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_tenant_id_name_key;
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_pkey;
+
+ALTER TABLE task_tags DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE project_members DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE projects DROP COLUMN IF EXISTS tenant_id;
+
+ALTER TABLE projects ADD PRIMARY KEY (id);
+ALTER TABLE projects ADD UNIQUE (name);
+CREATE SEQUENCE IF NOT EXISTS projects_id_seq OWNED BY projects.id;
+SELECT setval('projects_id_seq', COALESCE((SELECT MAX(id) FROM projects), 0) + 1, false);
+ALTER TABLE projects ALTER COLUMN id SET DEFAULT nextval('projects_id_seq');
+ALTER TABLE project_members ADD PRIMARY KEY (project_id, user_id);
+ALTER TABLE project_members ADD FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE;
+ALTER TABLE tasks ADD PRIMARY KEY (project_id, id);
+ALTER TABLE tasks ADD FOREIGN KEY (project_id) REFERENCES projects(id);
+ALTER TABLE task_tags ADD PRIMARY KEY (project_id, task_id, tag);
+ALTER TABLE task_tags ADD FOREIGN KEY (project_id, task_id) REFERENCES tasks(project_id, id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS idx_task_tags_tag ON task_tags(project_id, tag, task_id);
+
+-- пользователи других арендаторов иначе увидели бы общие задачи
+DELETE FROM users WHERE tenant <> 'default';
+ALTER TABLE users DROP COLUMN IF EXISTS tenant;
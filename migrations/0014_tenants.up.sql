@@ -0,0 +1,65 @@
+-- арендаторы: у пользователя свой арендатор, у его данных — tenant_id под row-level security.
+-- Приложение выставляет app.tenant в каждой транзакции (см. repository.inTenantTx),
+-- и политики ниже не пускают к чужим строкам, даже если в запросе забыли фильтр.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant TEXT NOT NULL DEFAULT 'default';
+
+-- ключи переезжают на (tenant_id, ...): снимаем старые
+ALTER TABLE task_tags DROP CONSTRAINT IF EXISTS task_tags_project_id_task_id_fkey;
+ALTER TABLE task_tags DROP CONSTRAINT IF EXISTS task_tags_pkey;
+DROP INDEX IF EXISTS idx_task_tags_tag;
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_project_id_fkey;
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_pkey;
+ALTER TABLE project_members DROP CONSTRAINT IF EXISTS project_members_project_id_fkey;
+ALTER TABLE project_members DROP CONSTRAINT IF EXISTS project_members_pkey;
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_name_key;
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_pkey;
+-- ID проектов теперь свои у каждого арендатора, их выдаёт PostgresProjectStore
+ALTER TABLE projects ALTER COLUMN id DROP DEFAULT;
+DROP SEQUENCE IF EXISTS projects_id_seq;
+
+-- всё, что уже есть, — общего арендатора; новые строки берут его из app.tenant
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE project_members ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE task_tags ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE projects ALTER COLUMN tenant_id SET DEFAULT current_setting('app.tenant');
+ALTER TABLE project_members ALTER COLUMN tenant_id SET DEFAULT current_setting('app.tenant');
+ALTER TABLE tasks ALTER COLUMN tenant_id SET DEFAULT current_setting('app.tenant');
+ALTER TABLE task_tags ALTER COLUMN tenant_id SET DEFAULT current_setting('app.tenant');
+
+ALTER TABLE projects ADD PRIMARY KEY (tenant_id, id);
+ALTER TABLE projects ADD UNIQUE (tenant_id, name);
+ALTER TABLE project_members ADD PRIMARY KEY (tenant_id, project_id, user_id);
+ALTER TABLE project_members ADD FOREIGN KEY (tenant_id, project_id) REFERENCES projects(tenant_id, id) ON DELETE CASCADE;
+ALTER TABLE tasks ADD PRIMARY KEY (tenant_id, project_id, id);
+ALTER TABLE tasks ADD FOREIGN KEY (tenant_id, project_id) REFERENCES projects(tenant_id, id);
+ALTER TABLE task_tags ADD PRIMARY KEY (tenant_id, project_id, task_id, tag);
+ALTER TABLE task_tags ADD FOREIGN KEY (tenant_id, project_id, task_id) REFERENCES tasks(tenant_id, project_id, id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS idx_task_tags_tag ON task_tags(tenant_id, project_id, tag, task_id);
+
+-- FORCE — чтобы политики действовали и на владельца таблиц. Суперпользователь и роли
+-- с BYPASSRLS их всё равно обходят, поэтому приложению нужна обычная роль.
+-- Без app.tenant current_setting падает с ошибкой: запрос мимо inTenantTx не пройдёт вовсе.
+ALTER TABLE projects ENABLE ROW LEVEL SECURITY;
+ALTER TABLE projects FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON projects
+    USING (tenant_id = current_setting('app.tenant'))
+    WITH CHECK (tenant_id = current_setting('app.tenant'));
+
+ALTER TABLE project_members ENABLE ROW LEVEL SECURITY;
+ALTER TABLE project_members FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON project_members
+    USING (tenant_id = current_setting('app.tenant'))
+    WITH CHECK (tenant_id = current_setting('app.tenant'));
+
+ALTER TABLE tasks ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tasks FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON tasks
+    USING (tenant_id = current_setting('app.tenant'))
+    WITH CHECK (tenant_id = current_setting('app.tenant'));
+
+ALTER TABLE task_tags ENABLE ROW LEVEL SECURITY;
+ALTER TABLE task_tags FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON task_tags
+    USING (tenant_id = current_setting('app.tenant'))
+    WITH CHECK (tenant_id = current_setting('app.tenant'));
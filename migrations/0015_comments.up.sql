@@ -0,0 +1,21 @@
+-- комментарии к задачам. Внешнего ключа на tasks нет: при перенумерации задача
+-- удаляется и вставляется заново, комментарии за ней переносятся в той же транзакции
+-- (Store.Renumber), а вместе с задачей их удаляет сервис
+CREATE TABLE IF NOT EXISTS task_comments (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT current_setting('app.tenant'),
+    project_id INT NOT NULL,
+    task_id INT NOT NULL,
+    author INT REFERENCES users(id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    edited_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_task_comments_task ON task_comments(tenant_id, project_id, task_id, id);
+
+-- арендаторы — как у задач (см. 0014_tenants)
+ALTER TABLE task_comments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE task_comments FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON task_comments
+    USING (tenant_id = current_setting('app.tenant'))
+    WITH CHECK (tenant_id = current_setting('app.tenant'));
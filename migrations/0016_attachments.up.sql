@@ -0,0 +1,23 @@
+-- вложения задач: здесь только описание, содержимое — в хранилище файлов (диск, S3)
+-- под ключом blob_key. Внешнего ключа на tasks нет по той же причине, что и у
+-- task_comments: при перенумерации вложения переносятся в той же транзакции (Store.Renumber)
+CREATE TABLE IF NOT EXISTS task_attachments (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT current_setting('app.tenant'),
+    project_id INT NOT NULL,
+    task_id INT NOT NULL,
+    name TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    content_type TEXT NOT NULL,
+    checksum TEXT NOT NULL,
+    blob_key TEXT NOT NULL UNIQUE,
+    uploaded_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_task_attachments_task ON task_attachments(tenant_id, project_id, task_id, id);
+
+ALTER TABLE task_attachments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE task_attachments FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON task_attachments
+    USING (tenant_id = current_setting('app.tenant'))
+    WITH CHECK (tenant_id = current_setting('app.tenant'));
@@ -0,0 +1,3 @@
+-- чек-лист задачи: упорядоченный список пунктов [{"id":1,"text":"...","done":true}].
+-- Пункты живут и меняются только вместе с задачей, поэтому JSONB в самой строке
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS checklist JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,3 @@
+-- версия задачи для оптимистичных блокировок (ETag / If-Match).
+-- Приложение меняет строку только через UPDATE ... WHERE version = <ожидаемая>
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+-- корзина: удалённая задача остаётся строкой с deleted_at, пока её не восстановят
+-- или не вычистит фоновая очистка (TRASH_RETENTION)
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_tasks_deleted_at ON tasks(tenant_id, project_id, deleted_at) WHERE deleted_at IS NOT NULL;
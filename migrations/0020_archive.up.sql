@@ -0,0 +1,15 @@
+-- архив закрытых задач: отдельная таблица, сервис при старте её не читает.
+-- Колонки и поисковая search — как у tasks; теги прямо в строке, task_tags только для горячих
+CREATE TABLE IF NOT EXISTS tasks_archive (LIKE tasks INCLUDING DEFAULTS INCLUDING GENERATED);
+ALTER TABLE tasks_archive ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE tasks_archive ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE tasks_archive ADD PRIMARY KEY (tenant_id, project_id, id);
+CREATE INDEX IF NOT EXISTS idx_tasks_archive_search ON tasks_archive USING GIN (search);
+CREATE INDEX IF NOT EXISTS idx_tasks_archive_tags ON tasks_archive USING GIN (tags);
+
+-- арендаторы — как у задач (см. 0014_tenants)
+ALTER TABLE tasks_archive ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tasks_archive FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON tasks_archive
+    USING (tenant_id = current_setting('app.tenant'))
+    WITH CHECK (tenant_id = current_setting('app.tenant'));
@@ -0,0 +1,4 @@
+ALTER TABLE tasks_archive DROP COLUMN IF EXISTS detached_dependents;
+ALTER TABLE tasks_archive DROP COLUMN IF EXISTS detached_children;
+ALTER TABLE tasks DROP COLUMN IF EXISTS detached_dependents;
+ALTER TABLE tasks DROP COLUMN IF EXISTS detached_children;
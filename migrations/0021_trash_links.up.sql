@@ -0,0 +1,6 @@
+-- что Delete отцепил от задачи в корзине: поднятые подзадачи и зависимые, потерявшие блокер.
+-- Restore цепляет их обратно. У архива колонки те же, что у tasks (см. 0020_archive)
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS detached_children BIGINT[] NOT NULL DEFAULT '{}';
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS detached_dependents BIGINT[] NOT NULL DEFAULT '{}';
+ALTER TABLE tasks_archive ADD COLUMN IF NOT EXISTS detached_children BIGINT[] NOT NULL DEFAULT '{}';
+ALTER TABLE tasks_archive ADD COLUMN IF NOT EXISTS detached_dependents BIGINT[] NOT NULL DEFAULT '{}';